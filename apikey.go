@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+)
+
+var (
+	apiKeyCmd = &cobra.Command{
+		Use:   "apikey",
+		Short: "Manage API keys",
+	}
+	apiKeyGenerateCmd = &cobra.Command{
+		Use:   "generate",
+		Short: "Generate a new API key and the hash to put in the auth config",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, err := auth.GenerateAPIKey()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("key:  %s\n", key)
+			fmt.Printf("hash: %s\n", auth.HashAPIKey(key))
+			return nil
+		},
+	}
+	apiKeyHashCmd = &cobra.Command{
+		Use:   "hash <key>",
+		Short: "Print the hash of an existing API key",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Println(auth.HashAPIKey(args[0]))
+		},
+	}
+)
+
+func init() {
+	apiKeyCmd.AddCommand(apiKeyGenerateCmd, apiKeyHashCmd)
+	rootCmd.AddCommand(apiKeyCmd)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/audit"
+)
+
+var (
+	auditVerifyDir string
+
+	auditCmd = &cobra.Command{
+		Use:   "audit",
+		Short: "Inspect the transaction audit log",
+	}
+	auditVerifyCmd = &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the hash chain of the audit log",
+		// A broken chain is a finding, not a usage error
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := audit.Verify(auditVerifyDir)
+			if err != nil {
+				return fmt.Errorf("audit log verification failed: %w", err)
+			}
+			fmt.Printf("Audit log is intact: %d entries in %d files\n", result.Entries, result.Files)
+			fmt.Printf("Head: entry %d, hash %s\n", result.Head.Sequence, result.Head.Hash)
+			return nil
+		},
+	}
+)
+
+func init() {
+	auditVerifyCmd.Flags().StringVar(&auditVerifyDir, "dir", getEnvOrDefault("AUDIT_DIR", "audit"), "Directory containing the audit log")
+	auditCmd.AddCommand(auditVerifyCmd)
+	rootCmd.AddCommand(auditCmd)
+}
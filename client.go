@@ -0,0 +1,362 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/logging"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+var (
+	outputFormat string
+	verbose      bool
+	signAs       string
+
+	invokeCmd = &cobra.Command{
+		Use:   "invoke <chaincode> <function> [args...]",
+		Short: "Submit a transaction and wait for it to be committed",
+		Args:  cobra.MinimumNArgs(2),
+		// Failed transactions are findings, not usage errors
+		SilenceUsage: true,
+		RunE:         runInvoke,
+	}
+	queryCmd = &cobra.Command{
+		Use:          "query <chaincode> <function> [args...]",
+		Short:        "Evaluate a transaction without submitting it",
+		Args:         cobra.MinimumNArgs(2),
+		SilenceUsage: true,
+		RunE:         runQuery,
+	}
+	txCmd = &cobra.Command{
+		Use:          "tx <txid>",
+		Short:        "Show a transaction of the channel's ledger",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE:         runTx,
+	}
+	blockCmd = &cobra.Command{
+		Use:          "block <number|latest>",
+		Short:        "Show a block of the channel's ledger and its transactions",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE:         runBlock,
+	}
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{invokeCmd, queryCmd, txCmd, blockCmd} {
+		addFabricFlags(cmd.Flags())
+		cmd.Flags().StringVarP(&outputFormat, "output", "o", outputTable, "Output format: table or json")
+		cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Log the Fabric client's activity to stderr")
+		rootCmd.AddCommand(cmd)
+	}
+	for _, cmd := range []*cobra.Command{invokeCmd, queryCmd} {
+		cmd.Flags().StringVar(&signAs, "as", "", "Named identity to sign with instead of the default one")
+	}
+}
+
+// newCommandClient builds the Fabric client of a CLI command from its flags
+// and config file, and the context the command runs in
+func newCommandClient(cmd *cobra.Command) (context.Context, *fabric.FabricClient, func(), error) {
+	if outputFormat != outputTable && outputFormat != outputJSON {
+		return nil, nil, nil, fmt.Errorf("unknown output format %q (expected table or json)", outputFormat)
+	}
+	logOutput := io.Discard
+	if verbose {
+		logOutput = os.Stderr
+	}
+	if err := logging.Setup(logOutput, logging.Options{Level: "debug", Format: "text"}); err != nil {
+		return nil, nil, nil, err
+	}
+
+	cfg, err := buildConfig(cmd.Flags())
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	fabricClient, err := fabric.NewFabricClient(cfg.ClientConfig())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+	if signAs != "" {
+		if !fabricClient.HasIdentity(signAs) {
+			stop()
+			fabricClient.Close()
+			return nil, nil, nil, fmt.Errorf("identity %q is not configured", signAs)
+		}
+		ctx = fabric.WithIdentity(ctx, signAs)
+	}
+	return ctx, fabricClient, func() {
+		stop()
+		fabricClient.Close()
+	}, nil
+}
+
+// invokeOutput is the JSON output of the invoke command
+type invokeOutput struct {
+	TxID           string `json:"tx_id"`
+	BlockNumber    uint64 `json:"block_number"`
+	ValidationCode string `json:"validation_code"`
+	Success        bool   `json:"success"`
+	Result         any    `json:"result"`
+}
+
+func runInvoke(cmd *cobra.Command, args []string) error {
+	ctx, fabricClient, closeClient, err := newCommandClient(cmd)
+	if err != nil {
+		return err
+	}
+	defer closeClient()
+
+	result, err := fabricClient.InvokeTransaction(ctx, args[0], args[1], args[2:])
+	if err != nil {
+		return err
+	}
+	output := invokeOutput{
+		TxID:           result.TxID,
+		BlockNumber:    result.BlockNumber,
+		ValidationCode: fabric.ValidationCodeName(result.ResultCode),
+		Success:        result.Success,
+		Result:         resultValue(result.Result),
+	}
+	if outputFormat == outputJSON {
+		err = printJSON(output)
+	} else {
+		err = printFields([][2]string{
+			{"TX ID", output.TxID},
+			{"BLOCK", strconv.FormatUint(output.BlockNumber, 10)},
+			{"VALIDATION CODE", output.ValidationCode},
+			{"RESULT", string(result.Result)},
+		})
+	}
+	if err != nil {
+		return err
+	}
+	if !result.Success {
+		return fmt.Errorf("transaction %s was invalidated with %s", output.TxID, output.ValidationCode)
+	}
+	return nil
+}
+
+func runQuery(cmd *cobra.Command, args []string) error {
+	ctx, fabricClient, closeClient, err := newCommandClient(cmd)
+	if err != nil {
+		return err
+	}
+	defer closeClient()
+
+	result, err := fabricClient.EvaluateTransaction(ctx, args[0], args[1], args[2:])
+	if err != nil {
+		return err
+	}
+	if outputFormat == outputJSON {
+		return printJSON(resultValue(result))
+	}
+	return printResultTable(result)
+}
+
+func runTx(cmd *cobra.Command, args []string) error {
+	ctx, fabricClient, closeClient, err := newCommandClient(cmd)
+	if err != nil {
+		return err
+	}
+	defer closeClient()
+
+	tx, err := fabricClient.Transaction(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	if outputFormat == outputJSON {
+		return printJSON(tx)
+	}
+	return printFields([][2]string{
+		{"TX ID", tx.ID},
+		{"BLOCK", strconv.FormatUint(tx.BlockNumber, 10)},
+		{"INDEX", strconv.Itoa(tx.Index)},
+		{"TYPE", tx.Type},
+		{"VALIDATION CODE", tx.ValidationCode},
+		{"TIMESTAMP", formatTime(tx.Timestamp)},
+		{"CREATOR MSP", tx.CreatorMSP},
+		{"CREATOR", tx.CreatorSubject},
+		{"CHAINCODE", tx.Chaincode},
+		{"FUNCTION", tx.Function},
+		{"ARGS", strings.Join(tx.Args, " ")},
+		{"ENDORSERS", strings.Join(tx.Endorsers, ", ")},
+	})
+}
+
+func runBlock(cmd *cobra.Command, args []string) error {
+	ctx, fabricClient, closeClient, err := newCommandClient(cmd)
+	if err != nil {
+		return err
+	}
+	defer closeClient()
+
+	var number uint64
+	if args[0] == "latest" {
+		info, err := fabricClient.ChainInfo(ctx)
+		if err != nil {
+			return err
+		}
+		if info.Height == 0 {
+			return fmt.Errorf("the channel has no blocks")
+		}
+		number = info.Height - 1
+	} else if number, err = strconv.ParseUint(args[0], 10, 64); err != nil {
+		return fmt.Errorf("invalid block number %q", args[0])
+	}
+
+	block, err := fabricClient.Block(ctx, number)
+	if err != nil {
+		return err
+	}
+	if outputFormat == outputJSON {
+		return printJSON(block)
+	}
+
+	if err := printFields([][2]string{
+		{"BLOCK", strconv.FormatUint(block.Number, 10)},
+		{"DATA HASH", block.DataHash},
+		{"PREVIOUS HASH", block.PreviousHash},
+		{"TRANSACTIONS", strconv.Itoa(len(block.Transactions))},
+	}); err != nil {
+		return err
+	}
+	fmt.Println()
+	rows := [][]string{{"INDEX", "TX ID", "TYPE", "VALIDATION CODE", "CREATOR MSP", "CHAINCODE", "FUNCTION", "TIMESTAMP"}}
+	for _, tx := range block.Transactions {
+		rows = append(rows, []string{
+			strconv.Itoa(tx.Index), tx.ID, tx.Type, tx.ValidationCode, tx.CreatorMSP, tx.Chaincode, tx.Function, formatTime(tx.Timestamp),
+		})
+	}
+	return printTable(rows)
+}
+
+// resultValue returns a chaincode result as JSON when it is valid JSON and
+// as a string otherwise
+func resultValue(result []byte) any {
+	if json.Valid(result) {
+		return json.RawMessage(result)
+	}
+	return string(result)
+}
+
+// printResultTable prints a query result as a table when it is a JSON object
+// or an array of objects, and as is otherwise
+func printResultTable(result []byte) error {
+	var objects []map[string]any
+	if err := json.Unmarshal(result, &objects); err == nil && len(objects) > 0 {
+		columns := map[string]bool{}
+		for _, object := range objects {
+			for key := range object {
+				columns[key] = true
+			}
+		}
+		header := make([]string, 0, len(columns))
+		for key := range columns {
+			header = append(header, key)
+		}
+		sort.Strings(header)
+
+		rows := [][]string{make([]string, len(header))}
+		for i, key := range header {
+			rows[0][i] = strings.ToUpper(key)
+		}
+		for _, object := range objects {
+			row := make([]string, len(header))
+			for i, key := range header {
+				if value, ok := object[key]; ok {
+					row[i] = formatValue(value)
+				}
+			}
+			rows = append(rows, row)
+		}
+		return printTable(rows)
+	}
+
+	var object map[string]any
+	if err := json.Unmarshal(result, &object); err == nil {
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fields := make([][2]string, 0, len(keys))
+		for _, key := range keys {
+			fields = append(fields, [2]string{strings.ToUpper(key), formatValue(object[key])})
+		}
+		return printFields(fields)
+	}
+
+	_, err := fmt.Println(string(result))
+	return err
+}
+
+// formatValue formats a decoded JSON value for a table cell
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]any, []any:
+		var buf bytes.Buffer
+		if err := json.NewEncoder(&buf).Encode(v); err != nil {
+			return fmt.Sprint(v)
+		}
+		return strings.TrimSpace(buf.String())
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// printFields prints name/value pairs as two aligned columns
+func printFields(fields [][2]string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, field := range fields {
+		fmt.Fprintf(w, "%s:\t%s\n", field[0], field[1])
+	}
+	return w.Flush()
+}
+
+// printTable prints rows as aligned columns, the first row being the header
+func printTable(rows [][]string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
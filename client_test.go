@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestResultValue(t *testing.T) {
+	if _, ok := resultValue([]byte(`{"id":"asset1"}`)).(json.RawMessage); !ok {
+		t.Error("resultValue() of a JSON object is not raw JSON")
+	}
+	if got := resultValue([]byte("not json")); got != "not json" {
+		t.Errorf("resultValue() = %v, want the string", got)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		value any
+		want  string
+	}{
+		{nil, ""},
+		{"blue", "blue"},
+		{float64(5), "5"},
+		{true, "true"},
+		{map[string]any{"a": float64(1)}, `{"a":1}`},
+		{[]any{"x", "y"}, `["x","y"]`},
+	}
+	for _, tt := range tests {
+		if got := formatValue(tt.value); got != tt.want {
+			t.Errorf("formatValue(%v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+	if got := formatTime(time.Time{}); got != "" {
+		t.Errorf("formatTime() of the zero time = %q, want empty", got)
+	}
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/config"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/logging"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/ratelimit"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/tlsconfig"
+)
+
+var (
+	configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration file",
+	}
+	configValidateCmd = &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the configuration file and the files it references",
+		Long: "Validates the structure of the configuration file, then checks that the " +
+			"certificates, keys and other files it references exist and parse.",
+		Args: cobra.NoArgs,
+		// An invalid configuration is a finding, not a usage error
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if configPath == "" {
+				return fmt.Errorf("--config is required")
+			}
+			cfg, err := config.Load(configPath)
+			if err != nil {
+				return err
+			}
+			if err := cfg.Validate(); err != nil {
+				return fmt.Errorf("invalid configuration:\n%w", err)
+			}
+			if err := cfg.CheckFiles(); err != nil {
+				return fmt.Errorf("invalid configuration:\n%w", err)
+			}
+			fmt.Printf("Configuration %s is valid\n", configPath)
+			return nil
+		},
+	}
+)
+
+func init() {
+	configValidateCmd.Flags().StringVar(&configPath, "config", getEnvOrDefault("CONFIG_FILE", ""), "Path to the YAML config file")
+	configCmd.AddCommand(configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// buildConfig assembles the serve configuration. Without --config every
+// setting comes from the flags and their environment variables; with it, the
+// file is used and only the flags given on the command line override it.
+func buildConfig(flags *pflag.FlagSet) (*config.Config, error) {
+	cfg := config.Default()
+	fromFile := configPath != ""
+	if fromFile {
+		var err error
+		if cfg, err = config.Load(configPath); err != nil {
+			return nil, err
+		}
+	}
+	set := func(name string) bool {
+		return !fromFile || flags.Changed(name)
+	}
+
+	if set("port") {
+		cfg.Server.Port = port
+	}
+	if set("grpc-port") {
+		cfg.GRPC.Port = grpcPort
+	}
+	if set("tls-cert") {
+		cfg.Server.TLS.Cert = tlsCert
+	}
+	if set("tls-key") {
+		cfg.Server.TLS.Key = tlsKey
+	}
+	if set("tls-client-ca") {
+		cfg.Server.TLS.ClientCA = tlsClientCA
+	}
+	if set("tls-client-auth") {
+		cfg.Server.TLS.ClientAuth = tlsClientAuth
+	}
+	if set("probe-port") {
+		cfg.Server.ProbePort = probePort
+	}
+	if set("max-body-size") {
+		cfg.Server.MaxBodySizeMB = maxBodySizeMB
+	}
+
+	if set("mspid") {
+		cfg.Fabric.MspID = mspID
+	}
+	if set("cert") {
+		cfg.Fabric.Cert = certPath
+	}
+	if set("key") {
+		cfg.Fabric.Key = keyPath
+	}
+	if set("channel") {
+		cfg.Fabric.Channel = channelName
+	}
+	if (set("peers") || set("tlscerts")) && (peerEndpoints != "" || tlsCertPaths != "") {
+		peers := strings.Split(peerEndpoints, ",")
+		tlsCerts := strings.Split(tlsCertPaths, ",")
+		if len(peers) != len(tlsCerts) {
+			return nil, fmt.Errorf("number of peer endpoints (%d) must match number of TLS certificates (%d)", len(peers), len(tlsCerts))
+		}
+		cfg.Fabric.Peers = nil
+		for i := range peers {
+			cfg.Fabric.Peers = append(cfg.Fabric.Peers, config.Peer{
+				Endpoint: strings.TrimSpace(peers[i]),
+				TLSCert:  strings.TrimSpace(tlsCerts[i]),
+			})
+		}
+	}
+	if set("identity") {
+		identityConfigs, err := parseIdentities(identities)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse identities: %w", err)
+		}
+		cfg.Fabric.Identities = make(map[string]config.Identity, len(identityConfigs))
+		for name, id := range identityConfigs {
+			cfg.Fabric.Identities[name] = config.Identity{MspID: id.MspID, Cert: id.CertPath, Key: id.KeyPath}
+		}
+	}
+
+	if set("auth-config") && authConfigPath != "" {
+		authConfig, err := auth.LoadConfig(authConfigPath)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Auth = authConfig
+	}
+	if set("rate-limit-config") && rateLimitConfigPath != "" {
+		rateLimitConfig, err := ratelimit.LoadConfig(rateLimitConfigPath)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RateLimits = rateLimitConfig
+	}
+
+	if set("batch-parallelism") {
+		cfg.Batch.Parallelism = batchParallelism
+	}
+	if set("batch-max-operations") {
+		cfg.Batch.MaxOperations = batchMaxOperations
+	}
+	if set("idempotency-store") {
+		cfg.Idempotency.Store = idempotencyStore
+	}
+	if set("idempotency-db") {
+		cfg.Idempotency.DB = idempotencyDB
+	}
+	if set("idempotency-ttl") {
+		cfg.Idempotency.TTL = idempotencyTTL
+	}
+	if set("audit-dir") {
+		cfg.Audit.Dir = auditDir
+	}
+	if set("audit-max-size") {
+		cfg.Audit.MaxSizeMB = auditMaxSizeMB
+	}
+	if set("audit-anchor-chaincode") {
+		cfg.Audit.Anchor.Chaincode = auditAnchorChaincode
+	}
+	if set("audit-anchor-function") {
+		cfg.Audit.Anchor.Function = auditAnchorFunction
+	}
+	if set("audit-anchor-interval") {
+		cfg.Audit.Anchor.Interval = auditAnchorInterval
+	}
+	if set("schema-dir") {
+		cfg.Schemas.Dir = schemaDir
+	}
+	if set("contract-chaincodes") {
+		cfg.Contracts.Chaincodes = contractChaincodes
+	}
+	if set("contract-refresh") {
+		cfg.Contracts.Refresh = contractRefresh
+	}
+	if set("offline-signing") {
+		cfg.Offline.Enabled = offlineSigning
+	}
+	if set("offline-signing-ttl") {
+		cfg.Offline.TTL = offlineSigningTTL
+	}
+	if set("indexer-db") {
+		cfg.Indexer.DB = indexerDB
+	}
+	if set("indexer-start-block") {
+		cfg.Indexer.StartBlock = indexerStartBlock
+	}
+	if set("outbox-db") {
+		cfg.Outbox.DB = outboxDB
+	}
+	if set("outbox-min-backoff") {
+		cfg.Outbox.MinBackoff = outboxMinBackoff
+	}
+	if set("outbox-max-backoff") {
+		cfg.Outbox.MaxBackoff = outboxMaxBackoff
+	}
+	if set("outbox-retention") {
+		cfg.Outbox.Retention = outboxRetention
+	}
+	if set("webhook-db") {
+		cfg.Webhooks.DB = webhookDB
+	}
+	if set("webhook-max-attempts") {
+		cfg.Webhooks.MaxAttempts = webhookMaxAttempts
+	}
+	if set("webhook-timeout") {
+		cfg.Webhooks.Timeout = webhookTimeout
+	}
+	if set("webhook-min-backoff") {
+		cfg.Webhooks.MinBackoff = webhookMinBackoff
+	}
+	if set("webhook-max-backoff") {
+		cfg.Webhooks.MaxBackoff = webhookMaxBackoff
+	}
+	if set("webhook-allowed-networks") {
+		cfg.Webhooks.AllowedNetworks = webhookNetworks
+	}
+	if set("shutdown-timeout") {
+		cfg.Shutdown.Timeout = shutdownTimeout
+	}
+	if set("shutdown-delay") {
+		cfg.Shutdown.Delay = shutdownDelay
+	}
+	if set("ready-min-peers") {
+		cfg.Health.ReadyMinPeers = readyMinPeers
+	}
+	if set("health-check-interval") {
+		cfg.Health.Interval = healthCheckInterval
+	}
+	if set("health-check-timeout") {
+		cfg.Health.Timeout = healthCheckTimeout
+	}
+	if set("log-level") {
+		cfg.Logging.Level = logLevel
+	}
+	if set("log-format") {
+		cfg.Logging.Format = logFormat
+	}
+	if set("log-sensitive") {
+		cfg.Logging.Sensitive = logSensitive
+	}
+	if set("trace-exporter") {
+		cfg.Tracing.Exporter = traceExporter
+	}
+	if set("trace-file") {
+		cfg.Tracing.File = traceFile
+	}
+	if set("trace-sample-ratio") {
+		cfg.Tracing.SampleRatio = traceSampleRatio
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// configReloader applies the settings that can change at runtime when the
+// server receives SIGHUP: authentication, rate limits, the server TLS
+// certificates and the log level and redaction, for the default network and
+// the additional ones
+type configReloader struct {
+	flags        *pflag.FlagSet
+	current      *config.Config
+	fabricClient *fabric.FabricClient
+	authChain    *auth.Chain
+	limiter      *ratelimit.Limiter
+	tlsReloader  *tlsconfig.Reloader
+	networks     []*network
+}
+
+// reload re-reads the configuration and applies it only if all of it is valid
+func (r *configReloader) reload() error {
+	next, err := buildConfig(r.flags)
+	if err != nil {
+		return err
+	}
+
+	if (next.Auth != nil) != (r.current.Auth != nil) {
+		return fmt.Errorf("enabling or disabling authentication requires a restart")
+	}
+	if (next.RateLimits != nil) != (r.current.RateLimits != nil) {
+		return fmt.Errorf("enabling or disabling rate limits requires a restart")
+	}
+	var authenticators []auth.Authenticator
+	if next.Auth != nil {
+		if authenticators, err = next.Auth.Authenticators(); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+		for _, name := range next.Auth.Identities() {
+			if !r.fabricClient.HasIdentity(name) {
+				return fmt.Errorf("auth references identity %q which is not loaded; adding identities requires a restart", name)
+			}
+		}
+	}
+	networkAuthenticators := make(map[string][]auth.Authenticator, len(r.networks))
+	for _, n := range r.networks {
+		if _, ok := next.Networks[n.name]; !ok {
+			return fmt.Errorf("removing network %s requires a restart", n.name)
+		}
+		if (next.NetworkRateLimits(n.name) != nil) != (n.limiter != nil) {
+			return fmt.Errorf("enabling or disabling rate limits of network %s requires a restart", n.name)
+		}
+		authCfg := next.NetworkAuth(n.name)
+		if (authCfg != nil) != (n.authChain != nil) {
+			return fmt.Errorf("enabling or disabling authentication of network %s requires a restart", n.name)
+		}
+		if authCfg == nil {
+			continue
+		}
+		if networkAuthenticators[n.name], err = authCfg.Authenticators(); err != nil {
+			return fmt.Errorf("networks.%s.auth: %w", n.name, err)
+		}
+		for _, name := range authCfg.Identities() {
+			if !n.fabricClient.HasIdentity(name) {
+				return fmt.Errorf("network %s: auth references identity %q which is not loaded; adding identities requires a restart", n.name, name)
+			}
+		}
+	}
+	if r.tlsReloader != nil {
+		if err := r.tlsReloader.Reload(); err != nil {
+			return fmt.Errorf("tls: %w", err)
+		}
+	}
+	if err := logging.SetLevel(next.Logging.Level); err != nil {
+		return err
+	}
+
+	logging.SetSensitive(next.Logging.Sensitive)
+	if r.authChain != nil {
+		r.authChain.Set(authenticators)
+	}
+	if r.limiter != nil {
+		r.limiter.SetConfig(*next.RateLimits)
+	}
+	for _, n := range r.networks {
+		if n.authChain != nil {
+			n.authChain.Set(networkAuthenticators[n.name])
+		}
+		if n.limiter != nil {
+			n.limiter.SetConfig(*next.NetworkRateLimits(n.name))
+		}
+	}
+
+	if !reflect.DeepEqual(withoutReloadable(r.current), withoutReloadable(next)) {
+		slog.Warn("the configuration changed in settings that are only applied after a restart")
+	}
+	r.current.Auth = next.Auth
+	r.current.RateLimits = next.RateLimits
+	for name, network := range r.current.Networks {
+		network.Auth = next.Networks[name].Auth
+		network.RateLimits = next.Networks[name].RateLimits
+		r.current.Networks[name] = network
+	}
+	r.current.Logging.Level = next.Logging.Level
+	r.current.Logging.Sensitive = next.Logging.Sensitive
+	return nil
+}
+
+// withoutReloadable returns a copy of cfg without the settings applied on reload
+func withoutReloadable(cfg *config.Config) config.Config {
+	c := *cfg
+	c.Auth = nil
+	c.RateLimits = nil
+	c.Networks = make(map[string]config.Network, len(cfg.Networks))
+	for name, network := range cfg.Networks {
+		network.Auth = nil
+		network.RateLimits = nil
+		c.Networks[name] = network
+	}
+	c.Logging.Level = ""
+	c.Logging.Sensitive = false
+	return c
+}
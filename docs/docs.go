@@ -15,9 +15,17 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/evaluate": {
+        "/api/batch": {
             "post": {
-                "description": "Evaluates a transaction on the Hyperledger Fabric network without committing it",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Executes many invoke and evaluate operations concurrently and returns per-operation results in request order. Operations calling functions with a registered schema are validated before any operation runs, and the batch is rejected with the invalid fields of the first invalid operation.",
                 "consumes": [
                     "application/json"
                 ],
@@ -27,16 +35,121 @@ const docTemplate = `{
                 "tags": [
                     "transactions"
                 ],
-                "summary": "Evaluate a chaincode transaction",
+                "summary": "Execute a batch of chaincode transactions",
                 "parameters": [
                     {
-                        "description": "Transaction Request",
+                        "description": "Batch Request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.TransactionRequest"
+                            "$ref": "#/definitions/api.BatchRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key used to deduplicate retries; the first outcome is replayed for the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.BatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chaincodes/{cc}/contracts/{contract}/{fn}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Submits or evaluates a transaction of a contract, as marked in the chaincode's metadata, taking its parameters by name instead of as positional arguments. Only the chaincodes configured for contract routes are served; each has its own OpenAPI document at /swagger/chaincodes/{cc}/doc.json.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "contracts"
+                ],
+                "summary": "Call a contract transaction with named parameters",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chaincode name",
+                        "name": "cc",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Contract name",
+                        "name": "contract",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Transaction name",
+                        "name": "fn",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Parameters of the transaction by name",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key used to deduplicate retries of submitted transactions; the first outcome is replayed for the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -46,24 +159,227 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.TransactionResponse"
                         }
                     },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/api.TransactionResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.TransactionResponse"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/invoke": {
+        "/api/chaincodes/{cc}/keys/{key}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the value of a key of the chaincode's state after block at_block was committed, or as of at_time, with the transaction that set it. Without either, the value after the last indexed block is returned. is_delete is true when the key had been deleted. at_time compares the timestamps the clients set on their transactions. A block or time after the latest indexed transaction answers 409, and one before the first indexed block 400.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "index"
+                ],
+                "summary": "Get the value of a key at a point in time",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chaincode name",
+                        "name": "cc",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key, URL encoded",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Block number",
+                        "name": "at_block",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timestamp (RFC 3339)",
+                        "name": "at_time",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/indexer.StateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chaincodes/{cc}/keys/{key}/history": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the writes and deletes of a key of the chaincode's state by valid transactions, newest first, with the writing transaction and its creator. The history is built from the write sets of the indexed blocks and does not need GetHistoryForKey support of the chaincode. Values are base64 encoded.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "index"
+                ],
+                "summary": "Get the history of a key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chaincode name",
+                        "name": "cc",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key, URL encoded",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "asc for oldest first",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Page size, at most 500",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/indexer.HistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/evaluate": {
             "post": {
-                "description": "Invokes a transaction on the Hyperledger Fabric network",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Evaluates a transaction on the Hyperledger Fabric network without committing it. Requests to functions with a registered schema are validated first and rejected with the invalid fields.",
                 "consumes": [
                     "application/json"
                 ],
@@ -73,7 +389,7 @@ const docTemplate = `{
                 "tags": [
                     "transactions"
                 ],
-                "summary": "Invoke a chaincode transaction",
+                "summary": "Evaluate a chaincode transaction",
                 "parameters": [
                     {
                         "description": "Transaction Request",
@@ -98,6 +414,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.TransactionResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -106,77 +440,2505 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.TransactionRequest": {
-            "description": "Transaction request structure for invoking or evaluating chaincode",
-            "type": "object",
-            "properties": {
-                "args": {
-                    "description": "Arguments to pass to the chaincode function",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
+        },
+        "/api/index/status": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
                     },
-                    "example": [
-                        "[\"asset1\"",
-                        "\"value1\"]"
-                    ]
-                },
-                "chaincode_name": {
-                    "description": "Name of the chaincode to invoke",
-                    "type": "string",
-                    "example": "mycc"
-                },
-                "function": {
-                    "description": "Function name to call in the chaincode",
-                    "type": "string",
-                    "example": "createAsset"
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the next block to index and the number of indexed transactions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "index"
+                ],
+                "summary": "Get the progress of the indexer",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/indexer.Status"
+                        }
+                    }
                 }
             }
         },
-        "api.TransactionResponse": {
-            "description": "Response structure for chaincode transactions",
-            "type": "object",
-            "properties": {
-                "block_number": {
-                    "description": "Block number where the transaction was committed",
-                    "type": "integer",
-                    "example": 123
-                },
-                "error": {
-                    "description": "Error message (if failed)",
-                    "type": "string",
-                    "example": "Invalid arguments"
-                },
+        "/api/index/transactions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the transactions of the local index matching every given filter, newest first. Callers need a scope that allows evaluating the given chaincode, or any chaincode of the channel when no chaincode is given. Transactions calling functions outside the caller's scopes are left out, so a page may hold fewer than limit transactions.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "index"
+                ],
+                "summary": "Search indexed transactions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chaincode name",
+                        "name": "chaincode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Function name",
+                        "name": "function",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "MSP ID of the creator",
+                        "name": "creator_mspid",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Certificate subject of the creator",
+                        "name": "creator_subject",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Validation code, e.g. VALID or MVCC_READ_CONFLICT",
+                        "name": "validation_code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Earliest transaction timestamp (RFC 3339), inclusive",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Latest transaction timestamp (RFC 3339), exclusive",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "asc for oldest first",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Page size, at most 500",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/indexer.SearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/invoke": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Invokes a transaction on the Hyperledger Fabric network. When the transaction was sent for ordering but its commit status could not be obtained, the response is 202 with the status submitted and the transaction ID; the transaction may still be committed and must not be sent again. Requests to functions with a registered schema are validated before endorsement and rejected with the invalid fields. When the outbox is enabled, requests with the header Prefer: respond-async are stored and answered with 202 and the status queued; they are submitted in order in the background and their outcome is looked up under /api/outbox/{id}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Invoke a chaincode transaction",
+                "parameters": [
+                    {
+                        "description": "Transaction Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key used to deduplicate retries; the first outcome is replayed for the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "respond-async to queue the invoke in the outbox",
+                        "name": "Prefer",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/offline/transactions": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates the proposal of a transaction for the holder of the given certificate and returns its digest, which the client signs with its own key for the endorse step. The handle expires when no step follows within the configured time. Requests to functions with a registered schema are validated first and rejected with the invalid fields.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "offline"
+                ],
+                "summary": "Prepare a transaction for offline signing",
+                "parameters": [
+                    {
+                        "description": "Transaction and signer",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/offline.PrepareRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/offline.StepResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/offline.InvalidArgumentsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/offline/transactions/{handle}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the step an offline transaction waits for and the digest to sign for it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "offline"
+                ],
+                "summary": "Get an offline transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Handle of the prepared transaction",
+                        "name": "handle",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/offline.StepResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Releases the handle of an offline transaction that will not be completed",
+                "tags": [
+                    "offline"
+                ],
+                "summary": "Discard an offline transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Handle of the prepared transaction",
+                        "name": "handle",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/offline/transactions/{handle}/commit": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Waits for the commit of the submitted transaction with the commit status request signed by the client and releases the handle",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "offline"
+                ],
+                "summary": "Get the commit status of an offline transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Handle of the prepared transaction",
+                        "name": "handle",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Signature of the commit status request digest",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/offline.SignRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/offline.CommitResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/offline/transactions/{handle}/endorse": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Endorses the proposal with the client's signature of the proposal digest and returns the chaincode response and the transaction digest to sign for the submit step",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "offline"
+                ],
+                "summary": "Endorse an offline transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Handle of the prepared transaction",
+                        "name": "handle",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Signature of the proposal digest",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/offline.SignRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/offline.StepResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/offline/transactions/{handle}/submit": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sends the endorsed transaction signed by the client for ordering and returns the digest of the commit status request to sign for the commit step",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "offline"
+                ],
+                "summary": "Submit an offline transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Handle of the prepared transaction",
+                        "name": "handle",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Signature of the transaction digest",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/offline.SignRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/offline.StepResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/outbox": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the invokes waiting to be submitted, in submission order. Callers see the invokes they queued; callers allowed to invoke every chaincode of the channel see all of them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "outbox"
+                ],
+                "summary": "List queued invokes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/outbox.Item"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/outbox/dead-letters": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the invokes that failed permanently, oldest failure first: the chaincode rejected them, their transaction was invalidated, or the outcome of their submitted transaction could not be found",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "outbox"
+                ],
+                "summary": "List failed invokes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/outbox.Item"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/outbox/dead-letters/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "outbox"
+                ],
+                "summary": "Discard a failed invoke",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Outbox item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/outbox/dead-letters/{id}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Moves a failed invoke to the end of the queue. It is submitted as a new transaction.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "outbox"
+                ],
+                "summary": "Retry a failed invoke",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Outbox item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/outbox.Item"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/outbox/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a queued invoke with its attempts, or the transaction it was committed with. Committed invokes are kept for the configured retention.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "outbox"
+                ],
+                "summary": "Get the status of a queued invoke",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Outbox item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/outbox.Item"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/quota": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the rate limits that apply to the calling client and how much of them is currently used",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quotas"
+                ],
+                "summary": "Get the caller's quota usage",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ratelimit.Usage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/simulate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Endorses a transaction like /api/invoke but never submits it for ordering, returning the chaincode result, the keys read and written per namespace, the events it would emit and the endorsing peers. The ledger is not changed; the read versions show which keys would conflict with concurrent updates.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Simulate a chaincode transaction",
+                "parameters": [
+                    {
+                        "description": "Transaction Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.SimulationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the caller's subscriptions without their secrets",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook subscriptions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/webhook.Subscription"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delivers the chaincode's events whose name matches event_pattern to url as signed POST requests. The secret is only returned in this response. The url must not address a loopback, private or link-local network unless the server allows it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create a webhook subscription",
+                "parameters": [
+                    {
+                        "description": "Subscription",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/webhook.CreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Subscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/webhooks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a subscription with its checkpoint and the number of dead letters",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get a webhook subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.SubscriptionStatus"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stops the deliveries of a subscription and removes its checkpoint and dead letters",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete a webhook subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/webhooks/{id}/dead-letters": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the events that could not be delivered within the configured attempts, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List the dead letters of a webhook subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/webhook.DeadLetter"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/webhooks/{id}/dead-letters/redeliver": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Tries every dead letter once, oldest first. Delivered letters are removed; the others keep their place with the new error.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Redeliver all dead letters of a webhook subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.RedeliverResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/webhooks/{id}/dead-letters/{letter}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Discard a dead letter",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Dead letter ID",
+                        "name": "letter",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/webhooks/{id}/dead-letters/{letter}/redeliver": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Tries to deliver a dead letter once. The letter is removed when the receiver acknowledges it.",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Redeliver a dead letter",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Dead letter ID",
+                        "name": "letter",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/graphql": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Executes GraphQL queries for ledger info, blocks, transactions and chaincode evaluation, and the invoke mutation. Subscriptions are served on the same path over WebSocket with the graphql-transport-ws protocol.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graphql"
+                ],
+                "summary": "Execute a GraphQL query or mutation",
+                "parameters": [
+                    {
+                        "description": "GraphQL Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/graphqlapi.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/graphqlapi.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/graphqlapi.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/graphqlapi.Response"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/graphqlapi.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/livez": {
+            "get": {
+                "description": "Reports that the process is running and able to serve HTTP requests",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Check"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Reports whether the identities load, enough peers are reachable and the channel is accessible, with the state of every peer",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.BatchOperation": {
+            "description": "Single invoke or evaluate operation of a batch request",
+            "type": "object",
+            "properties": {
+                "args": {
+                    "description": "Arguments to pass to the chaincode function",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "[\"asset1\"]"
+                    ]
+                },
+                "chaincode_name": {
+                    "description": "Name of the chaincode to invoke",
+                    "type": "string",
+                    "example": "mycc"
+                },
+                "function": {
+                    "description": "Function name to call in the chaincode",
+                    "type": "string",
+                    "example": "readAsset"
+                },
+                "type": {
+                    "description": "Operation type (\"invoke\" or \"evaluate\")",
+                    "type": "string",
+                    "example": "evaluate"
+                }
+            }
+        },
+        "api.BatchRequest": {
+            "description": "Batch of invoke and evaluate operations",
+            "type": "object",
+            "properties": {
+                "operations": {
+                    "description": "Operations to execute, results are returned in the same order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.BatchOperation"
+                    }
+                },
+                "parallelism": {
+                    "description": "Maximum number of operations executed concurrently, capped by the server limit",
+                    "type": "integer",
+                    "example": 5
+                },
+                "stop_on_error": {
+                    "description": "Skip the operations that have not started yet once an invoke fails",
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "api.BatchResponse": {
+            "description": "Per-operation results of a batch request, in request order",
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "description": "Number of operations that failed",
+                    "type": "integer",
+                    "example": 0
+                },
+                "results": {
+                    "description": "Result of each operation, in the same order as the request",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.TransactionResponse"
+                    }
+                },
+                "skipped": {
+                    "description": "Number of operations that were not executed",
+                    "type": "integer",
+                    "example": 0
+                },
+                "status": {
+                    "description": "Status of the batch (\"success\" when every operation succeeded, \"partial_failure\" otherwise)",
+                    "type": "string",
+                    "example": "success"
+                },
+                "succeeded": {
+                    "description": "Number of operations that succeeded",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "api.SimulationResponse": {
+            "description": "Endorsement results of a transaction that was not submitted",
+            "type": "object",
+            "properties": {
+                "endorsers": {
+                    "description": "Peers that endorsed the transaction",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fabric.Endorser"
+                    }
+                },
+                "events": {
+                    "description": "Chaincode events the transaction would emit",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fabric.SimulatedEvent"
+                    }
+                },
+                "read_write_sets": {
+                    "description": "Keys read and written per namespace; values are base64 encoded",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fabric.ReadWriteSet"
+                    }
+                },
+                "result": {
+                    "description": "Result of the chaincode function",
+                    "type": "string",
+                    "example": "{\"key\":\"value\"}"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                },
+                "tx_id": {
+                    "description": "ID the transaction would have been submitted with",
+                    "type": "string",
+                    "example": "tx123"
+                }
+            }
+        },
+        "api.TransactionRequest": {
+            "description": "Transaction request structure for invoking or evaluating chaincode",
+            "type": "object",
+            "properties": {
+                "args": {
+                    "description": "Arguments to pass to the chaincode function",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "[\"asset1\"",
+                        "\"value1\"]"
+                    ]
+                },
+                "chaincode_name": {
+                    "description": "Name of the chaincode to invoke",
+                    "type": "string",
+                    "example": "mycc"
+                },
+                "function": {
+                    "description": "Function name to call in the chaincode",
+                    "type": "string",
+                    "example": "createAsset"
+                },
+                "transient": {
+                    "description": "Transient data passed to the chaincode but not recorded on the ledger",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.TransactionResponse": {
+            "description": "Response structure for chaincode transactions",
+            "type": "object",
+            "properties": {
+                "block_number": {
+                    "description": "Block number where the transaction was committed",
+                    "type": "integer",
+                    "example": 123
+                },
+                "error": {
+                    "description": "Error message (if failed)",
+                    "type": "string",
+                    "example": "Invalid arguments"
+                },
+                "fields": {
+                    "description": "Invalid request fields, when the arguments do not match the function's schema",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.FieldError"
+                    }
+                },
+                "outbox_id": {
+                    "description": "ID of the queued invoke, used to look up its status",
+                    "type": "string",
+                    "example": "4f7d0c1e9b2a43d58c6e1f0a2b3c4d5e"
+                },
                 "result": {
                     "description": "Result of the transaction (if successful)",
                     "type": "string",
-                    "example": "{\"key\":\"value\"}"
+                    "example": "{\"key\":\"value\"}"
+                },
+                "result_code": {
+                    "description": "Result code from the chaincode",
+                    "type": "integer",
+                    "example": 200
+                },
+                "status": {
+                    "description": "Status of the transaction (\"success\" or \"error\", \"skipped\" for batch operations that did not run, \"queued\" for invokes accepted into the outbox, \"submitted\" for invokes sent for ordering whose commit status is unknown)",
+                    "type": "string",
+                    "example": "success"
+                },
+                "success": {
+                    "description": "Whether the transaction was successful",
+                    "type": "boolean",
+                    "example": true
+                },
+                "tx_id": {
+                    "description": "Transaction ID",
+                    "type": "string",
+                    "example": "tx123"
+                }
+            }
+        },
+        "fabric.ChaincodeEvent": {
+            "type": "object",
+            "properties": {
+                "block_number": {
+                    "type": "integer"
+                },
+                "chaincode_name": {
+                    "type": "string"
+                },
+                "event_name": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "tx_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "fabric.CollectionReadWriteSet": {
+            "type": "object",
+            "properties": {
+                "collection": {
+                    "type": "string"
+                },
+                "reads": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fabric.HashedKVRead"
+                    }
+                },
+                "writes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fabric.HashedKVWrite"
+                    }
+                }
+            }
+        },
+        "fabric.Endorser": {
+            "type": "object",
+            "properties": {
+                "mspid": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                }
+            }
+        },
+        "fabric.HashedKVRead": {
+            "type": "object",
+            "properties": {
+                "key_hash": {
+                    "type": "string"
+                },
+                "version": {
+                    "$ref": "#/definitions/fabric.Version"
+                }
+            }
+        },
+        "fabric.HashedKVWrite": {
+            "type": "object",
+            "properties": {
+                "is_delete": {
+                    "type": "boolean"
+                },
+                "is_purge": {
+                    "type": "boolean"
+                },
+                "key_hash": {
+                    "type": "string"
+                },
+                "value_hash": {
+                    "type": "string"
+                }
+            }
+        },
+        "fabric.KVRead": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string"
+                },
+                "version": {
+                    "$ref": "#/definitions/fabric.Version"
+                }
+            }
+        },
+        "fabric.KVWrite": {
+            "type": "object",
+            "properties": {
+                "is_delete": {
+                    "type": "boolean"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "fabric.RangeQuery": {
+            "type": "object",
+            "properties": {
+                "end_key": {
+                    "type": "string"
+                },
+                "is_exhausted": {
+                    "type": "boolean"
+                },
+                "reads": {
+                    "description": "Reads are the keys the iteration returned, unless the peer only kept\ntheir Merkle summary",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fabric.KVRead"
+                    }
+                },
+                "start_key": {
+                    "type": "string"
+                }
+            }
+        },
+        "fabric.ReadWriteSet": {
+            "type": "object",
+            "properties": {
+                "collections": {
+                    "description": "Collections holds the hashed read/write sets of private data collections",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fabric.CollectionReadWriteSet"
+                    }
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "range_queries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fabric.RangeQuery"
+                    }
+                },
+                "reads": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fabric.KVRead"
+                    }
+                },
+                "writes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fabric.KVWrite"
+                    }
+                }
+            }
+        },
+        "fabric.SimulatedEvent": {
+            "type": "object",
+            "properties": {
+                "chaincode_name": {
+                    "type": "string"
+                },
+                "event_name": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "fabric.Transaction": {
+            "type": "object",
+            "properties": {
+                "args": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "block_number": {
+                    "type": "integer"
+                },
+                "chaincode": {
+                    "description": "Chaincode, Function and Args are only set for endorser transactions",
+                    "type": "string"
+                },
+                "channel": {
+                    "type": "string"
+                },
+                "creator_mspid": {
+                    "type": "string"
+                },
+                "creator_subject": {
+                    "type": "string"
+                },
+                "endorsers": {
+                    "description": "Endorsers lists the MSP IDs of the endorsing peers",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "function": {
+                    "type": "string"
+                },
+                "index": {
+                    "description": "Index is the position of the transaction in its block",
+                    "type": "integer"
+                },
+                "read_write_sets": {
+                    "description": "ReadWriteSets holds the keys read and written per namespace; the\nwrites only took effect if the transaction is VALID",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fabric.ReadWriteSet"
+                    }
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "tx_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "validation_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "fabric.Version": {
+            "type": "object",
+            "properties": {
+                "block_number": {
+                    "type": "integer"
+                },
+                "tx_number": {
+                    "type": "integer"
+                }
+            }
+        },
+        "graphqlapi.Request": {
+            "description": "GraphQL request with its variables",
+            "type": "object",
+            "properties": {
+                "operationName": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string",
+                    "example": "{ ledgerInfo { height } }"
+                },
+                "variables": {
+                    "type": "object"
+                }
+            }
+        },
+        "graphqlapi.Response": {
+            "description": "Data and errors of a GraphQL request",
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "object"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                }
+            }
+        },
+        "health.Check": {
+            "description": "Outcome of a single readiness check",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error describing why the check failed",
+                    "type": "string",
+                    "example": "1 of 3 peers reachable, 2 required"
+                },
+                "status": {
+                    "description": "Status of the check (\"ok\" or \"failed\")",
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "health.PeerReport": {
+            "description": "State of a peer as seen by the last check",
+            "type": "object",
+            "properties": {
+                "block_height": {
+                    "description": "Height of the channel's ledger on the peer",
+                    "type": "integer",
+                    "example": 42
+                },
+                "endpoint": {
+                    "type": "string",
+                    "example": "peer0.org1.example.com:7051"
+                },
+                "last_error": {
+                    "description": "Last error returned by the peer, kept after it recovered",
+                    "type": "string"
+                },
+                "last_error_at": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "description": "Duration of the last check in milliseconds",
+                    "type": "number",
+                    "example": 12.5
+                },
+                "state": {
+                    "description": "State of the peer (\"up\" or \"down\")",
+                    "type": "string",
+                    "example": "up"
+                }
+            }
+        },
+        "health.Report": {
+            "description": "Detailed readiness state with the result of each check and the state of each peer",
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string"
+                },
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/health.Check"
+                    }
+                },
+                "peers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/health.PeerReport"
+                    }
+                },
+                "status": {
+                    "description": "Status of the server (\"ready\" or \"not_ready\")",
+                    "type": "string",
+                    "example": "ready"
+                }
+            }
+        },
+        "indexer.HistoryResponse": {
+            "type": "object",
+            "properties": {
+                "chaincode": {
+                    "type": "string",
+                    "example": "basic"
+                },
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/indexer.KeyModification"
+                    }
+                },
+                "key": {
+                    "type": "string",
+                    "example": "asset1"
+                },
+                "next_cursor": {
+                    "description": "NextCursor fetches the next page; it is omitted on the last page",
+                    "type": "string"
+                }
+            }
+        },
+        "indexer.KeyModification": {
+            "type": "object",
+            "properties": {
+                "block_number": {
+                    "type": "integer"
+                },
+                "chaincode": {
+                    "description": "Chaincode and Function are the call that wrote the key, which belongs\nto another chaincode for chaincode-to-chaincode calls",
+                    "type": "string"
+                },
+                "creator_mspid": {
+                    "type": "string"
+                },
+                "creator_subject": {
+                    "type": "string"
+                },
+                "function": {
+                    "type": "string"
+                },
+                "is_delete": {
+                    "type": "boolean"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "tx_id": {
+                    "type": "string"
+                },
+                "tx_index": {
+                    "description": "TxIndex is the position of the transaction in its block",
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "indexer.SearchResponse": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "description": "NextCursor fetches the next page; it is omitted on the last page",
+                    "type": "string"
+                },
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fabric.Transaction"
+                    }
+                }
+            }
+        },
+        "indexer.StateResponse": {
+            "type": "object",
+            "properties": {
+                "block_number": {
+                    "type": "integer"
+                },
+                "chaincode": {
+                    "description": "Chaincode and Function are the call that wrote the key, which belongs\nto another chaincode for chaincode-to-chaincode calls",
+                    "type": "string"
+                },
+                "creator_mspid": {
+                    "type": "string"
+                },
+                "creator_subject": {
+                    "type": "string"
+                },
+                "function": {
+                    "type": "string"
+                },
+                "is_delete": {
+                    "type": "boolean"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "asset1"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "tx_id": {
+                    "type": "string"
+                },
+                "tx_index": {
+                    "description": "TxIndex is the position of the transaction in its block",
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "indexer.Status": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error is why the block stream was interrupted, until it is resumed",
+                    "type": "string"
+                },
+                "height": {
+                    "description": "Height is the number of the next block to index",
+                    "type": "integer"
+                },
+                "last_indexed_at": {
+                    "description": "LastIndexedAt is when the last block was indexed",
+                    "type": "string"
+                },
+                "transactions": {
+                    "type": "integer"
+                }
+            }
+        },
+        "offline.CommitResponse": {
+            "type": "object",
+            "properties": {
+                "block_number": {
+                    "type": "integer",
+                    "example": 123
+                },
+                "result": {
+                    "type": "string"
                 },
                 "result_code": {
-                    "description": "Result code from the chaincode",
                     "type": "integer",
-                    "example": 200
+                    "example": 0
                 },
                 "status": {
-                    "description": "Status of the transaction (\"success\" or \"error\")",
                     "type": "string",
                     "example": "success"
                 },
                 "success": {
-                    "description": "Whether the transaction was successful",
                     "type": "boolean",
                     "example": true
                 },
                 "tx_id": {
-                    "description": "Transaction ID",
+                    "type": "string"
+                },
+                "validation_code": {
                     "type": "string",
-                    "example": "tx123"
+                    "example": "VALID"
+                }
+            }
+        },
+        "offline.InvalidArgumentsResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.FieldError"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "error"
+                }
+            }
+        },
+        "offline.PrepareRequest": {
+            "type": "object",
+            "properties": {
+                "args": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "[\"asset1\"",
+                        "\"blue\"]"
+                    ]
+                },
+                "certificate": {
+                    "description": "PEM encoded X.509 certificate of the signer, with an ECDSA public key",
+                    "type": "string"
+                },
+                "chaincode_name": {
+                    "type": "string",
+                    "example": "basic"
+                },
+                "function": {
+                    "type": "string",
+                    "example": "CreateAsset"
+                },
+                "mspid": {
+                    "description": "MSP ID of the signer",
+                    "type": "string",
+                    "example": "Org1MSP"
+                },
+                "transient": {
+                    "description": "Transient data passed to the chaincode but not recorded on the ledger",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "offline.SignRequest": {
+            "type": "object",
+            "properties": {
+                "signature": {
+                    "description": "Base64 encoded ASN.1 DER ECDSA signature of the digest",
+                    "type": "string",
+                    "format": "base64"
+                }
+            }
+        },
+        "offline.StepResponse": {
+            "type": "object",
+            "properties": {
+                "digest": {
+                    "description": "Base64 encoded SHA-256 digest to sign",
+                    "type": "string",
+                    "format": "base64"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "handle": {
+                    "type": "string",
+                    "example": "3f1c9a7e0b5d4c2a8e6f1b3d5a7c9e0f"
+                },
+                "next": {
+                    "description": "Next is the step the digest is signed for: endorse, submit or commit",
+                    "type": "string",
+                    "example": "endorse"
+                },
+                "result": {
+                    "description": "Chaincode response, once endorsed",
+                    "type": "string"
+                },
+                "tx_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "outbox.Item": {
+            "type": "object",
+            "properties": {
+                "args": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "attempts": {
+                    "type": "integer"
+                },
+                "block_number": {
+                    "type": "integer"
+                },
+                "chaincode_name": {
+                    "type": "string",
+                    "example": "basic"
+                },
+                "completed_at": {
+                    "description": "CompletedAt is when the item was committed or dead-lettered",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "function": {
+                    "type": "string",
+                    "example": "CreateAsset"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "4f7d0c1e9b2a43d58c6e1f0a2b3c4d5e"
+                },
+                "last_error": {
+                    "description": "LastError is why the last attempt failed",
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "description": "NextAttemptAt is when a failed attempt is retried",
+                    "type": "string"
+                },
+                "owner": {
+                    "description": "Owner is the ID of the principal that queued the invoke",
+                    "type": "string"
+                },
+                "result": {
+                    "description": "Result is the chaincode response of the committed transaction",
+                    "type": "string"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/outbox.Status"
+                        }
+                    ],
+                    "example": "queued"
+                },
+                "tx_id": {
+                    "description": "TxID is the ID of the last transaction created for the item",
+                    "type": "string"
+                },
+                "validation_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "outbox.Status": {
+            "type": "string",
+            "enum": [
+                "queued",
+                "committed",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "StatusQueued",
+                "StatusCommitted",
+                "StatusFailed"
+            ]
+        },
+        "ratelimit.Limit": {
+            "type": "object",
+            "properties": {
+                "burst": {
+                    "description": "Burst is the number of requests that may be made at once (default: rate rounded up)",
+                    "type": "integer"
+                },
+                "max_in_flight_invokes": {
+                    "description": "MaxInFlightInvokes is the number of invokes a client may have running at\nthe same time; 0 means unlimited",
+                    "type": "integer"
+                },
+                "rate": {
+                    "description": "Rate is the sustained number of requests per second; 0 means unlimited",
+                    "type": "number"
+                }
+            }
+        },
+        "ratelimit.QuotaUsage": {
+            "type": "object",
+            "properties": {
+                "in_flight_invokes": {
+                    "description": "InFlightInvokes is the number of invokes currently running",
+                    "type": "integer"
+                },
+                "limit": {
+                    "$ref": "#/definitions/ratelimit.Limit"
+                },
+                "remaining": {
+                    "description": "Remaining is the number of requests that can be made right now",
+                    "type": "number"
+                }
+            }
+        },
+        "ratelimit.Usage": {
+            "type": "object",
+            "properties": {
+                "chaincodes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/ratelimit.QuotaUsage"
+                    }
+                },
+                "client": {
+                    "type": "string"
+                },
+                "in_flight_invokes": {
+                    "type": "integer"
+                },
+                "max_in_flight_invokes": {
+                    "type": "integer"
+                },
+                "routes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/ratelimit.QuotaUsage"
+                    }
+                }
+            }
+        },
+        "response.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "error"
+                }
+            }
+        },
+        "schema.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "JSON pointer of the invalid value in the request, e.g. /args/1/color",
+                    "type": "string",
+                    "example": "/args/0"
+                },
+                "message": {
+                    "description": "What is wrong with the value",
+                    "type": "string",
+                    "example": "minLength: got 0, want 1"
+                }
+            }
+        },
+        "webhook.Checkpoint": {
+            "type": "object",
+            "properties": {
+                "block_number": {
+                    "type": "integer"
+                },
+                "tx_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "webhook.CreateRequest": {
+            "type": "object",
+            "properties": {
+                "chaincode_name": {
+                    "type": "string",
+                    "example": "basic"
+                },
+                "event_pattern": {
+                    "type": "string",
+                    "example": "Asset*"
+                },
+                "secret": {
+                    "description": "Secret signs the deliveries; one is generated when omitted",
+                    "type": "string"
+                },
+                "start_block": {
+                    "description": "StartBlock is the first block whose events are delivered; omitted\nstarts with the next block committed",
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/hooks/fabric"
+                }
+            }
+        },
+        "webhook.DeadLetter": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "event": {
+                    "$ref": "#/definitions/fabric.ChaincodeEvent"
+                },
+                "failed_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "webhook.RedeliverResponse": {
+            "type": "object",
+            "properties": {
+                "delivered": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                }
+            }
+        },
+        "webhook.Subscription": {
+            "type": "object",
+            "properties": {
+                "chaincode_name": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_pattern": {
+                    "description": "EventPattern selects events by name as understood by path.Match; empty\nselects every event of the chaincode",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "owner": {
+                    "description": "Owner is the ID of the principal that created the subscription",
+                    "type": "string"
+                },
+                "secret": {
+                    "description": "Secret is the key deliveries are signed with; it is only returned when\nthe subscription is created",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "webhook.SubscriptionStatus": {
+            "type": "object",
+            "properties": {
+                "chaincode_name": {
+                    "type": "string"
+                },
+                "checkpoint": {
+                    "$ref": "#/definitions/webhook.Checkpoint"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "dead_letters": {
+                    "type": "integer"
+                },
+                "event_pattern": {
+                    "description": "EventPattern selects events by name as understood by path.Match; empty\nselects every event of the chaincode",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "owner": {
+                    "description": "Owner is the ID of the principal that created the subscription",
+                    "type": "string"
+                },
+                "secret": {
+                    "description": "Secret is the key deliveries are signed with; it is only returned when\nthe subscription is created",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "JWT issued by the configured identity provider, as \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
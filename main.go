@@ -1,20 +1,52 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	_ "github.com/kfsoftware/chainlaunch-plugin-hlf/docs" // This will be generated
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	httpSwagger "github.com/swaggo/http-swagger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 
 	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/api"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/audit"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/config"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/contract"
 	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/graphqlapi"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/grpcapi"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/health"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/idempotency"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/indexer"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/logging"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/metrics"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/offline"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/outbox"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/ratelimit"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/schema"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/tlsconfig"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/tracing"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/webhook"
+	hlfapiv1 "github.com/kfsoftware/chainlaunch-plugin-hlf/proto/hlfapi/v1"
 )
 
 // @title Hyperledger Fabric API
@@ -22,15 +54,85 @@ import (
 // @description API for interacting with Hyperledger Fabric network
 // @BasePath /
 // @schemes http https
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT issued by the configured identity provider, as "Bearer <token>"
 
 var (
+	configPath string
+
 	port          string
+	grpcPort      string
+	tlsCert       string
+	tlsKey        string
+	tlsClientCA   string
+	tlsClientAuth string
+	maxBodySizeMB int
+	probePort     string
 	mspID         string
 	certPath      string
 	keyPath       string
 	peerEndpoints string
 	tlsCertPaths  string
 	channelName   string
+	identities    []string
+
+	batchParallelism   int
+	batchMaxOperations int
+
+	idempotencyStore string
+	idempotencyDB    string
+	idempotencyTTL   time.Duration
+
+	authConfigPath string
+
+	rateLimitConfigPath string
+
+	auditDir             string
+	auditMaxSizeMB       int
+	auditAnchorChaincode string
+	auditAnchorFunction  string
+	auditAnchorInterval  time.Duration
+
+	schemaDir string
+
+	contractChaincodes []string
+	contractRefresh    time.Duration
+
+	offlineSigning    bool
+	offlineSigningTTL time.Duration
+	indexerDB         string
+	indexerStartBlock uint64
+	outboxDB          string
+	outboxMinBackoff  time.Duration
+	outboxMaxBackoff  time.Duration
+	outboxRetention   time.Duration
+
+	webhookDB          string
+	webhookMaxAttempts int
+	webhookTimeout     time.Duration
+	webhookMinBackoff  time.Duration
+	webhookMaxBackoff  time.Duration
+	webhookNetworks    []string
+
+	shutdownTimeout time.Duration
+	shutdownDelay   time.Duration
+
+	readyMinPeers       int
+	healthCheckInterval time.Duration
+	healthCheckTimeout  time.Duration
+
+	logLevel     string
+	logFormat    string
+	logSensitive bool
+
+	traceExporter    string
+	traceFile        string
+	traceSampleRatio float64
 
 	rootCmd  = &cobra.Command{Use: "hlf-api"}
 	serveCmd = &cobra.Command{
@@ -41,28 +143,104 @@ var (
 )
 
 func init() {
+	defaults := config.Default()
+
 	// Server flags
-	serveCmd.Flags().StringVarP(&port, "port", "p", getEnvOrDefault("PORT_API", "8180"), "Port to run the server on")
-
-	// Fabric connection flags
-	serveCmd.Flags().StringVar(&mspID, "mspid", getEnvOrDefault("FABRIC_MSPID", ""), "MSP ID of the organization")
-	serveCmd.Flags().StringVar(&certPath, "cert", getEnvOrDefault("FABRIC_CERT_PATH", ""), "Path to the client certificate")
-	serveCmd.Flags().StringVar(&keyPath, "key", getEnvOrDefault("FABRIC_KEY_PATH", ""), "Path to the client private key")
-	serveCmd.Flags().StringVar(&peerEndpoints, "peers", getEnvOrDefault("FABRIC_PEERS", ""), "Comma-separated list of peer endpoints (host:port)")
-	serveCmd.Flags().StringVar(&tlsCertPaths, "tlscerts", getEnvOrDefault("FABRIC_TLS_CERTS", ""), "Comma-separated list of paths to the TLS certificates (one per peer)")
-	serveCmd.Flags().StringVar(&channelName, "channel", getEnvOrDefault("FABRIC_CHANNEL", ""), "Channel name")
-
-	// Mark required flags
-	serveCmd.MarkFlagRequired("mspid")
-	serveCmd.MarkFlagRequired("cert")
-	serveCmd.MarkFlagRequired("key")
-	serveCmd.MarkFlagRequired("peers")
-	serveCmd.MarkFlagRequired("tlscerts")
-	serveCmd.MarkFlagRequired("channel")
+	serveCmd.Flags().StringVarP(&port, "port", "p", getEnvOrDefault("PORT_API", defaults.Server.Port), "Port to run the server on")
+	serveCmd.Flags().StringVar(&grpcPort, "grpc-port", getEnvOrDefault("GRPC_PORT", ""), "Port of the gRPC API; the gRPC API is disabled when empty")
+	serveCmd.Flags().StringVar(&probePort, "probe-port", getEnvOrDefault("PROBE_PORT", ""), "Port serving /livez, /readyz and /metrics over plain HTTP, for probes and scrapes without a client certificate; required with --tls-client-auth require")
+	serveCmd.Flags().StringVar(&tlsCert, "tls-cert", getEnvOrDefault("TLS_CERT_PATH", ""), "Path to the server TLS certificate; serves HTTPS when set")
+	serveCmd.Flags().StringVar(&tlsKey, "tls-key", getEnvOrDefault("TLS_KEY_PATH", ""), "Path to the server TLS private key")
+	serveCmd.Flags().StringVar(&tlsClientCA, "tls-client-ca", getEnvOrDefault("TLS_CLIENT_CA_PATH", ""), "Path to a CA bundle used to verify client certificates (mutual TLS)")
+	serveCmd.Flags().StringVar(&tlsClientAuth, "tls-client-auth", getEnvOrDefault("TLS_CLIENT_AUTH", ""), "Client certificate mode: none, request or require (default require when --tls-client-ca is set)")
+	serveCmd.Flags().IntVar(&maxBodySizeMB, "max-body-size", getEnvIntOrDefault("MAX_BODY_SIZE_MB", defaults.Server.MaxBodySizeMB), "Largest request body accepted, in MB; larger requests are rejected with 413")
+	serveCmd.Flags().IntVar(&batchParallelism, "batch-parallelism", getEnvIntOrDefault("BATCH_PARALLELISM", defaults.Batch.Parallelism), "Maximum number of batch operations executed concurrently")
+	serveCmd.Flags().IntVar(&batchMaxOperations, "batch-max-operations", getEnvIntOrDefault("BATCH_MAX_OPERATIONS", defaults.Batch.MaxOperations), "Maximum number of operations accepted in a single batch request")
+
+	// Authentication flags
+	serveCmd.Flags().StringVar(&authConfigPath, "auth-config", getEnvOrDefault("AUTH_CONFIG", ""), "Path to the authentication config file (API keys and their scopes); the API is unauthenticated when empty")
+
+	// Rate limit flags
+	serveCmd.Flags().StringVar(&rateLimitConfigPath, "rate-limit-config", getEnvOrDefault("RATE_LIMIT_CONFIG", ""), "Path to the per-client rate limit config file; no limits are applied when empty")
+
+	// Audit flags
+	serveCmd.Flags().StringVar(&auditDir, "audit-dir", getEnvOrDefault("AUDIT_DIR", ""), "Directory of the hash-chained audit log of submitted transactions; auditing is disabled when empty")
+	serveCmd.Flags().IntVar(&auditMaxSizeMB, "audit-max-size", getEnvIntOrDefault("AUDIT_MAX_SIZE_MB", defaults.Audit.MaxSizeMB), "Size in MB after which a new audit file is started")
+	serveCmd.Flags().StringVar(&auditAnchorChaincode, "audit-anchor-chaincode", getEnvOrDefault("AUDIT_ANCHOR_CHAINCODE", ""), "Chaincode used to periodically anchor the audit log head on the ledger; anchoring is disabled when empty")
+	serveCmd.Flags().StringVar(&auditAnchorFunction, "audit-anchor-function", getEnvOrDefault("AUDIT_ANCHOR_FUNCTION", defaults.Audit.Anchor.Function), "Chaincode function invoked with the head sequence number and hash")
+	serveCmd.Flags().DurationVar(&auditAnchorInterval, "audit-anchor-interval", getEnvDurationOrDefault("AUDIT_ANCHOR_INTERVAL", defaults.Audit.Anchor.Interval), "Interval between audit log anchors")
+
+	// Schema flags
+	serveCmd.Flags().StringVar(&schemaDir, "schema-dir", getEnvOrDefault("SCHEMA_DIR", ""), "Directory of JSON Schemas validating chaincode function arguments, as <chaincode>/<function>.json; requests are not validated when empty")
+
+	// Contract route flags
+	serveCmd.Flags().StringSliceVar(&contractChaincodes, "contract-chaincodes", splitEnv("CONTRACT_CHAINCODES", ","), "Chaincodes whose contract metadata is served as typed routes and OpenAPI documents (comma separated)")
+	serveCmd.Flags().DurationVar(&contractRefresh, "contract-refresh", getEnvDurationOrDefault("CONTRACT_REFRESH", defaults.Contracts.Refresh), "Interval at which the contract metadata is fetched again")
+
+	// Offline signing flags
+	serveCmd.Flags().BoolVar(&offlineSigning, "offline-signing", getEnvBoolOrDefault("OFFLINE_SIGNING", false), "Enable the offline signing endpoints for clients that sign transactions with their own keys")
+	serveCmd.Flags().DurationVar(&offlineSigningTTL, "offline-signing-ttl", getEnvDurationOrDefault("OFFLINE_SIGNING_TTL", defaults.Offline.TTL), "How long a prepared offline transaction waits for the client's next signature")
+
+	// Indexer flags
+	serveCmd.Flags().StringVar(&indexerDB, "indexer-db", getEnvOrDefault("INDEXER_DB", ""), "Database file of the local transaction index; the indexer is disabled when empty")
+	serveCmd.Flags().Uint64Var(&indexerStartBlock, "indexer-start-block", uint64(getEnvIntOrDefault("INDEXER_START_BLOCK", int(defaults.Indexer.StartBlock))), "First block indexed into an empty index database")
+
+	// Outbox flags
+	serveCmd.Flags().StringVar(&outboxDB, "outbox-db", getEnvOrDefault("OUTBOX_DB", ""), "Database file of the queue of invokes submitted in the background; invokes are not queued when empty")
+	serveCmd.Flags().DurationVar(&outboxMinBackoff, "outbox-min-backoff", getEnvDurationOrDefault("OUTBOX_MIN_BACKOFF", defaults.Outbox.MinBackoff), "Wait before the first retry of a queued invoke, doubled for every further retry")
+	serveCmd.Flags().DurationVar(&outboxMaxBackoff, "outbox-max-backoff", getEnvDurationOrDefault("OUTBOX_MAX_BACKOFF", defaults.Outbox.MaxBackoff), "Maximum wait between retries of a queued invoke")
+	serveCmd.Flags().DurationVar(&outboxRetention, "outbox-retention", getEnvDurationOrDefault("OUTBOX_RETENTION", defaults.Outbox.Retention), "How long committed invokes can be looked up in the outbox")
+
+	// Webhook flags
+	serveCmd.Flags().StringVar(&webhookDB, "webhook-db", getEnvOrDefault("WEBHOOK_DB", ""), "Database file of the webhook subscriptions; webhooks are disabled when empty")
+	serveCmd.Flags().IntVar(&webhookMaxAttempts, "webhook-max-attempts", getEnvIntOrDefault("WEBHOOK_MAX_ATTEMPTS", defaults.Webhooks.MaxAttempts), "Delivery attempts after which an event is dead-lettered")
+	serveCmd.Flags().DurationVar(&webhookTimeout, "webhook-timeout", getEnvDurationOrDefault("WEBHOOK_TIMEOUT", defaults.Webhooks.Timeout), "Timeout of a single webhook delivery")
+	serveCmd.Flags().DurationVar(&webhookMinBackoff, "webhook-min-backoff", getEnvDurationOrDefault("WEBHOOK_MIN_BACKOFF", defaults.Webhooks.MinBackoff), "Wait before the first webhook delivery retry, doubled for every further retry")
+	serveCmd.Flags().DurationVar(&webhookMaxBackoff, "webhook-max-backoff", getEnvDurationOrDefault("WEBHOOK_MAX_BACKOFF", defaults.Webhooks.MaxBackoff), "Maximum wait between webhook delivery retries")
+	serveCmd.Flags().StringSliceVar(&webhookNetworks, "webhook-allowed-networks", splitEnv("WEBHOOK_ALLOWED_NETWORKS", ","), "Loopback, private or link-local networks webhook receivers may be in, as CIDRs (comma separated); only public addresses are allowed otherwise")
+
+	// Idempotency flags
+	serveCmd.Flags().StringVar(&idempotencyStore, "idempotency-store", getEnvOrDefault("IDEMPOTENCY_STORE", defaults.Idempotency.Store), "Store for Idempotency-Key responses (memory or bolt)")
+	serveCmd.Flags().StringVar(&idempotencyDB, "idempotency-db", getEnvOrDefault("IDEMPOTENCY_DB_PATH", defaults.Idempotency.DB), "Path to the database file used by the bolt idempotency store")
+	serveCmd.Flags().DurationVar(&idempotencyTTL, "idempotency-ttl", getEnvDurationOrDefault("IDEMPOTENCY_TTL", defaults.Idempotency.TTL), "How long responses are replayed for retried Idempotency-Key requests")
+
+	// Shutdown flags
+	serveCmd.Flags().DurationVar(&shutdownTimeout, "shutdown-timeout", getEnvDurationOrDefault("SHUTDOWN_TIMEOUT", defaults.Shutdown.Timeout), "Maximum time to wait for in-flight requests and invokes to finish on SIGTERM/SIGINT")
+	serveCmd.Flags().DurationVar(&shutdownDelay, "shutdown-delay", getEnvDurationOrDefault("SHUTDOWN_DELAY", defaults.Shutdown.Delay), "Time between failing readiness and closing the listener on shutdown, for load balancers to deregister the server")
+
+	// Health check flags
+	serveCmd.Flags().IntVar(&readyMinPeers, "ready-min-peers", getEnvIntOrDefault("READY_MIN_PEERS", defaults.Health.ReadyMinPeers), "Number of reachable peers required for the server to report ready")
+	serveCmd.Flags().DurationVar(&healthCheckInterval, "health-check-interval", getEnvDurationOrDefault("HEALTH_CHECK_INTERVAL", defaults.Health.Interval), "Interval between readiness checks of the identities, peers and channel")
+	serveCmd.Flags().DurationVar(&healthCheckTimeout, "health-check-timeout", getEnvDurationOrDefault("HEALTH_CHECK_TIMEOUT", defaults.Health.Timeout), "Timeout of the readiness query sent to each peer")
+
+	// Logging flags
+	serveCmd.Flags().StringVar(&logLevel, "log-level", getEnvOrDefault("LOG_LEVEL", defaults.Logging.Level), "Log level: debug, info, warn or error")
+	serveCmd.Flags().StringVar(&logFormat, "log-format", getEnvOrDefault("LOG_FORMAT", defaults.Logging.Format), "Log format: json or text")
+	serveCmd.Flags().BoolVar(&logSensitive, "log-sensitive", getEnvBoolOrDefault("LOG_SENSITIVE", false), "Log transaction arguments instead of redacting them (debugging only)")
+
+	// Tracing flags
+	serveCmd.Flags().StringVar(&traceExporter, "trace-exporter", getEnvOrDefault("TRACE_EXPORTER", defaults.Tracing.Exporter), "Trace exporter: none, otlp (configured with the OTEL_EXPORTER_OTLP_* variables) or file")
+	serveCmd.Flags().StringVar(&traceFile, "trace-file", getEnvOrDefault("TRACE_FILE", defaults.Tracing.File), "File the spans are written to by the file trace exporter")
+	serveCmd.Flags().Float64Var(&traceSampleRatio, "trace-sample-ratio", getEnvFloatOrDefault("TRACE_SAMPLE_RATIO", defaults.Tracing.SampleRatio), "Fraction of new traces that are sampled")
+
+	addFabricFlags(serveCmd.Flags())
 
 	rootCmd.AddCommand(serveCmd)
 }
 
+// addFabricFlags registers the config file and Fabric connection flags shared
+// by the commands that talk to the network
+func addFabricFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&configPath, "config", getEnvOrDefault("CONFIG_FILE", ""), "Path to a YAML config file; flags given on the command line override its settings")
+	flags.StringVar(&mspID, "mspid", getEnvOrDefault("FABRIC_MSPID", ""), "MSP ID of the organization")
+	flags.StringVar(&certPath, "cert", getEnvOrDefault("FABRIC_CERT_PATH", ""), "Path to the client certificate")
+	flags.StringVar(&keyPath, "key", getEnvOrDefault("FABRIC_KEY_PATH", ""), "Path to the client private key")
+	flags.StringVar(&peerEndpoints, "peers", getEnvOrDefault("FABRIC_PEERS", ""), "Comma-separated list of peer endpoints (host:port)")
+	flags.StringVar(&tlsCertPaths, "tlscerts", getEnvOrDefault("FABRIC_TLS_CERTS", ""), "Comma-separated list of paths to the TLS certificates (one per peer)")
+	flags.StringVar(&channelName, "channel", getEnvOrDefault("FABRIC_CHANNEL", ""), "Channel name")
+	flags.StringArrayVar(&identities, "identity", splitEnv("FABRIC_IDENTITIES", ";"), "Additional named signing identity as name=<name>,mspid=<mspid>,cert=<path>,key=<path> (repeatable)")
+}
+
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -70,6 +248,88 @@ func getEnvOrDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
+func splitEnv(key, sep string) []string {
+	if value := os.Getenv(key); value != "" {
+		return strings.Split(value, sep)
+	}
+	return nil
+}
+
+// parseIdentities parses the --identity flag values
+func parseIdentities(values []string) (map[string]fabric.IdentityConfig, error) {
+	result := make(map[string]fabric.IdentityConfig, len(values))
+	for _, value := range values {
+		fields := make(map[string]string)
+		for _, part := range strings.Split(value, ",") {
+			k, v, found := strings.Cut(strings.TrimSpace(part), "=")
+			if !found {
+				return nil, fmt.Errorf("invalid identity %q: expected key=value pairs", value)
+			}
+			fields[k] = v
+		}
+		name := fields["name"]
+		if name == "" || fields["mspid"] == "" || fields["cert"] == "" || fields["key"] == "" {
+			return nil, fmt.Errorf("invalid identity %q: name, mspid, cert and key are required", value)
+		}
+		if _, exists := result[name]; exists {
+			return nil, fmt.Errorf("duplicate identity %q", name)
+		}
+		result[name] = fabric.IdentityConfig{
+			MspID:    fields["mspid"],
+			CertPath: fields["cert"],
+			KeyPath:  fields["key"],
+		}
+	}
+	return result, nil
+}
+
+func getEnvIntOrDefault(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvBoolOrDefault(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvFloatOrDefault(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func newIdempotencyStore(cfg config.Idempotency) (idempotency.Store, error) {
+	switch cfg.Store {
+	case "memory":
+		return idempotency.NewMemoryStore(), nil
+	case "bolt":
+		return idempotency.NewBoltStore(cfg.DB)
+	default:
+		return nil, fmt.Errorf("unknown idempotency store %q (expected memory or bolt)", cfg.Store)
+	}
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
@@ -78,72 +338,519 @@ func main() {
 }
 
 func runServer(cmd *cobra.Command, args []string) {
+	cfg, err := buildConfig(cmd.Flags())
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
+
+	if err := logging.Setup(os.Stderr, logging.Options{
+		Level:        cfg.Logging.Level,
+		Format:       cfg.Logging.Format,
+		LogSensitive: cfg.Logging.Sensitive,
+	}); err != nil {
+		log.Fatalf("Failed to configure logging: %v", err)
+	}
+
 	// Log all configuration parameters
-	log.Printf("Starting server with the following configuration:")
-	log.Printf("Port: %s", port)
-	log.Printf("MSP ID: %s", mspID)
-	log.Printf("Certificate Path: %s", certPath)
-	log.Printf("Key Path: %s", keyPath)
-	log.Printf("Peer Endpoints: %s", peerEndpoints)
-	log.Printf("TLS Certificate Paths: %s", tlsCertPaths)
-	log.Printf("Channel Name: %s", channelName)
-	// Parse peer endpoints and TLS cert paths
-	peers := strings.Split(peerEndpoints, ",")
-	tlsCerts := strings.Split(tlsCertPaths, ",")
-	log.Printf("Number of peers: %v", peers)
-	log.Printf("Number of TLS certificates: %v", tlsCerts)
-	if len(peers) != len(tlsCerts) {
-		log.Fatalf("Number of peer endpoints (%d) must match number of TLS certificates (%d)", len(peers), len(tlsCerts))
-	}
-
-	// Create peer configurations
-	var peerConfigs []fabric.PeerConfig
-	for i := range peers {
-		peerConfigs = append(peerConfigs, fabric.PeerConfig{
-			Endpoint:    strings.TrimSpace(peers[i]),
-			TLSCertPath: strings.TrimSpace(tlsCerts[i]),
-		})
+	slog.Info("starting server",
+		"config", configPath,
+		"port", cfg.Server.Port,
+		"tls_cert", cfg.Server.TLS.Cert,
+		"tls_client_ca", cfg.Server.TLS.ClientCA,
+		"mspid", cfg.Fabric.MspID,
+		"cert", cfg.Fabric.Cert,
+		"key", cfg.Fabric.Key,
+		"peers", len(cfg.Fabric.Peers),
+		"channel", cfg.Fabric.Channel,
+		"identities", len(cfg.Fabric.Identities),
+		"batch_parallelism", cfg.Batch.Parallelism,
+		"ready_min_peers", cfg.Health.ReadyMinPeers,
+		"idempotency_store", cfg.Idempotency.Store,
+		"idempotency_ttl", cfg.Idempotency.TTL.String(),
+		"auth", cfg.Auth != nil,
+		"rate_limits", cfg.RateLimits != nil,
+		"audit_dir", cfg.Audit.Dir,
+		"webhook_db", cfg.Webhooks.DB,
+		"schema_dir", cfg.Schemas.Dir,
+		"contract_chaincodes", cfg.Contracts.Chaincodes,
+		"offline_signing", cfg.Offline.Enabled,
+		"indexer_db", cfg.Indexer.DB,
+		"outbox_db", cfg.Outbox.DB,
+		"networks", len(cfg.Networks),
+		"trace_exporter", cfg.Tracing.Exporter,
+		"log_level", cfg.Logging.Level,
+		"log_sensitive", cfg.Logging.Sensitive,
+	)
+	if cfg.Logging.Sensitive {
+		slog.Warn("sensitive logging is enabled, transaction arguments are written to the log")
+	}
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		FilePath:    cfg.Tracing.File,
+		SampleRatio: cfg.Tracing.SampleRatio,
+		ServiceName: "hlf-api",
+	})
+	if err != nil {
+		logging.Fatal("failed to set up tracing", "error", err)
 	}
+	defer shutdownTracing(context.Background())
 
 	// Initialize Fabric client
-	fabricClient, err := fabric.NewFabricClient(&fabric.ClientConfig{
-		MspID:       mspID,
-		CertPath:    certPath,
-		KeyPath:     keyPath,
-		Peers:       peerConfigs,
-		ChannelName: channelName,
-	})
+	fabricClient, err := fabric.NewFabricClient(cfg.ClientConfig())
 	if err != nil {
-		log.Fatalf("Failed to create Fabric client: %v", err)
+		logging.Fatal("failed to create Fabric client", "error", err)
 	}
 	defer fabricClient.Close()
 
+	// backgroundCtx is cancelled once the in-flight requests have drained on shutdown
+	backgroundCtx, cancelBackground := context.WithCancel(context.Background())
+	defer cancelBackground()
+
+	apiMetrics := metrics.New()
+	fabricClient.AddObserver(apiMetrics)
+	fabricClient.AddTransactionListener(apiMetrics.RecordTransaction)
+	for _, file := range fabricClient.CertificateFiles() {
+		apiMetrics.WatchCertificate(file.Kind, file.Name, file.Path)
+	}
+	if cfg.Server.TLS.Cert != "" {
+		apiMetrics.WatchCertificate("server_tls", "server", cfg.Server.TLS.Cert)
+	}
+
+	var auditLogger *audit.Logger
+	if cfg.Audit.Dir != "" {
+		auditLogger, err = audit.NewLogger(cfg.Audit.Dir, int64(cfg.Audit.MaxSizeMB)*1024*1024)
+		if err != nil {
+			logging.Fatal("failed to open audit log", "error", err)
+		}
+		defer auditLogger.Close()
+		fabricClient.AddTransactionListener(auditLogger.RecordTransaction)
+
+		if cfg.Audit.Anchor.Chaincode != "" {
+			anchorer := audit.NewAnchorer(auditLogger, fabricClient, cfg.Audit.Anchor.Chaincode, cfg.Audit.Anchor.Function, cfg.Audit.Anchor.Interval)
+			go anchorer.Run(backgroundCtx)
+		}
+	}
+
+	store, err := newIdempotencyStore(cfg.Idempotency)
+	if err != nil {
+		logging.Fatal("failed to create idempotency store", "error", err)
+	}
+	defer store.Close()
+	idempotencyManager := idempotency.NewManager(store, cfg.Idempotency.TTL)
+
+	var webhooks *webhook.Manager
+	if cfg.Webhooks.DB != "" {
+		webhookStore, err := webhook.NewStore(cfg.Webhooks.DB)
+		if err != nil {
+			logging.Fatal("failed to open webhook store", "error", err)
+		}
+		defer webhookStore.Close()
+		webhooks = webhook.NewManager(webhookStore, fabricClient, cfg.Webhooks.WebhookConfig())
+		go webhooks.Run(backgroundCtx)
+	}
+
+	var ledgerIndex *indexer.Indexer
+	if cfg.Indexer.DB != "" {
+		indexStore, err := indexer.NewStore(cfg.Indexer.DB)
+		if err != nil {
+			logging.Fatal("failed to open index store", "error", err)
+		}
+		defer indexStore.Close()
+		ledgerIndex = indexer.New(indexStore, fabricClient, cfg.Indexer.StartBlock)
+		go ledgerIndex.Run(backgroundCtx)
+	}
+
+	var invokeOutbox *outbox.Manager
+	if cfg.Outbox.DB != "" {
+		outboxStore, err := outbox.NewStore(cfg.Outbox.DB)
+		if err != nil {
+			logging.Fatal("failed to open outbox store", "error", err)
+		}
+		defer outboxStore.Close()
+		invokeOutbox = outbox.NewManager(outboxStore, fabricClient, outbox.Config{
+			MinBackoff: cfg.Outbox.MinBackoff,
+			MaxBackoff: cfg.Outbox.MaxBackoff,
+			Retention:  cfg.Outbox.Retention,
+		})
+		go invokeOutbox.Run(backgroundCtx)
+	}
+
+	var authChain *auth.Chain
+	if cfg.Auth != nil {
+		authenticators, err := cfg.Auth.Authenticators()
+		if err != nil {
+			logging.Fatal("failed to configure authentication", "error", err)
+		}
+		authChain = auth.NewChain(authenticators)
+	} else {
+		slog.Warn("no auth config given, the API accepts unauthenticated requests")
+	}
+
+	handlerOpts := []api.HandlerOption{api.WithBatchLimits(cfg.Batch.Parallelism, cfg.Batch.MaxOperations)}
+	var limiter *ratelimit.Limiter
+	if cfg.RateLimits != nil {
+		limiter = ratelimit.NewLimiter(*cfg.RateLimits)
+		handlerOpts = append(handlerOpts, api.WithRateLimiter(limiter))
+	}
+
+	var schemas *schema.Registry
+	if cfg.Schemas.Dir != "" {
+		schemas, err = schema.Load(cfg.Schemas.Dir)
+		if err != nil {
+			logging.Fatal("failed to load function schemas", "error", err)
+		}
+		handlerOpts = append(handlerOpts, api.WithSchemas(schemas))
+		slog.Info("loaded function schemas", "functions", len(schemas.Functions()))
+	}
+
+	var contracts *contract.Registry
+	if len(cfg.Contracts.Chaincodes) > 0 {
+		contracts = contract.NewRegistry(fabricClient, cfg.Contracts.Chaincodes, cfg.Contracts.Refresh)
+		go contracts.Run(backgroundCtx)
+		handlerOpts = append(handlerOpts, api.WithContracts(contracts))
+	}
+
+	if invokeOutbox != nil {
+		handlerOpts = append(handlerOpts, api.WithOutbox(invokeOutbox))
+	}
+
+	var offlineSigner *offline.Manager
+	if cfg.Offline.Enabled {
+		var offlineOpts []offline.Option
+		if limiter != nil {
+			offlineOpts = append(offlineOpts, offline.WithRateLimiter(limiter))
+		}
+		if schemas != nil {
+			offlineOpts = append(offlineOpts, offline.WithSchemas(schemas))
+		}
+		offlineSigner = offline.NewManager(fabricClient, cfg.Offline.TTL, offlineOpts...)
+		defer offlineSigner.Close()
+		go offlineSigner.Run(backgroundCtx)
+	}
+
+	networks, err := newNetworks(cfg, idempotencyManager)
+	if err != nil {
+		logging.Fatal("failed to create networks", "error", err)
+	}
+	for _, n := range networks {
+		defer n.close()
+		if auditLogger != nil {
+			n.fabricClient.AddTransactionListener(auditLogger.NetworkListener(n.name))
+		}
+		n.run(backgroundCtx)
+		slog.Info("serving network", "network", n.name, "mspid", cfg.Networks[n.name].Fabric.MspID, "channel", n.fabricClient.ChannelName(), "peers", len(cfg.Networks[n.name].Fabric.Peers))
+	}
+
+	healthChecker := health.NewChecker(fabricClient, health.Config{
+		MinPeers: cfg.Health.ReadyMinPeers,
+		Interval: cfg.Health.Interval,
+		Timeout:  cfg.Health.Timeout,
+	})
+	go healthChecker.Run(backgroundCtx)
+
 	// Initialize API handlers
-	handler := api.NewHandler(fabricClient)
+	defaultAPI := &apiServices{
+		handler:     api.NewHandler(fabricClient, handlerOpts...),
+		authChain:   authChain,
+		limiter:     limiter,
+		idempotency: idempotencyManager,
+		contracts:   contracts,
+		offline:     offlineSigner,
+		ledgerIndex: ledgerIndex,
+		outbox:      invokeOutbox,
+		webhooks:    webhooks,
+	}
+
+	graphqlOpts := []graphqlapi.Option{}
+	if authChain != nil {
+		graphqlOpts = append(graphqlOpts, graphqlapi.WithAuthentication(authChain))
+	}
+	if limiter != nil {
+		graphqlOpts = append(graphqlOpts, graphqlapi.WithRateLimiter(limiter))
+	}
+	if schemas != nil {
+		graphqlOpts = append(graphqlOpts, graphqlapi.WithSchemas(schemas))
+	}
+	graphqlAPI, err := graphqlapi.NewServer(fabricClient, graphqlOpts...)
+	if err != nil {
+		logging.Fatal("failed to build the GraphQL schema", "error", err)
+	}
 
 	// Set up Chi router
 	r := chi.NewRouter()
-	r.Use(middleware.Logger)
+	r.Use(middleware.RequestID)
+	r.Use(logging.Middleware)
 	r.Use(middleware.Recoverer)
+	r.Use(tracing.Middleware)
+	r.Use(auth.ClientCertMiddleware)
+	r.Use(api.LimitBody(int64(cfg.Server.MaxBodySizeMB) * 1024 * 1024))
 
-	// Health check endpoint
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
+	// Networks are mounted outside the group of the default network, so
+	// that their requests only show up in their own metrics
+	for _, n := range networks {
+		r.Mount("/networks/"+n.name, n.routes())
+	}
+
+	r.Group(func(r chi.Router) {
+		r.Use(apiMetrics.Middleware)
+
+		// Health check endpoints
+		r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("OK"))
+		})
+		r.Get("/livez", healthChecker.LivezHandler)
+		r.Get("/readyz", healthChecker.ReadyzHandler)
+
+		// Prometheus metrics
+		r.Handle("/metrics", apiMetrics.Handler())
 
-	// Swagger documentation
-	r.Get("/swagger/*", httpSwagger.Handler(
-		httpSwagger.URL("/swagger/doc.json"),
-	))
+		// Swagger documentation, extended with the function schemas
+		r.Get("/swagger/doc.json", schemas.DocHandler())
+		if contracts != nil {
+			r.Get("/swagger/chaincodes/{cc}/doc.json", contracts.DocHandler)
+			r.Get("/swagger/chaincodes/{cc}/*", httpSwagger.Handler(httpSwagger.URL("doc.json")))
+		}
+		r.Get("/swagger/*", httpSwagger.Handler(
+			httpSwagger.URL("/swagger/doc.json"),
+		))
 
-	// API routes
-	r.Route("/api", func(r chi.Router) {
-		r.Post("/invoke", handler.InvokeHandler)
-		r.Post("/evaluate", handler.EvaluateHandler)
+		// API routes
+		r.Route("/api", defaultAPI.routes)
+
+		// GraphQL API; it authenticates and rate limits itself since WebSocket
+		// clients may only send their credentials once connected
+		r.Handle(graphqlapi.Route, graphqlAPI)
 	})
 
-	log.Printf("Server starting on port %s with %d peers configured", port, len(peerConfigs))
-	log.Printf("Swagger documentation available at http://localhost:%s/swagger/", port)
-	log.Fatal(http.ListenAndServe(":"+port, r))
+	server := &http.Server{
+		Addr:    ":" + cfg.Server.Port,
+		Handler: r,
+	}
+	// Upgraded WebSocket connections are not closed by server.Shutdown
+	server.RegisterOnShutdown(graphqlAPI.Shutdown)
+	for _, n := range networks {
+		server.RegisterOnShutdown(n.graphqlAPI.Shutdown)
+	}
+
+	listen := server.ListenAndServe
+	var tlsReloader *tlsconfig.Reloader
+	if cfg.Server.TLS.Cert == "" {
+		slog.Info("server listening", "port", cfg.Server.Port, "peers", len(cfg.Fabric.Peers), "swagger", "http://localhost:"+cfg.Server.Port+"/swagger/")
+	} else {
+		tlsReloader, err = tlsconfig.NewReloader(tlsconfig.Config{
+			CertPath:     cfg.Server.TLS.Cert,
+			KeyPath:      cfg.Server.TLS.Key,
+			ClientCAPath: cfg.Server.TLS.ClientCA,
+			ClientAuth:   cfg.Server.TLS.ClientAuth,
+		})
+		if err != nil {
+			logging.Fatal("failed to configure TLS", "error", err)
+		}
+		server.TLSConfig = tlsReloader.ServerConfig()
+		listen = func() error { return server.ListenAndServeTLS("", "") }
+		slog.Info("server listening with TLS", "port", cfg.Server.Port, "peers", len(cfg.Fabric.Peers), "swagger", "https://localhost:"+cfg.Server.Port+"/swagger/")
+	}
+
+	var grpcServer *grpc.Server
+	var grpcAPI *grpcapi.Server
+	if cfg.GRPC.Port != "" {
+		grpcOpts := []grpcapi.Option{}
+		if authChain != nil {
+			grpcOpts = append(grpcOpts, grpcapi.WithAuthentication(authChain))
+		}
+		if limiter != nil {
+			grpcOpts = append(grpcOpts, grpcapi.WithRateLimiter(limiter))
+		}
+		if schemas != nil {
+			grpcOpts = append(grpcOpts, grpcapi.WithSchemas(schemas))
+		}
+		grpcAPI = grpcapi.NewServer(fabricClient, grpcOpts...)
+		grpcServer = newGRPCServer(grpcAPI, apiMetrics, healthChecker, tlsReloader)
+	}
+	for _, n := range networks {
+		if n.grpcAPI != nil {
+			n.grpcServer = newGRPCServer(n.grpcAPI, n.metrics, n.healthChecker, tlsReloader)
+		}
+	}
+
+	reloader := &configReloader{
+		flags:        cmd.Flags(),
+		current:      cfg,
+		fabricClient: fabricClient,
+		authChain:    authChain,
+		limiter:      limiter,
+		tlsReloader:  tlsReloader,
+		networks:     networks,
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	go func() {
+		for range hup {
+			if err := reloader.reload(); err != nil {
+				slog.Error("configuration reload failed, keeping the current configuration", "error", err)
+				continue
+			}
+			slog.Info("configuration reloaded")
+		}
+	}()
+
+	var probeServer *http.Server
+	if cfg.Server.ProbePort != "" {
+		probeServer = &http.Server{
+			Addr:    ":" + cfg.Server.ProbePort,
+			Handler: probeHandler(healthChecker, apiMetrics, networks),
+		}
+	}
+
+	signalCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- listen()
+	}()
+	if probeServer != nil {
+		slog.Info("probe server listening", "port", cfg.Server.ProbePort)
+		go func() {
+			serverErr <- probeServer.ListenAndServe()
+		}()
+	}
+	if grpcServer != nil {
+		grpcListener, err := net.Listen("tcp", ":"+cfg.GRPC.Port)
+		if err != nil {
+			logging.Fatal("failed to listen for gRPC", "port", cfg.GRPC.Port, "error", err)
+		}
+		slog.Info("gRPC server listening", "port", cfg.GRPC.Port, "tls", tlsReloader != nil)
+		go func() {
+			serverErr <- grpcServer.Serve(grpcListener)
+		}()
+	}
+	for _, n := range networks {
+		if n.grpcServer == nil {
+			continue
+		}
+		port := cfg.Networks[n.name].GRPC.Port
+		grpcListener, err := net.Listen("tcp", ":"+port)
+		if err != nil {
+			logging.Fatal("failed to listen for gRPC", "network", n.name, "port", port, "error", err)
+		}
+		slog.Info("gRPC server listening", "network", n.name, "port", port, "tls", tlsReloader != nil)
+		go func() {
+			serverErr <- n.grpcServer.Serve(grpcListener)
+		}()
+	}
+
+	select {
+	case err := <-serverErr:
+		logging.Fatal("server stopped", "error", err)
+	case <-signalCtx.Done():
+	}
+	// A second signal terminates the process immediately
+	stop()
+	shutdown(server, probeServer, grpcServer, grpcAPI, fabricClient, healthChecker, networks, cfg.Shutdown, cancelBackground)
+}
+
+// newGRPCServer creates the gRPC server of a network, with its transaction
+// and health services
+func newGRPCServer(grpcAPI *grpcapi.Server, m *metrics.Metrics, healthChecker *health.Checker, tlsReloader *tlsconfig.Reloader) *grpc.Server {
+	serverOpts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(logging.UnaryServerInterceptor, m.UnaryServerInterceptor, grpcAPI.UnaryInterceptor),
+		grpc.ChainStreamInterceptor(logging.StreamServerInterceptor, m.StreamServerInterceptor, grpcAPI.StreamInterceptor),
+	}
+	if tlsReloader != nil {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(tlsReloader.ServerConfig())))
+	}
+	grpcServer := grpc.NewServer(serverOpts...)
+	hlfapiv1.RegisterTransactionServiceServer(grpcServer, grpcAPI)
+	healthpb.RegisterHealthServer(grpcServer, health.NewGRPCServer(healthChecker, hlfapiv1.TransactionService_ServiceDesc.ServiceName))
+	reflection.Register(grpcServer)
+	return grpcServer
+}
+
+// probeHandler serves the health probes and metrics of the default and the
+// additional networks without TLS, on the probe port
+func probeHandler(healthChecker *health.Checker, apiMetrics *metrics.Metrics, networks []*network) http.Handler {
+	r := chi.NewRouter()
+	r.Get("/livez", healthChecker.LivezHandler)
+	r.Get("/readyz", healthChecker.ReadyzHandler)
+	r.Handle("/metrics", apiMetrics.Handler())
+	for _, n := range networks {
+		r.Route("/networks/"+n.name, func(r chi.Router) {
+			r.Get("/livez", n.healthChecker.LivezHandler)
+			r.Get("/readyz", n.healthChecker.ReadyzHandler)
+			r.Handle("/metrics", n.metrics.Handler())
+		})
+	}
+	return r
+}
+
+// shutdown stops the HTTP and gRPC servers gracefully: it reports not ready,
+// stops accepting requests, ends the event streams and waits, up to
+// --shutdown-timeout, for the running requests and Fabric operations to finish
+// before the background tasks are stopped
+func shutdown(server, probeServer *http.Server, grpcServer *grpc.Server, grpcAPI *grpcapi.Server, fabricClient *fabric.FabricClient, healthChecker *health.Checker, networks []*network, cfg config.Shutdown, cancelBackground context.CancelFunc) {
+	slog.Info("shutting down", "delay", cfg.Delay.String(), "timeout", cfg.Timeout.String())
+	healthChecker.SetDraining()
+	for _, n := range networks {
+		n.healthChecker.SetDraining()
+	}
+	// Give load balancers time to observe the failing readiness probe
+	time.Sleep(cfg.Delay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+	var grpcServers []*grpc.Server
+	if grpcServer != nil {
+		grpcAPI.Shutdown()
+		grpcServers = append(grpcServers, grpcServer)
+	}
+	for _, n := range networks {
+		if n.grpcServer != nil {
+			n.grpcAPI.Shutdown()
+			grpcServers = append(grpcServers, n.grpcServer)
+		}
+	}
+	grpcStopped := make(chan struct{})
+	go func() {
+		var wg sync.WaitGroup
+		for _, s := range grpcServers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.GracefulStop()
+			}()
+		}
+		wg.Wait()
+		close(grpcStopped)
+	}()
+	if err := server.Shutdown(ctx); err != nil {
+		slog.Warn("requests still running at the shutdown deadline were aborted", "error", err)
+		server.Close()
+	}
+	if len(grpcServers) > 0 {
+		select {
+		case <-grpcStopped:
+		case <-ctx.Done():
+			slog.Warn("gRPC calls still running at the shutdown deadline were aborted")
+			for _, s := range grpcServers {
+				s.Stop()
+			}
+		}
+	}
+	if err := fabricClient.Drain(ctx); err != nil {
+		slog.Warn("fabric operations still running at the shutdown deadline were abandoned", "in_flight", fabricClient.InFlight(), "error", err)
+	}
+	for _, n := range networks {
+		if err := n.fabricClient.Drain(ctx); err != nil {
+			slog.Warn("fabric operations still running at the shutdown deadline were abandoned", "network", n.name, "in_flight", n.fabricClient.InFlight(), "error", err)
+		}
+	}
+	// The probe server keeps answering until the end, so that the draining
+	// status stays visible
+	if probeServer != nil {
+		probeServer.Close()
+	}
+	cancelBackground()
+	slog.Info("server stopped")
 }
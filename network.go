@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/grpc"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/api"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/config"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/contract"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/graphqlapi"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/grpcapi"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/health"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/idempotency"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/indexer"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/logging"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/metrics"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/offline"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/outbox"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/ratelimit"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/schema"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/webhook"
+)
+
+// apiServices serve the /api routes of a network. The optional services are
+// nil when they are disabled, and their routes are then not mounted.
+type apiServices struct {
+	handler     *api.Handler
+	authChain   *auth.Chain
+	limiter     *ratelimit.Limiter
+	idempotency *idempotency.Manager
+	contracts   *contract.Registry
+	offline     *offline.Manager
+	ledgerIndex *indexer.Indexer
+	outbox      *outbox.Manager
+	webhooks    *webhook.Manager
+}
+
+// routes registers the authentication, rate limits and API routes of the services
+func (s *apiServices) routes(r chi.Router) {
+	if s.authChain != nil {
+		r.Use(s.authChain.Middleware)
+	}
+	if s.limiter != nil {
+		r.Use(s.limiter.Middleware)
+	}
+	r.With(s.handler.RequireScope(auth.OperationInvoke), s.idempotency.Middleware, s.handler.LimitChaincode(auth.OperationInvoke)).Post("/invoke", s.handler.InvokeHandler)
+	r.With(s.handler.RequireScope(auth.OperationEvaluate), s.handler.LimitChaincode(auth.OperationEvaluate)).Post("/evaluate", s.handler.EvaluateHandler)
+	r.With(s.handler.RequireScope(auth.OperationInvoke), s.handler.LimitChaincode(auth.OperationInvoke)).Post("/simulate", s.handler.SimulateHandler)
+	r.With(s.idempotency.Middleware).Post("/batch", s.handler.BatchHandler)
+	r.Get("/quota", s.handler.QuotaHandler)
+	if s.contracts != nil {
+		r.With(s.idempotency.Middleware).Post("/chaincodes/{cc}/contracts/{contract}/{fn}", s.handler.ContractTransactionHandler)
+	}
+	if s.offline != nil {
+		r.Route("/offline/transactions", func(r chi.Router) {
+			r.Post("/", s.offline.PrepareHandler)
+			r.Get("/{handle}", s.offline.GetHandler)
+			r.Delete("/{handle}", s.offline.DiscardHandler)
+			r.Post("/{handle}/endorse", s.offline.EndorseHandler)
+			r.Post("/{handle}/submit", s.offline.SubmitHandler)
+			r.Post("/{handle}/commit", s.offline.CommitHandler)
+		})
+	}
+	if s.ledgerIndex != nil {
+		r.Get("/index/transactions", s.ledgerIndex.SearchHandler)
+		r.Get("/index/status", s.ledgerIndex.StatusHandler)
+		r.Get("/chaincodes/{cc}/keys/{key}", s.ledgerIndex.StateHandler)
+		r.Get("/chaincodes/{cc}/keys/{key}/history", s.ledgerIndex.HistoryHandler)
+	}
+	if s.outbox != nil {
+		r.Route("/outbox", func(r chi.Router) {
+			r.Get("/", s.outbox.ListHandler)
+			r.Get("/dead-letters", s.outbox.DeadLettersHandler)
+			r.Post("/dead-letters/{id}/retry", s.outbox.RetryHandler)
+			r.Delete("/dead-letters/{id}", s.outbox.DiscardHandler)
+			r.Get("/{id}", s.outbox.GetHandler)
+		})
+	}
+	if s.webhooks != nil {
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Post("/", s.webhooks.CreateHandler)
+			r.Get("/", s.webhooks.ListHandler)
+			r.Get("/{id}", s.webhooks.GetHandler)
+			r.Delete("/{id}", s.webhooks.DeleteHandler)
+			r.Get("/{id}/dead-letters", s.webhooks.DeadLettersHandler)
+			r.Post("/{id}/dead-letters/redeliver", s.webhooks.RedeliverAllHandler)
+			r.Post("/{id}/dead-letters/{letter}/redeliver", s.webhooks.RedeliverHandler)
+			r.Delete("/{id}/dead-letters/{letter}", s.webhooks.DeleteDeadLetterHandler)
+		})
+	}
+}
+
+// network is an additional Fabric network served under /networks/{name}. It
+// has its own client, metrics, health checks, authentication, rate limit
+// buckets, stores and gRPC port, so that its load and its failures do not
+// show up on the other networks.
+type network struct {
+	apiServices
+	name          string
+	fabricClient  *fabric.FabricClient
+	metrics       *metrics.Metrics
+	healthChecker *health.Checker
+	graphqlAPI    *graphqlapi.Server
+	// grpcAPI is nil when the network has no gRPC port; grpcServer is
+	// created once the TLS configuration of the server is known
+	grpcAPI    *grpcapi.Server
+	grpcServer *grpc.Server
+	// closers release the stores and the client, in reverse order
+	closers []func()
+}
+
+// newNetworks creates the networks of the configuration, sorted by name
+func newNetworks(cfg *config.Config, idempotencyManager *idempotency.Manager) ([]*network, error) {
+	names := make([]string, 0, len(cfg.Networks))
+	for name := range cfg.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var networks []*network
+	for _, name := range names {
+		n, err := newNetwork(cfg, name, idempotencyManager)
+		if err != nil {
+			for _, created := range networks {
+				created.close()
+			}
+			return nil, fmt.Errorf("network %s: %w", name, err)
+		}
+		networks = append(networks, n)
+	}
+	return networks, nil
+}
+
+func newNetwork(cfg *config.Config, name string, idempotencyManager *idempotency.Manager) (*network, error) {
+	networkCfg := cfg.Networks[name]
+	fabricClient, err := fabric.NewFabricClient(networkCfg.Fabric.ClientConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Fabric client: %w", err)
+	}
+	n := &network{
+		name:         name,
+		fabricClient: fabricClient,
+		metrics:      metrics.New(),
+		closers:      []func(){func() { fabricClient.Close() }},
+	}
+	n.idempotency = idempotencyManager
+	if err := n.setup(cfg, networkCfg); err != nil {
+		n.close()
+		return nil, err
+	}
+	return n, nil
+}
+
+// setup creates the services enabled in the configuration of the network
+func (n *network) setup(cfg *config.Config, networkCfg config.Network) error {
+	n.fabricClient.AddObserver(n.metrics)
+	n.fabricClient.AddTransactionListener(n.metrics.RecordTransaction)
+	for _, file := range n.fabricClient.CertificateFiles() {
+		n.metrics.WatchCertificate(file.Kind, file.Name, file.Path)
+	}
+
+	if authCfg := cfg.NetworkAuth(n.name); authCfg != nil {
+		authenticators, err := authCfg.Authenticators()
+		if err != nil {
+			return fmt.Errorf("failed to configure authentication: %w", err)
+		}
+		n.authChain = auth.NewChain(authenticators)
+	}
+
+	handlerOpts := []api.HandlerOption{api.WithBatchLimits(cfg.Batch.Parallelism, cfg.Batch.MaxOperations)}
+	if rateLimits := cfg.NetworkRateLimits(n.name); rateLimits != nil {
+		n.limiter = ratelimit.NewLimiter(*rateLimits)
+		handlerOpts = append(handlerOpts, api.WithRateLimiter(n.limiter))
+	}
+	var schemas *schema.Registry
+	if networkCfg.Schemas.Dir != "" {
+		var err error
+		schemas, err = schema.Load(networkCfg.Schemas.Dir)
+		if err != nil {
+			return fmt.Errorf("failed to load function schemas: %w", err)
+		}
+		handlerOpts = append(handlerOpts, api.WithSchemas(schemas))
+	}
+	if len(networkCfg.Contracts.Chaincodes) > 0 {
+		n.contracts = contract.NewRegistry(n.fabricClient, networkCfg.Contracts.Chaincodes, networkCfg.Contracts.Refresh)
+		handlerOpts = append(handlerOpts, api.WithContracts(n.contracts))
+	}
+
+	if networkCfg.Webhooks.DB != "" {
+		store, err := webhook.NewStore(networkCfg.Webhooks.DB)
+		if err != nil {
+			return fmt.Errorf("failed to open webhook store: %w", err)
+		}
+		n.closers = append(n.closers, func() { store.Close() })
+		n.webhooks = webhook.NewManager(store, n.fabricClient, networkCfg.Webhooks.WebhookConfig())
+	}
+	if networkCfg.Indexer.DB != "" {
+		store, err := indexer.NewStore(networkCfg.Indexer.DB)
+		if err != nil {
+			return fmt.Errorf("failed to open index store: %w", err)
+		}
+		n.closers = append(n.closers, func() { store.Close() })
+		n.ledgerIndex = indexer.New(store, n.fabricClient, networkCfg.Indexer.StartBlock)
+	}
+	if networkCfg.Outbox.DB != "" {
+		store, err := outbox.NewStore(networkCfg.Outbox.DB)
+		if err != nil {
+			return fmt.Errorf("failed to open outbox store: %w", err)
+		}
+		n.closers = append(n.closers, func() { store.Close() })
+		n.outbox = outbox.NewManager(store, n.fabricClient, outbox.Config{
+			MinBackoff: networkCfg.Outbox.MinBackoff,
+			MaxBackoff: networkCfg.Outbox.MaxBackoff,
+			Retention:  networkCfg.Outbox.Retention,
+		})
+		handlerOpts = append(handlerOpts, api.WithOutbox(n.outbox))
+	}
+	if networkCfg.Offline.Enabled {
+		var offlineOpts []offline.Option
+		if n.limiter != nil {
+			offlineOpts = append(offlineOpts, offline.WithRateLimiter(n.limiter))
+		}
+		if schemas != nil {
+			offlineOpts = append(offlineOpts, offline.WithSchemas(schemas))
+		}
+		n.offline = offline.NewManager(n.fabricClient, networkCfg.Offline.TTL, offlineOpts...)
+		n.closers = append(n.closers, n.offline.Close)
+	}
+	n.handler = api.NewHandler(n.fabricClient, handlerOpts...)
+
+	var graphqlOpts []graphqlapi.Option
+	if n.authChain != nil {
+		graphqlOpts = append(graphqlOpts, graphqlapi.WithAuthentication(n.authChain))
+	}
+	if n.limiter != nil {
+		graphqlOpts = append(graphqlOpts, graphqlapi.WithRateLimiter(n.limiter))
+	}
+	if schemas != nil {
+		graphqlOpts = append(graphqlOpts, graphqlapi.WithSchemas(schemas))
+	}
+	graphqlAPI, err := graphqlapi.NewServer(n.fabricClient, graphqlOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to build the GraphQL schema: %w", err)
+	}
+	n.graphqlAPI = graphqlAPI
+
+	if networkCfg.GRPC.Port != "" {
+		var grpcOpts []grpcapi.Option
+		if n.authChain != nil {
+			grpcOpts = append(grpcOpts, grpcapi.WithAuthentication(n.authChain))
+		}
+		if n.limiter != nil {
+			grpcOpts = append(grpcOpts, grpcapi.WithRateLimiter(n.limiter))
+		}
+		if schemas != nil {
+			grpcOpts = append(grpcOpts, grpcapi.WithSchemas(schemas))
+		}
+		n.grpcAPI = grpcapi.NewServer(n.fabricClient, grpcOpts...)
+	}
+
+	minPeers := networkCfg.ReadyMinPeers
+	if minPeers == 0 {
+		minPeers = 1
+	}
+	n.healthChecker = health.NewChecker(n.fabricClient, health.Config{
+		MinPeers: minPeers,
+		Interval: cfg.Health.Interval,
+		Timeout:  cfg.Health.Timeout,
+	})
+	return nil
+}
+
+// run starts the health checks and the background workers of the network
+// until ctx is cancelled
+func (n *network) run(ctx context.Context) {
+	go n.healthChecker.Run(ctx)
+	if n.webhooks != nil {
+		go n.webhooks.Run(ctx)
+	}
+	if n.ledgerIndex != nil {
+		go n.ledgerIndex.Run(ctx)
+	}
+	if n.outbox != nil {
+		go n.outbox.Run(ctx)
+	}
+	if n.contracts != nil {
+		go n.contracts.Run(ctx)
+	}
+	if n.offline != nil {
+		go n.offline.Run(ctx)
+	}
+}
+
+// close releases the stores and the Fabric client of the network
+func (n *network) close() {
+	for i := len(n.closers) - 1; i >= 0; i-- {
+		n.closers[i]()
+	}
+}
+
+// routes returns the health, metrics, API and GraphQL routes of the network
+func (n *network) routes() http.Handler {
+	r := chi.NewRouter()
+	r.Use(n.metrics.Middleware)
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logging.Add(r.Context(), slog.String("network", n.name))
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	r.Get("/livez", n.healthChecker.LivezHandler)
+	r.Get("/readyz", n.healthChecker.ReadyzHandler)
+	r.Handle("/metrics", n.metrics.Handler())
+	r.Route("/api", n.apiServices.routes)
+	r.Handle(graphqlapi.Route, n.graphqlAPI)
+	return r
+}
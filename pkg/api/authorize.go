@@ -0,0 +1,77 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+)
+
+// RequireScope rejects transaction requests that the authenticated principal
+// is not allowed to perform. The body is decoded once, the way the handlers
+// decode it, so the scope is checked against the transaction the handler runs;
+// a body that cannot be decoded or names no chaincode is rejected rather than
+// let through unchecked.
+func (h *Handler) RequireScope(op auth.Operation) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r, req, err := decodeTransactionRequest(r)
+			if err != nil {
+				sendBodyError(w, err)
+				return
+			}
+			if principal := auth.FromContext(r.Context()); principal != nil {
+				if req.ChaincodeName == "" {
+					sendErrorResponse(w, http.StatusBadRequest, "chaincode_name is required")
+					return
+				}
+				if !principal.Allows(h.fabricClient.ChannelName(), req.ChaincodeName, req.Function, op) {
+					sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("%s is not allowed to %s %s on chaincode %s", principal.Name, op, req.Function, req.ChaincodeName))
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type transactionRequestKey struct{}
+
+// decodeTransactionRequest returns the transaction request of r, decoding the
+// body the first time it is called. The returned request carries the decoded
+// request in its context for the middleware and handler that follow, and its
+// body can still be read, as the idempotency middleware does.
+func decodeTransactionRequest(r *http.Request) (*http.Request, *TransactionRequest, error) {
+	if req, ok := r.Context().Value(transactionRequestKey{}).(*TransactionRequest); ok {
+		return r, req, nil
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return r, nil, err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	var req TransactionRequest
+	if err := json.NewDecoder(bytes.NewReader(body)).Decode(&req); err != nil {
+		return r, nil, err
+	}
+	return r.WithContext(context.WithValue(r.Context(), transactionRequestKey{}, &req)), &req, nil
+}
+
+// authorizeBatch checks every operation of a batch against the authenticated principal
+func (h *Handler) authorizeBatch(r *http.Request, req BatchRequest) error {
+	principal := auth.FromContext(r.Context())
+	if principal == nil {
+		return nil
+	}
+	for i, op := range req.Operations {
+		if !principal.Allows(h.fabricClient.ChannelName(), op.ChaincodeName, op.Function, auth.Operation(op.Type)) {
+			return fmt.Errorf("operations[%d]: %s is not allowed to %s %s on chaincode %s", i, principal.Name, op.Type, op.Function, op.ChaincodeName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+)
+
+func TestRequireScope(t *testing.T) {
+	h := newTestHandler(t)
+	var reached *TransactionRequest
+	scoped := h.RequireScope(auth.OperationInvoke)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, reached, _ = decodeTransactionRequest(r)
+	}))
+	principal := &auth.Principal{Name: "ci", Method: "api_key", Scopes: []auth.Scope{{Chaincodes: []string{"basic"}}}}
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{name: "allowed", body: `{"chaincode_name": "basic", "function": "f"}`, status: http.StatusOK},
+		{name: "chaincode out of scope", body: `{"chaincode_name": "secret", "function": "f"}`, status: http.StatusForbidden},
+		// The handlers decode the first JSON value and ignore what follows it
+		{name: "trailing data", body: `{"chaincode_name": "secret", "function": "f"} x`, status: http.StatusForbidden},
+		{name: "malformed body", body: `{"chaincode_name": "secret"`, status: http.StatusBadRequest},
+		{name: "missing chaincode", body: `{"function": "f"}`, status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reached = nil
+			r := httptest.NewRequest(http.MethodPost, "/api/invoke", strings.NewReader(tt.body))
+			r = r.WithContext(auth.NewContext(r.Context(), principal))
+			w := httptest.NewRecorder()
+			scoped.ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status != http.StatusOK && reached != nil {
+				t.Error("the handler was reached")
+			}
+			if tt.status == http.StatusOK && (reached == nil || reached.ChaincodeName != "basic") {
+				t.Errorf("handler decoded %+v, want the checked request", reached)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/logging"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/ratelimit"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/response"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/schema"
+)
+
+const (
+	defaultBatchParallelism   = 10
+	defaultBatchMaxOperations = 1000
+
+	// OperationInvoke submits the transaction to the ledger
+	OperationInvoke = "invoke"
+	// OperationEvaluate queries the chaincode without submitting
+	OperationEvaluate = "evaluate"
+)
+
+// BatchOperation represents a single operation inside a batch request
+// @Description Single invoke or evaluate operation of a batch request
+type BatchOperation struct {
+	// Operation type ("invoke" or "evaluate")
+	Type string `json:"type" example:"evaluate"`
+	// Name of the chaincode to invoke
+	ChaincodeName string `json:"chaincode_name" example:"mycc"`
+	// Function name to call in the chaincode
+	Function string `json:"function" example:"readAsset"`
+	// Arguments to pass to the chaincode function
+	Args []string `json:"args" example:"[\"asset1\"]"`
+}
+
+// BatchRequest represents a batch of operations executed in a single HTTP request
+// @Description Batch of invoke and evaluate operations
+type BatchRequest struct {
+	// Operations to execute, results are returned in the same order
+	Operations []BatchOperation `json:"operations"`
+	// Maximum number of operations executed concurrently, capped by the server limit
+	Parallelism int `json:"parallelism,omitempty" example:"5"`
+	// Skip the operations that have not started yet once an invoke fails
+	StopOnError bool `json:"stop_on_error,omitempty" example:"false"`
+}
+
+// BatchResponse represents the outcome of a batch request
+// @Description Per-operation results of a batch request, in request order
+type BatchResponse struct {
+	// Status of the batch ("success" when every operation succeeded, "partial_failure" otherwise)
+	Status string `json:"status" example:"success"`
+	// Number of operations that succeeded
+	Succeeded int `json:"succeeded" example:"2"`
+	// Number of operations that failed
+	Failed int `json:"failed" example:"0"`
+	// Number of operations that were not executed
+	Skipped int `json:"skipped" example:"0"`
+	// Result of each operation, in the same order as the request
+	Results []TransactionResponse `json:"results"`
+}
+
+// BatchHandler godoc
+// @Summary Execute a batch of chaincode transactions
+// @Description Executes many invoke and evaluate operations concurrently and returns per-operation results in request order. Operations calling functions with a registered schema are validated before any operation runs, and the batch is rejected with the invalid fields of the first invalid operation.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param request body BatchRequest true "Batch Request"
+// @Param Idempotency-Key header string false "Key used to deduplicate retries; the first outcome is replayed for the same key"
+// @Success 200 {object} BatchResponse
+// @Failure 400 {object} TransactionResponse
+// @Failure 401 {object} TransactionResponse
+// @Failure 403 {object} TransactionResponse
+// @Failure 422 {object} TransactionResponse
+// @Failure 429 {object} TransactionResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/batch [post]
+func (h *Handler) BatchHandler(w http.ResponseWriter, r *http.Request) {
+	var req BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendBodyError(w, err)
+		return
+	}
+
+	if len(req.Operations) == 0 {
+		sendErrorResponse(w, http.StatusBadRequest, "operations is required")
+		return
+	}
+	if len(req.Operations) > h.batchMaxOperations {
+		sendErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("batch exceeds the maximum of %d operations", h.batchMaxOperations))
+		return
+	}
+	for i, op := range req.Operations {
+		if op.Type != OperationInvoke && op.Type != OperationEvaluate {
+			sendErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("operations[%d]: type must be %q or %q", i, OperationInvoke, OperationEvaluate))
+			return
+		}
+		if op.ChaincodeName == "" {
+			sendErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("operations[%d]: chaincode_name is required", i))
+			return
+		}
+		if err := h.schemas.Check(op.ChaincodeName, op.Function, op.Args, nil); err != nil {
+			fields := make([]schema.FieldError, len(err.Fields))
+			for j, field := range err.Fields {
+				fields[j] = schema.FieldError{Field: fmt.Sprintf("/operations/%d%s", i, field.Field), Message: field.Message}
+			}
+			response.JSON(w, http.StatusBadRequest, TransactionResponse{
+				Status: "error",
+				Error:  fmt.Sprintf("operations[%d]: %s", i, err),
+				Fields: fields,
+			})
+			return
+		}
+	}
+
+	if err := h.authorizeBatch(r, req); err != nil {
+		sendErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	results := h.executeBatch(r.Context(), ratelimit.ClientKey(r), req)
+
+	resp := BatchResponse{Results: results}
+	for _, result := range results {
+		switch result.Status {
+		case "success":
+			resp.Succeeded++
+		case "skipped":
+			resp.Skipped++
+		default:
+			resp.Failed++
+		}
+	}
+	resp.Status = "success"
+	if resp.Failed > 0 || resp.Skipped > 0 {
+		resp.Status = "partial_failure"
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// executeBatch runs the operations with a bounded number of workers. Operations
+// are started in request order, so with stop_on_error every operation that was
+// not started before the failing invoke completed is reported as skipped.
+func (h *Handler) executeBatch(ctx context.Context, client string, req BatchRequest) []TransactionResponse {
+	workers := h.batchParallelism
+	if req.Parallelism > 0 && req.Parallelism < workers {
+		workers = req.Parallelism
+	}
+	if workers > len(req.Operations) {
+		workers = len(req.Operations)
+	}
+
+	results := make([]TransactionResponse, len(req.Operations))
+	var stopped atomic.Bool
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for n := 0; n < workers; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if stopped.Load() {
+					results[i] = skippedResponse("skipped after a previous invoke failed")
+					continue
+				}
+				select {
+				case h.batchSlots <- struct{}{}:
+				case <-ctx.Done():
+					results[i] = skippedResponse("request cancelled")
+					continue
+				}
+				opCtx := logging.With(ctx, slog.Int("batch_operation", i))
+				results[i] = h.executeBatchOperation(opCtx, client, req.Operations[i])
+				<-h.batchSlots
+
+				if req.StopOnError && req.Operations[i].Type == OperationInvoke && results[i].Status != "success" {
+					stopped.Store(true)
+				}
+			}
+		}()
+	}
+	for i := range req.Operations {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// executeBatchOperation runs a single operation. Per-chaincode rate limits apply
+// to each operation and are reported as the operation's error.
+func (h *Handler) executeBatchOperation(ctx context.Context, client string, op BatchOperation) TransactionResponse {
+	if h.limiter != nil {
+		if err := h.limiter.AllowChaincode(client, op.ChaincodeName); err != nil {
+			return TransactionResponse{Status: "error", Error: err.Error()}
+		}
+	}
+
+	if op.Type == OperationInvoke {
+		if h.limiter != nil {
+			release, err := h.limiter.AcquireInvoke(client, op.ChaincodeName)
+			if err != nil {
+				return TransactionResponse{Status: "error", Error: err.Error()}
+			}
+			defer release()
+		}
+
+		txResult, err := h.fabricClient.InvokeTransaction(ctx, op.ChaincodeName, op.Function, op.Args)
+		if err != nil {
+			return invokeErrorResponse(err)
+		}
+		return newInvokeResponse(txResult)
+	}
+
+	result, err := h.fabricClient.EvaluateTransaction(ctx, op.ChaincodeName, op.Function, op.Args)
+	if err != nil {
+		return TransactionResponse{Status: "error", Error: err.Error()}
+	}
+	return TransactionResponse{
+		Status: "success",
+		Result: string(result),
+	}
+}
+
+func skippedResponse(reason string) TransactionResponse {
+	return TransactionResponse{
+		Status: "skipped",
+		Error:  reason,
+	}
+}
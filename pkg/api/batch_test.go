@@ -0,0 +1,130 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+)
+
+// newTestHandler creates a handler whose peer cannot be reached, so that every
+// Fabric call fails without a network
+func newTestHandler(t *testing.T, opts ...HandlerOption) *Handler {
+	t.Helper()
+	fabricClient, err := fabric.NewFabricClient(&fabric.ClientConfig{
+		MspID:       "Org1MSP",
+		CertPath:    t.TempDir() + "/missing-cert.pem",
+		KeyPath:     t.TempDir() + "/missing-key.pem",
+		ChannelName: "mychannel",
+		Peers:       []fabric.PeerConfig{{Endpoint: "127.0.0.1:1", TLSCertPath: t.TempDir() + "/missing-ca.pem"}},
+	})
+	if err != nil {
+		t.Fatalf("NewFabricClient: %v", err)
+	}
+	t.Cleanup(func() { fabricClient.Close() })
+	return NewHandler(fabricClient, opts...)
+}
+
+func postBatch(t *testing.T, h *Handler, principal *auth.Principal, req BatchRequest) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest(http.MethodPost, "/api/batch", bytes.NewReader(body))
+	if principal != nil {
+		r = r.WithContext(auth.NewContext(r.Context(), principal))
+	}
+	w := httptest.NewRecorder()
+	h.BatchHandler(w, r)
+	return w
+}
+
+func TestBatchHandlerRejectsInvalidBatches(t *testing.T) {
+	h := newTestHandler(t, WithBatchLimits(2, 2))
+	evaluate := BatchOperation{Type: OperationEvaluate, ChaincodeName: "basic", Function: "ReadAsset"}
+
+	tests := []struct {
+		name    string
+		req     BatchRequest
+		message string
+	}{
+		{name: "no operations", req: BatchRequest{}, message: "operations is required"},
+		{name: "too many operations", req: BatchRequest{Operations: []BatchOperation{evaluate, evaluate, evaluate}}, message: "maximum of 2 operations"},
+		{name: "unknown type", req: BatchRequest{Operations: []BatchOperation{{Type: "query", ChaincodeName: "basic"}}}, message: "operations[0]: type must be"},
+		{name: "missing chaincode", req: BatchRequest{Operations: []BatchOperation{evaluate, {Type: OperationInvoke}}}, message: "operations[1]: chaincode_name is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postBatch(t, h, nil, tt.req)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+			if !strings.Contains(w.Body.String(), tt.message) {
+				t.Errorf("body = %s, want it to contain %q", w.Body.String(), tt.message)
+			}
+		})
+	}
+}
+
+func TestBatchHandlerValidatesSchemas(t *testing.T) {
+	h := newTestHandler(t, WithSchemas(loadTestSchemas(t)))
+	w := postBatch(t, h, nil, BatchRequest{Operations: []BatchOperation{
+		{Type: OperationInvoke, ChaincodeName: "basic", Function: "CreateAsset", Args: []string{"asset1"}},
+		{Type: OperationInvoke, ChaincodeName: "basic", Function: "CreateAsset", Args: []string{""}},
+	}})
+	var resp TransactionResponse
+	if w.Code != http.StatusBadRequest || json.Unmarshal(w.Body.Bytes(), &resp) != nil {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body)
+	}
+	if !strings.HasPrefix(resp.Error, "operations[1]: ") || len(resp.Fields) != 1 || resp.Fields[0].Field != "/operations/1/args/0" {
+		t.Errorf("response = %+v, want the invalid field of the second operation", resp)
+	}
+}
+
+func TestBatchHandlerAuthorizesEveryOperation(t *testing.T) {
+	h := newTestHandler(t)
+	reader := &auth.Principal{
+		Name:   "reporting",
+		Method: "api_key",
+		Scopes: []auth.Scope{{Operations: []auth.Operation{auth.OperationEvaluate}}},
+	}
+	w := postBatch(t, h, reader, BatchRequest{Operations: []BatchOperation{
+		{Type: OperationEvaluate, ChaincodeName: "basic", Function: "ReadAsset"},
+		{Type: OperationInvoke, ChaincodeName: "basic", Function: "CreateAsset"},
+	}})
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+	if !strings.Contains(w.Body.String(), "operations[1]") {
+		t.Errorf("body = %s, want the rejected operation to be named", w.Body.String())
+	}
+}
+
+func TestBatchHandlerStopOnError(t *testing.T) {
+	h := newTestHandler(t)
+	invoke := BatchOperation{Type: OperationInvoke, ChaincodeName: "basic", Function: "CreateAsset"}
+	w := postBatch(t, h, nil, BatchRequest{
+		Operations:  []BatchOperation{invoke, invoke, invoke},
+		Parallelism: 1,
+		StopOnError: true,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp BatchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != "partial_failure" || resp.Failed != 1 || resp.Skipped != 2 {
+		t.Fatalf("response = %+v, want 1 failed and 2 skipped operations", resp)
+	}
+	if len(resp.Results) != 3 || resp.Results[0].Status != "error" || resp.Results[1].Status != "skipped" || resp.Results[2].Status != "skipped" {
+		t.Errorf("results = %+v, want the results in request order", resp.Results)
+	}
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/contract"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/metrics"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/ratelimit"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/response"
+)
+
+// WithContracts serves the transactions described by the contract metadata
+// of the registry's chaincodes
+func WithContracts(contracts *contract.Registry) HandlerOption {
+	return func(h *Handler) {
+		h.contracts = contracts
+	}
+}
+
+// ContractTransactionHandler godoc
+// @Summary Call a contract transaction with named parameters
+// @Description Submits or evaluates a transaction of a contract, as marked in the chaincode's metadata, taking its parameters by name instead of as positional arguments. Only the chaincodes configured for contract routes are served; each has its own OpenAPI document at /swagger/chaincodes/{cc}/doc.json.
+// @Tags contracts
+// @Accept json
+// @Produce json
+// @Param cc path string true "Chaincode name"
+// @Param contract path string true "Contract name"
+// @Param fn path string true "Transaction name"
+// @Param request body object true "Parameters of the transaction by name"
+// @Param Idempotency-Key header string false "Key used to deduplicate retries of submitted transactions; the first outcome is replayed for the same key"
+// @Success 200 {object} TransactionResponse
+// @Success 202 {object} TransactionResponse
+// @Failure 400 {object} TransactionResponse
+// @Failure 401 {object} TransactionResponse
+// @Failure 403 {object} TransactionResponse
+// @Failure 404 {object} TransactionResponse
+// @Failure 429 {object} TransactionResponse
+// @Failure 500 {object} TransactionResponse
+// @Failure 503 {object} TransactionResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/chaincodes/{cc}/contracts/{contract}/{fn} [post]
+func (h *Handler) ContractTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	chaincodeName := chi.URLParam(r, "cc")
+	if h.contracts == nil {
+		sendErrorResponse(w, http.StatusNotFound, "contract routes are not enabled")
+		return
+	}
+	cc, configured := h.contracts.Chaincode(chaincodeName)
+	if !configured {
+		sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("chaincode %s is not served by contract routes", chaincodeName))
+		return
+	}
+	if cc == nil {
+		sendErrorResponse(w, http.StatusServiceUnavailable, fmt.Sprintf("the metadata of chaincode %s has not been fetched yet", chaincodeName))
+		return
+	}
+	tx, ok := cc.Transaction(chi.URLParam(r, "contract"), chi.URLParam(r, "fn"))
+	if !ok {
+		sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("chaincode %s has no transaction %s:%s", chaincodeName, chi.URLParam(r, "contract"), chi.URLParam(r, "fn")))
+		return
+	}
+
+	op := auth.OperationEvaluate
+	if tx.Submit {
+		op = auth.OperationInvoke
+	}
+	if principal := auth.FromContext(r.Context()); principal != nil && !principal.Allows(h.fabricClient.ChannelName(), chaincodeName, tx.Function(), op) {
+		sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("%s is not allowed to %s %s on chaincode %s", principal.Name, op, tx.Function(), chaincodeName))
+		return
+	}
+
+	body := map[string]json.RawMessage{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		sendBodyError(w, err)
+		return
+	}
+	args, fields := tx.Args(body)
+	if len(fields) > 0 {
+		response.JSON(w, http.StatusBadRequest, TransactionResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("invalid parameters for %s on chaincode %s", tx.Function(), chaincodeName),
+			Fields: fields,
+		})
+		return
+	}
+	if err := h.schemas.Check(chaincodeName, tx.Function(), args, nil); err != nil {
+		sendValidationError(w, err)
+		return
+	}
+	metrics.SetTransaction(r.Context(), chaincodeName, tx.Function())
+
+	if h.limiter != nil {
+		client := ratelimit.ClientKey(r)
+		if err := h.limiter.AllowChaincode(client, chaincodeName); err != nil {
+			ratelimit.WriteError(w, err)
+			return
+		}
+		if tx.Submit {
+			release, err := h.limiter.AcquireInvoke(client, chaincodeName)
+			if err != nil {
+				ratelimit.WriteError(w, err)
+				return
+			}
+			defer release()
+		}
+	}
+
+	if tx.Submit {
+		txResult, err := h.fabricClient.InvokeTransaction(r.Context(), chaincodeName, tx.Function(), args)
+		if err != nil {
+			sendInvokeError(w, err)
+			return
+		}
+		response.JSON(w, http.StatusOK, newInvokeResponse(txResult))
+		return
+	}
+
+	result, err := h.fabricClient.EvaluateTransaction(r.Context(), chaincodeName, tx.Function(), args)
+	if err != nil {
+		sendErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	response.JSON(w, http.StatusOK, TransactionResponse{
+		Status: "success",
+		Result: string(result),
+	})
+}
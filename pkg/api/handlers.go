@@ -1,10 +1,18 @@
 package api
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"net/http"
+	"strings"
 
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/contract"
 	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/metrics"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/outbox"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/ratelimit"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/response"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/schema"
 )
 
 // TransactionRequest represents the incoming request structure
@@ -16,12 +24,14 @@ type TransactionRequest struct {
 	Function string `json:"function" example:"createAsset"`
 	// Arguments to pass to the chaincode function
 	Args []string `json:"args" example:"[\"asset1\",\"value1\"]"`
+	// Transient data passed to the chaincode but not recorded on the ledger
+	Transient map[string]string `json:"transient,omitempty"`
 }
 
 // TransactionResponse represents the response structure
 // @Description Response structure for chaincode transactions
 type TransactionResponse struct {
-	// Status of the transaction ("success" or "error")
+	// Status of the transaction ("success" or "error", "skipped" for batch operations that did not run, "queued" for invokes accepted into the outbox, "submitted" for invokes sent for ordering whose commit status is unknown)
 	Status string `json:"status" example:"success"`
 	// Result of the transaction (if successful)
 	Result interface{} `json:"result,omitempty" example:"{\"key\":\"value\"}" swaggertype:"string"`
@@ -35,104 +45,267 @@ type TransactionResponse struct {
 	ResultCode uint32 `json:"result_code,omitempty" example:"200"`
 	// Whether the transaction was successful
 	Success bool `json:"success,omitempty" example:"true"`
+	// Invalid request fields, when the arguments do not match the function's schema
+	Fields []schema.FieldError `json:"fields,omitempty"`
+	// ID of the queued invoke, used to look up its status
+	OutboxID string `json:"outbox_id,omitempty" example:"4f7d0c1e9b2a43d58c6e1f0a2b3c4d5e"`
 }
 
 type Handler struct {
 	fabricClient *fabric.FabricClient
+
+	batchParallelism   int
+	batchMaxOperations int
+	// batchSlots bounds the number of batch operations running concurrently
+	// against the shared Fabric client across all batch requests
+	batchSlots chan struct{}
+
+	limiter   *ratelimit.Limiter
+	schemas   *schema.Registry
+	contracts *contract.Registry
+	outbox    *outbox.Manager
+}
+
+// HandlerOption configures optional behaviour of a Handler
+type HandlerOption func(*Handler)
+
+// WithBatchLimits sets the maximum number of batch operations executed
+// concurrently and the maximum number of operations accepted per batch
+func WithBatchLimits(parallelism, maxOperations int) HandlerOption {
+	return func(h *Handler) {
+		if parallelism > 0 {
+			h.batchParallelism = parallelism
+		}
+		if maxOperations > 0 {
+			h.batchMaxOperations = maxOperations
+		}
+	}
 }
 
-func NewHandler(fabricClient *fabric.FabricClient) *Handler {
-	return &Handler{
-		fabricClient: fabricClient,
+// WithRateLimiter enables the per-chaincode request rate and in-flight invoke limits
+func WithRateLimiter(limiter *ratelimit.Limiter) HandlerOption {
+	return func(h *Handler) {
+		h.limiter = limiter
 	}
 }
 
+// WithSchemas validates the requests of the invoke and evaluate endpoints
+// against the registered function schemas before they are endorsed
+func WithSchemas(schemas *schema.Registry) HandlerOption {
+	return func(h *Handler) {
+		h.schemas = schemas
+	}
+}
+
+// WithOutbox lets invoke requests that prefer an asynchronous response be
+// queued for submission in the background
+func WithOutbox(outbox *outbox.Manager) HandlerOption {
+	return func(h *Handler) {
+		h.outbox = outbox
+	}
+}
+
+func NewHandler(fabricClient *fabric.FabricClient, opts ...HandlerOption) *Handler {
+	h := &Handler{
+		fabricClient:       fabricClient,
+		batchParallelism:   defaultBatchParallelism,
+		batchMaxOperations: defaultBatchMaxOperations,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.batchSlots = make(chan struct{}, h.batchParallelism)
+	return h
+}
+
 // InvokeHandler godoc
 // @Summary Invoke a chaincode transaction
-// @Description Invokes a transaction on the Hyperledger Fabric network
+// @Description Invokes a transaction on the Hyperledger Fabric network. When the transaction was sent for ordering but its commit status could not be obtained, the response is 202 with the status submitted and the transaction ID; the transaction may still be committed and must not be sent again. Requests to functions with a registered schema are validated before endorsement and rejected with the invalid fields. When the outbox is enabled, requests with the header Prefer: respond-async are stored and answered with 202 and the status queued; they are submitted in order in the background and their outcome is looked up under /api/outbox/{id}.
 // @Tags transactions
 // @Accept json
 // @Produce json
 // @Param request body TransactionRequest true "Transaction Request"
+// @Param Idempotency-Key header string false "Key used to deduplicate retries; the first outcome is replayed for the same key"
+// @Param Prefer header string false "respond-async to queue the invoke in the outbox"
 // @Success 200 {object} TransactionResponse
+// @Success 202 {object} TransactionResponse
 // @Failure 400 {object} TransactionResponse
+// @Failure 401 {object} TransactionResponse
+// @Failure 403 {object} TransactionResponse
+// @Failure 422 {object} TransactionResponse
+// @Failure 429 {object} TransactionResponse
 // @Failure 500 {object} TransactionResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /api/invoke [post]
 func (h *Handler) InvokeHandler(w http.ResponseWriter, r *http.Request) {
-	var req TransactionRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		sendErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+	_, decoded, err := decodeTransactionRequest(r)
+	if err != nil {
+		sendBodyError(w, err)
 		return
 	}
 
+	req := *decoded
 	if req.ChaincodeName == "" {
 		sendErrorResponse(w, http.StatusBadRequest, "chaincode_name is required")
 		return
 	}
-
-	txResult, err := h.fabricClient.InvokeTransaction(r.Context(), req.ChaincodeName, req.Function, req.Args)
+	ctx, ok := h.validate(w, r, req)
+	if !ok {
+		return
+	}
+	if h.outbox != nil && prefersAsync(r) {
+		item, err := h.outbox.Enqueue(r.Context(), outbox.Request{
+			ChaincodeName: req.ChaincodeName,
+			Function:      req.Function,
+			Args:          req.Args,
+			Transient:     req.Transient,
+		})
+		if err != nil {
+			sendErrorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Preference-Applied", "respond-async")
+		response.JSON(w, http.StatusAccepted, TransactionResponse{Status: "queued", OutboxID: item.ID})
+		return
+	}
+	txResult, err := h.fabricClient.InvokeTransaction(ctx, req.ChaincodeName, req.Function, req.Args)
 	if err != nil {
-		sendErrorResponse(w, http.StatusInternalServerError, err.Error())
+		sendInvokeError(w, err)
 		return
 	}
 
-	response := TransactionResponse{
-		Status:      "success",
-		Result:      string(txResult.Result),
-		TxID:        txResult.TxID,
-		Success:     txResult.Success,
-		BlockNumber: txResult.BlockNumber,
-		ResultCode:  txResult.ResultCode,
-	}
-	sendJSONResponse(w, http.StatusOK, response)
+	response.JSON(w, http.StatusOK, newInvokeResponse(txResult))
 }
 
 // EvaluateHandler godoc
 // @Summary Evaluate a chaincode transaction
-// @Description Evaluates a transaction on the Hyperledger Fabric network without committing it
+// @Description Evaluates a transaction on the Hyperledger Fabric network without committing it. Requests to functions with a registered schema are validated first and rejected with the invalid fields.
 // @Tags transactions
 // @Accept json
 // @Produce json
 // @Param request body TransactionRequest true "Transaction Request"
 // @Success 200 {object} TransactionResponse
 // @Failure 400 {object} TransactionResponse
+// @Failure 401 {object} TransactionResponse
+// @Failure 403 {object} TransactionResponse
+// @Failure 429 {object} TransactionResponse
 // @Failure 500 {object} TransactionResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /api/evaluate [post]
 func (h *Handler) EvaluateHandler(w http.ResponseWriter, r *http.Request) {
-	var req TransactionRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		sendErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+	_, decoded, err := decodeTransactionRequest(r)
+	if err != nil {
+		sendBodyError(w, err)
 		return
 	}
 
+	req := *decoded
 	if req.ChaincodeName == "" {
 		sendErrorResponse(w, http.StatusBadRequest, "chaincode_name is required")
 		return
 	}
-
-	result, err := h.fabricClient.EvaluateTransaction(r.Context(), req.ChaincodeName, req.Function, req.Args)
+	ctx, ok := h.validate(w, r, req)
+	if !ok {
+		return
+	}
+	result, err := h.fabricClient.EvaluateTransaction(ctx, req.ChaincodeName, req.Function, req.Args)
 	if err != nil {
 		sendErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	response := TransactionResponse{
+	resp := TransactionResponse{
 		Status: "success",
 		Result: string(result),
 	}
-	sendJSONResponse(w, http.StatusOK, response)
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// validate checks the request against the function's schema, replying 400
+// with the invalid fields when it does not match. It returns the context the
+// transaction runs with, carrying the request's transient data, and labels the
+// request's metrics with the chaincode and function.
+func (h *Handler) validate(w http.ResponseWriter, r *http.Request, req TransactionRequest) (context.Context, bool) {
+	if err := h.schemas.Check(req.ChaincodeName, req.Function, req.Args, req.Transient); err != nil {
+		sendValidationError(w, err)
+		return nil, false
+	}
+	metrics.SetTransaction(r.Context(), req.ChaincodeName, req.Function)
+	ctx := r.Context()
+	if len(req.Transient) > 0 {
+		transient := make(map[string][]byte, len(req.Transient))
+		for name, value := range req.Transient {
+			transient[name] = []byte(value)
+		}
+		ctx = fabric.WithTransient(ctx, transient)
+	}
+	return ctx, true
+}
+
+// sendValidationError replies 400 with the fields of a call that does not
+// match its function's schema
+func sendValidationError(w http.ResponseWriter, err *schema.ValidationError) {
+	response.JSON(w, http.StatusBadRequest, TransactionResponse{
+		Status: "error",
+		Error:  err.Error(),
+		Fields: err.Fields,
+	})
+}
+
+// prefersAsync reports whether the request carries the respond-async
+// preference of RFC 7240
+func prefersAsync(r *http.Request) bool {
+	for _, header := range r.Header.Values("Prefer") {
+		for _, preference := range strings.Split(header, ",") {
+			if strings.EqualFold(strings.TrimSpace(preference), "respond-async") {
+				return true
+			}
+		}
+	}
+	return false
 }
 
-func sendJSONResponse(w http.ResponseWriter, status int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(data)
+func newInvokeResponse(txResult *fabric.TransactionResult) TransactionResponse {
+	return TransactionResponse{
+		Status:      "success",
+		Result:      string(txResult.Result),
+		TxID:        txResult.TxID,
+		Success:     txResult.Success,
+		BlockNumber: txResult.BlockNumber,
+		ResultCode:  txResult.ResultCode,
+	}
+}
+
+// invokeErrorResponse describes a failed invoke. A transaction whose commit
+// status is unknown was already sent for ordering and may still be committed,
+// so it is reported with the status "submitted" and its transaction ID.
+func invokeErrorResponse(err error) TransactionResponse {
+	var commitErr *fabric.CommitStatusError
+	if errors.As(err, &commitErr) {
+		return TransactionResponse{Status: "submitted", Error: err.Error(), TxID: commitErr.TxID}
+	}
+	return TransactionResponse{Status: "error", Error: err.Error()}
+}
+
+// sendInvokeError replies to a failed invoke. Transactions that were
+// submitted are answered with 202 rather than a server error, so that the
+// response is recorded against the idempotency key and a retry with the key
+// does not submit the transaction a second time.
+func sendInvokeError(w http.ResponseWriter, err error) {
+	resp := invokeErrorResponse(err)
+	if resp.Status == "submitted" {
+		response.JSON(w, http.StatusAccepted, resp)
+		return
+	}
+	response.JSON(w, http.StatusInternalServerError, resp)
 }
 
 func sendErrorResponse(w http.ResponseWriter, status int, message string) {
-	response := TransactionResponse{
+	resp := TransactionResponse{
 		Status: "error",
 		Error:  message,
 	}
-	sendJSONResponse(w, status, response)
+	response.JSON(w, status, resp)
 }
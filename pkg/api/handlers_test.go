@@ -0,0 +1,129 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/schema"
+)
+
+func TestSendInvokeError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   int
+		wantStatus string
+		wantTxID   string
+	}{
+		{
+			name:       "failed before submission",
+			err:        errors.New("endorsement failed"),
+			wantCode:   http.StatusInternalServerError,
+			wantStatus: "error",
+		},
+		{
+			name:       "commit status unknown",
+			err:        &fabric.CommitStatusError{TxID: "tx123", Err: errors.New("deadline exceeded")},
+			wantCode:   http.StatusAccepted,
+			wantStatus: "submitted",
+			wantTxID:   "tx123",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			sendInvokeError(w, tt.err)
+			if w.Code != tt.wantCode {
+				t.Fatalf("status code = %d, want %d", w.Code, tt.wantCode)
+			}
+			var resp TransactionResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Status != tt.wantStatus || resp.TxID != tt.wantTxID {
+				t.Errorf("response = %+v, want status %q and tx_id %q", resp, tt.wantStatus, tt.wantTxID)
+			}
+		})
+	}
+}
+
+func TestLimitBody(t *testing.T) {
+	h := newTestHandler(t)
+	limited := LimitBody(16)(http.HandlerFunc(h.EvaluateHandler))
+
+	tests := []struct {
+		name string
+		body string
+		// chunked hides the length, so that the limit applies while reading
+		chunked  bool
+		wantCode int
+	}{
+		{name: "declared length over the limit", body: strings.Repeat("x", 17), wantCode: http.StatusRequestEntityTooLarge},
+		{name: "streamed body over the limit", body: `{"chaincode_name": "basic"}`, chunked: true, wantCode: http.StatusRequestEntityTooLarge},
+		{name: "invalid body within the limit", body: "{", wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/evaluate", strings.NewReader(tt.body))
+			if tt.chunked {
+				r.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+			limited.ServeHTTP(w, r)
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
+
+// loadTestSchemas returns a registry whose basic CreateAsset function needs a
+// non-empty first argument
+func loadTestSchemas(t *testing.T) *schema.Registry {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "basic"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	schemaFile := `{"properties": {"args": {"prefixItems": [{"type": "string", "minLength": 1}]}}}`
+	if err := os.WriteFile(filepath.Join(dir, "basic", "CreateAsset.json"), []byte(schemaFile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	schemas, err := schema.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schemas
+}
+
+func TestHandlersValidateSchemas(t *testing.T) {
+	h := newTestHandler(t, WithSchemas(loadTestSchemas(t)))
+	handlers := map[string]http.HandlerFunc{
+		"invoke":   h.InvokeHandler,
+		"evaluate": h.EvaluateHandler,
+		"simulate": h.SimulateHandler,
+	}
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest(http.MethodPost, "/api/"+name, strings.NewReader(`{"chaincode_name": "basic", "function": "CreateAsset", "args": [""]}`)))
+			var resp TransactionResponse
+			if w.Code != http.StatusBadRequest || json.Unmarshal(w.Body.Bytes(), &resp) != nil || len(resp.Fields) != 1 || resp.Fields[0].Field != "/args/0" {
+				t.Errorf("invalid arguments = %d %s, want 400 with the invalid field", w.Code, w.Body)
+			}
+
+			w = httptest.NewRecorder()
+			handler(w, httptest.NewRequest(http.MethodPost, "/api/"+name, strings.NewReader(`{"chaincode_name": "basic", "function": "CreateAsset", "args": ["asset1"]}`)))
+			if w.Code == http.StatusBadRequest {
+				t.Errorf("valid arguments = %d %s, want them to reach the peer", w.Code, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/ratelimit"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/response"
+)
+
+// LimitChaincode applies the per-chaincode request rate of the client and, for
+// invokes, holds one of its in-flight invoke slots while the request runs.
+// Requests whose body cannot be decoded or names no chaincode are rejected,
+// since no limit could be applied to them.
+func (h *Handler) LimitChaincode(op auth.Operation) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if h.limiter == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			r, req, err := decodeTransactionRequest(r)
+			if err != nil {
+				sendBodyError(w, err)
+				return
+			}
+			if req.ChaincodeName == "" {
+				sendErrorResponse(w, http.StatusBadRequest, "chaincode_name is required")
+				return
+			}
+
+			client := ratelimit.ClientKey(r)
+			if err := h.limiter.AllowChaincode(client, req.ChaincodeName); err != nil {
+				ratelimit.WriteError(w, err)
+				return
+			}
+			if op == auth.OperationInvoke {
+				release, err := h.limiter.AcquireInvoke(client, req.ChaincodeName)
+				if err != nil {
+					ratelimit.WriteError(w, err)
+					return
+				}
+				defer release()
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// QuotaHandler godoc
+// @Summary Get the caller's quota usage
+// @Description Returns the rate limits that apply to the calling client and how much of them is currently used
+// @Tags quotas
+// @Produce json
+// @Success 200 {object} ratelimit.Usage
+// @Failure 404 {object} TransactionResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/quota [get]
+func (h *Handler) QuotaHandler(w http.ResponseWriter, r *http.Request) {
+	if h.limiter == nil {
+		sendErrorResponse(w, http.StatusNotFound, "rate limiting is not enabled")
+		return
+	}
+	response.JSON(w, http.StatusOK, h.limiter.Usage(ratelimit.ClientKey(r)))
+}
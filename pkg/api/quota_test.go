@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/ratelimit"
+)
+
+func TestLimitChaincode(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.Config{Chaincodes: map[string]ratelimit.Limit{"basic": {Rate: 1, Burst: 1}}})
+	h := newTestHandler(t, WithRateLimiter(limiter))
+	limited := h.LimitChaincode(auth.OperationEvaluate)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	post := func(body string) int {
+		w := httptest.NewRecorder()
+		limited.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/evaluate", strings.NewReader(body)))
+		return w.Code
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{name: "first request", body: `{"chaincode_name": "basic"}`, status: http.StatusOK},
+		// The handlers decode the first JSON value and ignore what follows it
+		{name: "trailing data", body: `{"chaincode_name": "basic"} x`, status: http.StatusTooManyRequests},
+		{name: "malformed body", body: `{"chaincode_name": "basic"`, status: http.StatusBadRequest},
+		{name: "missing chaincode", body: `{}`, status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if got := post(tt.body); got != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, got, tt.status)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/response"
+)
+
+// SimulationResponse is the outcome of a simulated transaction
+// @Description Endorsement results of a transaction that was not submitted
+type SimulationResponse struct {
+	Status string `json:"status" example:"success"`
+	// ID the transaction would have been submitted with
+	TxID string `json:"tx_id" example:"tx123"`
+	// Result of the chaincode function
+	Result string `json:"result" example:"{\"key\":\"value\"}"`
+	// Keys read and written per namespace; values are base64 encoded
+	ReadWriteSets []fabric.ReadWriteSet `json:"read_write_sets"`
+	// Chaincode events the transaction would emit
+	Events []fabric.SimulatedEvent `json:"events"`
+	// Peers that endorsed the transaction
+	Endorsers []fabric.Endorser `json:"endorsers"`
+}
+
+// SimulateHandler godoc
+// @Summary Simulate a chaincode transaction
+// @Description Endorses a transaction like /api/invoke but never submits it for ordering, returning the chaincode result, the keys read and written per namespace, the events it would emit and the endorsing peers. The ledger is not changed; the read versions show which keys would conflict with concurrent updates.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param request body TransactionRequest true "Transaction Request"
+// @Success 200 {object} SimulationResponse
+// @Failure 400 {object} TransactionResponse
+// @Failure 401 {object} TransactionResponse
+// @Failure 403 {object} TransactionResponse
+// @Failure 429 {object} TransactionResponse
+// @Failure 500 {object} TransactionResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/simulate [post]
+func (h *Handler) SimulateHandler(w http.ResponseWriter, r *http.Request) {
+	_, decoded, err := decodeTransactionRequest(r)
+	if err != nil {
+		sendBodyError(w, err)
+		return
+	}
+
+	req := *decoded
+	if req.ChaincodeName == "" {
+		sendErrorResponse(w, http.StatusBadRequest, "chaincode_name is required")
+		return
+	}
+	ctx, ok := h.validate(w, r, req)
+	if !ok {
+		return
+	}
+	simulation, err := h.fabricClient.SimulateTransaction(ctx, req.ChaincodeName, req.Function, req.Args)
+	if err != nil {
+		sendErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.JSON(w, http.StatusOK, SimulationResponse{
+		Status:        "success",
+		TxID:          simulation.TxID,
+		Result:        string(simulation.Result),
+		ReadWriteSets: simulation.ReadWriteSets,
+		Events:        simulation.Events,
+		Endorsers:     simulation.Endorsers,
+	})
+}
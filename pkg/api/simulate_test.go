@@ -0,0 +1,39 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSimulateHandler(t *testing.T) {
+	h := newTestHandler(t)
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		message  string
+	}{
+		{name: "invalid body", body: "{", wantCode: http.StatusBadRequest, message: "Invalid request body"},
+		{name: "missing chaincode", body: `{"function": "CreateAsset"}`, wantCode: http.StatusBadRequest, message: "chaincode_name is required"},
+		{name: "endorsement failed", body: `{"chaincode_name": "basic", "function": "CreateAsset", "args": ["asset1"]}`, wantCode: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.SimulateHandler(w, httptest.NewRequest(http.MethodPost, "/api/simulate", strings.NewReader(tt.body)))
+			if w.Code != tt.wantCode {
+				t.Fatalf("status code = %d, want %d", w.Code, tt.wantCode)
+			}
+			var resp TransactionResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Status != "error" || !strings.Contains(resp.Error, tt.message) {
+				t.Errorf("response = %+v, want an error containing %q", resp, tt.message)
+			}
+		})
+	}
+}
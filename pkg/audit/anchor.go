@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+)
+
+// Anchorer periodically records the head of the audit log on the ledger by
+// invoking a chaincode function with the head sequence number and hash. Once
+// anchored, entries up to the head can no longer be rewritten unnoticed.
+type Anchorer struct {
+	logger    *Logger
+	client    *fabric.FabricClient
+	chaincode string
+	function  string
+	interval  time.Duration
+
+	lastAnchored uint64
+}
+
+// NewAnchorer creates an anchorer invoking function on chaincode every interval
+func NewAnchorer(logger *Logger, client *fabric.FabricClient, chaincode, function string, interval time.Duration) *Anchorer {
+	return &Anchorer{
+		logger:    logger,
+		client:    client,
+		chaincode: chaincode,
+		function:  function,
+		interval:  interval,
+	}
+}
+
+// Run anchors the head every interval until ctx is cancelled
+func (a *Anchorer) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.Anchor(ctx); err != nil {
+				slog.ErrorContext(ctx, "failed to anchor audit log", "error", err)
+			}
+		}
+	}
+}
+
+// Anchor records the current head on the ledger if it changed since the last anchor
+func (a *Anchorer) Anchor(ctx context.Context) error {
+	head := a.logger.Head()
+	if head.Sequence <= a.lastAnchored {
+		return nil
+	}
+
+	ctx = auth.NewContext(ctx, &auth.Principal{Name: "audit-anchor", Method: "internal"})
+	result, err := a.client.InvokeTransaction(ctx, a.chaincode, a.function, []string{strconv.FormatUint(head.Sequence, 10), head.Hash})
+	if err != nil {
+		return err
+	}
+	// The anchor transaction is itself audited right after the anchored head;
+	// do not anchor again for that entry alone
+	a.lastAnchored = head.Sequence + 1
+	slog.InfoContext(ctx, "anchored audit log", "sequence", head.Sequence, "tx_id", result.TxID)
+	return nil
+}
@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// GenesisHash is the previous hash of the first entry of a log
+const GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// Entry is a single audit record of a submitted transaction. Each entry
+// includes the hash of the previous one, so that removing or modifying an
+// entry breaks the chain.
+type Entry struct {
+	Sequence uint64    `json:"seq"`
+	Time     time.Time `json:"time"`
+	// Caller is the authenticated client that requested the transaction
+	Caller            string `json:"caller"`
+	AuthMethod        string `json:"auth_method,omitempty"`
+	ClientCertSubject string `json:"client_cert_subject,omitempty"`
+	// Identity is the signing identity used to submit the transaction
+	Identity string `json:"identity"`
+	MspID    string `json:"msp_id,omitempty"`
+	// Network is the additional network the transaction was submitted to,
+	// empty for the default network. Omitting it keeps the hashes of
+	// entries written before networks were recorded.
+	Network   string `json:"network,omitempty"`
+	Channel   string `json:"channel"`
+	Chaincode string `json:"chaincode"`
+	Function  string `json:"function"`
+	// ArgsHash is the SHA-256 of the JSON encoded arguments
+	ArgsHash       string    `json:"args_hash"`
+	TxID           string    `json:"tx_id,omitempty"`
+	Submitted      bool      `json:"submitted"`
+	ValidationCode string    `json:"validation_code,omitempty"`
+	BlockNumber    uint64    `json:"block_number,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	StartedAt      time.Time `json:"started_at"`
+	CompletedAt    time.Time `json:"completed_at"`
+	PrevHash       string    `json:"prev_hash"`
+	Hash           string    `json:"hash"`
+}
+
+// ComputeHash returns the hash of the entry, covering every field but Hash
+func (e Entry) ComputeHash() (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(data)
+	return hex.EncodeToString(digest[:]), nil
+}
+
+// HashArgs returns the hash stored in the ArgsHash field of an entry
+func HashArgs(args []string) string {
+	if args == nil {
+		args = []string{}
+	}
+	data, _ := json.Marshal(args)
+	digest := sha256.Sum256(data)
+	return hex.EncodeToString(digest[:])
+}
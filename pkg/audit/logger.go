@@ -0,0 +1,327 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+)
+
+const (
+	filePrefix = "audit-"
+	fileSuffix = ".jsonl"
+)
+
+// Head identifies the last entry of the log
+type Head struct {
+	Sequence uint64 `json:"seq"`
+	Hash     string `json:"hash"`
+}
+
+// Logger appends hash-chained entries to files in a directory. A new file,
+// named after the sequence number of its first entry, is started once the
+// current one exceeds the maximum size; the chain continues across files.
+type Logger struct {
+	dir     string
+	maxSize int64
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	head Head
+}
+
+// NewLogger opens the audit log in dir, continuing the chain of the existing files
+func NewLogger(dir string, maxSize int64) (*Logger, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create audit directory: %w", err)
+	}
+
+	l := &Logger{
+		dir:     dir,
+		maxSize: maxSize,
+		head:    Head{Hash: GenesisHash},
+	}
+
+	files, err := logFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return l, l.openFile(1)
+	}
+
+	current := files[len(files)-1]
+	last, size, err := recoverLastEntry(current)
+	if err != nil {
+		return nil, err
+	}
+	if last != nil {
+		l.head = Head{Sequence: last.Sequence, Hash: last.Hash}
+	} else if len(files) > 1 {
+		// The current file is empty, continue from the end of the previous one
+		prev, _, err := recoverLastEntry(files[len(files)-2])
+		if err != nil {
+			return nil, err
+		}
+		if prev != nil {
+			l.head = Head{Sequence: prev.Sequence, Hash: prev.Hash}
+		}
+	}
+
+	file, err := os.OpenFile(current, os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	l.file = file
+	l.size = size
+	return l, nil
+}
+
+// logFiles returns the audit files in dir in chain order
+func logFiles(dir string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, filePrefix+"*"+fileSuffix))
+	if err != nil {
+		return nil, err
+	}
+	// File names embed zero padded sequence numbers, so lexical order is chain order
+	sort.Strings(matches)
+	return matches, nil
+}
+
+func fileName(dir string, firstSequence uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%s%020d%s", filePrefix, firstSequence, fileSuffix))
+}
+
+// recoverLastEntry returns the last complete entry of the file. A trailing
+// partial line, left by a crash in the middle of a write, is truncated: it was
+// never acknowledged to the caller.
+func recoverLastEntry(path string) (*Entry, int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	if n := len(data); n > 0 && data[n-1] != '\n' {
+		cut := bytes.LastIndexByte(data, '\n') + 1
+		slog.Warn("truncating incomplete audit entry", "file", path)
+		if err := os.Truncate(path, int64(cut)); err != nil {
+			return nil, 0, fmt.Errorf("failed to truncate incomplete audit entry: %w", err)
+		}
+		data = data[:cut]
+	}
+
+	lines := bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n"))
+	last := lines[len(lines)-1]
+	if len(last) == 0 {
+		return nil, int64(len(data)), nil
+	}
+	var entry Entry
+	if err := json.Unmarshal(last, &entry); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse last audit entry of %s: %w", path, err)
+	}
+	return &entry, int64(len(data)), nil
+}
+
+func (l *Logger) openFile(firstSequence uint64) error {
+	file, err := os.OpenFile(fileName(l.dir, firstSequence), os.O_WRONLY|os.O_APPEND|os.O_CREATE|os.O_EXCL, 0640)
+	if err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+	if l.file != nil {
+		l.file.Close()
+	}
+	l.file = file
+	l.size = 0
+	return nil
+}
+
+// Head returns the last entry written to the log
+func (l *Logger) Head() Head {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.head
+}
+
+// Append chains the entry to the log and writes it durably, filling in its
+// sequence number and hashes
+func (l *Logger) Append(entry *Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.maxSize > 0 && l.size >= l.maxSize {
+		if err := l.openFile(l.head.Sequence + 1); err != nil {
+			return err
+		}
+	}
+
+	entry.Sequence = l.head.Sequence + 1
+	entry.Time = time.Now().UTC()
+	entry.PrevHash = l.head.Hash
+	hash, err := entry.ComputeHash()
+	if err != nil {
+		return fmt.Errorf("failed to hash audit entry: %w", err)
+	}
+	entry.Hash = hash
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	line = append(line, '\n')
+	if _, err := l.file.Write(line); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync audit log: %w", err)
+	}
+
+	l.size += int64(len(line))
+	l.head = Head{Sequence: entry.Sequence, Hash: entry.Hash}
+	return nil
+}
+
+// RecordTransaction is a fabric.TransactionListener writing an entry for every
+// invoke of the default network
+func (l *Logger) RecordTransaction(ctx context.Context, event *fabric.TransactionEvent) {
+	l.record(ctx, "", event)
+}
+
+// NetworkListener returns a fabric.TransactionListener writing an entry for
+// every invoke of the named network. Networks may use the same channel
+// names, so their entries are told apart by the network.
+func (l *Logger) NetworkListener(network string) fabric.TransactionListener {
+	return func(ctx context.Context, event *fabric.TransactionEvent) {
+		l.record(ctx, network, event)
+	}
+}
+
+func (l *Logger) record(ctx context.Context, network string, event *fabric.TransactionEvent) {
+	entry := &Entry{
+		Caller:            "anonymous",
+		ClientCertSubject: auth.ClientCertSubjectFromContext(ctx),
+		Identity:          event.Identity,
+		MspID:             event.MspID,
+		Network:           network,
+		Channel:           event.Channel,
+		Chaincode:         event.ChaincodeName,
+		Function:          event.Function,
+		ArgsHash:          HashArgs(event.Args),
+		TxID:              event.TxID,
+		Submitted:         event.Submitted,
+		ValidationCode:    event.ValidationCode,
+		BlockNumber:       event.BlockNumber,
+		StartedAt:         event.StartedAt.UTC(),
+		CompletedAt:       event.CompletedAt.UTC(),
+	}
+	if principal := auth.FromContext(ctx); principal != nil {
+		entry.Caller = principal.Name
+		entry.AuthMethod = principal.Method
+	}
+	if entry.Identity == "" {
+		entry.Identity = "default"
+	}
+	if event.Err != nil {
+		entry.Error = event.Err.Error()
+	}
+
+	if err := l.Append(entry); err != nil {
+		slog.ErrorContext(ctx, "failed to write audit entry", "tx_id", event.TxID, "error", err)
+	}
+}
+
+// Close closes the current log file
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// VerifyResult summarizes a successful verification
+type VerifyResult struct {
+	Files   int
+	Entries uint64
+	Head    Head
+}
+
+// Verify checks the hash chain of every audit file in dir
+func Verify(dir string) (*VerifyResult, error) {
+	files, err := logFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no audit files found in %s", dir)
+	}
+
+	result := &VerifyResult{Files: len(files), Head: Head{Hash: GenesisHash}}
+	for _, path := range files {
+		name := filepath.Base(path)
+		var first uint64
+		if _, err := fmt.Sscanf(strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix), "%d", &first); err != nil {
+			return nil, fmt.Errorf("%s: unexpected file name", name)
+		}
+		if first != result.Head.Sequence+1 {
+			return nil, fmt.Errorf("%s: expected the file to start at entry %d, the chain is missing entries", name, result.Head.Sequence+1)
+		}
+
+		if err := verifyFile(path, result); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return result, nil
+}
+
+func verifyFile(path string, result *VerifyResult) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err == io.EOF && len(data) == 0 {
+			return nil
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if err == io.EOF {
+			return fmt.Errorf("line %d: incomplete entry", line)
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return fmt.Errorf("line %d: invalid entry: %w", line, err)
+		}
+		if entry.Sequence != result.Head.Sequence+1 {
+			return fmt.Errorf("line %d: expected entry %d, found %d", line, result.Head.Sequence+1, entry.Sequence)
+		}
+		if entry.PrevHash != result.Head.Hash {
+			return fmt.Errorf("entry %d: previous hash does not match entry %d", entry.Sequence, result.Head.Sequence)
+		}
+		hash, err := entry.ComputeHash()
+		if err != nil {
+			return fmt.Errorf("entry %d: %w", entry.Sequence, err)
+		}
+		if hash != entry.Hash {
+			return fmt.Errorf("entry %d: hash mismatch, the entry was modified", entry.Sequence)
+		}
+
+		result.Entries++
+		result.Head = Head{Sequence: entry.Sequence, Hash: entry.Hash}
+	}
+}
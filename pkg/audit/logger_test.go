@@ -0,0 +1,212 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+)
+
+// writeLog appends n entries to a new log in dir, starting a new file after
+// every few entries
+func writeLog(t *testing.T, dir string, n int) Head {
+	t.Helper()
+	l, err := NewLogger(dir, 600)
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+	defer l.Close()
+	for i := 0; i < n; i++ {
+		if err := l.Append(&Entry{Caller: "ci", Chaincode: "basic", Function: "CreateAsset", ArgsHash: HashArgs([]string{"asset"})}); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	return l.Head()
+}
+
+func TestVerify(t *testing.T) {
+	dir := t.TempDir()
+	head := writeLog(t, dir, 10)
+
+	result, err := Verify(dir)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if result.Entries != 10 || result.Head != head {
+		t.Errorf("Verify() = %+v, want 10 entries and head %+v", result, head)
+	}
+	if result.Files < 2 {
+		t.Errorf("Verify() checked %d files, want the log to be rotated", result.Files)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(t *testing.T, files []string)
+		want   string
+	}{
+		{
+			name: "modified entry",
+			tamper: func(t *testing.T, files []string) {
+				rewrite(t, files[0], func(data []byte) []byte {
+					return bytes.Replace(data, []byte(`"caller":"ci"`), []byte(`"caller":"xx"`), 1)
+				})
+			},
+			want: "hash mismatch",
+		},
+		{
+			name: "removed entry",
+			tamper: func(t *testing.T, files []string) {
+				rewrite(t, files[0], func(data []byte) []byte {
+					_, rest, _ := bytes.Cut(data, []byte("\n"))
+					return rest
+				})
+			},
+			want: "expected entry 1, found 2",
+		},
+		{
+			name: "removed file",
+			tamper: func(t *testing.T, files []string) {
+				if err := os.Remove(files[1]); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: "missing entries",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeLog(t, dir, 10)
+			files, err := logFiles(dir)
+			if err != nil || len(files) < 3 {
+				t.Fatalf("logFiles() = %v, %v; want at least 3 files", files, err)
+			}
+			tt.tamper(t, files)
+			if _, err := Verify(dir); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Verify() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func rewrite(t *testing.T, path string, change func([]byte) []byte) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, change(data), 0o640); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewLoggerContinuesChain(t *testing.T) {
+	dir := t.TempDir()
+	head := writeLog(t, dir, 3)
+
+	// A crash in the middle of a write leaves a partial line behind
+	files, err := logFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(files[len(files)-1], os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"seq":4,"caller":`)
+	f.Close()
+
+	l, err := NewLogger(dir, 600)
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+	if l.Head() != head {
+		t.Fatalf("Head() = %+v, want %+v", l.Head(), head)
+	}
+	if err := l.Append(&Entry{Caller: "ci"}); err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	result, err := Verify(dir)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if result.Entries != 4 {
+		t.Errorf("Verify() entries = %d, want 4", result.Entries)
+	}
+}
+
+func TestRecordTransaction(t *testing.T) {
+	dir := t.TempDir()
+	l, err := NewLogger(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	ctx := auth.NewContext(context.Background(), &auth.Principal{Name: "ci", Method: "api_key"})
+	l.RecordTransaction(ctx, &fabric.TransactionEvent{
+		Channel:       "mychannel",
+		ChaincodeName: "basic",
+		Function:      "CreateAsset",
+		Args:          []string{"secret"},
+		TxID:          "tx123",
+		Submitted:     true,
+		StartedAt:     time.Now(),
+		CompletedAt:   time.Now(),
+	})
+
+	files, err := logFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"caller":"ci"`, `"auth_method":"api_key"`, `"identity":"default"`, `"tx_id":"tx123"`, HashArgs([]string{"secret"})} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("entry %s does not contain %s", data, want)
+		}
+	}
+	if bytes.Contains(data, []byte(`"secret"`)) {
+		t.Error("the entry contains the transaction arguments")
+	}
+	if bytes.Contains(data, []byte(`"network"`)) {
+		t.Errorf("entry %s of the default network names a network", data)
+	}
+}
+
+func TestNetworkListener(t *testing.T) {
+	dir := t.TempDir()
+	l, err := NewLogger(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	l.NetworkListener("org2")(context.Background(), &fabric.TransactionEvent{Channel: "mychannel", ChaincodeName: "basic", Function: "CreateAsset"})
+
+	files, err := logFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, []byte(`"network":"org2","channel":"mychannel"`)) {
+		t.Errorf("entry %s does not name network org2", data)
+	}
+	if _, err := Verify(dir); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// HeaderAPIKey is the request header carrying the API key
+	HeaderAPIKey = "X-API-Key"
+
+	apiKeyHashPrefix = "sha256:"
+)
+
+// APIKeyConfig describes a single API key. Only the hash of the key is stored.
+type APIKeyConfig struct {
+	Name   string  `yaml:"name"`
+	Hash   string  `yaml:"hash"`
+	Scopes []Scope `yaml:"scopes"`
+	// Identity selects the signing identity used for the key's transactions
+	Identity string `yaml:"identity"`
+}
+
+// APIKeyAuthenticator authenticates requests by the X-API-Key header
+type APIKeyAuthenticator struct {
+	principals map[string]*Principal
+}
+
+// NewAPIKeyAuthenticator creates an authenticator for the configured keys
+func NewAPIKeyAuthenticator(keys []APIKeyConfig) (*APIKeyAuthenticator, error) {
+	principals := make(map[string]*Principal, len(keys))
+	names := make(map[string]bool, len(keys))
+	for i, key := range keys {
+		if key.Name == "" {
+			return nil, fmt.Errorf("api_keys[%d]: name is required", i)
+		}
+		if names[key.Name] {
+			return nil, fmt.Errorf("api_keys[%d]: duplicate name %q", i, key.Name)
+		}
+		names[key.Name] = true
+
+		if !strings.HasPrefix(key.Hash, apiKeyHashPrefix) {
+			return nil, fmt.Errorf("api_keys[%d]: hash must start with %q", i, apiKeyHashPrefix)
+		}
+		digest, err := hex.DecodeString(strings.TrimPrefix(key.Hash, apiKeyHashPrefix))
+		if err != nil || len(digest) != sha256.Size {
+			return nil, fmt.Errorf("api_keys[%d]: hash is not a valid SHA-256 digest", i)
+		}
+		principals[string(digest)] = &Principal{
+			Name:     key.Name,
+			Method:   "api_key",
+			Scopes:   key.Scopes,
+			Identity: key.Identity,
+		}
+	}
+	return &APIKeyAuthenticator{principals: principals}, nil
+}
+
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	key := r.Header.Get(HeaderAPIKey)
+	if key == "" {
+		return nil, nil
+	}
+	digest := sha256.Sum256([]byte(key))
+	principal, ok := a.principals[string(digest[:])]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown API key", ErrInvalidCredentials)
+	}
+	return principal, nil
+}
+
+// HashAPIKey returns the value to store in the hash field of an API key entry
+func HashAPIKey(key string) string {
+	digest := sha256.Sum256([]byte(key))
+	return apiKeyHashPrefix + hex.EncodeToString(digest[:])
+}
+
+// GenerateAPIKey returns a new random API key
+func GenerateAPIKey() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	return "hlf_" + base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func apiKeyRequest(key string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/api/invoke", nil)
+	if key != "" {
+		r.Header.Set(HeaderAPIKey, key)
+	}
+	return r
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	key, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key, "hlf_") {
+		t.Errorf("GenerateAPIKey() = %q, want the hlf_ prefix", key)
+	}
+	a, err := NewAPIKeyAuthenticator([]APIKeyConfig{{
+		Name:     "ci",
+		Hash:     HashAPIKey(key),
+		Scopes:   []Scope{{Chaincodes: []string{"basic"}}},
+		Identity: "deployer",
+	}})
+	if err != nil {
+		t.Fatalf("NewAPIKeyAuthenticator: %v", err)
+	}
+
+	principal, err := a.Authenticate(apiKeyRequest(key))
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if principal.Name != "ci" || principal.Method != "api_key" || principal.Identity != "deployer" {
+		t.Errorf("principal = %+v", principal)
+	}
+
+	if _, err := a.Authenticate(apiKeyRequest(key + "x")); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Authenticate() with an unknown key error = %v, want ErrInvalidCredentials", err)
+	}
+	if principal, err := a.Authenticate(apiKeyRequest("")); principal != nil || err != nil {
+		t.Errorf("Authenticate() without a key = %v, %v; want nil, nil", principal, err)
+	}
+}
+
+func TestNewAPIKeyAuthenticatorRejectsInvalidKeys(t *testing.T) {
+	valid := HashAPIKey("secret")
+	tests := []struct {
+		name string
+		keys []APIKeyConfig
+		want string
+	}{
+		{name: "missing name", keys: []APIKeyConfig{{Hash: valid}}, want: "name is required"},
+		{name: "duplicate name", keys: []APIKeyConfig{{Name: "a", Hash: valid}, {Name: "a", Hash: HashAPIKey("other")}}, want: "duplicate name"},
+		{name: "plain text key", keys: []APIKeyConfig{{Name: "a", Hash: "secret"}}, want: "hash must start with"},
+		{name: "short digest", keys: []APIKeyConfig{{Name: "a", Hash: "sha256:abcd"}}, want: "not a valid SHA-256 digest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAPIKeyAuthenticator(tt.keys)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("NewAPIKeyAuthenticator() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
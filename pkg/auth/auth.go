@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/response"
+)
+
+// Operation is the kind of chaincode call a scope grants
+type Operation string
+
+const (
+	// OperationInvoke submits transactions to the ledger
+	OperationInvoke Operation = "invoke"
+	// OperationEvaluate queries chaincode without submitting
+	OperationEvaluate Operation = "evaluate"
+)
+
+// UnmarshalYAML rejects operations other than invoke and evaluate, so that a
+// misspelt operation fails the configuration instead of granting nothing
+func (o *Operation) UnmarshalYAML(value *yaml.Node) error {
+	var name string
+	if err := value.Decode(&name); err != nil {
+		return err
+	}
+	switch op := Operation(name); op {
+	case OperationInvoke, OperationEvaluate:
+		*o = op
+		return nil
+	}
+	return fmt.Errorf("line %d: unknown operation %q, must be %s or %s", value.Line, name, OperationInvoke, OperationEvaluate)
+}
+
+var (
+	// ErrInvalidCredentials is returned when the credentials carried by a request are not valid
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrAuthenticationRequired is returned when a request carries no credentials
+	// recognised by any authenticator
+	ErrAuthenticationRequired = errors.New("authentication required")
+)
+
+// Scope grants access to chaincode functions. Empty lists match anything;
+// function entries are shell patterns as understood by path.Match.
+type Scope struct {
+	Channels   []string    `yaml:"channels"`
+	Chaincodes []string    `yaml:"chaincodes"`
+	Functions  []string    `yaml:"functions"`
+	Operations []Operation `yaml:"operations"`
+}
+
+// Allows reports whether the scope grants the given call
+func (s Scope) Allows(channel, chaincode, function string, op Operation) bool {
+	return matchAny(s.Channels, channel) &&
+		matchAny(s.Chaincodes, chaincode) &&
+		matchAny(s.Functions, function) &&
+		(len(s.Operations) == 0 || containsOperation(s.Operations, op))
+}
+
+func matchAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func containsOperation(ops []Operation, op Operation) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	// Name identifies the client in logs, quotas and audit records
+	Name string
+	// Method is the authentication method that produced the principal
+	Method string
+	// Scopes lists what the principal may call; a principal without scopes may call nothing
+	Scopes []Scope
+	// Identity is the configured signing identity used for the principal's
+	// transactions; empty selects the default identity
+	Identity string
+}
+
+// ID identifies the principal across authentication methods. Names are only
+// unique within a method: an API key and a JWT subject may share a name.
+func (p *Principal) ID() string {
+	return p.Method + ":" + p.Name
+}
+
+// Allows reports whether any of the principal's scopes grants the given call
+func (p *Principal) Allows(channel, chaincode, function string, op Operation) bool {
+	for _, scope := range p.Scopes {
+		if scope.Allows(channel, chaincode, function, op) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsChaincode reports whether any of the principal's scopes grants the
+// operation on some function of the chaincode
+func (p *Principal) AllowsChaincode(channel, chaincode string, op Operation) bool {
+	for _, scope := range p.Scopes {
+		if matchAny(scope.Channels, channel) && matchAny(scope.Chaincodes, chaincode) &&
+			(len(scope.Operations) == 0 || containsOperation(scope.Operations, op)) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsChannel reports whether any of the principal's scopes grants the
+// operation on every chaincode and function of the channel
+func (p *Principal) AllowsChannel(channel string, op Operation) bool {
+	for _, scope := range p.Scopes {
+		if matchAny(scope.Channels, channel) && len(scope.Chaincodes) == 0 && len(scope.Functions) == 0 &&
+			(len(scope.Operations) == 0 || containsOperation(scope.Operations, op)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator resolves the caller of an HTTP request
+type Authenticator interface {
+	// Authenticate returns the principal for the credentials carried by the request,
+	// nil if the request carries no credentials handled by this authenticator, or
+	// an error if the credentials are present but invalid.
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+type principalKey struct{}
+
+// NewContext returns a copy of ctx carrying the principal
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored in ctx, or nil when the request is unauthenticated
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// Chain tries its authenticators in order. The authenticators can be replaced
+// while requests are served, e.g. when the configuration is reloaded.
+type Chain struct {
+	mu             sync.RWMutex
+	authenticators []Authenticator
+}
+
+// NewChain creates a chain of the given authenticators
+func NewChain(authenticators []Authenticator) *Chain {
+	return &Chain{authenticators: authenticators}
+}
+
+// Set replaces the authenticators of the chain
+func (c *Chain) Set(authenticators []Authenticator) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.authenticators = authenticators
+}
+
+// Authenticate returns the result of the first authenticator that recognised
+// the credentials of the request
+func (c *Chain) Authenticate(r *http.Request) (*Principal, error) {
+	c.mu.RLock()
+	authenticators := c.authenticators
+	c.mu.RUnlock()
+	for _, authenticator := range authenticators {
+		principal, err := authenticator.Authenticate(r)
+		if err != nil || principal != nil {
+			return principal, err
+		}
+	}
+	return nil, nil
+}
+
+// Authenticate authenticates r and returns its context carrying the principal
+// and the signing identity the principal maps to
+func Authenticate(authenticator Authenticator, r *http.Request) (context.Context, error) {
+	principal, err := authenticator.Authenticate(r)
+	if err != nil {
+		return nil, err
+	}
+	if principal == nil {
+		return nil, ErrAuthenticationRequired
+	}
+	ctx := NewContext(r.Context(), principal)
+	if principal.Identity != "" {
+		ctx = fabric.WithIdentity(ctx, principal.Identity)
+	}
+	return ctx, nil
+}
+
+// Middleware rejects requests that no authenticator accepts and stores the
+// authenticated principal, and the signing identity it maps to, in the request context
+func Middleware(authenticators ...Authenticator) func(http.Handler) http.Handler {
+	return NewChain(authenticators).Middleware
+}
+
+// Middleware is the middleware of the chain's authenticators; it follows the
+// authenticators set on the chain later on
+func (c *Chain) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, err := Authenticate(c, r)
+		if err != nil {
+			response.Error(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
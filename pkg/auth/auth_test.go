@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScopeAllows(t *testing.T) {
+	scope := Scope{
+		Channels:   []string{"mychannel"},
+		Chaincodes: []string{"basic"},
+		Functions:  []string{"Read*", "GetAllAssets"},
+		Operations: []Operation{OperationEvaluate},
+	}
+	tests := []struct {
+		channel, chaincode, function string
+		op                           Operation
+		want                         bool
+	}{
+		{"mychannel", "basic", "ReadAsset", OperationEvaluate, true},
+		{"mychannel", "basic", "GetAllAssets", OperationEvaluate, true},
+		{"mychannel", "basic", "ReadAsset", OperationInvoke, false},
+		{"mychannel", "basic", "CreateAsset", OperationEvaluate, false},
+		{"mychannel", "other", "ReadAsset", OperationEvaluate, false},
+		{"otherchannel", "basic", "ReadAsset", OperationEvaluate, false},
+	}
+	for _, tt := range tests {
+		if got := scope.Allows(tt.channel, tt.chaincode, tt.function, tt.op); got != tt.want {
+			t.Errorf("Allows(%s, %s, %s, %s) = %v, want %v", tt.channel, tt.chaincode, tt.function, tt.op, got, tt.want)
+		}
+	}
+
+	if !(Scope{}).Allows("any", "any", "any", OperationInvoke) {
+		t.Error("an empty scope should allow everything")
+	}
+}
+
+func TestPrincipalAllowsChaincodeAndChannel(t *testing.T) {
+	p := &Principal{Scopes: []Scope{
+		{Chaincodes: []string{"basic"}, Functions: []string{"Read*"}, Operations: []Operation{OperationInvoke}},
+		{Channels: []string{"audit"}},
+	}}
+	if !p.AllowsChaincode("mychannel", "basic", OperationInvoke) {
+		t.Error("AllowsChaincode should ignore the functions of the scope")
+	}
+	if p.AllowsChaincode("mychannel", "basic", OperationEvaluate) {
+		t.Error("AllowsChaincode granted an operation outside the scope")
+	}
+	if p.AllowsChannel("mychannel", OperationInvoke) {
+		t.Error("AllowsChannel granted a channel through a scope limited to a chaincode")
+	}
+	if !p.AllowsChannel("audit", OperationEvaluate) {
+		t.Error("AllowsChannel did not grant the channel of an unrestricted scope")
+	}
+	if (&Principal{}).Allows("mychannel", "basic", "ReadAsset", OperationEvaluate) {
+		t.Error("a principal without scopes was granted a call")
+	}
+}
+
+func TestPrincipalID(t *testing.T) {
+	apiKey := &Principal{Name: "alice", Method: "api_key"}
+	subject := &Principal{Name: "alice", Method: "jwt"}
+	if apiKey.ID() == subject.ID() {
+		t.Errorf("principals of different methods share the ID %q", apiKey.ID())
+	}
+}
+
+func TestLoadConfigRejectsUnknownOperations(t *testing.T) {
+	tests := []struct {
+		name       string
+		operations string
+		wantErr    string
+	}{
+		{name: "known", operations: "[invoke, evaluate]"},
+		{name: "wrong case", operations: "[Invoke]", wantErr: `unknown operation "Invoke"`},
+		{name: "unknown", operations: "[evaluate, write]", wantErr: `unknown operation "write"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "auth.yaml")
+			data := "api_keys:\n  - name: ci\n    hash: " + HashAPIKey("secret") + "\n    scopes:\n      - operations: " + tt.operations + "\n"
+			if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadConfig(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("LoadConfig() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("LoadConfig() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	keys, err := NewAPIKeyAuthenticator([]APIKeyConfig{{Name: "ci", Hash: HashAPIKey("secret"), Identity: "deployer"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got *Principal
+	h := Middleware(keys)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = FromContext(r.Context())
+	}))
+
+	for _, key := range []string{"", "wrong"} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, apiKeyRequest(key))
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("status with key %q = %d, want %d", key, w.Code, http.StatusUnauthorized)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, apiKeyRequest("secret"))
+	if w.Code != http.StatusOK || got == nil || got.Name != "ci" {
+		t.Fatalf("status = %d, principal = %+v", w.Code, got)
+	}
+}
+
+func TestChainMiddlewareFollowsSet(t *testing.T) {
+	keys, err := NewAPIKeyAuthenticator([]APIKeyConfig{{Name: "ci", Hash: HashAPIKey("secret")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := NewChain(nil)
+	h := chain.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, apiKeyRequest("secret"))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status without authenticators = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	chain.Set([]Authenticator{keys})
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, apiKeyRequest("secret"))
+	if w.Code != http.StatusOK {
+		t.Errorf("status after Set = %d, want %d", w.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+	"path"
+)
+
+// ClientCertRule grants scopes, and optionally a signing identity, to clients
+// whose verified certificate matches. Empty fields match anything; values are
+// shell patterns as understood by path.Match.
+type ClientCertRule struct {
+	CommonName   string  `yaml:"common_name"`
+	Organization string  `yaml:"organization"`
+	Identity     string  `yaml:"identity"`
+	Scopes       []Scope `yaml:"scopes"`
+}
+
+func (rule ClientCertRule) matches(cert *x509.Certificate) bool {
+	if rule.CommonName != "" {
+		if ok, _ := path.Match(rule.CommonName, cert.Subject.CommonName); !ok {
+			return false
+		}
+	}
+	if rule.Organization != "" {
+		matched := false
+		for _, org := range cert.Subject.Organization {
+			if ok, _ := path.Match(rule.Organization, org); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// ClientCertAuthenticator authenticates requests by the client certificate
+// verified during the TLS handshake. Certificates that match no rule are
+// ignored so that other authenticators can handle the request.
+type ClientCertAuthenticator struct {
+	rules []ClientCertRule
+}
+
+// NewClientCertAuthenticator creates an authenticator for the given rules
+func NewClientCertAuthenticator(rules []ClientCertRule) *ClientCertAuthenticator {
+	return &ClientCertAuthenticator{rules: rules}
+}
+
+func (a *ClientCertAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	cert := VerifiedClientCert(r)
+	if cert == nil {
+		return nil, nil
+	}
+
+	var principal *Principal
+	for _, rule := range a.rules {
+		if !rule.matches(cert) {
+			continue
+		}
+		if principal == nil {
+			principal = &Principal{
+				Name:   cert.Subject.String(),
+				Method: "client_cert",
+			}
+		}
+		principal.Scopes = append(principal.Scopes, rule.Scopes...)
+		if principal.Identity == "" {
+			principal.Identity = rule.Identity
+		}
+	}
+	return principal, nil
+}
+
+// VerifiedClientCert returns the client certificate verified against the
+// configured CA bundle, or nil when the request did not present one
+func VerifiedClientCert(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return r.TLS.VerifiedChains[0][0]
+}
+
+type clientCertSubjectKey struct{}
+
+// ClientCertSubjectFromContext returns the subject of the verified client
+// certificate of the request, or an empty string when there is none
+func ClientCertSubjectFromContext(ctx context.Context) string {
+	subject, _ := ctx.Value(clientCertSubjectKey{}).(string)
+	return subject
+}
+
+// ClientCertContext returns the context of r carrying the subject of the
+// verified client certificate, if any
+func ClientCertContext(r *http.Request) context.Context {
+	if cert := VerifiedClientCert(r); cert != nil {
+		return context.WithValue(r.Context(), clientCertSubjectKey{}, cert.Subject.String())
+	}
+	return r.Context()
+}
+
+// ClientCertMiddleware stores the subject of the verified client certificate
+// in the request context, independently of how the request is authenticated
+func ClientCertMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(ClientCertContext(r)))
+	})
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func clientCertRequest(subject *pkix.Name) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/api/invoke", nil)
+	if subject != nil {
+		cert := &x509.Certificate{Subject: *subject}
+		r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	}
+	return r
+}
+
+func TestClientCertAuthenticator(t *testing.T) {
+	a := NewClientCertAuthenticator([]ClientCertRule{
+		{Organization: "Org1", Scopes: []Scope{{Chaincodes: []string{"basic"}}}},
+		{CommonName: "admin.*", Organization: "Org1", Identity: "admin", Scopes: []Scope{{}}},
+	})
+
+	tests := []struct {
+		name         string
+		subject      *pkix.Name
+		wantScopes   int
+		wantIdentity string
+	}{
+		{name: "no certificate"},
+		{name: "no matching rule", subject: &pkix.Name{CommonName: "app", Organization: []string{"Org2"}}},
+		{name: "organization rule", subject: &pkix.Name{CommonName: "app", Organization: []string{"Org1"}}, wantScopes: 1},
+		{name: "both rules", subject: &pkix.Name{CommonName: "admin.org1", Organization: []string{"Org1"}}, wantScopes: 2, wantIdentity: "admin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := a.Authenticate(clientCertRequest(tt.subject))
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if tt.wantScopes == 0 {
+				if principal != nil {
+					t.Fatalf("Authenticate() = %+v, want nil", principal)
+				}
+				return
+			}
+			if principal == nil {
+				t.Fatal("Authenticate() = nil")
+			}
+			if principal.Method != "client_cert" || principal.Name != tt.subject.String() {
+				t.Errorf("principal = %+v", principal)
+			}
+			if len(principal.Scopes) != tt.wantScopes || principal.Identity != tt.wantIdentity {
+				t.Errorf("scopes = %d, identity = %q; want %d, %q", len(principal.Scopes), principal.Identity, tt.wantScopes, tt.wantIdentity)
+			}
+		})
+	}
+}
+
+func TestClientCertMiddleware(t *testing.T) {
+	subject := pkix.Name{CommonName: "app", Organization: []string{"Org1"}}
+	var got string
+	h := ClientCertMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = ClientCertSubjectFromContext(r.Context())
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), clientCertRequest(&subject))
+	if got != subject.String() {
+		t.Errorf("subject = %q, want %q", got, subject.String())
+	}
+}
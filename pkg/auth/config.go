@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the authentication configuration file
+type Config struct {
+	APIKeys []APIKeyConfig `yaml:"api_keys"`
+	JWT     *JWTConfig     `yaml:"jwt"`
+	// ClientCerts map verified TLS client certificates to scopes
+	ClientCerts []ClientCertRule `yaml:"client_certs"`
+}
+
+// LoadConfig reads and parses the authentication configuration file at path
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auth config: %w", err)
+	}
+
+	var config Config
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse auth config %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// Authenticators builds the authenticators enabled by the configuration
+func (c *Config) Authenticators() ([]Authenticator, error) {
+	var authenticators []Authenticator
+	if len(c.APIKeys) > 0 {
+		apiKeys, err := NewAPIKeyAuthenticator(c.APIKeys)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, apiKeys)
+	}
+	if c.JWT != nil {
+		jwtAuthenticator, err := NewJWTAuthenticator(*c.JWT)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: %w", err)
+		}
+		authenticators = append(authenticators, jwtAuthenticator)
+	}
+	if len(c.ClientCerts) > 0 {
+		authenticators = append(authenticators, NewClientCertAuthenticator(c.ClientCerts))
+	}
+	if len(authenticators) == 0 {
+		return nil, fmt.Errorf("auth config does not enable any authentication method")
+	}
+	return authenticators, nil
+}
+
+// Identities returns the names of the signing identities referenced by the configuration
+func (c *Config) Identities() []string {
+	var names []string
+	for _, key := range c.APIKeys {
+		if key.Identity != "" {
+			names = append(names, key.Identity)
+		}
+	}
+	for _, rule := range c.ClientCerts {
+		if rule.Identity != "" {
+			names = append(names, rule.Identity)
+		}
+	}
+	if c.JWT != nil {
+		for _, rule := range c.JWT.Rules {
+			if rule.Identity != "" {
+				names = append(names, rule.Identity)
+			}
+		}
+	}
+	return names
+}
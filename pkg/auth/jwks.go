@@ -0,0 +1,277 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// minJWKSRefetchInterval limits how often an unknown key ID triggers a refetch
+const minJWKSRefetchInterval = time.Minute
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// ParseJWKS parses a JSON Web Key Set into public keys indexed by key ID.
+// Keys other than RSA and EC signing keys are ignored.
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set jsonWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for i, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		var (
+			key crypto.PublicKey
+			err error
+		)
+		switch jwk.Kty {
+		case "RSA":
+			key, err = jwk.rsaPublicKey()
+		case "EC":
+			key, err = jwk.ecdsaPublicKey()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("JWKS key %d (%s): %w", i, jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+// MarshalJWKS encodes RSA and ECDSA public keys, indexed by key ID, as a JSON Web Key Set
+func MarshalJWKS(keys map[string]crypto.PublicKey) ([]byte, error) {
+	set := jsonWebKeySet{Keys: []jsonWebKey{}}
+	for kid, key := range keys {
+		jwk := jsonWebKey{Kid: kid, Use: "sig"}
+		switch k := key.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(k.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes())
+		case *ecdsa.PublicKey:
+			size := (k.Curve.Params().BitSize + 7) / 8
+			jwk.Kty = "EC"
+			jwk.Crv = k.Curve.Params().Name
+			jwk.X = base64.RawURLEncoding.EncodeToString(k.X.FillBytes(make([]byte, size)))
+			jwk.Y = base64.RawURLEncoding.EncodeToString(k.Y.FillBytes(make([]byte, size)))
+		default:
+			return nil, fmt.Errorf("unsupported key type %T for key %q", key, kid)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return json.MarshalIndent(set, "", "  ")
+}
+
+func (jwk jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := decodeBase64URLInt(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	e, err := decodeBase64URLInt(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+	if !e.IsInt64() || e.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("exponent is too large")
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (jwk jsonWebKey) ecdsaPublicKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch jwk.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+	}
+	x, err := decodeBase64URLInt(jwk.X)
+	if err != nil {
+		return nil, fmt.Errorf("invalid x coordinate: %w", err)
+	}
+	y, err := decodeBase64URLInt(jwk.Y)
+	if err != nil {
+		return nil, fmt.Errorf("invalid y coordinate: %w", err)
+	}
+	if !curve.IsOnCurve(x, y) {
+		return nil, fmt.Errorf("point is not on curve %s", jwk.Crv)
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func decodeBase64URLInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// jwksCache holds the verification keys loaded from a JWKS URL or file and
+// refreshes them periodically and when a token references an unknown key.
+// Keys are fetched without holding the lock, so that tokens signed by a known
+// key are verified while the key set is being fetched.
+type jwksCache struct {
+	url             string
+	file            string
+	refreshInterval time.Duration
+	httpClient      *http.Client
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	lastFetched time.Time
+	// inflight is the fetch in progress, shared by the requests waiting for it
+	inflight *jwksFetch
+}
+
+type jwksFetch struct {
+	done chan struct{}
+	err  error
+}
+
+func newJWKSCache(url, file string, refreshInterval time.Duration) (*jwksCache, error) {
+	if (url == "") == (file == "") {
+		return nil, fmt.Errorf("exactly one of jwks_url and jwks_file must be set")
+	}
+	c := &jwksCache{
+		url:             url,
+		file:            file,
+		refreshInterval: refreshInterval,
+		httpClient:      &http.Client{Timeout: 10 * time.Second},
+	}
+	keys, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	c.keys = keys
+	c.lastFetched = time.Now()
+	return c, nil
+}
+
+// key returns the public key with the given ID. A known key is returned
+// right away, refreshing a stale key set in the background; an unknown key
+// waits for the key set to be fetched again.
+func (c *jwksCache) key(kid string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	stale := c.refreshInterval > 0 && time.Since(c.lastFetched) > c.refreshInterval
+	key, ok := c.keys[kid]
+	if !ok && time.Since(c.lastFetched) > minJWKSRefetchInterval {
+		stale = true
+	}
+	fetch := c.inflight
+	if stale {
+		fetch = c.refreshLocked()
+	}
+	c.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+	if fetch == nil {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	<-fetch.done
+	if fetch.err != nil {
+		return nil, fetch.err
+	}
+	c.mu.Lock()
+	key, ok = c.keys[kid]
+	c.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// refreshLocked starts fetching the key set unless a fetch is in progress and
+// returns the fetch. c.mu must be held.
+func (c *jwksCache) refreshLocked() *jwksFetch {
+	if c.inflight != nil {
+		return c.inflight
+	}
+	fetch := &jwksFetch{done: make(chan struct{})}
+	c.inflight = fetch
+	// Record the attempt even when it fails so an unreachable IdP is not hammered
+	c.lastFetched = time.Now()
+	go func() {
+		keys, err := c.load()
+		c.mu.Lock()
+		if err == nil {
+			c.keys = keys
+		}
+		c.inflight = nil
+		c.mu.Unlock()
+		fetch.err = err
+		close(fetch.done)
+	}()
+	return fetch
+}
+
+// load fetches and parses the key set
+func (c *jwksCache) load() (map[string]crypto.PublicKey, error) {
+	data, err := c.fetch()
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
+
+func (c *jwksCache) fetch() ([]byte, error) {
+	if c.file != "" {
+		data, err := os.ReadFile(c.file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+		}
+		return data, nil
+	}
+
+	resp, err := c.httpClient.Get(c.url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS response: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var defaultJWTAlgorithms = []string{"RS256", "ES256"}
+
+// JWTConfig configures validation of bearer tokens issued by an OIDC provider
+type JWTConfig struct {
+	// JWKSURL is the URL of the provider's key set; mutually exclusive with JWKSFile
+	JWKSURL string `yaml:"jwks_url"`
+	// JWKSFile is a local key set, useful for testing with locally generated keys
+	JWKSFile string `yaml:"jwks_file"`
+	// JWKSRefreshInterval is how often the key set is reloaded (default 15m)
+	JWKSRefreshInterval time.Duration `yaml:"jwks_refresh_interval"`
+	Issuer              string        `yaml:"issuer"`
+	Audience            string        `yaml:"audience"`
+	// ClockSkew is the leeway applied to exp, nbf and iat checks
+	ClockSkew time.Duration `yaml:"clock_skew"`
+	// Algorithms lists the accepted signing algorithms (default RS256 and ES256)
+	Algorithms []string `yaml:"algorithms"`
+	// SubjectClaim is the claim used as the principal name (default sub)
+	SubjectClaim string `yaml:"subject_claim"`
+	// Rules map token claims to scopes and signing identities
+	Rules []JWTRule `yaml:"rules"`
+}
+
+// JWTRule grants scopes, and optionally a signing identity, to tokens whose
+// claims match. Every listed claim must match; a claim matches when it equals
+// the value or, for array claims, contains it. Nested claims use dots, e.g.
+// realm_access.roles.
+type JWTRule struct {
+	Claims   map[string]string `yaml:"claims"`
+	Identity string            `yaml:"identity"`
+	Scopes   []Scope           `yaml:"scopes"`
+}
+
+// JWTAuthenticator authenticates requests by an "Authorization: Bearer" token
+type JWTAuthenticator struct {
+	config JWTConfig
+	keys   *jwksCache
+	parser *jwt.Parser
+}
+
+// NewJWTAuthenticator creates an authenticator and loads the configured key set
+func NewJWTAuthenticator(config JWTConfig) (*JWTAuthenticator, error) {
+	if config.JWKSRefreshInterval == 0 {
+		config.JWKSRefreshInterval = 15 * time.Minute
+	}
+	if len(config.Algorithms) == 0 {
+		config.Algorithms = defaultJWTAlgorithms
+	}
+	if config.SubjectClaim == "" {
+		config.SubjectClaim = "sub"
+	}
+	for i, rule := range config.Rules {
+		if len(rule.Claims) == 0 {
+			return nil, fmt.Errorf("jwt.rules[%d]: claims is required", i)
+		}
+	}
+
+	keys, err := newJWKSCache(config.JWKSURL, config.JWKSFile, config.JWKSRefreshInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(config.Algorithms),
+		jwt.WithLeeway(config.ClockSkew),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+	if config.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		opts = append(opts, jwt.WithAudience(config.Audience))
+	}
+
+	return &JWTAuthenticator{
+		config: config,
+		keys:   keys,
+		parser: jwt.NewParser(opts...),
+	}, nil
+}
+
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return nil, nil
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := a.parser.ParseWithClaims(strings.TrimSpace(token), claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return a.keys.key(kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	subject := claimString(claims, a.config.SubjectClaim)
+	if subject == "" {
+		return nil, fmt.Errorf("%w: token has no %s claim", ErrInvalidCredentials, a.config.SubjectClaim)
+	}
+
+	principal := &Principal{
+		Name:   subject,
+		Method: "jwt",
+	}
+	for _, rule := range a.config.Rules {
+		if !rule.matches(claims) {
+			continue
+		}
+		principal.Scopes = append(principal.Scopes, rule.Scopes...)
+		if principal.Identity == "" {
+			principal.Identity = rule.Identity
+		}
+	}
+	return principal, nil
+}
+
+func (rule JWTRule) matches(claims jwt.MapClaims) bool {
+	for name, want := range rule.Claims {
+		if !claimContains(lookupClaim(claims, name), want) {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupClaim resolves a possibly dotted claim name
+func lookupClaim(claims jwt.MapClaims, name string) interface{} {
+	var value interface{} = map[string]interface{}(claims)
+	for _, part := range strings.Split(name, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[part]
+	}
+	return value
+}
+
+func claimContains(value interface{}, want string) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case []interface{}:
+		for _, item := range v {
+			if fmt.Sprint(item) == want {
+				return true
+			}
+		}
+		return false
+	default:
+		return fmt.Sprint(v) == want
+	}
+}
+
+func claimString(claims jwt.MapClaims, name string) string {
+	value := lookupClaim(claims, name)
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer   = "https://idp.example.com"
+	testAudience = "hlf-api"
+)
+
+// testIdP serves a JWKS of locally generated keys
+type testIdP struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu   sync.Mutex
+	keys map[string]*ecdsa.PrivateKey
+	// block delays the JWKS responses until it is closed
+	block chan struct{}
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	idp := &testIdP{t: t, keys: make(map[string]*ecdsa.PrivateKey)}
+	idp.addKey("key-1")
+	idp.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idp.mu.Lock()
+		block := idp.block
+		public := make(map[string]crypto.PublicKey, len(idp.keys))
+		for kid, key := range idp.keys {
+			public[kid] = &key.PublicKey
+		}
+		idp.mu.Unlock()
+		if block != nil {
+			<-block
+		}
+		data, err := MarshalJWKS(public)
+		if err != nil {
+			t.Errorf("failed to marshal JWKS: %v", err)
+			return
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *testIdP) addKey(kid string) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		idp.t.Fatalf("failed to generate key: %v", err)
+	}
+	idp.mu.Lock()
+	idp.keys[kid] = key
+	idp.mu.Unlock()
+	return key
+}
+
+func (idp *testIdP) sign(kid string, key *ecdsa.PrivateKey, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		idp.t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func (idp *testIdP) authenticator() *JWTAuthenticator {
+	a, err := NewJWTAuthenticator(JWTConfig{
+		JWKSURL:  idp.server.URL,
+		Issuer:   testIssuer,
+		Audience: testAudience,
+		Rules: []JWTRule{{
+			Claims:   map[string]string{"roles": "writer"},
+			Identity: "writer",
+			Scopes:   []Scope{{Chaincodes: []string{"basic"}, Operations: []Operation{OperationInvoke}}},
+		}},
+	})
+	if err != nil {
+		idp.t.Fatalf("NewJWTAuthenticator: %v", err)
+	}
+	return a
+}
+
+func validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"sub":   "alice",
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"roles": []string{"writer"},
+	}
+}
+
+func bearerRequest(token string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/api/invoke", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	idp := newTestIdP(t)
+	a := idp.authenticator()
+	key := idp.keys["key-1"]
+	unknown, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		kid    string
+		key    *ecdsa.PrivateKey
+		claims func(jwt.MapClaims)
+	}{
+		{name: "expired", kid: "key-1", key: key, claims: func(c jwt.MapClaims) {
+			c["exp"] = time.Now().Add(-time.Minute).Unix()
+		}},
+		{name: "wrong audience", kid: "key-1", key: key, claims: func(c jwt.MapClaims) {
+			c["aud"] = "another-api"
+		}},
+		{name: "wrong issuer", kid: "key-1", key: key, claims: func(c jwt.MapClaims) {
+			c["iss"] = "https://other.example.com"
+		}},
+		{name: "unknown kid", kid: "key-2", key: unknown},
+		{name: "wrong key for kid", kid: "key-1", key: unknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			if tt.claims != nil {
+				tt.claims(claims)
+			}
+			principal, err := a.Authenticate(bearerRequest(idp.sign(tt.kid, tt.key, claims)))
+			if !errors.Is(err, ErrInvalidCredentials) {
+				t.Fatalf("Authenticate() = %v, %v; want ErrInvalidCredentials", principal, err)
+			}
+		})
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		principal, err := a.Authenticate(bearerRequest(idp.sign("key-1", key, validClaims())))
+		if err != nil {
+			t.Fatalf("Authenticate() error = %v", err)
+		}
+		if principal.Name != "alice" || principal.Method != "jwt" || principal.Identity != "writer" {
+			t.Fatalf("principal = %+v", principal)
+		}
+		if !principal.Allows("mychannel", "basic", "CreateAsset", OperationInvoke) {
+			t.Error("the scope of the matching rule was not granted")
+		}
+		if principal.Allows("mychannel", "other", "CreateAsset", OperationInvoke) {
+			t.Error("a chaincode outside the scope was granted")
+		}
+	})
+
+	t.Run("no bearer token", func(t *testing.T) {
+		principal, err := a.Authenticate(httptest.NewRequest(http.MethodGet, "/api/quota", nil))
+		if principal != nil || err != nil {
+			t.Fatalf("Authenticate() = %v, %v; want nil, nil", principal, err)
+		}
+	})
+}
+
+func TestJWTAuthenticatorFetchesRotatedKeys(t *testing.T) {
+	idp := newTestIdP(t)
+	a := idp.authenticator()
+	rotated := idp.addKey("key-2")
+	token := idp.sign("key-2", rotated, validClaims())
+
+	// Unknown keys only trigger a fetch once minJWKSRefetchInterval has passed
+	if _, err := a.Authenticate(bearerRequest(token)); err == nil {
+		t.Fatal("a key added after the last fetch was accepted before the refetch interval")
+	}
+	a.keys.mu.Lock()
+	a.keys.lastFetched = time.Now().Add(-2 * minJWKSRefetchInterval)
+	a.keys.mu.Unlock()
+	if _, err := a.Authenticate(bearerRequest(token)); err != nil {
+		t.Fatalf("Authenticate() with a rotated key error = %v", err)
+	}
+}
+
+func TestJWTAuthenticatorDoesNotWaitForStaleKeySet(t *testing.T) {
+	idp := newTestIdP(t)
+	a := idp.authenticator()
+	key := idp.keys["key-1"]
+
+	block := make(chan struct{})
+	idp.mu.Lock()
+	idp.block = block
+	idp.mu.Unlock()
+	defer close(block)
+	a.keys.mu.Lock()
+	a.keys.lastFetched = time.Now().Add(-2 * a.config.JWKSRefreshInterval)
+	a.keys.mu.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := a.Authenticate(bearerRequest(idp.sign("key-1", key, validClaims())))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Authenticate() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("a token signed by a known key waited for the key set to be fetched")
+	}
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/schema"
+)
+
+// CheckFiles verifies that the files referenced by the configuration exist
+// and that the certificates and keys they contain parse
+func (c *Config) CheckFiles() error {
+	var errs []error
+
+	if c.Server.TLS.Cert != "" && c.Server.TLS.Key != "" {
+		if _, err := tls.LoadX509KeyPair(c.Server.TLS.Cert, c.Server.TLS.Key); err != nil {
+			errs = append(errs, fmt.Errorf("server.tls: %w", err))
+		}
+	}
+	if c.Server.TLS.ClientCA != "" {
+		if err := checkCertificates(c.Server.TLS.ClientCA); err != nil {
+			errs = append(errs, fmt.Errorf("server.tls.client_ca: %w", err))
+		}
+	}
+
+	errs = append(errs, c.Fabric.checkFiles("fabric")...)
+	if c.Auth != nil {
+		if _, err := c.Auth.Authenticators(); err != nil {
+			errs = append(errs, fmt.Errorf("auth: %w", err))
+		}
+	}
+	for name, network := range c.Networks {
+		errs = append(errs, network.Fabric.checkFiles("networks."+name+".fabric")...)
+		if network.Auth != nil {
+			if _, err := network.Auth.Authenticators(); err != nil {
+				errs = append(errs, fmt.Errorf("networks.%s.auth: %w", name, err))
+			}
+		}
+	}
+
+	if c.Schemas.Dir != "" {
+		if _, err := schema.Load(c.Schemas.Dir); err != nil {
+			errs = append(errs, fmt.Errorf("schemas: %w", err))
+		}
+	}
+
+	if c.Audit.Dir != "" {
+		if info, err := os.Stat(c.Audit.Dir); err == nil && !info.IsDir() {
+			errs = append(errs, fmt.Errorf("audit.dir: %s is not a directory", c.Audit.Dir))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkFiles checks the peer TLS certificates and the identities of a
+// network, reporting problems under prefix
+func (f Fabric) checkFiles(prefix string) []error {
+	var errs []error
+	for i, peer := range f.Peers {
+		if err := checkCertificates(peer.TLSCert); err != nil {
+			errs = append(errs, fmt.Errorf("%s.peers[%d].tls_cert: %w", prefix, i, err))
+		}
+	}
+	if len(f.Peers) > 0 {
+		client, err := fabric.NewFabricClient(f.ClientConfig())
+		if err != nil {
+			return append(errs, fmt.Errorf("%s: %w", prefix, err))
+		}
+		for name, err := range client.CheckIdentities() {
+			if name == "default" {
+				errs = append(errs, fmt.Errorf("%s: %w", prefix, err))
+			} else {
+				errs = append(errs, fmt.Errorf("%s.identities.%s: %w", prefix, name, err))
+			}
+		}
+	}
+	return errs
+}
+
+// checkCertificates verifies that path holds at least one PEM certificate
+// and that all of them parse
+func checkCertificates(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	found := false
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		found = true
+	}
+	if !found {
+		return fmt.Errorf("%s: no PEM certificate found", path)
+	}
+	return nil
+}
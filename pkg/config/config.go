@@ -0,0 +1,732 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/netip"
+	"os"
+	"regexp"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/ratelimit"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/tlsconfig"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/tracing"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/webhook"
+)
+
+// Config is the configuration file of the API server. It covers every flag of
+// the serve command and embeds the authentication and rate limit configuration.
+type Config struct {
+	Server      Server            `yaml:"server"`
+	GRPC        GRPC              `yaml:"grpc"`
+	Fabric      Fabric            `yaml:"fabric"`
+	Auth        *auth.Config      `yaml:"auth"`
+	RateLimits  *ratelimit.Config `yaml:"rate_limits"`
+	Batch       Batch             `yaml:"batch"`
+	Idempotency Idempotency       `yaml:"idempotency"`
+	Audit       Audit             `yaml:"audit"`
+	Webhooks    Webhooks          `yaml:"webhooks"`
+	Schemas     Schemas           `yaml:"schemas"`
+	Contracts   Contracts         `yaml:"contracts"`
+	Offline     Offline           `yaml:"offline_signing"`
+	Indexer     Indexer           `yaml:"indexer"`
+	Outbox      Outbox            `yaml:"outbox"`
+	Logging     Logging           `yaml:"logging"`
+	Tracing     Tracing           `yaml:"tracing"`
+	Health      Health            `yaml:"health"`
+	Shutdown    Shutdown          `yaml:"shutdown"`
+	// Networks are additional Fabric networks served under /networks/{name}
+	Networks map[string]Network `yaml:"networks"`
+}
+
+// Server configures the HTTP listener
+type Server struct {
+	Port string `yaml:"port"`
+	TLS  TLS    `yaml:"tls"`
+	// MaxBodySizeMB is the largest request body accepted, in MB
+	MaxBodySizeMB int `yaml:"max_body_size_mb"`
+	// ProbePort serves the health probes and metrics over plain HTTP, for
+	// probes and scrapes that cannot present a client certificate
+	ProbePort string `yaml:"probe_port"`
+}
+
+// GRPC configures the gRPC listener, which uses the TLS settings of the HTTP server
+type GRPC struct {
+	// Port of the gRPC API; the gRPC API is disabled when empty
+	Port string `yaml:"port"`
+}
+
+// TLS configures HTTPS and client certificate verification
+type TLS struct {
+	Cert     string `yaml:"cert"`
+	Key      string `yaml:"key"`
+	ClientCA string `yaml:"client_ca"`
+	// ClientAuth is none, request or require (default require when client_ca is set)
+	ClientAuth string `yaml:"client_auth"`
+}
+
+// Fabric configures the connection to the network
+type Fabric struct {
+	MspID   string `yaml:"mspid"`
+	Cert    string `yaml:"cert"`
+	Key     string `yaml:"key"`
+	Channel string `yaml:"channel"`
+	Peers   []Peer `yaml:"peers"`
+	// Identities are additional signing identities selected by the auth configuration
+	Identities map[string]Identity `yaml:"identities"`
+}
+
+// Network is an additional Fabric network with its own client, metrics,
+// health checks, authentication and rate limits. The webhooks, schemas,
+// contract routes, offline signing, indexer, outbox and gRPC API of a network
+// are configured in its own sections and are disabled when not set.
+type Network struct {
+	Fabric Fabric `yaml:"fabric"`
+	// Auth authenticates the requests to the network. It is required when
+	// the top-level auth is set: the top-level scopes name channels and
+	// identities of the default network and are not applied to the others.
+	Auth *auth.Config `yaml:"auth"`
+	// RateLimits limits the requests to the network; the top-level rate
+	// limits apply when it is not set
+	RateLimits *ratelimit.Config `yaml:"rate_limits"`
+	// ReadyMinPeers is the number of reachable peers required for the
+	// network to report ready (default 1)
+	ReadyMinPeers int       `yaml:"ready_min_peers"`
+	GRPC          GRPC      `yaml:"grpc"`
+	Webhooks      Webhooks  `yaml:"webhooks"`
+	Schemas       Schemas   `yaml:"schemas"`
+	Contracts     Contracts `yaml:"contracts"`
+	Offline       Offline   `yaml:"offline_signing"`
+	Indexer       Indexer   `yaml:"indexer"`
+	Outbox        Outbox    `yaml:"outbox"`
+}
+
+// withDefaults returns the network with the defaults of the top-level
+// sections applied to the settings it leaves unset
+func (n Network) withDefaults() Network {
+	d := Default()
+	if n.Webhooks.MaxAttempts == 0 {
+		n.Webhooks.MaxAttempts = d.Webhooks.MaxAttempts
+	}
+	if n.Webhooks.Timeout == 0 {
+		n.Webhooks.Timeout = d.Webhooks.Timeout
+	}
+	if n.Webhooks.MinBackoff == 0 {
+		n.Webhooks.MinBackoff = d.Webhooks.MinBackoff
+	}
+	if n.Webhooks.MaxBackoff == 0 {
+		n.Webhooks.MaxBackoff = d.Webhooks.MaxBackoff
+	}
+	if n.Contracts.Refresh == 0 {
+		n.Contracts.Refresh = d.Contracts.Refresh
+	}
+	if n.Offline.TTL == 0 {
+		n.Offline.TTL = d.Offline.TTL
+	}
+	if n.Outbox.MinBackoff == 0 {
+		n.Outbox.MinBackoff = d.Outbox.MinBackoff
+	}
+	if n.Outbox.MaxBackoff == 0 {
+		n.Outbox.MaxBackoff = d.Outbox.MaxBackoff
+	}
+	if n.Outbox.Retention == 0 {
+		n.Outbox.Retention = d.Outbox.Retention
+	}
+	return n
+}
+
+// networkName restricts network names to what can be used in a URL path
+var networkName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Peer is a gateway peer and the CA certificate of its TLS server certificate
+type Peer struct {
+	Endpoint string `yaml:"endpoint"`
+	TLSCert  string `yaml:"tls_cert"`
+}
+
+// Identity is a named signing identity
+type Identity struct {
+	MspID string `yaml:"mspid"`
+	Cert  string `yaml:"cert"`
+	Key   string `yaml:"key"`
+}
+
+// Batch configures the batch endpoint
+type Batch struct {
+	Parallelism   int `yaml:"parallelism"`
+	MaxOperations int `yaml:"max_operations"`
+}
+
+// Idempotency configures the storage of Idempotency-Key responses
+type Idempotency struct {
+	// Store is memory or bolt
+	Store string        `yaml:"store"`
+	DB    string        `yaml:"db"`
+	TTL   time.Duration `yaml:"ttl"`
+}
+
+// Audit configures the audit log
+type Audit struct {
+	Dir       string      `yaml:"dir"`
+	MaxSizeMB int         `yaml:"max_size_mb"`
+	Anchor    AuditAnchor `yaml:"anchor"`
+}
+
+// AuditAnchor configures the periodic anchoring of the audit log on the ledger
+type AuditAnchor struct {
+	Chaincode string        `yaml:"chaincode"`
+	Function  string        `yaml:"function"`
+	Interval  time.Duration `yaml:"interval"`
+}
+
+// Webhooks configures the delivery of chaincode events to webhook subscriptions
+type Webhooks struct {
+	// DB is the database file of the subscriptions; webhooks are disabled when empty
+	DB          string        `yaml:"db"`
+	MaxAttempts int           `yaml:"max_attempts"`
+	Timeout     time.Duration `yaml:"timeout"`
+	MinBackoff  time.Duration `yaml:"min_backoff"`
+	MaxBackoff  time.Duration `yaml:"max_backoff"`
+	// AllowedNetworks are the CIDRs of loopback, private or link-local
+	// networks receivers may be in; only public addresses are allowed otherwise
+	AllowedNetworks []string `yaml:"allowed_networks"`
+}
+
+// Schemas configures the validation of chaincode function arguments
+type Schemas struct {
+	// Dir holds a directory per chaincode with a <function>.json JSON Schema
+	// per function; requests are not validated when empty
+	Dir string `yaml:"dir"`
+}
+
+// Contracts configures the routes generated from the contract metadata of chaincodes
+type Contracts struct {
+	// Chaincodes whose transactions are served by name; none when empty
+	Chaincodes []string `yaml:"chaincodes"`
+	// Refresh is the interval at which the metadata is fetched again
+	Refresh time.Duration `yaml:"refresh"`
+}
+
+// Offline configures the signing of transactions by clients holding their own keys
+type Offline struct {
+	Enabled bool `yaml:"enabled"`
+	// TTL is how long a prepared transaction waits for the client's next signature
+	TTL time.Duration `yaml:"ttl"`
+}
+
+// Indexer configures the local index of the channel's transactions
+type Indexer struct {
+	// DB is the database file of the index; the indexer is disabled when empty
+	DB string `yaml:"db"`
+	// StartBlock is the first block indexed into an empty database
+	StartBlock uint64 `yaml:"start_block"`
+}
+
+// Outbox configures the queue of invokes submitted in the background
+type Outbox struct {
+	// DB is the database file of the queue; invokes are not queued when empty
+	DB         string        `yaml:"db"`
+	MinBackoff time.Duration `yaml:"min_backoff"`
+	MaxBackoff time.Duration `yaml:"max_backoff"`
+	// Retention is how long committed invokes can be looked up
+	Retention time.Duration `yaml:"retention"`
+}
+
+// Logging configures the log output
+type Logging struct {
+	Level     string `yaml:"level"`
+	Format    string `yaml:"format"`
+	Sensitive bool   `yaml:"sensitive"`
+}
+
+// Tracing configures the OpenTelemetry exporter
+type Tracing struct {
+	Exporter    string  `yaml:"exporter"`
+	File        string  `yaml:"file"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// Health configures the readiness checks
+type Health struct {
+	ReadyMinPeers int           `yaml:"ready_min_peers"`
+	Interval      time.Duration `yaml:"interval"`
+	Timeout       time.Duration `yaml:"timeout"`
+}
+
+// Shutdown configures the graceful shutdown
+type Shutdown struct {
+	Timeout time.Duration `yaml:"timeout"`
+	Delay   time.Duration `yaml:"delay"`
+}
+
+// Default returns the configuration used for settings that are not given
+func Default() *Config {
+	return &Config{
+		Server: Server{Port: "8180", MaxBodySizeMB: 4},
+		Batch: Batch{
+			Parallelism:   10,
+			MaxOperations: 1000,
+		},
+		Idempotency: Idempotency{
+			Store: "memory",
+			DB:    "idempotency.db",
+			TTL:   24 * time.Hour,
+		},
+		Audit: Audit{
+			MaxSizeMB: 100,
+			Anchor: AuditAnchor{
+				Function: "AnchorAuditLog",
+				Interval: time.Hour,
+			},
+		},
+		Webhooks: Webhooks{
+			MaxAttempts: 10,
+			Timeout:     10 * time.Second,
+			MinBackoff:  time.Second,
+			MaxBackoff:  5 * time.Minute,
+		},
+		Contracts: Contracts{
+			Refresh: 5 * time.Minute,
+		},
+		Offline: Offline{
+			TTL: 5 * time.Minute,
+		},
+		Outbox: Outbox{
+			MinBackoff: time.Second,
+			MaxBackoff: time.Minute,
+			Retention:  24 * time.Hour,
+		},
+		Logging: Logging{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: Tracing{
+			Exporter:    tracing.ExporterNone,
+			File:        "traces.json",
+			SampleRatio: 1,
+		},
+		Health: Health{
+			ReadyMinPeers: 1,
+			Interval:      15 * time.Second,
+			Timeout:       5 * time.Second,
+		},
+		Shutdown: Shutdown{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Load reads the configuration file at path on top of the defaults. ${VAR}
+// and ${VAR:-default} references to environment variables are expanded in
+// values before the file is decoded; unknown fields are rejected.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	config := Default()
+	if root.Kind == 0 {
+		return config, nil
+	}
+	if err := interpolate(&root); err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+
+	// Decode the interpolated document again to get strict field checking,
+	// which is only available on a decoder
+	expanded, err := yaml.Marshal(&root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(expanded))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	for name, network := range config.Networks {
+		config.Networks[name] = network.withDefaults()
+	}
+	return config, nil
+}
+
+// Validate checks the structure of the configuration and reports every
+// problem found
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Server.Port == "" {
+		fail("server.port is required")
+	}
+	if (c.Server.TLS.Cert == "") != (c.Server.TLS.Key == "") {
+		fail("server.tls.cert and server.tls.key must be set together")
+	}
+	if c.Server.TLS.ClientCA != "" && c.Server.TLS.Cert == "" {
+		fail("server.tls.client_ca requires server.tls.cert and server.tls.key")
+	}
+	if c.Server.MaxBodySizeMB <= 0 {
+		fail("server.max_body_size_mb must be positive")
+	}
+	if c.Server.TLS.Cert != "" && c.Server.ProbePort == "" &&
+		tlsconfig.ResolveClientAuth(c.Server.TLS.ClientCA, c.Server.TLS.ClientAuth) == tlsconfig.ClientAuthRequire {
+		fail("server.tls.client_auth require rejects probes and metric scrapes without a client certificate; set server.probe_port to serve /livez, /readyz and /metrics on a separate port")
+	}
+	switch c.Server.TLS.ClientAuth {
+	case "", "none", "request", "require":
+	default:
+		fail("server.tls.client_auth must be none, request or require")
+	}
+
+	c.Fabric.validate("fabric", fail)
+	if c.Auth != nil {
+		for _, name := range c.Auth.Identities() {
+			if _, ok := c.Fabric.Identities[name]; !ok {
+				fail("auth references identity %q which is not defined in fabric.identities", name)
+			}
+		}
+	}
+	for name, network := range c.Networks {
+		prefix := "networks." + name
+		if !networkName.MatchString(name) {
+			fail("%s: network names may only contain lowercase letters, digits, - and _", prefix)
+		}
+		network.Fabric.validate(prefix+".fabric", fail)
+		if c.Auth != nil && network.Auth == nil {
+			fail("%s.auth is required when auth is set", prefix)
+		}
+		if networkAuth := c.NetworkAuth(name); networkAuth != nil {
+			for _, identity := range networkAuth.Identities() {
+				if _, ok := network.Fabric.Identities[identity]; !ok {
+					fail("%s: auth references identity %q which is not defined in %s.fabric.identities", prefix, identity, prefix)
+				}
+			}
+		}
+		if network.ReadyMinPeers < 0 {
+			fail("%s.ready_min_peers must not be negative", prefix)
+		} else if len(network.Fabric.Peers) > 0 && network.ReadyMinPeers > len(network.Fabric.Peers) {
+			fail("%s.ready_min_peers (%d) exceeds the number of peers (%d)", prefix, network.ReadyMinPeers, len(network.Fabric.Peers))
+		}
+		if network.RateLimits != nil {
+			if err := network.RateLimits.Validate(); err != nil {
+				fail("%s.rate_limits.%v", prefix, err)
+			}
+		}
+		network.Webhooks.validate(prefix+".webhooks", fail)
+		network.Contracts.validate(prefix+".contracts", fail)
+		network.Offline.validate(prefix+".offline_signing", fail)
+		network.Outbox.validate(prefix+".outbox", fail)
+	}
+	if c.RateLimits != nil {
+		if err := c.RateLimits.Validate(); err != nil {
+			fail("rate_limits.%v", err)
+		}
+	}
+	c.validatePorts(fail)
+	c.validateDatabases(fail)
+
+	if c.Batch.Parallelism <= 0 {
+		fail("batch.parallelism must be positive")
+	}
+	if c.Batch.MaxOperations <= 0 {
+		fail("batch.max_operations must be positive")
+	}
+	switch c.Idempotency.Store {
+	case "memory":
+	case "bolt":
+		if c.Idempotency.DB == "" {
+			fail("idempotency.db is required for the bolt store")
+		}
+	default:
+		fail("idempotency.store must be memory or bolt")
+	}
+	if c.Idempotency.TTL <= 0 {
+		fail("idempotency.ttl must be positive")
+	}
+
+	if c.Audit.MaxSizeMB <= 0 {
+		fail("audit.max_size_mb must be positive")
+	}
+	if c.Audit.Anchor.Chaincode != "" {
+		if c.Audit.Dir == "" {
+			fail("audit.anchor.chaincode requires audit.dir")
+		}
+		if c.Audit.Anchor.Function == "" {
+			fail("audit.anchor.function is required")
+		}
+		if c.Audit.Anchor.Interval <= 0 {
+			fail("audit.anchor.interval must be positive")
+		}
+	}
+
+	c.Webhooks.validate("webhooks", fail)
+	c.Contracts.validate("contracts", fail)
+	c.Offline.validate("offline_signing", fail)
+	c.Outbox.validate("outbox", fail)
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Logging.Level)); err != nil {
+		fail("logging.level must be debug, info, warn or error")
+	}
+	switch c.Logging.Format {
+	case "json", "text":
+	default:
+		fail("logging.format must be json or text")
+	}
+
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterOTLP:
+	case tracing.ExporterFile:
+		if c.Tracing.File == "" {
+			fail("tracing.file is required for the file exporter")
+		}
+	default:
+		fail("tracing.exporter must be %s, %s or %s", tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterFile)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		fail("tracing.sample_ratio must be between 0 and 1")
+	}
+
+	if c.Health.ReadyMinPeers <= 0 {
+		fail("health.ready_min_peers must be positive")
+	} else if len(c.Fabric.Peers) > 0 && c.Health.ReadyMinPeers > len(c.Fabric.Peers) {
+		fail("health.ready_min_peers (%d) exceeds the number of peers (%d)", c.Health.ReadyMinPeers, len(c.Fabric.Peers))
+	}
+	if c.Health.Interval <= 0 || c.Health.Timeout <= 0 {
+		fail("health.interval and health.timeout must be positive")
+	}
+	if c.Shutdown.Timeout <= 0 {
+		fail("shutdown.timeout must be positive")
+	}
+	if c.Shutdown.Delay < 0 {
+		fail("shutdown.delay must not be negative")
+	}
+
+	return errors.Join(errs...)
+}
+
+// NetworkRateLimits returns the rate limit configuration of a network: its
+// own, or the top-level one when it has none
+func (c *Config) NetworkRateLimits(name string) *ratelimit.Config {
+	if network := c.Networks[name]; network.RateLimits != nil {
+		return network.RateLimits
+	}
+	return c.RateLimits
+}
+
+// NetworkAuth returns the authentication configuration of a network. Unlike
+// the rate limits, the top-level one is never inherited, so that scopes
+// granted on a channel of the default network do not extend to a channel of
+// the same name on another network.
+func (c *Config) NetworkAuth(name string) *auth.Config {
+	return c.Networks[name].Auth
+}
+
+// validate checks the connection settings of a network, reporting problems
+// under prefix
+func (f Fabric) validate(prefix string, fail func(format string, args ...any)) {
+	if f.MspID == "" {
+		fail("%s.mspid is required", prefix)
+	}
+	if f.Cert == "" {
+		fail("%s.cert is required", prefix)
+	}
+	if f.Key == "" {
+		fail("%s.key is required", prefix)
+	}
+	if f.Channel == "" {
+		fail("%s.channel is required", prefix)
+	}
+	if len(f.Peers) == 0 {
+		fail("%s.peers requires at least one peer", prefix)
+	}
+	for i, peer := range f.Peers {
+		if peer.Endpoint == "" {
+			fail("%s.peers[%d].endpoint is required", prefix, i)
+		}
+		if peer.TLSCert == "" {
+			fail("%s.peers[%d].tls_cert is required", prefix, i)
+		}
+	}
+	for name, id := range f.Identities {
+		if id.MspID == "" || id.Cert == "" || id.Key == "" {
+			fail("%s.identities.%s: mspid, cert and key are required", prefix, name)
+		}
+	}
+}
+
+// ClientConfig returns the configuration of the Fabric client
+func (c *Config) ClientConfig() *fabric.ClientConfig {
+	return c.Fabric.ClientConfig()
+}
+
+// ClientConfig returns the configuration of a Fabric client for the network
+func (f Fabric) ClientConfig() *fabric.ClientConfig {
+	clientConfig := &fabric.ClientConfig{
+		MspID:       f.MspID,
+		CertPath:    f.Cert,
+		KeyPath:     f.Key,
+		ChannelName: f.Channel,
+		Identities:  make(map[string]fabric.IdentityConfig, len(f.Identities)),
+	}
+	for _, peer := range f.Peers {
+		clientConfig.Peers = append(clientConfig.Peers, fabric.PeerConfig{
+			Endpoint:    peer.Endpoint,
+			TLSCertPath: peer.TLSCert,
+		})
+	}
+	for name, id := range f.Identities {
+		clientConfig.Identities[name] = fabric.IdentityConfig{
+			MspID:    id.MspID,
+			CertPath: id.Cert,
+			KeyPath:  id.Key,
+		}
+	}
+	return clientConfig
+}
+
+// validatePorts checks that the HTTP, probe and gRPC listeners of the server
+// and of the networks do not share a port
+func (c *Config) validatePorts(fail func(format string, args ...any)) {
+	ports := map[string]string{}
+	use := func(setting, port string) {
+		if port == "" {
+			return
+		}
+		if other, ok := ports[port]; ok {
+			fail("%s must differ from %s", setting, other)
+			return
+		}
+		ports[port] = setting
+	}
+	use("server.port", c.Server.Port)
+	use("grpc.port", c.GRPC.Port)
+	use("server.probe_port", c.Server.ProbePort)
+	for _, name := range c.networkNames() {
+		use("networks."+name+".grpc.port", c.Networks[name].GRPC.Port)
+	}
+}
+
+// validateDatabases checks that the database files are not shared, since a
+// database can only be opened once
+func (c *Config) validateDatabases(fail func(format string, args ...any)) {
+	files := map[string]string{}
+	use := func(setting, path string) {
+		if path == "" {
+			return
+		}
+		if other, ok := files[path]; ok {
+			fail("%s must differ from %s", setting, other)
+			return
+		}
+		files[path] = setting
+	}
+	if c.Idempotency.Store == "bolt" {
+		use("idempotency.db", c.Idempotency.DB)
+	}
+	use("webhooks.db", c.Webhooks.DB)
+	use("indexer.db", c.Indexer.DB)
+	use("outbox.db", c.Outbox.DB)
+	for _, name := range c.networkNames() {
+		network := c.Networks[name]
+		prefix := "networks." + name
+		use(prefix+".webhooks.db", network.Webhooks.DB)
+		use(prefix+".indexer.db", network.Indexer.DB)
+		use(prefix+".outbox.db", network.Outbox.DB)
+	}
+}
+
+// networkNames returns the names of the networks in order, so that problems
+// are reported in the same order on every run
+func (c *Config) networkNames() []string {
+	names := make([]string, 0, len(c.Networks))
+	for name := range c.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validate checks the webhook delivery settings when webhooks are enabled
+func (w Webhooks) validate(prefix string, fail func(format string, args ...any)) {
+	if w.DB == "" {
+		return
+	}
+	if w.MaxAttempts <= 0 {
+		fail("%s.max_attempts must be positive", prefix)
+	}
+	if w.Timeout <= 0 {
+		fail("%s.timeout must be positive", prefix)
+	}
+	if w.MinBackoff <= 0 {
+		fail("%s.min_backoff must be positive", prefix)
+	}
+	if w.MaxBackoff < w.MinBackoff {
+		fail("%s.max_backoff must not be less than %s.min_backoff", prefix, prefix)
+	}
+	for i, network := range w.AllowedNetworks {
+		if _, err := netip.ParsePrefix(network); err != nil {
+			fail("%s.allowed_networks[%d]: %q is not a CIDR", prefix, i, network)
+		}
+	}
+}
+
+// WebhookConfig returns the delivery settings of the webhooks
+func (w Webhooks) WebhookConfig() webhook.Config {
+	config := webhook.Config{
+		MaxAttempts: w.MaxAttempts,
+		Timeout:     w.Timeout,
+		MinBackoff:  w.MinBackoff,
+		MaxBackoff:  w.MaxBackoff,
+	}
+	for _, network := range w.AllowedNetworks {
+		if prefix, err := netip.ParsePrefix(network); err == nil {
+			config.AllowedNetworks = append(config.AllowedNetworks, prefix)
+		}
+	}
+	return config
+}
+
+// validate checks the metadata refresh interval when contract routes are enabled
+func (c Contracts) validate(prefix string, fail func(format string, args ...any)) {
+	if len(c.Chaincodes) > 0 && c.Refresh <= 0 {
+		fail("%s.refresh must be positive", prefix)
+	}
+}
+
+// validate checks the TTL of prepared transactions when offline signing is enabled
+func (o Offline) validate(prefix string, fail func(format string, args ...any)) {
+	if o.Enabled && o.TTL <= 0 {
+		fail("%s.ttl must be positive", prefix)
+	}
+}
+
+// validate checks the retry settings when the outbox is enabled
+func (o Outbox) validate(prefix string, fail func(format string, args ...any)) {
+	if o.DB == "" {
+		return
+	}
+	if o.MinBackoff <= 0 {
+		fail("%s.min_backoff must be positive", prefix)
+	}
+	if o.MaxBackoff < o.MinBackoff {
+		fail("%s.max_backoff must not be less than %s.min_backoff", prefix, prefix)
+	}
+	if o.Retention <= 0 {
+		fail("%s.retention must be positive", prefix)
+	}
+}
@@ -0,0 +1,389 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/ratelimit"
+)
+
+// writeConfig writes a configuration file and returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// validConfig returns a configuration that passes Validate
+func validConfig() *Config {
+	c := Default()
+	c.Fabric = Fabric{
+		MspID:   "Org1MSP",
+		Cert:    "cert.pem",
+		Key:     "key.pem",
+		Channel: "mychannel",
+		Peers:   []Peer{{Endpoint: "peer0:7051", TLSCert: "ca.pem"}},
+	}
+	return c
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv("HLF_API_PORT", "9000")
+	t.Setenv("HLF_API_MSPID", "")
+	path := writeConfig(t, `
+server:
+  port: ${HLF_API_PORT}
+fabric:
+  mspid: ${HLF_API_MSPID:-Org1MSP}
+  channel: "price$$"
+batch:
+  parallelism: 4
+`)
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if c.Server.Port != "9000" || c.Fabric.MspID != "Org1MSP" || c.Fabric.Channel != "price$" {
+		t.Errorf("Load() = %+v %+v, want the references expanded", c.Server, c.Fabric)
+	}
+	if c.Batch.Parallelism != 4 || c.Batch.MaxOperations != Default().Batch.MaxOperations {
+		t.Errorf("Batch = %+v, want the file on top of the defaults", c.Batch)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "unknown field", content: "server:\n  prot: 9000\n", want: "field prot not found"},
+		{name: "unset variable", content: "fabric:\n  cert: ${HLF_API_UNSET_CERT}\n", want: "line 2: environment variable HLF_API_UNSET_CERT is not set"},
+		{name: "invalid yaml", content: "server: [", want: "failed to parse config"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+
+	if c, err := Load(writeConfig(t, "")); err != nil || c.Server.Port != Default().Server.Port {
+		t.Errorf("Load() of an empty file = %v, %v; want the defaults", c, err)
+	}
+}
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("HLF_API_HOST", "peer0")
+	t.Setenv("HLF_API_EMPTY", "")
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"${HLF_API_HOST}:7051", "peer0:7051"},
+		{"${HLF_API_EMPTY}", ""},
+		{"${HLF_API_EMPTY:-fallback}", "fallback"},
+		{"${HLF_API_UNSET:-}", ""},
+		{"$$HOME and $HOME", "$HOME and $HOME"},
+	}
+	for _, tt := range tests {
+		got, err := expandEnv(tt.value)
+		if err != nil || got != tt.want {
+			t.Errorf("expandEnv(%q) = %q, %v; want %q", tt.value, got, err, tt.want)
+		}
+	}
+	if _, err := expandEnv("${HLF_API_UNSET_A}${HLF_API_UNSET_B}"); err == nil || !strings.Contains(err.Error(), "HLF_API_UNSET_A, HLF_API_UNSET_B") {
+		t.Errorf("expandEnv() error = %v, want both missing variables", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		want   string
+	}{
+		{
+			name:   "missing fabric settings",
+			change: func(c *Config) { c.Fabric = Fabric{} },
+			want:   "fabric.mspid is required",
+		},
+		{
+			name:   "tls key without cert",
+			change: func(c *Config) { c.Server.TLS.Key = "key.pem" },
+			want:   "server.tls.cert and server.tls.key must be set together",
+		},
+		{
+			name: "required client certificates without probe port",
+			change: func(c *Config) {
+				c.Server.TLS = TLS{Cert: "cert.pem", Key: "key.pem", ClientCA: "ca.pem"}
+			},
+			want: "set server.probe_port",
+		},
+		{
+			name:   "shared port",
+			change: func(c *Config) { c.GRPC.Port = c.Server.Port },
+			want:   "grpc.port must differ from server.port",
+		},
+		{
+			name:   "unknown idempotency store",
+			change: func(c *Config) { c.Idempotency.Store = "redis" },
+			want:   "idempotency.store must be memory or bolt",
+		},
+		{
+			name:   "anchor without audit log",
+			change: func(c *Config) { c.Audit.Anchor.Chaincode = "audit" },
+			want:   "audit.anchor.chaincode requires audit.dir",
+		},
+		{
+			name: "webhook backoff",
+			change: func(c *Config) {
+				c.Webhooks.DB = "webhooks.db"
+				c.Webhooks.MaxBackoff = time.Millisecond
+			},
+			want: "webhooks.max_backoff must not be less than webhooks.min_backoff",
+		},
+		{
+			name: "webhook allowed networks",
+			change: func(c *Config) {
+				c.Webhooks.DB = "webhooks.db"
+				c.Webhooks.AllowedNetworks = []string{"10.0.0.0/8", "10.0.0.1"}
+			},
+			want: `webhooks.allowed_networks[1]: "10.0.0.1" is not a CIDR`,
+		},
+		{
+			name:   "log level",
+			change: func(c *Config) { c.Logging.Level = "verbose" },
+			want:   "logging.level must be debug, info, warn or error",
+		},
+		{
+			name:   "sample ratio",
+			change: func(c *Config) { c.Tracing.SampleRatio = 2 },
+			want:   "tracing.sample_ratio must be between 0 and 1",
+		},
+		{
+			name:   "too many ready peers",
+			change: func(c *Config) { c.Health.ReadyMinPeers = 2 },
+			want:   "health.ready_min_peers (2) exceeds the number of peers (1)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := validConfig()
+			tt.change(c)
+			err := c.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	c := validConfig()
+	c.Batch.Parallelism = 0
+	c.Shutdown.Timeout = 0
+	err := c.Validate()
+	if err == nil {
+		t.Fatal("Validate() succeeded")
+	}
+	for _, want := range []string{"batch.parallelism must be positive", "shutdown.timeout must be positive"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error = %v, want it to contain %q", err, want)
+		}
+	}
+}
+
+func TestCheckFiles(t *testing.T) {
+	c := validConfig()
+	dir := t.TempDir()
+	c.Fabric.Cert = filepath.Join(dir, "cert.pem")
+	c.Fabric.Key = filepath.Join(dir, "key.pem")
+	c.Fabric.Peers[0].TLSCert = filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(c.Fabric.Peers[0].TLSCert, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := c.CheckFiles()
+	if err == nil {
+		t.Fatal("CheckFiles() succeeded with missing files")
+	}
+	for _, want := range []string{"fabric.peers[0].tls_cert: " + c.Fabric.Peers[0].TLSCert + ": no PEM certificate found", "fabric: "} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("CheckFiles() error = %v, want it to contain %q", err, want)
+		}
+	}
+}
+
+// validNetwork returns a network that passes Validate
+func validNetwork() Network {
+	return Network{Fabric: Fabric{
+		MspID:   "Org2MSP",
+		Cert:    "org2-cert.pem",
+		Key:     "org2-key.pem",
+		Channel: "otherchannel",
+		Peers:   []Peer{{Endpoint: "peer0.org2:7051", TLSCert: "org2-ca.pem"}},
+	}}
+}
+
+func TestLoadNetworks(t *testing.T) {
+	c, err := Load(writeConfig(t, `
+networks:
+  org2:
+    fabric:
+      mspid: Org2MSP
+      channel: otherchannel
+    webhooks:
+      db: org2-webhooks.db
+      max_attempts: 3
+`))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	network, ok := c.Networks["org2"]
+	if !ok || network.Fabric.MspID != "Org2MSP" {
+		t.Fatalf("Networks = %+v", c.Networks)
+	}
+	d := Default()
+	if network.Webhooks.MaxAttempts != 3 || network.Webhooks.Timeout != d.Webhooks.Timeout {
+		t.Errorf("Webhooks = %+v, want the file on top of the defaults", network.Webhooks)
+	}
+	if network.Offline.TTL != d.Offline.TTL || network.Outbox.Retention != d.Outbox.Retention || network.Contracts.Refresh != d.Contracts.Refresh {
+		t.Errorf("network = %+v, want the defaults of the unset sections", network)
+	}
+}
+
+func TestValidateNetworks(t *testing.T) {
+	c := validConfig()
+	c.Networks = map[string]Network{"org2": validNetwork()}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		change func(c *Config, n *Network)
+		want   string
+	}{
+		{
+			name:   "missing fabric settings",
+			change: func(c *Config, n *Network) { n.Fabric.Channel = "" },
+			want:   "networks.org2.fabric.channel is required",
+		},
+		{
+			name:   "too many ready peers",
+			change: func(c *Config, n *Network) { n.ReadyMinPeers = 2 },
+			want:   "networks.org2.ready_min_peers (2) exceeds the number of peers (1)",
+		},
+		{
+			name: "negative rate limit",
+			change: func(c *Config, n *Network) {
+				n.RateLimits = &ratelimit.Config{Default: ratelimit.Limit{Rate: -1}}
+			},
+			want: "networks.org2.rate_limits.default: limits must not be negative",
+		},
+		{
+			name: "identity of another network",
+			change: func(c *Config, n *Network) {
+				c.Fabric.Identities = map[string]Identity{"admin": {MspID: "Org1MSP", Cert: "admin.pem", Key: "admin-key.pem"}}
+				n.Auth = &auth.Config{APIKeys: []auth.APIKeyConfig{{Name: "ops", Identity: "admin"}}}
+			},
+			want: `networks.org2: auth references identity "admin" which is not defined in networks.org2.fabric.identities`,
+		},
+		{
+			name:   "auth not isolated",
+			change: func(c *Config, n *Network) { c.Auth = &auth.Config{APIKeys: []auth.APIKeyConfig{{Name: "ops"}}} },
+			want:   "networks.org2.auth is required when auth is set",
+		},
+		{
+			name:   "shared gRPC port",
+			change: func(c *Config, n *Network) { n.GRPC.Port = c.Server.Port },
+			want:   "networks.org2.grpc.port must differ from server.port",
+		},
+		{
+			name: "shared database",
+			change: func(c *Config, n *Network) {
+				c.Outbox.DB = "outbox.db"
+				n.Outbox = Outbox{DB: "outbox.db", MinBackoff: time.Second, MaxBackoff: time.Minute, Retention: time.Hour}
+			},
+			want: "networks.org2.outbox.db must differ from outbox.db",
+		},
+		{
+			name: "outbox backoff",
+			change: func(c *Config, n *Network) {
+				n.Outbox = Outbox{DB: "org2-outbox.db", MinBackoff: time.Minute, MaxBackoff: time.Second, Retention: time.Hour}
+			},
+			want: "networks.org2.outbox.max_backoff must not be less than networks.org2.outbox.min_backoff",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := validConfig()
+			network := validNetwork()
+			tt.change(c, &network)
+			c.Networks = map[string]Network{"org2": network}
+			err := c.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+
+	for _, name := range []string{"Org2", "org/2", "-org2", ""} {
+		c := validConfig()
+		c.Networks = map[string]Network{name: validNetwork()}
+		if err := c.Validate(); err == nil || !strings.Contains(err.Error(), "network names may only contain") {
+			t.Errorf("Validate() of network %q error = %v, want the name rejected", name, err)
+		}
+	}
+}
+
+func TestNetworkOverrides(t *testing.T) {
+	c := validConfig()
+	c.Auth = &auth.Config{APIKeys: []auth.APIKeyConfig{{Name: "top"}}}
+	c.RateLimits = &ratelimit.Config{Default: ratelimit.Limit{Rate: 10}}
+	own := validNetwork()
+	own.Auth = &auth.Config{APIKeys: []auth.APIKeyConfig{{Name: "org2"}}}
+	own.RateLimits = &ratelimit.Config{Default: ratelimit.Limit{Rate: 5}}
+	c.Networks = map[string]Network{"org2": own, "org3": validNetwork()}
+
+	if got := c.NetworkAuth("org2"); got != own.Auth {
+		t.Errorf("NetworkAuth(org2) = %+v, want its own configuration", got)
+	}
+	if got := c.NetworkAuth("org3"); got != nil {
+		t.Errorf("NetworkAuth(org3) = %+v, want the top-level configuration not inherited", got)
+	}
+	if got := c.NetworkRateLimits("org2"); got != own.RateLimits {
+		t.Errorf("NetworkRateLimits(org2) = %+v, want its own configuration", got)
+	}
+	if got := c.NetworkRateLimits("org3"); got != c.RateLimits {
+		t.Errorf("NetworkRateLimits(org3) = %+v, want the top-level configuration", got)
+	}
+}
+
+func TestCheckFilesOfNetworks(t *testing.T) {
+	c := validConfig()
+	dir := t.TempDir()
+	c.Fabric.Peers = nil
+	network := validNetwork()
+	network.Fabric.Peers[0].TLSCert = filepath.Join(dir, "missing-ca.pem")
+	c.Networks = map[string]Network{"org2": network}
+
+	err := c.CheckFiles()
+	if err == nil || !strings.Contains(err.Error(), "networks.org2.fabric.peers[0].tls_cert: ") {
+		t.Errorf("CheckFiles() error = %v, want the peer certificate of the network", err)
+	}
+}
@@ -0,0 +1,244 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/schema"
+)
+
+// MetadataFunction is the system function of the contract API returning the
+// metadata of a chaincode
+const MetadataFunction = "org.hyperledger.fabric:GetMetadata"
+
+// systemContract is the contract the contract API adds to every chaincode
+const systemContract = "org.hyperledger.fabric"
+
+// metadata is the document returned by GetMetadata
+type metadata struct {
+	Info       map[string]interface{}      `json:"info"`
+	Contracts  map[string]contractMetadata `json:"contracts"`
+	Components struct {
+		Schemas map[string]interface{} `json:"schemas"`
+	} `json:"components"`
+}
+
+type contractMetadata struct {
+	Name         string                 `json:"name"`
+	Info         map[string]interface{} `json:"info"`
+	Transactions []transactionMetadata  `json:"transactions"`
+}
+
+type transactionMetadata struct {
+	Name       string              `json:"name"`
+	Tag        []string            `json:"tag"`
+	Tags       []string            `json:"tags"`
+	Parameters []parameterMetadata `json:"parameters"`
+	Returns    interface{}         `json:"returns"`
+}
+
+type parameterMetadata struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Schema      interface{} `json:"schema"`
+}
+
+// Chaincode is the parsed metadata of a chaincode
+type Chaincode struct {
+	Name string
+	// Contracts maps contract names to their transactions by name
+	Contracts map[string]map[string]*Transaction
+
+	metadata metadata
+}
+
+// Transaction is a transaction function of a contract
+type Transaction struct {
+	Contract string
+	Name     string
+	// Submit is set for transactions that are submitted to the ledger;
+	// the others are evaluated
+	Submit     bool
+	Parameters []*Parameter
+	Returns    interface{}
+}
+
+// Parameter is a named parameter of a transaction
+type Parameter struct {
+	Name        string
+	Description string
+	Schema      interface{}
+
+	// compiled is nil when the schema could not be compiled, in which case
+	// the parameter is not validated
+	compiled *jsonschema.Schema
+}
+
+// Function returns the name the transaction is invoked with
+func (t *Transaction) Function() string {
+	return t.Contract + ":" + t.Name
+}
+
+// parseMetadata parses the GetMetadata response of a chaincode
+func parseMetadata(chaincode string, data []byte) (*Chaincode, error) {
+	var md metadata
+	if err := json.Unmarshal(data, &md); err != nil {
+		return nil, fmt.Errorf("invalid metadata of chaincode %s: %w", chaincode, err)
+	}
+
+	// Parameter schemas refer to the shared components of the document
+	doc, err := jsonschema.UnmarshalJSON(strings.NewReader(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata of chaincode %s: %w", chaincode, err)
+	}
+	// The components may carry an $id, which would change the base URI that
+	// the #/components/schemas references of nested schemas resolve against
+	if root, ok := doc.(map[string]interface{}); ok {
+		components, _ := root["components"].(map[string]interface{})
+		schemas, _ := components["schemas"].(map[string]interface{})
+		for _, s := range schemas {
+			if s, ok := s.(map[string]interface{}); ok {
+				delete(s, "$id")
+			}
+		}
+	}
+	url := "urn:chaincode:" + chaincode
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft7)
+	if err := compiler.AddResource(url, doc); err != nil {
+		return nil, fmt.Errorf("invalid metadata of chaincode %s: %w", chaincode, err)
+	}
+
+	cc := &Chaincode{
+		Name:      chaincode,
+		Contracts: map[string]map[string]*Transaction{},
+		metadata:  md,
+	}
+	for key, contract := range md.Contracts {
+		name := contract.Name
+		if name == "" {
+			name = key
+		}
+		if name == systemContract {
+			continue
+		}
+		transactions := map[string]*Transaction{}
+		for i, tx := range contract.Transactions {
+			t := &Transaction{
+				Contract: name,
+				Name:     tx.Name,
+				Submit:   isSubmit(append(tx.Tag, tx.Tags...)),
+				Returns:  tx.Returns,
+			}
+			for j, param := range tx.Parameters {
+				p := &Parameter{Name: param.Name, Description: param.Description, Schema: param.Schema}
+				if param.Schema != nil {
+					location := fmt.Sprintf("%s#/contracts/%s/transactions/%d/parameters/%d/schema", url, pointerToken(key), i, j)
+					// Schemas the validator cannot compile are left to the chaincode
+					p.compiled, _ = compiler.Compile(location)
+				}
+				t.Parameters = append(t.Parameters, p)
+			}
+			transactions[tx.Name] = t
+		}
+		cc.Contracts[name] = transactions
+	}
+	return cc, nil
+}
+
+// isSubmit reports whether the tags mark a transaction for submission. The
+// contract API implementations tag them "submit" (Go, Java) or "submitTx"
+// (Node); transactions without such a tag are evaluated.
+func isSubmit(tags []string) bool {
+	for _, tag := range tags {
+		switch strings.ToLower(tag) {
+		case "submit", "submittx":
+			return true
+		}
+	}
+	return false
+}
+
+func pointerToken(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+// Transaction returns a transaction by contract and name
+func (c *Chaincode) Transaction(contract, name string) (*Transaction, bool) {
+	t, ok := c.Contracts[contract][name]
+	return t, ok
+}
+
+// Transactions returns the transactions ordered by contract and name
+func (c *Chaincode) Transactions() []*Transaction {
+	var transactions []*Transaction
+	for _, contract := range c.Contracts {
+		for _, t := range contract {
+			transactions = append(transactions, t)
+		}
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		if transactions[i].Contract != transactions[j].Contract {
+			return transactions[i].Contract < transactions[j].Contract
+		}
+		return transactions[i].Name < transactions[j].Name
+	})
+	return transactions
+}
+
+// Args converts the named parameters of a request body into the positional
+// arguments of the transaction. String values are passed as they are, other
+// values JSON encoded. Missing, unknown and invalid parameters are reported
+// as field errors.
+func (t *Transaction) Args(body map[string]json.RawMessage) ([]string, []schema.FieldError) {
+	var errs []schema.FieldError
+	known := make(map[string]bool, len(t.Parameters))
+	args := make([]string, len(t.Parameters))
+	for i, param := range t.Parameters {
+		known[param.Name] = true
+		raw, ok := body[param.Name]
+		if !ok {
+			errs = append(errs, schema.FieldError{Field: "/" + pointerToken(param.Name), Message: "missing parameter"})
+			continue
+		}
+		value, err := jsonschema.UnmarshalJSON(strings.NewReader(string(raw)))
+		if err != nil {
+			errs = append(errs, schema.FieldError{Field: "/" + pointerToken(param.Name), Message: "invalid JSON value"})
+			continue
+		}
+		if param.compiled != nil {
+			if err := param.compiled.Validate(value); err != nil {
+				if validationErr, ok := err.(*jsonschema.ValidationError); ok {
+					for _, fieldErr := range schema.FieldErrors(validationErr) {
+						fieldErr.Field = "/" + pointerToken(param.Name) + fieldErr.Field
+						errs = append(errs, fieldErr)
+					}
+				}
+				continue
+			}
+		}
+		if s, ok := value.(string); ok {
+			args[i] = s
+			continue
+		}
+		var compact bytes.Buffer
+		json.Compact(&compact, raw)
+		args[i] = compact.String()
+	}
+	var unknown []string
+	for name := range body {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		errs = append(errs, schema.FieldError{Field: "/" + pointerToken(name), Message: "unknown parameter"})
+	}
+	return args, errs
+}
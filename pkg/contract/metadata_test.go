@@ -0,0 +1,143 @@
+package contract
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// testMetadata is the metadata of a chaincode written with the contract API,
+// with a parameter referring to the shared components
+const testMetadata = `{
+  "info": {"title": "Asset transfer", "version": "2.0"},
+  "contracts": {
+    "AssetContract": {
+      "name": "AssetContract",
+      "transactions": [
+        {
+          "name": "CreateAsset",
+          "tag": ["submit"],
+          "parameters": [
+            {"name": "id", "schema": {"type": "string", "minLength": 1}},
+            {"name": "asset", "description": "The asset to create", "schema": {"$ref": "#/components/schemas/Asset"}}
+          ]
+        },
+        {
+          "name": "ReadAsset",
+          "tags": ["evaluate"],
+          "parameters": [{"name": "id", "schema": {"type": "string"}}],
+          "returns": {"$ref": "#/components/schemas/Asset"}
+        }
+      ]
+    },
+    "org.hyperledger.fabric": {
+      "name": "org.hyperledger.fabric",
+      "transactions": [{"name": "GetMetadata"}]
+    }
+  },
+  "components": {
+    "schemas": {
+      "Asset": {
+        "$id": "Asset",
+        "type": "object",
+        "properties": {"color": {"type": "string"}, "size": {"type": "integer"}},
+        "required": ["color", "size"]
+      }
+    }
+  }
+}`
+
+func parseTestMetadata(t *testing.T) *Chaincode {
+	t.Helper()
+	cc, err := parseMetadata("basic", []byte(testMetadata))
+	if err != nil {
+		t.Fatalf("parseMetadata() error = %v", err)
+	}
+	return cc
+}
+
+func TestParseMetadata(t *testing.T) {
+	cc := parseTestMetadata(t)
+	transactions := cc.Transactions()
+	if len(transactions) != 2 || transactions[0].Name != "CreateAsset" || transactions[1].Name != "ReadAsset" {
+		t.Fatalf("Transactions() = %v, want CreateAsset and ReadAsset without the system contract", transactions)
+	}
+	create, ok := cc.Transaction("AssetContract", "CreateAsset")
+	if !ok || !create.Submit || create.Function() != "AssetContract:CreateAsset" || len(create.Parameters) != 2 {
+		t.Errorf("CreateAsset = %+v", create)
+	}
+	if read, _ := cc.Transaction("AssetContract", "ReadAsset"); read.Submit {
+		t.Error("ReadAsset is marked for submission")
+	}
+	if _, err := parseMetadata("basic", []byte("{")); err == nil {
+		t.Error("parseMetadata() accepted invalid JSON")
+	}
+}
+
+func TestIsSubmit(t *testing.T) {
+	tests := []struct {
+		tags []string
+		want bool
+	}{
+		{[]string{"submit"}, true},
+		{[]string{"SUBMIT"}, true},
+		{[]string{"submitTx"}, true},
+		{[]string{"evaluate"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := isSubmit(tt.tags); got != tt.want {
+			t.Errorf("isSubmit(%v) = %v, want %v", tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestArgs(t *testing.T) {
+	create, _ := parseTestMetadata(t).Transaction("AssetContract", "CreateAsset")
+	tests := []struct {
+		name   string
+		body   string
+		args   []string
+		fields []string
+	}{
+		{
+			name: "valid",
+			body: `{"id": "asset1", "asset": {"color": "blue", "size": 5}}`,
+			args: []string{"asset1", `{"color":"blue","size":5}`},
+		},
+		{
+			name:   "missing parameter",
+			body:   `{"asset": {"color": "blue", "size": 5}}`,
+			fields: []string{"/id"},
+		},
+		{
+			name:   "unknown parameters",
+			body:   `{"id": "asset1", "asset": {"color": "blue", "size": 5}, "owner": "tom", "a/b": 1}`,
+			fields: []string{"/a~1b", "/owner"},
+		},
+		{
+			name:   "invalid referenced schema",
+			body:   `{"id": "", "asset": {"color": "blue", "size": "big"}}`,
+			fields: []string{"/id", "/asset/size"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(tt.body), &body); err != nil {
+				t.Fatal(err)
+			}
+			args, errs := create.Args(body)
+			var fields []string
+			for _, err := range errs {
+				fields = append(fields, err.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.fields, ",") {
+				t.Errorf("Args() errors = %+v, want errors for %v", errs, tt.fields)
+			}
+			if tt.args != nil && strings.Join(args, "|") != strings.Join(tt.args, "|") {
+				t.Errorf("Args() = %q, want %q", args, tt.args)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// OpenAPI returns an OpenAPI 3 document describing the routes of the
+// chaincode's transactions. The schemas of the metadata are used as they
+// are; their references to #/components/schemas resolve within the document.
+func (c *Chaincode) OpenAPI() map[string]interface{} {
+	info := map[string]interface{}{
+		"title":   fmt.Sprintf("%s chaincode", c.Name),
+		"version": "1.0",
+	}
+	for _, key := range []string{"title", "version", "description"} {
+		if value, ok := c.metadata.Info[key]; ok {
+			info[key] = value
+		}
+	}
+
+	schemas := map[string]interface{}{}
+	for name, s := range c.metadata.Components.Schemas {
+		schemas[name] = s
+	}
+	schemas["TransactionResponse"] = transactionResponseSchema
+	schemas["ErrorResponse"] = errorResponseSchema
+
+	paths := map[string]interface{}{}
+	for _, t := range c.Transactions() {
+		paths[fmt.Sprintf("/api/chaincodes/%s/contracts/%s/%s", c.Name, t.Contract, t.Name)] = map[string]interface{}{
+			"post": t.operation(),
+		}
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    info,
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"ApiKeyAuth": map[string]interface{}{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+				"BearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer"},
+			},
+		},
+		"security": []interface{}{
+			map[string]interface{}{"ApiKeyAuth": []string{}},
+			map[string]interface{}{"BearerAuth": []string{}},
+		},
+	}
+}
+
+func (t *Transaction) operation() map[string]interface{} {
+	kind := "Evaluates"
+	if t.Submit {
+		kind = "Submits"
+	}
+	properties := map[string]interface{}{}
+	required := []string{}
+	for _, param := range t.Parameters {
+		var s interface{} = map[string]interface{}{}
+		if param.Schema != nil {
+			s = param.Schema
+		}
+		if param.Description != "" {
+			s = map[string]interface{}{"allOf": []interface{}{s}, "description": param.Description}
+		}
+		properties[param.Name] = s
+		required = append(required, param.Name)
+	}
+	body := map[string]interface{}{"type": "object", "properties": properties, "additionalProperties": false}
+	if len(required) > 0 {
+		body["required"] = required
+	}
+
+	errorResponse := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"description": description,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": map[string]interface{}{"$ref": "#/components/schemas/ErrorResponse"},
+				},
+			},
+		}
+	}
+	success := "The result of the transaction"
+	if t.Returns != nil {
+		success = "The result of the transaction; result holds the returned value as described by x-returns"
+	}
+	ok := map[string]interface{}{
+		"description": success,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": map[string]interface{}{"$ref": "#/components/schemas/TransactionResponse"},
+			},
+		},
+	}
+	if t.Returns != nil {
+		ok["x-returns"] = t.Returns
+	}
+
+	operation := map[string]interface{}{
+		"summary":     fmt.Sprintf("%s %s of contract %s", kind, t.Name, t.Contract),
+		"operationId": t.Contract + "_" + t.Name,
+		"tags":        []string{t.Contract},
+		"requestBody": map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": body},
+			},
+		},
+		"responses": map[string]interface{}{
+			"200": ok,
+			"400": errorResponse("Missing, unknown or invalid parameters"),
+			"401": errorResponse("Authentication required"),
+			"403": errorResponse("Not allowed to call the transaction"),
+			"429": errorResponse("Rate limit exceeded"),
+			"500": errorResponse("The transaction failed"),
+		},
+	}
+	if t.Submit {
+		operation["parameters"] = []interface{}{map[string]interface{}{
+			"name":        "Idempotency-Key",
+			"in":          "header",
+			"required":    false,
+			"description": "Key used to deduplicate retries; the first outcome is replayed for the same key",
+			"schema":      map[string]interface{}{"type": "string"},
+		}}
+	}
+	return operation
+}
+
+var transactionResponseSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"status":       map[string]interface{}{"type": "string", "example": "success"},
+		"result":       map[string]interface{}{"type": "string"},
+		"tx_id":        map[string]interface{}{"type": "string"},
+		"block_number": map[string]interface{}{"type": "integer"},
+		"result_code":  map[string]interface{}{"type": "integer"},
+		"success":      map[string]interface{}{"type": "boolean"},
+	},
+}
+
+var errorResponseSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"status": map[string]interface{}{"type": "string", "example": "error"},
+		"error":  map[string]interface{}{"type": "string"},
+		"fields": map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"field":   map[string]interface{}{"type": "string"},
+					"message": map[string]interface{}{"type": "string"},
+				},
+			},
+		},
+	},
+}
+
+// DocHandler serves the OpenAPI document of the chaincode named by the cc
+// URL parameter
+func (r *Registry) DocHandler(w http.ResponseWriter, req *http.Request) {
+	name := chi.URLParam(req, "cc")
+	cc, configured := r.Chaincode(name)
+	if !configured {
+		http.Error(w, fmt.Sprintf("chaincode %s is not served by contract routes", name), http.StatusNotFound)
+		return
+	}
+	if cc == nil {
+		http.Error(w, fmt.Sprintf("the metadata of chaincode %s has not been fetched yet", name), http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cc.OpenAPI())
+}
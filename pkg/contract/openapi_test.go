@@ -0,0 +1,72 @@
+package contract
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestOpenAPI(t *testing.T) {
+	doc := parseTestMetadata(t).OpenAPI()
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec struct {
+		Info       map[string]string `json:"info"`
+		Paths      map[string]map[string]map[string]interface{}
+		Components struct {
+			Schemas map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	if spec.Info["title"] != "Asset transfer" || spec.Info["version"] != "2.0" {
+		t.Errorf("info = %v, want the info of the metadata", spec.Info)
+	}
+	for _, name := range []string{"Asset", "TransactionResponse", "ErrorResponse"} {
+		if _, ok := spec.Components.Schemas[name]; !ok {
+			t.Errorf("the document has no %s schema", name)
+		}
+	}
+	create := spec.Paths["/api/chaincodes/basic/contracts/AssetContract/CreateAsset"]["post"]
+	if create == nil || create["summary"] != "Submits CreateAsset of contract AssetContract" || create["parameters"] == nil {
+		t.Errorf("CreateAsset operation = %v, want a submit with the Idempotency-Key header", create)
+	}
+	read := spec.Paths["/api/chaincodes/basic/contracts/AssetContract/ReadAsset"]["post"]
+	if read == nil || read["summary"] != "Evaluates ReadAsset of contract AssetContract" || read["parameters"] != nil {
+		t.Errorf("ReadAsset operation = %v", read)
+	}
+	if len(spec.Paths) != 2 {
+		t.Errorf("paths = %v, want the system contract left out", spec.Paths)
+	}
+}
+
+func TestDocHandler(t *testing.T) {
+	r := NewRegistry(nil, []string{"basic", "pending"}, time.Minute)
+	r.metadata["basic"] = parseTestMetadata(t)
+	router := chi.NewRouter()
+	router.Get("/swagger/chaincodes/{cc}/doc.json", r.DocHandler)
+
+	tests := []struct {
+		chaincode string
+		status    int
+	}{
+		{"basic", http.StatusOK},
+		{"pending", http.StatusServiceUnavailable},
+		{"other", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger/chaincodes/"+tt.chaincode+"/doc.json", nil))
+		if w.Code != tt.status {
+			t.Errorf("GET the document of %s status = %d, want %d", tt.chaincode, w.Code, tt.status)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package contract
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+)
+
+// Registry keeps the metadata of the configured chaincodes, fetched from the
+// chaincodes' GetMetadata function and refreshed periodically so that
+// upgraded chaincodes are picked up
+type Registry struct {
+	fabricClient *fabric.FabricClient
+	chaincodes   []string
+	interval     time.Duration
+
+	mu       sync.RWMutex
+	metadata map[string]*Chaincode
+}
+
+// NewRegistry creates a registry for the given chaincodes, refreshed every interval
+func NewRegistry(fabricClient *fabric.FabricClient, chaincodes []string, interval time.Duration) *Registry {
+	return &Registry{
+		fabricClient: fabricClient,
+		chaincodes:   chaincodes,
+		interval:     interval,
+		metadata:     map[string]*Chaincode{},
+	}
+}
+
+// Run fetches the metadata right away and then every interval until ctx is cancelled
+func (r *Registry) Run(ctx context.Context) {
+	r.Refresh(ctx)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Refresh(ctx)
+		}
+	}
+}
+
+// Refresh fetches the metadata of every chaincode. A chaincode whose metadata
+// cannot be fetched keeps the metadata fetched before.
+func (r *Registry) Refresh(ctx context.Context) {
+	ctx = auth.NewContext(ctx, &auth.Principal{Name: "contract-metadata", Method: "internal"})
+	for _, name := range r.chaincodes {
+		data, err := r.fabricClient.EvaluateTransaction(ctx, name, MetadataFunction, nil)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to fetch contract metadata", "chaincode", name, "error", err)
+			continue
+		}
+		cc, err := parseMetadata(name, data)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to parse contract metadata", "chaincode", name, "error", err)
+			continue
+		}
+		r.mu.Lock()
+		r.metadata[name] = cc
+		r.mu.Unlock()
+		slog.DebugContext(ctx, "fetched contract metadata", "chaincode", name, "transactions", len(cc.Transactions()))
+	}
+}
+
+// Chaincode returns the metadata of a chaincode. configured is false for
+// chaincodes that are not served, and the metadata nil for served ones
+// whose metadata has not been fetched yet.
+func (r *Registry) Chaincode(name string) (cc *Chaincode, configured bool) {
+	for _, chaincode := range r.chaincodes {
+		if chaincode == name {
+			configured = true
+			break
+		}
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.metadata[name], configured
+}
@@ -6,12 +6,19 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"log/slog"
 	"math/rand"
 	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hyperledger/fabric-gateway/pkg/client"
 	"github.com/hyperledger/fabric-gateway/pkg/identity"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/logging"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 )
@@ -22,6 +29,13 @@ type PeerConfig struct {
 	TLSCertPath string
 }
 
+// IdentityConfig holds the material of a signing identity
+type IdentityConfig struct {
+	MspID    string
+	CertPath string
+	KeyPath  string
+}
+
 // ClientConfig holds the configuration for connecting to Fabric
 type ClientConfig struct {
 	MspID       string
@@ -29,6 +43,8 @@ type ClientConfig struct {
 	KeyPath     string
 	Peers       []PeerConfig
 	ChannelName string
+	// Identities holds additional named signing identities that can be selected per request with WithIdentity
+	Identities map[string]IdentityConfig
 }
 
 // TransactionResult represents the result of a transaction
@@ -43,7 +59,21 @@ type TransactionResult struct {
 // FabricClient represents a connection to the Fabric network
 type FabricClient struct {
 	config *ClientConfig
+	randMu sync.Mutex
 	rand   *rand.Rand
+
+	connsMu sync.Mutex
+	conns   map[string]*grpc.ClientConn
+	closed  bool
+
+	opsMu    sync.RWMutex
+	ops      sync.WaitGroup
+	inFlight atomic.Int64
+	draining bool
+
+	listenersMu          sync.RWMutex
+	transactionListeners []TransactionListener
+	observers            []Observer
 }
 
 func ParseX509Certificate(contents []byte) (*x509.Certificate, error) {
@@ -74,14 +104,89 @@ func NewFabricClient(config *ClientConfig) (*FabricClient, error) {
 	return &FabricClient{
 		config: config,
 		rand:   random,
+		conns:  make(map[string]*grpc.ClientConn),
 	}, nil
 }
 
-// selectRandomPeer returns a random peer connection from the available peers
-func (fc *FabricClient) selectRandomPeer() (*grpc.ClientConn, error) {
-	// Select a random peer configuration
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx that makes the client sign with the named
+// identity instead of the default one
+func WithIdentity(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, identityKey{}, name)
+}
+
+// IdentityFromContext returns the identity name selected with WithIdentity, or
+// an empty string for the default identity
+func IdentityFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(identityKey{}).(string)
+	return name
+}
+
+type transientKey struct{}
+
+// WithTransient returns a copy of ctx that makes the client pass transient
+// data to the chaincode with the proposal; transient data is not recorded on
+// the ledger
+func WithTransient(ctx context.Context, transient map[string][]byte) context.Context {
+	return context.WithValue(ctx, transientKey{}, transient)
+}
+
+// proposalOptions returns the options of a proposal carrying args and the
+// transient data selected in ctx
+func proposalOptions(ctx context.Context, args []string) []client.ProposalOption {
+	options := []client.ProposalOption{client.WithArguments(args...)}
+	if transient, _ := ctx.Value(transientKey{}).(map[string][]byte); len(transient) > 0 {
+		options = append(options, client.WithTransient(transient))
+	}
+	return options
+}
+
+// HasIdentity reports whether a named identity is configured
+func (fc *FabricClient) HasIdentity(name string) bool {
+	_, ok := fc.config.Identities[name]
+	return ok
+}
+
+// identityConfig returns the identity selected in ctx
+func (fc *FabricClient) identityConfig(ctx context.Context) (IdentityConfig, error) {
+	name := IdentityFromContext(ctx)
+	if name == "" {
+		return IdentityConfig{
+			MspID:    fc.config.MspID,
+			CertPath: fc.config.CertPath,
+			KeyPath:  fc.config.KeyPath,
+		}, nil
+	}
+	idConfig, ok := fc.config.Identities[name]
+	if !ok {
+		return IdentityConfig{}, fmt.Errorf("identity %q is not configured", name)
+	}
+	return idConfig, nil
+}
+
+// ChannelName returns the channel the client submits transactions to
+func (fc *FabricClient) ChannelName() string {
+	return fc.config.ChannelName
+}
+
+// selectRandomPeer picks a random peer from the configured peers
+func (fc *FabricClient) selectRandomPeer(ctx context.Context) PeerConfig {
+	_, span := tracer.Start(ctx, "fabric.SelectPeer")
+	defer span.End()
+
+	// rand.Rand is not safe for concurrent use
+	fc.randMu.Lock()
 	peerConfig := fc.config.Peers[fc.rand.Intn(len(fc.config.Peers))]
+	fc.randMu.Unlock()
+
+	span.SetAttributes(attributePeer.String(peerConfig.Endpoint))
+	return peerConfig
+}
 
+// dialPeer creates a gRPC connection to the peer; use peerConnection to reuse
+// the pooled connection instead
+func (fc *FabricClient) dialPeer(peerConfig PeerConfig) (*grpc.ClientConn, error) {
 	// Load TLS certificate for the peer
 	tlsCert, err := os.ReadFile(peerConfig.TLSCertPath)
 	if err != nil {
@@ -92,8 +197,12 @@ func (fc *FabricClient) selectRandomPeer() (*grpc.ClientConn, error) {
 	certPool.AppendCertsFromPEM(tlsCert)
 	transportCreds := credentials.NewClientTLSFromCert(certPool, "")
 
-	// Create gRPC connection
-	conn, err := grpc.Dial(peerConfig.Endpoint, grpc.WithTransportCredentials(transportCreds))
+	// Create gRPC connection; the stats handler propagates the trace of each
+	// call to the peer
+	conn, err := grpc.Dial(peerConfig.Endpoint,
+		grpc.WithTransportCredentials(transportCreds),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create gRPC connection to peer %s: %w", peerConfig.Endpoint, err)
 	}
@@ -101,79 +210,187 @@ func (fc *FabricClient) selectRandomPeer() (*grpc.ClientConn, error) {
 	return conn, nil
 }
 
-// createGatewayConnection creates a new gateway connection for a specific peer
-func (fc *FabricClient) createGatewayConnection(conn *grpc.ClientConn) (*client.Gateway, error) {
-	certPem, err := os.ReadFile(fc.config.CertPath)
+// createGatewayConnection creates a new gateway connection for a specific peer,
+// signing with the identity selected in ctx
+func (fc *FabricClient) createGatewayConnection(ctx context.Context, conn *grpc.ClientConn) (*client.Gateway, error) {
+	idConfig, err := fc.identityConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, signer, err := loadIdentity(idConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Connect(
+		id,
+		client.WithSign(signer),
+		client.WithClientConnection(conn),
+		client.WithEvaluateTimeout(30*time.Second),
+		client.WithEndorseTimeout(30*time.Second),
+		client.WithSubmitTimeout(30*time.Second),
+		client.WithCommitStatusTimeout(30*time.Second),
+	)
+}
+
+// loadIdentity reads the certificate and private key of a signing identity
+func loadIdentity(idConfig IdentityConfig) (*identity.X509Identity, identity.Sign, error) {
+	certPem, err := os.ReadFile(idConfig.CertPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read certificate file: %w", err)
+		return nil, nil, fmt.Errorf("failed to read certificate file: %w", err)
 	}
 
 	cert, err := ParseX509Certificate(certPem)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse certificate for the peer: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse certificate for the peer: %w", err)
 	}
 
-	id, err := identity.NewX509Identity(fc.config.MspID, cert)
+	id, err := identity.NewX509Identity(idConfig.MspID, cert)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create identity: %w", err)
+		return nil, nil, fmt.Errorf("failed to create identity: %w", err)
 	}
-	keyPem, err := os.ReadFile(fc.config.KeyPath)
+	keyPem, err := os.ReadFile(idConfig.KeyPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read private key file: %w", err)
+		return nil, nil, fmt.Errorf("failed to read private key file: %w", err)
 	}
 	pk, err := identity.PrivateKeyFromPEM(keyPem)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create private key: %w", err)
+		return nil, nil, fmt.Errorf("failed to create private key: %w", err)
 	}
 
 	signer, err := identity.NewPrivateKeySign(pk)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create signer: %w", err)
+		return nil, nil, fmt.Errorf("failed to create signer: %w", err)
 	}
-
-	return client.Connect(
-		id,
-		client.WithSign(signer),
-		client.WithClientConnection(conn),
-		client.WithEvaluateTimeout(30*time.Second),
-		client.WithEndorseTimeout(30*time.Second),
-		client.WithSubmitTimeout(30*time.Second),
-		client.WithCommitStatusTimeout(30*time.Second),
-	)
+	return id, signer, nil
 }
 
 // InvokeTransaction submits a transaction to the ledger
 func (fc *FabricClient) InvokeTransaction(ctx context.Context, chaincodeName string, fcn string, args []string) (*TransactionResult, error) {
+	release, err := fc.beginOperation()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	ctx, span := tracer.Start(ctx, "fabric.InvokeTransaction", trace.WithAttributes(
+		attributeChannel.String(fc.config.ChannelName),
+		attributeChaincode.String(chaincodeName),
+		attributeFunction.String(fcn),
+	))
+	defer span.End()
+
+	slog.DebugContext(ctx, "submitting transaction",
+		"chaincode", chaincodeName,
+		"function", fcn,
+		"args", logging.Sensitive(args),
+	)
+
+	event := &TransactionEvent{
+		Channel:       fc.config.ChannelName,
+		ChaincodeName: chaincodeName,
+		Function:      fcn,
+		Args:          args,
+		Identity:      IdentityFromContext(ctx),
+		StartedAt:     time.Now(),
+	}
+	result, err := fc.submitTransaction(ctx, event)
+	event.CompletedAt = time.Now()
+	event.Err = err
+	if event.TxID != "" {
+		span.SetAttributes(attributeTxID.String(event.TxID))
+	}
+	if event.ValidationCode != "" {
+		span.SetAttributes(
+			attributeValidationCode.String(event.ValidationCode),
+			attributeBlockNumber.Int64(int64(event.BlockNumber)),
+		)
+		if result != nil && !result.Success {
+			span.SetStatus(codes.Error, "transaction invalidated with "+event.ValidationCode)
+		}
+	}
+	endSpan(span, err)
+	if err != nil {
+		slog.ErrorContext(ctx, "transaction failed", "chaincode", chaincodeName, "function", fcn, "error", err)
+	} else {
+		slog.InfoContext(ctx, "transaction committed",
+			"chaincode", chaincodeName,
+			"function", fcn,
+			"block_number", event.BlockNumber,
+			"validation_code", event.ValidationCode,
+		)
+	}
+	fc.notifyTransactionListeners(ctx, event)
+	return result, err
+}
+
+// submitTransaction endorses, submits and waits for the commit of a
+// transaction, recording its progress in event
+func (fc *FabricClient) submitTransaction(ctx context.Context, event *TransactionEvent) (*TransactionResult, error) {
+	call := CallInfo{
+		Channel:       fc.config.ChannelName,
+		ChaincodeName: event.ChaincodeName,
+		Function:      event.Function,
+	}
+
 	// Select a random peer and create connection
-	selectedPeer, err := fc.selectRandomPeer()
+	peerConfig := fc.selectRandomPeer(ctx)
+	call.Peer = peerConfig.Endpoint
+	logging.Add(ctx, slog.String("peer", call.Peer))
+	_, connected := fc.observeCall(ctx, call.withPhase(PhaseConnect))
+	selectedPeer, err := fc.peerConnection(peerConfig)
 	if err != nil {
+		connected(err)
 		return nil, fmt.Errorf("failed to select peer: %w", err)
 	}
-	defer selectedPeer.Close()
 
 	// Create a new gateway connection
-	gw, err := fc.createGatewayConnection(selectedPeer)
+	gw, err := fc.createGatewayConnection(ctx, selectedPeer)
+	connected(err)
 	if err != nil {
-		selectedPeer.Close()
 		return nil, fmt.Errorf("failed to create gateway connection: %w", err)
 	}
 	defer gw.Close()
+	event.MspID = gw.Identity().MspID()
 
 	network := gw.GetNetwork(fc.config.ChannelName)
-	contract := network.GetContract(chaincodeName)
+	contract := network.GetContract(event.ChaincodeName)
+
+	proposal, err := contract.NewProposal(event.Function, proposalOptions(ctx, event.Args)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create proposal: %w", err)
+	}
+	event.TxID = proposal.TransactionID()
+	call.TxID = event.TxID
+	logging.Add(ctx, slog.String("tx_id", event.TxID))
 
-	result, commit, err := contract.SubmitAsync(fcn, client.WithArguments(args...))
+	callCtx, done := fc.observeCall(ctx, call.withPhase(PhaseEndorse))
+	transaction, err := proposal.EndorseWithContext(callCtx)
+	done(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to endorse transaction: %w", err)
+	}
+
+	callCtx, done = fc.observeCall(ctx, call.withPhase(PhaseSubmit))
+	commit, err := transaction.SubmitWithContext(callCtx)
+	done(err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to submit transaction: %w", err)
 	}
+	event.Submitted = true
 
-	status, err := commit.Status()
+	callCtx, done = fc.observeCall(ctx, call.withPhase(PhaseCommitStatus))
+	status, err := commit.StatusWithContext(callCtx)
+	done(err)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get commit status: %w", err)
+		return nil, &CommitStatusError{TxID: event.TxID, Err: err}
 	}
+	event.BlockNumber = status.BlockNumber
+	event.ValidationCode = status.Code.String()
 
 	return &TransactionResult{
-		Result:      result,
+		Result:      transaction.Result(),
 		TxID:        commit.TransactionID(),
 		BlockNumber: status.BlockNumber,
 		ResultCode:  uint32(status.Code.Number()),
@@ -182,17 +399,46 @@ func (fc *FabricClient) InvokeTransaction(ctx context.Context, chaincodeName str
 }
 
 // EvaluateTransaction evaluates a transaction without submitting to the ledger
-func (fc *FabricClient) EvaluateTransaction(ctx context.Context, chaincodeName string, fcn string, args []string) ([]byte, error) {
+func (fc *FabricClient) EvaluateTransaction(ctx context.Context, chaincodeName string, fcn string, args []string) (result []byte, err error) {
+	release, err := fc.beginOperation()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	ctx, span := tracer.Start(ctx, "fabric.EvaluateTransaction", trace.WithAttributes(
+		attributeChannel.String(fc.config.ChannelName),
+		attributeChaincode.String(chaincodeName),
+		attributeFunction.String(fcn),
+	))
+	defer func() { endSpan(span, err) }()
+
+	slog.DebugContext(ctx, "evaluating transaction",
+		"chaincode", chaincodeName,
+		"function", fcn,
+		"args", logging.Sensitive(args),
+	)
+
+	call := CallInfo{
+		Channel:       fc.config.ChannelName,
+		ChaincodeName: chaincodeName,
+		Function:      fcn,
+	}
+
 	// Select a random peer and create connection
-	selectedPeer, err := fc.selectRandomPeer()
+	peerConfig := fc.selectRandomPeer(ctx)
+	call.Peer = peerConfig.Endpoint
+	logging.Add(ctx, slog.String("peer", call.Peer))
+	_, connected := fc.observeCall(ctx, call.withPhase(PhaseConnect))
+	selectedPeer, err := fc.peerConnection(peerConfig)
 	if err != nil {
+		connected(err)
 		return nil, fmt.Errorf("failed to select peer: %w", err)
 	}
-	defer selectedPeer.Close()
 	// Create a new gateway connection
-	gw, err := fc.createGatewayConnection(selectedPeer)
+	gw, err := fc.createGatewayConnection(ctx, selectedPeer)
+	connected(err)
 	if err != nil {
-		selectedPeer.Close()
 		return nil, fmt.Errorf("failed to create gateway connection: %w", err)
 	}
 	defer gw.Close()
@@ -200,17 +446,33 @@ func (fc *FabricClient) EvaluateTransaction(ctx context.Context, chaincodeName s
 	network := gw.GetNetwork(fc.config.ChannelName)
 	contract := network.GetContract(chaincodeName)
 
-	result, err := contract.Evaluate(
-		fcn,
-		client.WithArguments(args...),
-	)
+	callCtx, done := fc.observeCall(ctx, call.withPhase(PhaseEvaluate))
+	result, err = contract.EvaluateWithContext(callCtx, fcn, proposalOptions(ctx, args)...)
+	done(err)
 	if err != nil {
+		slog.ErrorContext(ctx, "evaluation failed", "chaincode", chaincodeName, "function", fcn, "error", err)
 		return nil, fmt.Errorf("failed to evaluate transaction: %w", err)
 	}
 	return result, nil
 }
 
-// Close closes the client
-func (fc *FabricClient) Close() {
-	// Nothing to close as connections are created and closed per operation
+// CertificateFile is a certificate file used by the client
+type CertificateFile struct {
+	// Kind is "identity" for signing certificates and "peer_tls" for peer TLS CAs
+	Kind string
+	// Name is the identity name or the peer endpoint
+	Name string
+	Path string
+}
+
+// CertificateFiles lists the certificate files the client depends on
+func (fc *FabricClient) CertificateFiles() []CertificateFile {
+	files := []CertificateFile{{Kind: "identity", Name: "default", Path: fc.config.CertPath}}
+	for name, idConfig := range fc.config.Identities {
+		files = append(files, CertificateFile{Kind: "identity", Name: name, Path: idConfig.CertPath})
+	}
+	for _, peer := range fc.config.Peers {
+		files = append(files, CertificateFile{Kind: "peer_tls", Name: peer.Endpoint, Path: peer.TLSCertPath})
+	}
+	return files
 }
@@ -0,0 +1,128 @@
+package fabric
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/logging"
+)
+
+// ChaincodeEvent is an event emitted by a committed transaction
+type ChaincodeEvent struct {
+	BlockNumber   uint64 `json:"block_number"`
+	TxID          string `json:"tx_id"`
+	ChaincodeName string `json:"chaincode_name"`
+	EventName     string `json:"event_name"`
+	Payload       []byte `json:"payload"`
+}
+
+// EventOptions selects where an event stream starts
+type EventOptions struct {
+	// StartBlock is the first block events are delivered from; nil starts
+	// with the next block committed
+	StartBlock *uint64
+}
+
+// ChaincodeEvents streams the events emitted by the chaincode's committed
+// transactions from a random peer. The channel is closed when ctx is
+// cancelled or the stream fails; ctx.Err() tells the two apart.
+func (fc *FabricClient) ChaincodeEvents(ctx context.Context, chaincodeName string, opts EventOptions) (<-chan *ChaincodeEvent, error) {
+	gw, err := fc.eventsGateway(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var options []client.ChaincodeEventsOption
+	if opts.StartBlock != nil {
+		options = append(options, client.WithStartBlock(*opts.StartBlock))
+	}
+	events, err := gw.GetNetwork(fc.config.ChannelName).ChaincodeEvents(ctx, chaincodeName, options...)
+	if err != nil {
+		gw.Close()
+		return nil, fmt.Errorf("failed to listen for chaincode events: %w", err)
+	}
+
+	out := make(chan *ChaincodeEvent)
+	go func() {
+		defer close(out)
+		defer gw.Close()
+		for event := range events {
+			select {
+			case out <- &ChaincodeEvent{
+				BlockNumber:   event.BlockNumber,
+				TxID:          event.TransactionID,
+				ChaincodeName: event.ChaincodeName,
+				EventName:     event.EventName,
+				Payload:       event.Payload,
+			}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// BlockEvents streams the decoded blocks committed to the channel from a
+// random peer. The channel is closed when ctx is cancelled or the stream
+// fails; ctx.Err() tells the two apart.
+func (fc *FabricClient) BlockEvents(ctx context.Context, opts EventOptions) (<-chan *Block, error) {
+	gw, err := fc.eventsGateway(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var options []client.BlockEventsOption
+	if opts.StartBlock != nil {
+		options = append(options, client.WithStartBlock(*opts.StartBlock))
+	}
+	blocks, err := gw.GetNetwork(fc.config.ChannelName).BlockEvents(ctx, options...)
+	if err != nil {
+		gw.Close()
+		return nil, fmt.Errorf("failed to listen for block events: %w", err)
+	}
+
+	out := make(chan *Block)
+	go func() {
+		defer close(out)
+		defer gw.Close()
+		for block := range blocks {
+			decoded, err := DecodeBlock(block)
+			if err != nil {
+				// A block that cannot be decoded would be skipped silently
+				// otherwise, so end the stream and let the caller resume
+				slog.ErrorContext(ctx, "failed to decode block event", "error", err)
+				return
+			}
+			select {
+			case out <- decoded:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// eventsGateway connects to a random peer for an event stream. Streams are
+// not tracked as in-flight operations since they only end with their context.
+func (fc *FabricClient) eventsGateway(ctx context.Context) (*client.Gateway, error) {
+	call := CallInfo{Channel: fc.config.ChannelName}
+	peerConfig := fc.selectRandomPeer(ctx)
+	call.Peer = peerConfig.Endpoint
+	logging.Add(ctx, slog.String("peer", call.Peer))
+
+	_, connected := fc.observeCall(ctx, call.withPhase(PhaseConnect))
+	conn, err := fc.peerConnection(peerConfig)
+	if err != nil {
+		connected(err)
+		return nil, fmt.Errorf("failed to select peer: %w", err)
+	}
+	gw, err := fc.createGatewayConnection(ctx, conn)
+	connected(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gateway connection: %w", err)
+	}
+	return gw, nil
+}
@@ -0,0 +1,90 @@
+package fabric
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"google.golang.org/protobuf/proto"
+)
+
+// PeerStatus is the outcome of a connectivity check of a single peer
+type PeerStatus struct {
+	Endpoint string
+	// BlockHeight is the height of the channel's ledger on the peer
+	BlockHeight uint64
+	Latency     time.Duration
+	Err         error
+}
+
+// CheckIdentities loads the certificate and private key of the default and
+// of every named identity, returning the errors by identity name
+func (fc *FabricClient) CheckIdentities() map[string]error {
+	idConfigs := map[string]IdentityConfig{
+		"default": {MspID: fc.config.MspID, CertPath: fc.config.CertPath, KeyPath: fc.config.KeyPath},
+	}
+	for name, idConfig := range fc.config.Identities {
+		idConfigs[name] = idConfig
+	}
+
+	errs := make(map[string]error)
+	for name, idConfig := range idConfigs {
+		if _, _, err := loadIdentity(idConfig); err != nil {
+			errs[name] = err
+		}
+	}
+	return errs
+}
+
+// CheckPeers queries the channel height from every configured peer
+// concurrently through the gateway, which verifies that the peer is reachable
+// and that the default identity can access the channel on it
+func (fc *FabricClient) CheckPeers(ctx context.Context) []PeerStatus {
+	statuses := make([]PeerStatus, len(fc.config.Peers))
+	var wg sync.WaitGroup
+	for i, peerConfig := range fc.config.Peers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			height, err := fc.channelHeight(ctx, peerConfig)
+			statuses[i] = PeerStatus{
+				Endpoint:    peerConfig.Endpoint,
+				BlockHeight: height,
+				Latency:     time.Since(start),
+				Err:         err,
+			}
+		}()
+	}
+	wg.Wait()
+	return statuses
+}
+
+// channelHeight evaluates qscc GetChainInfo for the channel on the peer
+func (fc *FabricClient) channelHeight(ctx context.Context, peerConfig PeerConfig) (uint64, error) {
+	conn, err := fc.peerConnection(peerConfig)
+	if err != nil {
+		return 0, err
+	}
+
+	gw, err := fc.createGatewayConnection(context.Background(), conn)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create gateway connection: %w", err)
+	}
+	defer gw.Close()
+
+	contract := gw.GetNetwork(fc.config.ChannelName).GetContract("qscc")
+	result, err := contract.EvaluateWithContext(ctx, "GetChainInfo", client.WithArguments(fc.config.ChannelName))
+	if err != nil {
+		return 0, fmt.Errorf("failed to query channel %s: %w", fc.config.ChannelName, err)
+	}
+
+	var info common.BlockchainInfo
+	if err := proto.Unmarshal(result, &info); err != nil {
+		return 0, fmt.Errorf("failed to decode channel info: %w", err)
+	}
+	return info.GetHeight(), nil
+}
@@ -0,0 +1,235 @@
+package fabric
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+// qscc is the system chaincode that answers ledger queries
+const qscc = "qscc"
+
+// ChainInfo describes the current state of the channel's ledger
+type ChainInfo struct {
+	Height            uint64 `json:"height"`
+	CurrentBlockHash  string `json:"current_block_hash"`
+	PreviousBlockHash string `json:"previous_block_hash"`
+}
+
+// Block is a decoded block of the channel's ledger
+type Block struct {
+	Number       uint64        `json:"number"`
+	DataHash     string        `json:"data_hash"`
+	PreviousHash string        `json:"previous_hash"`
+	Transactions []Transaction `json:"transactions"`
+}
+
+// Transaction is a decoded transaction of a block
+type Transaction struct {
+	ID          string `json:"tx_id"`
+	BlockNumber uint64 `json:"block_number"`
+	// Index is the position of the transaction in its block
+	Index          int       `json:"index"`
+	Type           string    `json:"type"`
+	ChannelID      string    `json:"channel"`
+	Timestamp      time.Time `json:"timestamp"`
+	ValidationCode string    `json:"validation_code"`
+	CreatorMSP     string    `json:"creator_mspid"`
+	CreatorSubject string    `json:"creator_subject,omitempty"`
+	// Chaincode, Function and Args are only set for endorser transactions
+	Chaincode string   `json:"chaincode,omitempty"`
+	Function  string   `json:"function,omitempty"`
+	Args      []string `json:"args,omitempty"`
+	// Endorsers lists the MSP IDs of the endorsing peers
+	Endorsers []string `json:"endorsers,omitempty"`
+	// ReadWriteSets holds the keys read and written per namespace; the
+	// writes only took effect if the transaction is VALID
+	ReadWriteSets []ReadWriteSet `json:"read_write_sets,omitempty"`
+}
+
+// ValidationCodeName returns the name of a transaction validation code, e.g. VALID or MVCC_READ_CONFLICT
+func ValidationCodeName(code uint32) string {
+	return peer.TxValidationCode(code).String()
+}
+
+// ChainInfo queries the height and head hashes of the channel's ledger
+func (fc *FabricClient) ChainInfo(ctx context.Context) (*ChainInfo, error) {
+	result, err := fc.EvaluateTransaction(ctx, qscc, "GetChainInfo", []string{fc.config.ChannelName})
+	if err != nil {
+		return nil, err
+	}
+	var info common.BlockchainInfo
+	if err := proto.Unmarshal(result, &info); err != nil {
+		return nil, fmt.Errorf("failed to decode channel info: %w", err)
+	}
+	return &ChainInfo{
+		Height:            info.GetHeight(),
+		CurrentBlockHash:  hex.EncodeToString(info.GetCurrentBlockHash()),
+		PreviousBlockHash: hex.EncodeToString(info.GetPreviousBlockHash()),
+	}, nil
+}
+
+// Block queries the block with the given number
+func (fc *FabricClient) Block(ctx context.Context, number uint64) (*Block, error) {
+	return fc.queryBlock(ctx, "GetBlockByNumber", strconv.FormatUint(number, 10))
+}
+
+// BlockByTxID queries the block that contains the transaction
+func (fc *FabricClient) BlockByTxID(ctx context.Context, txID string) (*Block, error) {
+	return fc.queryBlock(ctx, "GetBlockByTxID", txID)
+}
+
+// Transaction queries a transaction by its ID
+func (fc *FabricClient) Transaction(ctx context.Context, txID string) (*Transaction, error) {
+	block, err := fc.BlockByTxID(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range block.Transactions {
+		if block.Transactions[i].ID == txID {
+			return &block.Transactions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("transaction %s not found in block %d", txID, block.Number)
+}
+
+func (fc *FabricClient) queryBlock(ctx context.Context, fcn, arg string) (*Block, error) {
+	result, err := fc.EvaluateTransaction(ctx, qscc, fcn, []string{fc.config.ChannelName, arg})
+	if err != nil {
+		return nil, err
+	}
+	var block common.Block
+	if err := proto.Unmarshal(result, &block); err != nil {
+		return nil, fmt.Errorf("failed to decode block: %w", err)
+	}
+	return DecodeBlock(&block)
+}
+
+// DecodeBlock decodes the header and the transactions of a block
+func DecodeBlock(block *common.Block) (*Block, error) {
+	decoded := &Block{
+		Number:       block.GetHeader().GetNumber(),
+		DataHash:     hex.EncodeToString(block.GetHeader().GetDataHash()),
+		PreviousHash: hex.EncodeToString(block.GetHeader().GetPreviousHash()),
+		Transactions: make([]Transaction, 0, len(block.GetData().GetData())),
+	}
+
+	var filter []byte
+	if metadata := block.GetMetadata().GetMetadata(); len(metadata) > int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		filter = metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER]
+	}
+
+	for i, data := range block.GetData().GetData() {
+		tx, err := decodeTransaction(data)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: transaction %d: %w", decoded.Number, i, err)
+		}
+		tx.BlockNumber = decoded.Number
+		tx.Index = i
+		if i < len(filter) {
+			tx.ValidationCode = peer.TxValidationCode(filter[i]).String()
+		}
+		decoded.Transactions = append(decoded.Transactions, *tx)
+	}
+	return decoded, nil
+}
+
+// decodeTransaction decodes an envelope of a block
+func decodeTransaction(data []byte) (*Transaction, error) {
+	var envelope common.Envelope
+	if err := proto.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to decode envelope: %w", err)
+	}
+	var payload common.Payload
+	if err := proto.Unmarshal(envelope.GetPayload(), &payload); err != nil {
+		return nil, fmt.Errorf("failed to decode payload: %w", err)
+	}
+	var channelHeader common.ChannelHeader
+	if err := proto.Unmarshal(payload.GetHeader().GetChannelHeader(), &channelHeader); err != nil {
+		return nil, fmt.Errorf("failed to decode channel header: %w", err)
+	}
+	var signatureHeader common.SignatureHeader
+	if err := proto.Unmarshal(payload.GetHeader().GetSignatureHeader(), &signatureHeader); err != nil {
+		return nil, fmt.Errorf("failed to decode signature header: %w", err)
+	}
+
+	tx := &Transaction{
+		ID:        channelHeader.GetTxId(),
+		Type:      common.HeaderType(channelHeader.GetType()).String(),
+		ChannelID: channelHeader.GetChannelId(),
+	}
+	if ts := channelHeader.GetTimestamp(); ts != nil {
+		tx.Timestamp = ts.AsTime()
+	}
+	tx.CreatorMSP, tx.CreatorSubject = decodeCreator(signatureHeader.GetCreator())
+
+	if common.HeaderType(channelHeader.GetType()) != common.HeaderType_ENDORSER_TRANSACTION {
+		return tx, nil
+	}
+	if err := decodeEndorserTransaction(payload.GetData(), tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// decodeEndorserTransaction fills in the chaincode call, the endorsers and the
+// read/write sets of the first action of an endorser transaction
+func decodeEndorserTransaction(data []byte, tx *Transaction) error {
+	var transaction peer.Transaction
+	if err := proto.Unmarshal(data, &transaction); err != nil {
+		return fmt.Errorf("failed to decode transaction: %w", err)
+	}
+	if len(transaction.GetActions()) == 0 {
+		return nil
+	}
+	var actionPayload peer.ChaincodeActionPayload
+	if err := proto.Unmarshal(transaction.GetActions()[0].GetPayload(), &actionPayload); err != nil {
+		return fmt.Errorf("failed to decode action payload: %w", err)
+	}
+	var proposalPayload peer.ChaincodeProposalPayload
+	if err := proto.Unmarshal(actionPayload.GetChaincodeProposalPayload(), &proposalPayload); err != nil {
+		return fmt.Errorf("failed to decode proposal payload: %w", err)
+	}
+	var invocation peer.ChaincodeInvocationSpec
+	if err := proto.Unmarshal(proposalPayload.GetInput(), &invocation); err != nil {
+		return fmt.Errorf("failed to decode chaincode invocation: %w", err)
+	}
+
+	tx.Chaincode = invocation.GetChaincodeSpec().GetChaincodeId().GetName()
+	if args := invocation.GetChaincodeSpec().GetInput().GetArgs(); len(args) > 0 {
+		tx.Function = string(args[0])
+		for _, arg := range args[1:] {
+			tx.Args = append(tx.Args, string(arg))
+		}
+	}
+	for _, endorsement := range actionPayload.GetAction().GetEndorsements() {
+		mspID, _ := decodeCreator(endorsement.GetEndorser())
+		tx.Endorsers = append(tx.Endorsers, mspID)
+	}
+	action, err := decodeChaincodeAction(actionPayload.GetAction().GetProposalResponsePayload())
+	if err != nil {
+		return err
+	}
+	tx.ReadWriteSets, err = decodeReadWriteSets(action.GetResults())
+	return err
+}
+
+// decodeCreator returns the MSP ID and the certificate subject of a
+// serialized identity
+func decodeCreator(creator []byte) (mspID, subject string) {
+	var id msp.SerializedIdentity
+	if err := proto.Unmarshal(creator, &id); err != nil {
+		return "", ""
+	}
+	if cert, err := ParseX509Certificate(id.GetIdBytes()); err == nil {
+		subject = cert.Subject.String()
+	}
+	return id.GetMspid(), subject
+}
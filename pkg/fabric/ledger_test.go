@@ -0,0 +1,130 @@
+package fabric
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func marshal(t *testing.T, m proto.Message) []byte {
+	t.Helper()
+	data, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func serializedIdentity(t *testing.T, mspID string) []byte {
+	return marshal(t, &msp.SerializedIdentity{Mspid: mspID})
+}
+
+// envelope returns a serialized envelope of the given type and payload data
+func envelope(t *testing.T, txID string, headerType common.HeaderType, timestamp time.Time, data []byte) []byte {
+	t.Helper()
+	return marshal(t, &common.Envelope{Payload: marshal(t, &common.Payload{
+		Header: &common.Header{
+			ChannelHeader: marshal(t, &common.ChannelHeader{
+				Type:      int32(headerType),
+				ChannelId: "mychannel",
+				TxId:      txID,
+				Timestamp: timestamppb.New(timestamp),
+			}),
+			SignatureHeader: marshal(t, &common.SignatureHeader{Creator: serializedIdentity(t, "Org1MSP")}),
+		},
+		Data: data,
+	})})
+}
+
+// endorserTransaction returns a serialized endorser transaction calling the
+// chaincode with args, whose simulation produced results
+func endorserTransaction(t *testing.T, txID, chaincode string, args []string, results []byte) []byte {
+	t.Helper()
+	input := &peer.ChaincodeInput{}
+	for _, arg := range args {
+		input.Args = append(input.Args, []byte(arg))
+	}
+	invocation := &peer.ChaincodeInvocationSpec{ChaincodeSpec: &peer.ChaincodeSpec{
+		ChaincodeId: &peer.ChaincodeID{Name: chaincode},
+		Input:       input,
+	}}
+	actionPayload := &peer.ChaincodeActionPayload{
+		ChaincodeProposalPayload: marshal(t, &peer.ChaincodeProposalPayload{Input: marshal(t, invocation)}),
+		Action: &peer.ChaincodeEndorsedAction{
+			ProposalResponsePayload: marshal(t, &peer.ProposalResponsePayload{
+				Extension: marshal(t, &peer.ChaincodeAction{Results: results}),
+			}),
+			Endorsements: []*peer.Endorsement{
+				{Endorser: serializedIdentity(t, "Org1MSP")},
+				{Endorser: serializedIdentity(t, "Org2MSP")},
+			},
+		},
+	}
+	transaction := &peer.Transaction{Actions: []*peer.TransactionAction{{Payload: marshal(t, actionPayload)}}}
+	return envelope(t, txID, common.HeaderType_ENDORSER_TRANSACTION, time.Unix(1700000000, 0), marshal(t, transaction))
+}
+
+// block returns a block holding the transactions with their validation codes
+func block(number uint64, txs [][]byte, codes ...peer.TxValidationCode) *common.Block {
+	filter := make([]byte, len(codes))
+	for i, code := range codes {
+		filter[i] = byte(code)
+	}
+	metadata := make([][]byte, common.BlockMetadataIndex_TRANSACTIONS_FILTER+1)
+	metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = filter
+	return &common.Block{
+		Header:   &common.BlockHeader{Number: number, DataHash: []byte{0xab}, PreviousHash: []byte{0xcd}},
+		Data:     &common.BlockData{Data: txs},
+		Metadata: &common.BlockMetadata{Metadata: metadata},
+	}
+}
+
+func TestDecodeBlock(t *testing.T) {
+	txs := [][]byte{
+		endorserTransaction(t, "tx1", "basic", []string{"CreateAsset", "asset1", "blue"}, nil),
+		envelope(t, "", common.HeaderType_CONFIG, time.Unix(1700000000, 0), nil),
+	}
+	decoded, err := DecodeBlock(block(7, txs, peer.TxValidationCode_MVCC_READ_CONFLICT, peer.TxValidationCode_VALID))
+	if err != nil {
+		t.Fatalf("DecodeBlock() error = %v", err)
+	}
+	if decoded.Number != 7 || decoded.DataHash != "ab" || decoded.PreviousHash != "cd" || len(decoded.Transactions) != 2 {
+		t.Fatalf("DecodeBlock() = %+v", decoded)
+	}
+
+	tx := decoded.Transactions[0]
+	if tx.ID != "tx1" || tx.BlockNumber != 7 || tx.Index != 0 || tx.Type != "ENDORSER_TRANSACTION" || tx.ChannelID != "mychannel" {
+		t.Errorf("transaction = %+v", tx)
+	}
+	if tx.ValidationCode != "MVCC_READ_CONFLICT" || tx.CreatorMSP != "Org1MSP" || !tx.Timestamp.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("transaction = %+v", tx)
+	}
+	if tx.Chaincode != "basic" || tx.Function != "CreateAsset" || len(tx.Args) != 2 || tx.Args[1] != "blue" {
+		t.Errorf("chaincode call = %s %s %v", tx.Chaincode, tx.Function, tx.Args)
+	}
+	if len(tx.Endorsers) != 2 || tx.Endorsers[1] != "Org2MSP" {
+		t.Errorf("Endorsers = %v", tx.Endorsers)
+	}
+
+	config := decoded.Transactions[1]
+	if config.Type != "CONFIG" || config.Index != 1 || config.ValidationCode != "VALID" || config.Chaincode != "" {
+		t.Errorf("config transaction = %+v", config)
+	}
+}
+
+func TestDecodeBlockRejectsInvalidTransactions(t *testing.T) {
+	if _, err := DecodeBlock(block(3, [][]byte{{0xff}})); err == nil {
+		t.Error("DecodeBlock() accepted an invalid envelope")
+	}
+}
+
+func TestValidationCodeName(t *testing.T) {
+	if got := ValidationCodeName(uint32(peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE)); got != "ENDORSEMENT_POLICY_FAILURE" {
+		t.Errorf("ValidationCodeName() = %q", got)
+	}
+}
@@ -0,0 +1,85 @@
+package fabric
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+)
+
+// ErrClosed is returned by operations started after the client began
+// draining or was closed
+var ErrClosed = errors.New("fabric client is shutting down")
+
+// peerConnection returns the pooled gRPC connection to the peer, dialing it
+// on first use. gRPC reconnects pooled connections when they break.
+func (fc *FabricClient) peerConnection(peerConfig PeerConfig) (*grpc.ClientConn, error) {
+	fc.connsMu.Lock()
+	defer fc.connsMu.Unlock()
+	if fc.closed {
+		return nil, ErrClosed
+	}
+	if conn, ok := fc.conns[peerConfig.Endpoint]; ok {
+		return conn, nil
+	}
+
+	conn, err := fc.dialPeer(peerConfig)
+	if err != nil {
+		return nil, err
+	}
+	fc.conns[peerConfig.Endpoint] = conn
+	return conn, nil
+}
+
+// beginOperation registers an in-flight operation; the returned function
+// must be called once it finished
+func (fc *FabricClient) beginOperation() (func(), error) {
+	fc.opsMu.RLock()
+	defer fc.opsMu.RUnlock()
+	if fc.draining {
+		return nil, ErrClosed
+	}
+	fc.ops.Add(1)
+	fc.inFlight.Add(1)
+	return func() {
+		fc.inFlight.Add(-1)
+		fc.ops.Done()
+	}, nil
+}
+
+// InFlight returns the number of operations currently running
+func (fc *FabricClient) InFlight() int64 {
+	return fc.inFlight.Load()
+}
+
+// Drain rejects new operations with ErrClosed and waits until the running
+// ones finished, such as invokes waiting for their commit status, or until
+// ctx is done
+func (fc *FabricClient) Drain(ctx context.Context) error {
+	fc.opsMu.Lock()
+	fc.draining = true
+	fc.opsMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		fc.ops.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close closes the pooled peer connections. Operations still running fail.
+func (fc *FabricClient) Close() {
+	fc.connsMu.Lock()
+	defer fc.connsMu.Unlock()
+	fc.closed = true
+	for endpoint, conn := range fc.conns {
+		conn.Close()
+		delete(fc.conns, endpoint)
+	}
+}
@@ -0,0 +1,85 @@
+package fabric
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a client whose peer cannot be dialed, so that
+// operations fail fast without a network
+func newTestClient(t *testing.T) *FabricClient {
+	t.Helper()
+	fc, err := NewFabricClient(&ClientConfig{
+		ChannelName: "mychannel",
+		Peers:       []PeerConfig{{Endpoint: "127.0.0.1:1", TLSCertPath: t.TempDir() + "/missing-ca.pem"}},
+	})
+	if err != nil {
+		t.Fatalf("NewFabricClient() error = %v", err)
+	}
+	return fc
+}
+
+func TestDrainWaitsForOperations(t *testing.T) {
+	fc := newTestClient(t)
+	release, err := fc.beginOperation()
+	if err != nil {
+		t.Fatalf("beginOperation() error = %v", err)
+	}
+	if fc.InFlight() != 1 {
+		t.Errorf("InFlight() = %d, want 1", fc.InFlight())
+	}
+
+	drained := make(chan error, 1)
+	go func() { drained <- fc.Drain(context.Background()) }()
+
+	// New operations are rejected as soon as draining started
+	deadline := time.Now().Add(time.Second)
+	for {
+		_, err := fc.InvokeTransaction(context.Background(), "basic", "CreateAsset", nil)
+		if errors.Is(err, ErrClosed) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("InvokeTransaction() error = %v, want ErrClosed while draining", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case err := <-drained:
+		t.Fatalf("Drain() = %v before the running operation finished", err)
+	default:
+	}
+
+	release()
+	if err := <-drained; err != nil {
+		t.Errorf("Drain() = %v, want nil", err)
+	}
+	if fc.InFlight() != 0 {
+		t.Errorf("InFlight() = %d, want 0", fc.InFlight())
+	}
+}
+
+func TestDrainTimeout(t *testing.T) {
+	fc := newTestClient(t)
+	release, err := fc.beginOperation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := fc.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Drain() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestClosedClientRejectsConnections(t *testing.T) {
+	fc := newTestClient(t)
+	fc.Close()
+	if _, err := fc.peerConnection(fc.config.Peers[0]); !errors.Is(err, ErrClosed) {
+		t.Errorf("peerConnection() error = %v, want %v", err, ErrClosed)
+	}
+}
@@ -0,0 +1,131 @@
+package fabric
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TransactionEvent describes the outcome of an invoke, successful or not
+type TransactionEvent struct {
+	Channel       string
+	ChaincodeName string
+	Function      string
+	Args          []string
+	// Identity is the name of the signing identity, empty for the default one
+	Identity string
+	MspID    string
+	// TxID is set once the proposal has been created
+	TxID string
+	// Submitted reports whether the transaction was sent for ordering
+	Submitted bool
+	// BlockNumber and ValidationCode are set once the commit status is known
+	BlockNumber    uint64
+	ValidationCode string
+	StartedAt      time.Time
+	CompletedAt    time.Time
+	Err            error
+}
+
+// CommitStatusError is returned by InvokeTransaction when the transaction was
+// sent for ordering but its commit status could not be obtained. The
+// transaction may still be committed, so it must not be submitted again.
+type CommitStatusError struct {
+	TxID string
+	Err  error
+}
+
+func (e *CommitStatusError) Error() string {
+	return fmt.Sprintf("failed to get commit status of transaction %s: %v", e.TxID, e.Err)
+}
+
+func (e *CommitStatusError) Unwrap() error {
+	return e.Err
+}
+
+// TransactionListener is called synchronously after every invoke
+type TransactionListener func(ctx context.Context, event *TransactionEvent)
+
+// AddTransactionListener registers a listener notified after every invoke
+func (fc *FabricClient) AddTransactionListener(listener TransactionListener) {
+	fc.listenersMu.Lock()
+	defer fc.listenersMu.Unlock()
+	fc.transactionListeners = append(fc.transactionListeners, listener)
+}
+
+func (fc *FabricClient) notifyTransactionListeners(ctx context.Context, event *TransactionEvent) {
+	fc.listenersMu.RLock()
+	defer fc.listenersMu.RUnlock()
+	for _, listener := range fc.transactionListeners {
+		listener(ctx, event)
+	}
+}
+
+// Phase identifies a step of a gateway operation
+type Phase string
+
+const (
+	// PhaseConnect covers connecting to the selected peer
+	PhaseConnect Phase = "connect"
+	// PhaseEvaluate covers evaluating a transaction
+	PhaseEvaluate Phase = "evaluate"
+	// PhaseEndorse covers collecting endorsements for a transaction
+	PhaseEndorse Phase = "endorse"
+	// PhaseSubmit covers sending the endorsed transaction to ordering
+	PhaseSubmit Phase = "submit"
+	// PhaseCommitStatus covers waiting for the transaction to be committed
+	PhaseCommitStatus Phase = "commit_status"
+)
+
+// CallInfo describes a call made to a peer
+type CallInfo struct {
+	Phase         Phase
+	Peer          string
+	Channel       string
+	ChaincodeName string
+	Function      string
+	// TxID is set once the proposal has been created
+	TxID string
+}
+
+func (c CallInfo) withPhase(phase Phase) CallInfo {
+	c.Phase = phase
+	return c
+}
+
+// Observer is notified of every call the client makes to a peer
+type Observer interface {
+	// StartCall is called before the call; the returned function is called
+	// with the outcome once the call completed
+	StartCall(ctx context.Context, call CallInfo) func(err error)
+}
+
+// AddObserver registers an observer notified of every call to a peer
+func (fc *FabricClient) AddObserver(observer Observer) {
+	fc.listenersMu.Lock()
+	defer fc.listenersMu.Unlock()
+	fc.observers = append(fc.observers, observer)
+}
+
+// observeCall starts a span for the call and notifies the observers. The
+// returned context carries the span, so that the gRPC calls made with it are
+// traced as its children; the returned function ends the span and must be
+// called with the outcome.
+func (fc *FabricClient) observeCall(ctx context.Context, call CallInfo) (context.Context, func(error)) {
+	ctx, span := startCallSpan(ctx, call)
+
+	fc.listenersMu.RLock()
+	observers := fc.observers
+	fc.listenersMu.RUnlock()
+
+	finish := make([]func(error), 0, len(observers))
+	for _, observer := range observers {
+		finish = append(finish, observer.StartCall(ctx, call))
+	}
+	return ctx, func(err error) {
+		for _, f := range finish {
+			f(err)
+		}
+		endSpan(span, err)
+	}
+}
@@ -0,0 +1,393 @@
+package fabric
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/logging"
+)
+
+// OfflineIdentity is the identity name recorded for transactions signed by
+// the client instead of the server
+const OfflineIdentity = "offline"
+
+var (
+	// ErrInvalidCertificate is returned when the client certificate of an
+	// offline transaction cannot be used
+	ErrInvalidCertificate = errors.New("invalid client certificate")
+	// ErrInvalidSignature is returned when a signature does not verify
+	// against the digest and the client certificate
+	ErrInvalidSignature = errors.New("signature does not match the digest and the client certificate")
+	// ErrUnexpectedStep is returned when a signature is given for a step the
+	// offline transaction is not waiting for
+	ErrUnexpectedStep = errors.New("the transaction is not waiting for this signature")
+	// errAbandoned is recorded for offline transactions closed before their
+	// commit status was known
+	errAbandoned = errors.New("the offline signing flow was not completed")
+)
+
+// OfflineStep is the signature an offline transaction waits for
+type OfflineStep string
+
+const (
+	// StepEndorse waits for the signature of the proposal digest
+	StepEndorse OfflineStep = "endorse"
+	// StepSubmit waits for the signature of the transaction digest
+	StepSubmit OfflineStep = "submit"
+	// StepCommit waits for the signature of the commit status request digest
+	StepCommit OfflineStep = "commit"
+	// StepDone is reached once the commit status is known
+	StepDone OfflineStep = "done"
+)
+
+// OfflineTransaction is a transaction signed by a client that keeps its
+// private key. Every step takes the signature of the digest returned by the
+// step before: the proposal digest is signed to endorse, the transaction
+// digest to submit and the commit digest to get the commit status.
+// Signatures are ASN.1 DER encoded ECDSA signatures of the digest.
+type OfflineTransaction struct {
+	fc        *FabricClient
+	gw        *client.Gateway
+	publicKey *ecdsa.PublicKey
+	// ctx carries the values of the preparing request, for the listeners
+	ctx   context.Context
+	call  CallInfo
+	event *TransactionEvent
+
+	mu          sync.Mutex
+	step        OfflineStep
+	proposal    *client.Proposal
+	transaction *client.Transaction
+	commit      *client.Commit
+	lastErr     error
+	closed      bool
+}
+
+// PrepareOffline creates the proposal of a transaction signed by the holder
+// of the PEM certificate. Transient data selected in ctx is included.
+func (fc *FabricClient) PrepareOffline(ctx context.Context, mspID string, certPEM []byte, chaincodeName, fcn string, args []string) (*OfflineTransaction, error) {
+	release, err := fc.beginOperation()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	cert, err := ParseX509Certificate(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCertificate, err)
+	}
+	publicKey, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: only ECDSA keys are supported", ErrInvalidCertificate)
+	}
+	id, err := identity.NewX509Identity(mspID, cert)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCertificate, err)
+	}
+
+	slog.DebugContext(ctx, "preparing offline transaction",
+		"chaincode", chaincodeName,
+		"function", fcn,
+		"args", logging.Sensitive(args),
+	)
+
+	call := CallInfo{
+		Channel:       fc.config.ChannelName,
+		ChaincodeName: chaincodeName,
+		Function:      fcn,
+	}
+	peerConfig := fc.selectRandomPeer(ctx)
+	call.Peer = peerConfig.Endpoint
+	logging.Add(ctx, slog.String("peer", call.Peer))
+	_, connected := fc.observeCall(ctx, call.withPhase(PhaseConnect))
+	conn, err := fc.peerConnection(peerConfig)
+	if err != nil {
+		connected(err)
+		return nil, fmt.Errorf("failed to select peer: %w", err)
+	}
+	// Without a signing implementation the gateway only takes signed requests
+	gw, err := client.Connect(
+		id,
+		client.WithClientConnection(conn),
+		client.WithEndorseTimeout(30*time.Second),
+		client.WithSubmitTimeout(30*time.Second),
+		client.WithCommitStatusTimeout(30*time.Second),
+	)
+	connected(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gateway connection: %w", err)
+	}
+
+	proposal, err := gw.GetNetwork(fc.config.ChannelName).GetContract(chaincodeName).NewProposal(fcn, proposalOptions(ctx, args)...)
+	if err != nil {
+		gw.Close()
+		return nil, fmt.Errorf("failed to create proposal: %w", err)
+	}
+	call.TxID = proposal.TransactionID()
+	logging.Add(ctx, slog.String("tx_id", call.TxID))
+
+	return &OfflineTransaction{
+		fc:        fc,
+		gw:        gw,
+		publicKey: publicKey,
+		ctx:       context.WithoutCancel(ctx),
+		call:      call,
+		event: &TransactionEvent{
+			Channel:       fc.config.ChannelName,
+			ChaincodeName: chaincodeName,
+			Function:      fcn,
+			Args:          args,
+			Identity:      OfflineIdentity,
+			MspID:         mspID,
+			TxID:          call.TxID,
+			StartedAt:     time.Now(),
+		},
+		step:     StepEndorse,
+		proposal: proposal,
+	}, nil
+}
+
+// TxID returns the transaction ID
+func (t *OfflineTransaction) TxID() string {
+	return t.call.TxID
+}
+
+// ChaincodeName returns the name of the chaincode the transaction invokes
+func (t *OfflineTransaction) ChaincodeName() string {
+	return t.call.ChaincodeName
+}
+
+// Function returns the function the transaction invokes
+func (t *OfflineTransaction) Function() string {
+	return t.call.Function
+}
+
+// Step returns the signature the transaction waits for
+func (t *OfflineTransaction) Step() OfflineStep {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.step
+}
+
+// Digest returns the digest to sign for the next step, nil once done
+func (t *OfflineTransaction) Digest() []byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch t.step {
+	case StepEndorse:
+		return t.proposal.Digest()
+	case StepSubmit:
+		return t.transaction.Digest()
+	case StepCommit:
+		return t.commit.Digest()
+	}
+	return nil
+}
+
+// Result returns the chaincode response, known once endorsed
+func (t *OfflineTransaction) Result() []byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.transaction == nil {
+		return nil
+	}
+	return t.transaction.Result()
+}
+
+// Endorse endorses the proposal signed with signature and returns the
+// digest of the transaction to sign for the submission
+func (t *OfflineTransaction) Endorse(ctx context.Context, signature []byte) ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	release, err := t.begin(ctx, StepEndorse)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	signature, err = t.verify(t.proposal.Digest(), signature)
+	if err != nil {
+		return nil, err
+	}
+	proposalBytes, err := t.proposal.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize proposal: %w", err)
+	}
+	proposal, err := t.gw.NewSignedProposal(proposalBytes, signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign proposal: %w", err)
+	}
+
+	callCtx, done := t.fc.observeCall(ctx, t.call.withPhase(PhaseEndorse))
+	transaction, err := proposal.EndorseWithContext(callCtx)
+	done(err)
+	if err != nil {
+		t.lastErr = fmt.Errorf("failed to endorse transaction: %w", err)
+		return nil, t.lastErr
+	}
+	t.transaction = transaction
+	t.step = StepSubmit
+	return transaction.Digest(), nil
+}
+
+// Submit sends the transaction signed with signature for ordering and
+// returns the digest of the commit status request to sign
+func (t *OfflineTransaction) Submit(ctx context.Context, signature []byte) ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	release, err := t.begin(ctx, StepSubmit)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	signature, err = t.verify(t.transaction.Digest(), signature)
+	if err != nil {
+		return nil, err
+	}
+	transactionBytes, err := t.transaction.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize transaction: %w", err)
+	}
+	transaction, err := t.gw.NewSignedTransaction(transactionBytes, signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	callCtx, done := t.fc.observeCall(ctx, t.call.withPhase(PhaseSubmit))
+	commit, err := transaction.SubmitWithContext(callCtx)
+	done(err)
+	if err != nil {
+		t.lastErr = fmt.Errorf("failed to submit transaction: %w", err)
+		return nil, t.lastErr
+	}
+	t.event.Submitted = true
+	t.commit = commit
+	t.step = StepCommit
+	return commit.Digest(), nil
+}
+
+// CommitStatus waits for the commit of the transaction with the status
+// request signed with signature
+func (t *OfflineTransaction) CommitStatus(ctx context.Context, signature []byte) (*TransactionResult, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	release, err := t.begin(ctx, StepCommit)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	signature, err = t.verify(t.commit.Digest(), signature)
+	if err != nil {
+		return nil, err
+	}
+	commitBytes, err := t.commit.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize commit status request: %w", err)
+	}
+	commit, err := t.gw.NewSignedCommit(commitBytes, signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign commit status request: %w", err)
+	}
+
+	callCtx, done := t.fc.observeCall(ctx, t.call.withPhase(PhaseCommitStatus))
+	status, err := commit.StatusWithContext(callCtx)
+	done(err)
+	if err != nil {
+		t.lastErr = fmt.Errorf("failed to get commit status: %w", err)
+		return nil, t.lastErr
+	}
+	t.event.BlockNumber = status.BlockNumber
+	t.event.ValidationCode = status.Code.String()
+	t.step = StepDone
+	t.finish(nil)
+
+	slog.InfoContext(ctx, "offline transaction committed",
+		"chaincode", t.call.ChaincodeName,
+		"function", t.call.Function,
+		"tx_id", t.call.TxID,
+		"block_number", status.BlockNumber,
+		"validation_code", t.event.ValidationCode,
+	)
+	return &TransactionResult{
+		Result:      t.transaction.Result(),
+		TxID:        t.call.TxID,
+		BlockNumber: status.BlockNumber,
+		ResultCode:  uint32(status.Code.Number()),
+		Success:     status.Successful,
+	}, nil
+}
+
+// Close releases the transaction. A transaction closed before its commit
+// status was known is reported to the listeners as failed.
+func (t *OfflineTransaction) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	t.closed = true
+	t.gw.Close()
+	if t.step != StepDone {
+		err := errAbandoned
+		if t.lastErr != nil {
+			err = fmt.Errorf("%w: %w", errAbandoned, t.lastErr)
+		}
+		t.finish(err)
+	}
+}
+
+// begin checks that the transaction waits for step and registers the step
+// as an in-flight operation of the client
+func (t *OfflineTransaction) begin(ctx context.Context, step OfflineStep) (func(), error) {
+	if t.closed || t.step != step {
+		return nil, ErrUnexpectedStep
+	}
+	logging.Add(ctx, slog.String("peer", t.call.Peer), slog.String("tx_id", t.call.TxID))
+	return t.fc.beginOperation()
+}
+
+func (t *OfflineTransaction) finish(err error) {
+	t.event.CompletedAt = time.Now()
+	t.event.Err = err
+	t.fc.notifyTransactionListeners(t.ctx, t.event)
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// verify checks an ASN.1 DER encoded ECDSA signature of digest against the
+// client certificate. It returns the signature in the low-S form Fabric
+// requires, since signers such as HSMs do not always produce it.
+func (t *OfflineTransaction) verify(digest, signature []byte) ([]byte, error) {
+	if !ecdsa.VerifyASN1(t.publicKey, digest, signature) {
+		return nil, ErrInvalidSignature
+	}
+	var sig ecdsaSignature
+	if _, err := asn1.Unmarshal(signature, &sig); err != nil {
+		return nil, ErrInvalidSignature
+	}
+	return lowS(t.publicKey.Curve, sig)
+}
+
+func lowS(curve elliptic.Curve, sig ecdsaSignature) ([]byte, error) {
+	order := curve.Params().N
+	halfOrder := new(big.Int).Rsh(order, 1)
+	if sig.S.Cmp(halfOrder) > 0 {
+		sig.S = new(big.Int).Sub(order, sig.S)
+	}
+	return asn1.Marshal(sig)
+}
@@ -0,0 +1,191 @@
+package fabric
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// certificate returns a self-signed PEM certificate of the public key of key
+func certificate(t *testing.T, key crypto.Signer) []byte {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "user1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// newOfflineTransaction prepares a transaction for a new ECDSA key. The peer
+// is never reached: connections are only dialled by the signing steps.
+func newOfflineTransaction(t *testing.T) (*FabricClient, *OfflineTransaction, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caPath, certificate(t, key), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fc, err := NewFabricClient(&ClientConfig{
+		ChannelName: "mychannel",
+		Peers:       []PeerConfig{{Endpoint: "127.0.0.1:1", TLSCertPath: caPath}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { fc.Close() })
+
+	tx, err := fc.PrepareOffline(context.Background(), "Org1MSP", certificate(t, key), "basic", "CreateAsset", []string{"asset1"})
+	if err != nil {
+		t.Fatalf("PrepareOffline() error = %v", err)
+	}
+	t.Cleanup(tx.Close)
+	return fc, tx, key
+}
+
+// sign returns the ASN.1 DER signature of digest made with key
+func sign(t *testing.T, key *ecdsa.PrivateKey, digest []byte) []byte {
+	t.Helper()
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signature
+}
+
+func TestPrepareOffline(t *testing.T) {
+	_, tx, _ := newOfflineTransaction(t)
+	if tx.Step() != StepEndorse || tx.TxID() == "" || len(tx.Digest()) == 0 || tx.Result() != nil {
+		t.Errorf("prepared transaction = step %s, tx %q, digest %x", tx.Step(), tx.TxID(), tx.Digest())
+	}
+	if tx.ChaincodeName() != "basic" || tx.Function() != "CreateAsset" {
+		t.Errorf("prepared transaction = %s %s, want basic CreateAsset", tx.ChaincodeName(), tx.Function())
+	}
+}
+
+func TestPrepareOfflineRejectsCertificates(t *testing.T) {
+	fc := newTestClient(t)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		cert []byte
+	}{
+		{"not PEM", []byte("certificate")},
+		{"RSA key", certificate(t, rsaKey)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fc.PrepareOffline(context.Background(), "Org1MSP", tt.cert, "basic", "CreateAsset", nil)
+			if !errors.Is(err, ErrInvalidCertificate) {
+				t.Errorf("PrepareOffline() error = %v, want %v", err, ErrInvalidCertificate)
+			}
+		})
+	}
+}
+
+func TestOfflineSignatures(t *testing.T) {
+	_, tx, key := newOfflineTransaction(t)
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tx.Submit(context.Background(), sign(t, key, tx.Digest())); !errors.Is(err, ErrUnexpectedStep) {
+		t.Errorf("Submit() before Endorse error = %v, want %v", err, ErrUnexpectedStep)
+	}
+	if _, err := tx.Endorse(context.Background(), sign(t, other, tx.Digest())); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Endorse() signed by another key error = %v, want %v", err, ErrInvalidSignature)
+	}
+	if _, err := tx.Endorse(context.Background(), []byte("signature")); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Endorse() of a malformed signature error = %v, want %v", err, ErrInvalidSignature)
+	}
+
+	// A valid signature reaches the unavailable peer
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := tx.Endorse(ctx, sign(t, key, tx.Digest())); err == nil || errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Endorse() error = %v, want the endorsement to fail", err)
+	}
+	if tx.Step() != StepEndorse {
+		t.Errorf("Step() = %s after a failed endorsement, want %s", tx.Step(), StepEndorse)
+	}
+}
+
+func TestOfflineClose(t *testing.T) {
+	fc, tx, key := newOfflineTransaction(t)
+	events := make(chan *TransactionEvent, 2)
+	fc.AddTransactionListener(func(_ context.Context, event *TransactionEvent) {
+		events <- event
+	})
+
+	tx.Close()
+	tx.Close()
+	if len(events) != 1 {
+		t.Fatalf("listeners notified %d times, want once", len(events))
+	}
+	if event := <-events; !errors.Is(event.Err, errAbandoned) || event.Identity != OfflineIdentity || event.TxID != tx.TxID() {
+		t.Errorf("event = %+v, want the abandoned offline transaction", event)
+	}
+	if _, err := tx.Endorse(context.Background(), sign(t, key, tx.Digest())); !errors.Is(err, ErrUnexpectedStep) {
+		t.Errorf("Endorse() after Close error = %v, want %v", err, ErrUnexpectedStep)
+	}
+}
+
+func TestLowS(t *testing.T) {
+	curve := elliptic.P256()
+	order := curve.Params().N
+	digest := sha256.Sum256([]byte("proposal"))
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sig ecdsaSignature
+	if _, err := asn1.Unmarshal(sign(t, key, digest[:]), &sig); err != nil {
+		t.Fatal(err)
+	}
+	halfOrder := new(big.Int).Rsh(order, 1)
+	low := new(big.Int).Set(sig.S)
+	if low.Cmp(halfOrder) > 0 {
+		low.Sub(order, low)
+	}
+	high := new(big.Int).Sub(order, low)
+
+	for _, s := range []*big.Int{low, high} {
+		der, err := lowS(curve, ecdsaSignature{R: sig.R, S: s})
+		if err != nil {
+			t.Fatalf("lowS() error = %v", err)
+		}
+		var got ecdsaSignature
+		if _, err := asn1.Unmarshal(der, &got); err != nil || got.S.Cmp(low) != 0 {
+			t.Errorf("lowS() S = %v, want %v", got.S, low)
+		}
+		if !ecdsa.VerifyASN1(&key.PublicKey, digest[:], der) {
+			t.Error("the low-S signature does not verify")
+		}
+	}
+}
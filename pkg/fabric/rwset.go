@@ -0,0 +1,173 @@
+package fabric
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/rwset/kvrwset"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+// ReadWriteSet is the decoded read/write set of a transaction in one
+// namespace, i.e. one chaincode
+type ReadWriteSet struct {
+	Namespace    string       `json:"namespace"`
+	Reads        []KVRead     `json:"reads"`
+	RangeQueries []RangeQuery `json:"range_queries,omitempty"`
+	Writes       []KVWrite    `json:"writes"`
+	// Collections holds the hashed read/write sets of private data collections
+	Collections []CollectionReadWriteSet `json:"collections,omitempty"`
+}
+
+// Version is the block and transaction that wrote the version of a key
+type Version struct {
+	BlockNumber uint64 `json:"block_number"`
+	TxNumber    uint64 `json:"tx_number"`
+}
+
+// KVRead is a key read by the transaction with the version it read; the
+// version is nil for keys that did not exist
+type KVRead struct {
+	Key     string   `json:"key"`
+	Version *Version `json:"version"`
+}
+
+// KVWrite is a key written or deleted by the transaction
+type KVWrite struct {
+	Key      string `json:"key"`
+	IsDelete bool   `json:"is_delete,omitempty"`
+	Value    []byte `json:"value,omitempty"`
+}
+
+// RangeQuery is a key range iterated by the transaction
+type RangeQuery struct {
+	StartKey    string `json:"start_key"`
+	EndKey      string `json:"end_key"`
+	IsExhausted bool   `json:"is_exhausted"`
+	// Reads are the keys the iteration returned, unless the peer only kept
+	// their Merkle summary
+	Reads []KVRead `json:"reads,omitempty"`
+}
+
+// CollectionReadWriteSet is the hashed read/write set of a private data collection
+type CollectionReadWriteSet struct {
+	Collection string          `json:"collection"`
+	Reads      []HashedKVRead  `json:"reads"`
+	Writes     []HashedKVWrite `json:"writes"`
+}
+
+// HashedKVRead is a private key read by the transaction, identified by its hash
+type HashedKVRead struct {
+	KeyHash string   `json:"key_hash"`
+	Version *Version `json:"version"`
+}
+
+// HashedKVWrite is a private key written by the transaction, identified by its hash
+type HashedKVWrite struct {
+	KeyHash   string `json:"key_hash"`
+	ValueHash string `json:"value_hash,omitempty"`
+	IsDelete  bool   `json:"is_delete,omitempty"`
+	IsPurge   bool   `json:"is_purge,omitempty"`
+}
+
+// decodeChaincodeAction decodes the chaincode action a proposal response
+// payload carries the results of the simulation in
+func decodeChaincodeAction(proposalResponsePayload []byte) (*peer.ChaincodeAction, error) {
+	var responsePayload peer.ProposalResponsePayload
+	if err := proto.Unmarshal(proposalResponsePayload, &responsePayload); err != nil {
+		return nil, fmt.Errorf("failed to decode proposal response payload: %w", err)
+	}
+	var action peer.ChaincodeAction
+	if err := proto.Unmarshal(responsePayload.GetExtension(), &action); err != nil {
+		return nil, fmt.Errorf("failed to decode chaincode action: %w", err)
+	}
+	return &action, nil
+}
+
+// decodeReadWriteSets decodes the serialized TxReadWriteSet of a chaincode action
+func decodeReadWriteSets(results []byte) ([]ReadWriteSet, error) {
+	var txRWSet rwset.TxReadWriteSet
+	if err := proto.Unmarshal(results, &txRWSet); err != nil {
+		return nil, fmt.Errorf("failed to decode read/write set: %w", err)
+	}
+	sets := make([]ReadWriteSet, 0, len(txRWSet.GetNsRwset()))
+	for _, nsRWSet := range txRWSet.GetNsRwset() {
+		var kv kvrwset.KVRWSet
+		if err := proto.Unmarshal(nsRWSet.GetRwset(), &kv); err != nil {
+			return nil, fmt.Errorf("failed to decode read/write set of %s: %w", nsRWSet.GetNamespace(), err)
+		}
+		set := ReadWriteSet{
+			Namespace: nsRWSet.GetNamespace(),
+			Reads:     decodeReads(kv.GetReads()),
+			Writes:    make([]KVWrite, 0, len(kv.GetWrites())),
+		}
+		for _, query := range kv.GetRangeQueriesInfo() {
+			set.RangeQueries = append(set.RangeQueries, RangeQuery{
+				StartKey:    query.GetStartKey(),
+				EndKey:      query.GetEndKey(),
+				IsExhausted: query.GetItrExhausted(),
+				Reads:       decodeReads(query.GetRawReads().GetKvReads()),
+			})
+		}
+		for _, write := range kv.GetWrites() {
+			set.Writes = append(set.Writes, KVWrite{
+				Key:      write.GetKey(),
+				IsDelete: write.GetIsDelete(),
+				Value:    write.GetValue(),
+			})
+		}
+		for _, collection := range nsRWSet.GetCollectionHashedRwset() {
+			decoded, err := decodeCollection(collection)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode read/write set of %s: %w", nsRWSet.GetNamespace(), err)
+			}
+			set.Collections = append(set.Collections, decoded)
+		}
+		sets = append(sets, set)
+	}
+	return sets, nil
+}
+
+func decodeReads(reads []*kvrwset.KVRead) []KVRead {
+	decoded := make([]KVRead, 0, len(reads))
+	for _, read := range reads {
+		decoded = append(decoded, KVRead{Key: read.GetKey(), Version: decodeVersion(read.GetVersion())})
+	}
+	return decoded
+}
+
+func decodeVersion(version *kvrwset.Version) *Version {
+	if version == nil {
+		return nil
+	}
+	return &Version{BlockNumber: version.GetBlockNum(), TxNumber: version.GetTxNum()}
+}
+
+func decodeCollection(collection *rwset.CollectionHashedReadWriteSet) (CollectionReadWriteSet, error) {
+	var hashed kvrwset.HashedRWSet
+	if err := proto.Unmarshal(collection.GetHashedRwset(), &hashed); err != nil {
+		return CollectionReadWriteSet{}, fmt.Errorf("collection %s: %w", collection.GetCollectionName(), err)
+	}
+	decoded := CollectionReadWriteSet{
+		Collection: collection.GetCollectionName(),
+		Reads:      make([]HashedKVRead, 0, len(hashed.GetHashedReads())),
+		Writes:     make([]HashedKVWrite, 0, len(hashed.GetHashedWrites())),
+	}
+	for _, read := range hashed.GetHashedReads() {
+		decoded.Reads = append(decoded.Reads, HashedKVRead{
+			KeyHash: hex.EncodeToString(read.GetKeyHash()),
+			Version: decodeVersion(read.GetVersion()),
+		})
+	}
+	for _, write := range hashed.GetHashedWrites() {
+		decoded.Writes = append(decoded.Writes, HashedKVWrite{
+			KeyHash:   hex.EncodeToString(write.GetKeyHash()),
+			ValueHash: hex.EncodeToString(write.GetValueHash()),
+			IsDelete:  write.GetIsDelete(),
+			IsPurge:   write.GetIsPurge(),
+		})
+	}
+	return decoded, nil
+}
@@ -0,0 +1,135 @@
+package fabric
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/rwset/kvrwset"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+)
+
+// readWriteSet returns the serialized read/write set of a transaction that
+// read asset1, iterated a range, wrote asset2, deleted asset3 and wrote a
+// private key of collection secrets
+func readWriteSet(t *testing.T) []byte {
+	t.Helper()
+	kv := &kvrwset.KVRWSet{
+		Reads: []*kvrwset.KVRead{
+			{Key: "asset1", Version: &kvrwset.Version{BlockNum: 4, TxNum: 1}},
+			{Key: "missing"},
+		},
+		RangeQueriesInfo: []*kvrwset.RangeQueryInfo{{
+			StartKey:     "asset0",
+			EndKey:       "asset9",
+			ItrExhausted: true,
+			ReadsInfo: &kvrwset.RangeQueryInfo_RawReads{RawReads: &kvrwset.QueryReads{
+				KvReads: []*kvrwset.KVRead{{Key: "asset1", Version: &kvrwset.Version{BlockNum: 4, TxNum: 1}}},
+			}},
+		}},
+		Writes: []*kvrwset.KVWrite{
+			{Key: "asset2", Value: []byte(`{"color":"blue"}`)},
+			{Key: "asset3", IsDelete: true},
+		},
+	}
+	hashed := &kvrwset.HashedRWSet{
+		HashedWrites: []*kvrwset.KVWriteHash{{KeyHash: []byte{0x01}, ValueHash: []byte{0x02}}},
+	}
+	return marshal(t, &rwset.TxReadWriteSet{
+		DataModel: rwset.TxReadWriteSet_KV,
+		NsRwset: []*rwset.NsReadWriteSet{
+			{Namespace: "_lifecycle", Rwset: marshal(t, &kvrwset.KVRWSet{})},
+			{
+				Namespace: "basic",
+				Rwset:     marshal(t, kv),
+				CollectionHashedRwset: []*rwset.CollectionHashedReadWriteSet{
+					{CollectionName: "secrets", HashedRwset: marshal(t, hashed)},
+				},
+			},
+		},
+	})
+}
+
+func TestDecodeReadWriteSets(t *testing.T) {
+	sets, err := decodeReadWriteSets(readWriteSet(t))
+	if err != nil {
+		t.Fatalf("decodeReadWriteSets() error = %v", err)
+	}
+	if len(sets) != 2 || sets[0].Namespace != "_lifecycle" || sets[1].Namespace != "basic" {
+		t.Fatalf("decodeReadWriteSets() = %+v, want the sets of _lifecycle and basic", sets)
+	}
+	if sets[0].Reads == nil || sets[0].Writes == nil {
+		t.Errorf("empty set = %+v, want empty reads and writes rather than null", sets[0])
+	}
+
+	set := sets[1]
+	if len(set.Reads) != 2 || *set.Reads[0].Version != (Version{BlockNumber: 4, TxNumber: 1}) || set.Reads[1].Version != nil {
+		t.Errorf("Reads = %+v, want asset1 at 4:1 and missing without a version", set.Reads)
+	}
+	if len(set.RangeQueries) != 1 || set.RangeQueries[0].EndKey != "asset9" || !set.RangeQueries[0].IsExhausted || len(set.RangeQueries[0].Reads) != 1 {
+		t.Errorf("RangeQueries = %+v", set.RangeQueries)
+	}
+	if len(set.Writes) != 2 || string(set.Writes[0].Value) != `{"color":"blue"}` || !set.Writes[1].IsDelete {
+		t.Errorf("Writes = %+v, want asset2 written and asset3 deleted", set.Writes)
+	}
+	want := HashedKVWrite{KeyHash: "01", ValueHash: "02"}
+	if len(set.Collections) != 1 || set.Collections[0].Collection != "secrets" || len(set.Collections[0].Writes) != 1 || set.Collections[0].Writes[0] != want {
+		t.Errorf("Collections = %+v", set.Collections)
+	}
+}
+
+func TestDecodeReadWriteSetsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []byte
+	}{
+		{"invalid set", []byte{0xff}},
+		{"invalid namespace", marshal(t, &rwset.TxReadWriteSet{NsRwset: []*rwset.NsReadWriteSet{{Namespace: "basic", Rwset: []byte{0xff}}}})},
+		{"invalid collection", marshal(t, &rwset.TxReadWriteSet{NsRwset: []*rwset.NsReadWriteSet{{
+			Namespace:             "basic",
+			CollectionHashedRwset: []*rwset.CollectionHashedReadWriteSet{{CollectionName: "secrets", HashedRwset: []byte{0xff}}},
+		}}})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeReadWriteSets(tt.results); err == nil {
+				t.Error("decodeReadWriteSets() accepted an invalid read/write set")
+			}
+		})
+	}
+}
+
+func TestDecodeSimulation(t *testing.T) {
+	action := &peer.ChaincodeAction{
+		Results: readWriteSet(t),
+		Events:  marshal(t, &peer.ChaincodeEvent{ChaincodeId: "basic", EventName: "AssetCreated", Payload: []byte("asset2")}),
+	}
+	actionPayload := &peer.ChaincodeActionPayload{Action: &peer.ChaincodeEndorsedAction{
+		ProposalResponsePayload: marshal(t, &peer.ProposalResponsePayload{Extension: marshal(t, action)}),
+		Endorsements:            []*peer.Endorsement{{Endorser: serializedIdentity(t, "Org1MSP")}},
+	}}
+	transaction := &peer.Transaction{Actions: []*peer.TransactionAction{{Payload: marshal(t, actionPayload)}}}
+	prepared := marshal(t, &gateway.PreparedTransaction{
+		TransactionId: "tx1",
+		Envelope:      &common.Envelope{Payload: marshal(t, &common.Payload{Data: marshal(t, transaction)})},
+	})
+
+	simulation, err := decodeSimulation(prepared)
+	if err != nil {
+		t.Fatalf("decodeSimulation() error = %v", err)
+	}
+	if len(simulation.ReadWriteSets) != 2 || simulation.ReadWriteSets[1].Namespace != "basic" {
+		t.Errorf("ReadWriteSets = %+v", simulation.ReadWriteSets)
+	}
+	if len(simulation.Events) != 1 || simulation.Events[0].ChaincodeName != "basic" || simulation.Events[0].EventName != "AssetCreated" || string(simulation.Events[0].Payload) != "asset2" {
+		t.Errorf("Events = %+v, want AssetCreated of basic", simulation.Events)
+	}
+	if len(simulation.Endorsers) != 1 || simulation.Endorsers[0].MspID != "Org1MSP" {
+		t.Errorf("Endorsers = %+v", simulation.Endorsers)
+	}
+
+	if _, err := decodeSimulation([]byte{0xff}); err == nil {
+		t.Error("decodeSimulation() accepted an invalid prepared transaction")
+	}
+}
@@ -0,0 +1,174 @@
+package fabric
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/logging"
+)
+
+// Simulation is the outcome of a transaction that was endorsed but not
+// submitted for ordering
+type Simulation struct {
+	TxID string `json:"tx_id"`
+	// Result is the payload of the chaincode response
+	Result []byte `json:"result"`
+	// ReadWriteSets holds the keys read and written per namespace
+	ReadWriteSets []ReadWriteSet `json:"read_write_sets"`
+	// Events are the chaincode events the transaction would emit
+	Events    []SimulatedEvent `json:"events"`
+	Endorsers []Endorser       `json:"endorsers"`
+}
+
+// SimulatedEvent is a chaincode event set by a simulated transaction
+type SimulatedEvent struct {
+	ChaincodeName string `json:"chaincode_name"`
+	EventName     string `json:"event_name"`
+	Payload       []byte `json:"payload"`
+}
+
+// Endorser is a peer that endorsed a transaction
+type Endorser struct {
+	MspID   string `json:"mspid"`
+	Subject string `json:"subject,omitempty"`
+}
+
+// SimulateTransaction collects the endorsements of a transaction like
+// InvokeTransaction does, but returns the simulation results instead of
+// submitting it. The ledger is not changed.
+func (fc *FabricClient) SimulateTransaction(ctx context.Context, chaincodeName string, fcn string, args []string) (simulation *Simulation, err error) {
+	release, err := fc.beginOperation()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	ctx, span := tracer.Start(ctx, "fabric.SimulateTransaction", trace.WithAttributes(
+		attributeChannel.String(fc.config.ChannelName),
+		attributeChaincode.String(chaincodeName),
+		attributeFunction.String(fcn),
+	))
+	defer func() { endSpan(span, err) }()
+
+	slog.DebugContext(ctx, "simulating transaction",
+		"chaincode", chaincodeName,
+		"function", fcn,
+		"args", logging.Sensitive(args),
+	)
+
+	call := CallInfo{
+		Channel:       fc.config.ChannelName,
+		ChaincodeName: chaincodeName,
+		Function:      fcn,
+	}
+
+	// Select a random peer and create connection
+	peerConfig := fc.selectRandomPeer(ctx)
+	call.Peer = peerConfig.Endpoint
+	logging.Add(ctx, slog.String("peer", call.Peer))
+	_, connected := fc.observeCall(ctx, call.withPhase(PhaseConnect))
+	selectedPeer, err := fc.peerConnection(peerConfig)
+	if err != nil {
+		connected(err)
+		return nil, fmt.Errorf("failed to select peer: %w", err)
+	}
+	gw, err := fc.createGatewayConnection(ctx, selectedPeer)
+	connected(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gateway connection: %w", err)
+	}
+	defer gw.Close()
+
+	contract := gw.GetNetwork(fc.config.ChannelName).GetContract(chaincodeName)
+	proposal, err := contract.NewProposal(fcn, proposalOptions(ctx, args)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create proposal: %w", err)
+	}
+	call.TxID = proposal.TransactionID()
+	logging.Add(ctx, slog.String("tx_id", call.TxID))
+	span.SetAttributes(attributeTxID.String(call.TxID))
+
+	callCtx, done := fc.observeCall(ctx, call.withPhase(PhaseEndorse))
+	transaction, err := proposal.EndorseWithContext(callCtx)
+	done(err)
+	if err != nil {
+		slog.ErrorContext(ctx, "simulation failed", "chaincode", chaincodeName, "function", fcn, "error", err)
+		return nil, fmt.Errorf("failed to endorse transaction: %w", err)
+	}
+
+	prepared, err := transaction.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize transaction: %w", err)
+	}
+	simulation, err = decodeSimulation(prepared)
+	if err != nil {
+		return nil, err
+	}
+	simulation.TxID = call.TxID
+	simulation.Result = transaction.Result()
+	return simulation, nil
+}
+
+// decodeSimulation decodes the results of the endorsed action of a prepared transaction
+func decodeSimulation(prepared []byte) (*Simulation, error) {
+	var preparedTransaction gateway.PreparedTransaction
+	if err := proto.Unmarshal(prepared, &preparedTransaction); err != nil {
+		return nil, fmt.Errorf("failed to decode prepared transaction: %w", err)
+	}
+	var payload common.Payload
+	if err := proto.Unmarshal(preparedTransaction.GetEnvelope().GetPayload(), &payload); err != nil {
+		return nil, fmt.Errorf("failed to decode payload: %w", err)
+	}
+	var transaction peer.Transaction
+	if err := proto.Unmarshal(payload.GetData(), &transaction); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction: %w", err)
+	}
+
+	simulation := &Simulation{
+		ReadWriteSets: []ReadWriteSet{},
+		Events:        []SimulatedEvent{},
+		Endorsers:     []Endorser{},
+	}
+	for _, transactionAction := range transaction.GetActions() {
+		var actionPayload peer.ChaincodeActionPayload
+		if err := proto.Unmarshal(transactionAction.GetPayload(), &actionPayload); err != nil {
+			return nil, fmt.Errorf("failed to decode action payload: %w", err)
+		}
+		action, err := decodeChaincodeAction(actionPayload.GetAction().GetProposalResponsePayload())
+		if err != nil {
+			return nil, err
+		}
+		sets, err := decodeReadWriteSets(action.GetResults())
+		if err != nil {
+			return nil, err
+		}
+		simulation.ReadWriteSets = append(simulation.ReadWriteSets, sets...)
+
+		if len(action.GetEvents()) > 0 {
+			var event peer.ChaincodeEvent
+			if err := proto.Unmarshal(action.GetEvents(), &event); err != nil {
+				return nil, fmt.Errorf("failed to decode chaincode event: %w", err)
+			}
+			if event.GetEventName() != "" {
+				simulation.Events = append(simulation.Events, SimulatedEvent{
+					ChaincodeName: event.GetChaincodeId(),
+					EventName:     event.GetEventName(),
+					Payload:       event.GetPayload(),
+				})
+			}
+		}
+
+		for _, endorsement := range actionPayload.GetAction().GetEndorsements() {
+			mspID, subject := decodeCreator(endorsement.GetEndorser())
+			simulation.Endorsers = append(simulation.Endorsers, Endorser{MspID: mspID, Subject: subject})
+		}
+	}
+	return simulation, nil
+}
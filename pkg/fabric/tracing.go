@@ -0,0 +1,54 @@
+package fabric
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer uses the global tracer provider, so spans are only recorded once
+// tracing has been set up
+var tracer = otel.Tracer("github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric")
+
+const (
+	attributeChannel        = attribute.Key("fabric.channel")
+	attributeChaincode      = attribute.Key("fabric.chaincode")
+	attributeFunction       = attribute.Key("fabric.function")
+	attributePeer           = attribute.Key("fabric.peer")
+	attributeTxID           = attribute.Key("fabric.tx_id")
+	attributeValidationCode = attribute.Key("fabric.validation_code")
+	attributeBlockNumber    = attribute.Key("fabric.block_number")
+)
+
+var phaseSpanNames = map[Phase]string{
+	PhaseConnect:      "fabric.Dial",
+	PhaseEvaluate:     "fabric.Evaluate",
+	PhaseEndorse:      "fabric.Endorse",
+	PhaseSubmit:       "fabric.Submit",
+	PhaseCommitStatus: "fabric.CommitStatus",
+}
+
+func startCallSpan(ctx context.Context, call CallInfo) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attributeChannel.String(call.Channel),
+		attributeChaincode.String(call.ChaincodeName),
+		attributeFunction.String(call.Function),
+		attributePeer.String(call.Peer),
+	}
+	if call.TxID != "" {
+		attrs = append(attrs, attributeTxID.String(call.TxID))
+	}
+	return tracer.Start(ctx, phaseSpanNames[call.Phase], trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// endSpan records err, if any, on the span and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
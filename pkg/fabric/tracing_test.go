@@ -0,0 +1,42 @@
+package fabric
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestObserveCallParentsTheGatewayCall(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	ctx, request := provider.Tracer("test").Start(context.Background(), "request")
+	fc := &FabricClient{}
+	callCtx, done := fc.observeCall(ctx, CallInfo{Phase: PhaseEndorse, Channel: "mychannel"})
+	// The gRPC instrumentation starts its client span from the context of the call
+	_, rpc := provider.Tracer("test").Start(callCtx, "gateway.Gateway/Endorse")
+	rpc.End()
+	done(nil)
+	request.End()
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	endorse, ok := spans["fabric.Endorse"]
+	if !ok {
+		t.Fatalf("no fabric.Endorse span among %v", spans)
+	}
+	if endorse.Parent().SpanID() != request.SpanContext().SpanID() {
+		t.Errorf("fabric.Endorse parent = %s, want the request span %s", endorse.Parent().SpanID(), request.SpanContext().SpanID())
+	}
+	if got := spans["gateway.Gateway/Endorse"].Parent().SpanID(); got != endorse.SpanContext().SpanID() {
+		t.Errorf("gateway call parent = %s, want the fabric.Endorse span %s", got, endorse.SpanContext().SpanID())
+	}
+}
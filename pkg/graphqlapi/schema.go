@@ -0,0 +1,489 @@
+package graphqlapi
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+)
+
+// uint64Type carries block numbers and heights, which do not fit the 32-bit
+// Int of GraphQL. Values are serialized as JSON numbers; inputs may also be
+// strings.
+var uint64Type = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "Uint64",
+	Description: "An unsigned 64-bit integer, e.g. a block number",
+	Serialize: func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case uint64:
+			return v
+		case *uint64:
+			if v == nil {
+				return nil
+			}
+			return *v
+		}
+		return nil
+	},
+	ParseValue: func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case float64:
+			if v < 0 || v > math.MaxUint64 || v != math.Trunc(v) {
+				return nil
+			}
+			return uint64(v)
+		case string:
+			return parseUint64(v)
+		}
+		return nil
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		switch v := valueAST.(type) {
+		case *ast.IntValue:
+			return parseUint64(v.Value)
+		case *ast.StringValue:
+			return parseUint64(v.Value)
+		}
+		return nil
+	},
+})
+
+func parseUint64(s string) interface{} {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return n
+}
+
+var ledgerInfoType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "LedgerInfo",
+	Description: "The height and head hashes of the channel's ledger",
+	Fields: graphql.Fields{
+		"height":            {Type: graphql.NewNonNull(uint64Type)},
+		"currentBlockHash":  {Type: graphql.NewNonNull(graphql.String)},
+		"previousBlockHash": {Type: graphql.NewNonNull(graphql.String)},
+	},
+})
+
+var transactionType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Transaction",
+	Description: "A transaction of a block",
+	Fields: graphql.Fields{
+		"txId":        {Type: graphql.NewNonNull(graphql.String)},
+		"blockNumber": {Type: graphql.NewNonNull(uint64Type)},
+		"index": {
+			Type:        graphql.NewNonNull(graphql.Int),
+			Description: "Position of the transaction in its block",
+		},
+		"type":    {Type: graphql.NewNonNull(graphql.String)},
+		"channel": {Type: graphql.NewNonNull(graphql.String)},
+		"timestamp": {
+			Type:        graphql.String,
+			Description: "Time the transaction was created, in RFC 3339 format",
+		},
+		"validationCode": {
+			Type:        graphql.NewNonNull(graphql.String),
+			Description: "Validation code of the transaction, e.g. VALID or MVCC_READ_CONFLICT",
+		},
+		"creatorMspId":   {Type: graphql.NewNonNull(graphql.String)},
+		"creatorSubject": {Type: graphql.String},
+		"chaincodeName": {
+			Type:        graphql.String,
+			Description: "Chaincode called by an endorser transaction",
+		},
+		"function": {Type: graphql.String},
+		"args":     {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))},
+		"endorsers": {
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+			Description: "MSP IDs of the endorsing peers",
+		},
+	},
+})
+
+var blockType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Block",
+	Description: "A block of the channel's ledger",
+	Fields: graphql.Fields{
+		"number":       {Type: graphql.NewNonNull(uint64Type)},
+		"dataHash":     {Type: graphql.NewNonNull(graphql.String)},
+		"previousHash": {Type: graphql.NewNonNull(graphql.String)},
+		"transactions": {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(transactionType)))},
+	},
+})
+
+var invokeResultType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "InvokeResult",
+	Description: "The outcome of a submitted transaction",
+	Fields: graphql.Fields{
+		"txId":           {Type: graphql.NewNonNull(graphql.String)},
+		"blockNumber":    {Type: graphql.NewNonNull(uint64Type)},
+		"resultCode":     {Type: graphql.NewNonNull(graphql.Int)},
+		"validationCode": {Type: graphql.NewNonNull(graphql.String)},
+		"success":        {Type: graphql.NewNonNull(graphql.Boolean)},
+		"result": {
+			Type:        graphql.NewNonNull(graphql.String),
+			Description: "Value returned by the chaincode function",
+		},
+	},
+})
+
+var chaincodeEventType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "ChaincodeEvent",
+	Description: "An event emitted by a committed transaction",
+	Fields: graphql.Fields{
+		"blockNumber":   {Type: graphql.NewNonNull(uint64Type)},
+		"txId":          {Type: graphql.NewNonNull(graphql.String)},
+		"chaincodeName": {Type: graphql.NewNonNull(graphql.String)},
+		"eventName":     {Type: graphql.NewNonNull(graphql.String)},
+		"payload":       {Type: graphql.NewNonNull(graphql.String)},
+	},
+})
+
+// transactionArgs are the arguments of the evaluate query and the invoke mutation
+var transactionArgs = graphql.FieldConfigArgument{
+	"chaincodeName": {Type: graphql.NewNonNull(graphql.String)},
+	"function":      {Type: graphql.NewNonNull(graphql.String)},
+	"args":          {Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+}
+
+// newSchema builds the schema with the server's resolvers. The root fields are
+// nullable so that a failing field does not discard the others of the request.
+func (s *Server) newSchema() (graphql.Schema, error) {
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"ledgerInfo": {
+				Type:        ledgerInfoType,
+				Description: "The height and head hashes of the channel's ledger",
+				Resolve:     s.resolveLedgerInfo,
+			},
+			"block": {
+				Type:        blockType,
+				Description: "The block with the given number, or the latest block when number is omitted",
+				Args: graphql.FieldConfigArgument{
+					"number": {Type: uint64Type},
+				},
+				Resolve: s.resolveBlock,
+			},
+			"blockByTxId": {
+				Type:        blockType,
+				Description: "The block that contains the transaction",
+				Args: graphql.FieldConfigArgument{
+					"txId": {Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: s.resolveBlockByTxID,
+			},
+			"transaction": {
+				Type:        transactionType,
+				Description: "The transaction with the given ID",
+				Args: graphql.FieldConfigArgument{
+					"txId": {Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: s.resolveTransaction,
+			},
+			"evaluate": {
+				Type:        graphql.String,
+				Description: "Evaluates a chaincode function without submitting it and returns its result",
+				Args:        transactionArgs,
+				Resolve:     s.resolveEvaluate,
+			},
+		},
+	})
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"invoke": {
+				Type:        invokeResultType,
+				Description: "Submits a transaction and waits for it to be committed",
+				Args:        transactionArgs,
+				Resolve:     s.resolveInvoke,
+			},
+		},
+	})
+	subscription := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"chaincodeEvents": {
+				Type:        chaincodeEventType,
+				Description: "Events emitted by the chaincode's committed transactions, from the next block or from startBlock",
+				Args: graphql.FieldConfigArgument{
+					"chaincodeName": {Type: graphql.NewNonNull(graphql.String)},
+					"startBlock":    {Type: uint64Type},
+				},
+				Subscribe: s.subscribeChaincodeEvents,
+				Resolve:   resolveEvent,
+			},
+			"blocks": {
+				Type:        blockType,
+				Description: "Blocks committed to the channel, from the next block or from startBlock",
+				Args: graphql.FieldConfigArgument{
+					"startBlock": {Type: uint64Type},
+				},
+				Subscribe: s.subscribeBlocks,
+				Resolve:   resolveEvent,
+			},
+		},
+	})
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:        query,
+		Mutation:     mutation,
+		Subscription: subscription,
+	})
+}
+
+func (s *Server) resolveLedgerInfo(p graphql.ResolveParams) (interface{}, error) {
+	if err := s.authorizeLedger(p, "GetChainInfo"); err != nil {
+		return nil, err
+	}
+	info, err := s.fabricClient.ChainInfo(p.Context)
+	if err != nil {
+		return nil, fabricError(err)
+	}
+	return map[string]interface{}{
+		"height":            info.Height,
+		"currentBlockHash":  info.CurrentBlockHash,
+		"previousBlockHash": info.PreviousBlockHash,
+	}, nil
+}
+
+func (s *Server) resolveBlock(p graphql.ResolveParams) (interface{}, error) {
+	if err := s.authorizeLedger(p, "GetBlockByNumber"); err != nil {
+		return nil, err
+	}
+	number, ok := p.Args["number"].(uint64)
+	if !ok {
+		info, err := s.fabricClient.ChainInfo(p.Context)
+		if err != nil {
+			return nil, fabricError(err)
+		}
+		if info.Height == 0 {
+			return nil, newError(codeNotFound, "the channel has no blocks")
+		}
+		number = info.Height - 1
+	}
+	block, err := s.fabricClient.Block(p.Context, number)
+	if err != nil {
+		return nil, fabricError(err)
+	}
+	return blockValue(block), nil
+}
+
+func (s *Server) resolveBlockByTxID(p graphql.ResolveParams) (interface{}, error) {
+	if err := s.authorizeLedger(p, "GetBlockByTxID"); err != nil {
+		return nil, err
+	}
+	block, err := s.fabricClient.BlockByTxID(p.Context, p.Args["txId"].(string))
+	if err != nil {
+		return nil, fabricError(err)
+	}
+	return blockValue(block), nil
+}
+
+func (s *Server) resolveTransaction(p graphql.ResolveParams) (interface{}, error) {
+	if err := s.authorizeLedger(p, "GetBlockByTxID"); err != nil {
+		return nil, err
+	}
+	tx, err := s.fabricClient.Transaction(p.Context, p.Args["txId"].(string))
+	if err != nil {
+		return nil, fabricError(err)
+	}
+	return transactionValue(tx), nil
+}
+
+func (s *Server) resolveEvaluate(p graphql.ResolveParams) (interface{}, error) {
+	chaincode, function, args := transactionArgValues(p)
+	if err := s.authorize(p.Context, chaincode, function, auth.OperationEvaluate); err != nil {
+		return nil, err
+	}
+	if err := s.validate(chaincode, function, args); err != nil {
+		return nil, err
+	}
+	release, err := s.limitChaincode(p.Context, chaincode, auth.OperationEvaluate)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	result, err := s.fabricClient.EvaluateTransaction(p.Context, chaincode, function, args)
+	if err != nil {
+		return nil, fabricError(err)
+	}
+	return string(result), nil
+}
+
+func (s *Server) resolveInvoke(p graphql.ResolveParams) (interface{}, error) {
+	chaincode, function, args := transactionArgValues(p)
+	if err := s.authorize(p.Context, chaincode, function, auth.OperationInvoke); err != nil {
+		return nil, err
+	}
+	if err := s.validate(chaincode, function, args); err != nil {
+		return nil, err
+	}
+	release, err := s.limitChaincode(p.Context, chaincode, auth.OperationInvoke)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	result, err := s.fabricClient.InvokeTransaction(p.Context, chaincode, function, args)
+	if err != nil {
+		return nil, fabricError(err)
+	}
+	return map[string]interface{}{
+		"txId":           result.TxID,
+		"blockNumber":    result.BlockNumber,
+		"resultCode":     int(result.ResultCode),
+		"validationCode": fabric.ValidationCodeName(result.ResultCode),
+		"success":        result.Success,
+		"result":         string(result.Result),
+	}, nil
+}
+
+// subscribeChaincodeEvents starts a chaincode event stream. Callers need a
+// scope that allows evaluating some function of the chaincode, as for the
+// gRPC ChaincodeEvents stream.
+func (s *Server) subscribeChaincodeEvents(p graphql.ResolveParams) (interface{}, error) {
+	chaincode := p.Args["chaincodeName"].(string)
+	if principal := auth.FromContext(p.Context); principal != nil && !principal.AllowsChaincode(s.fabricClient.ChannelName(), chaincode, auth.OperationEvaluate) {
+		return nil, graphqlError(newError(codeForbidden, fmt.Sprintf("%s is not allowed to read the events of chaincode %s", principal.Name, chaincode)))
+	}
+	if s.limiter != nil {
+		if err := s.limiter.AllowChaincode(clientKey(p.Context), chaincode); err != nil {
+			return nil, graphqlError(limitError(err))
+		}
+	}
+
+	events, err := s.fabricClient.ChaincodeEvents(p.Context, chaincode, eventOptions(p))
+	if err != nil {
+		return nil, graphqlError(fabricError(err))
+	}
+	return forward(p.Context, events, func(event *fabric.ChaincodeEvent) interface{} {
+		return map[string]interface{}{
+			"blockNumber":   event.BlockNumber,
+			"txId":          event.TxID,
+			"chaincodeName": event.ChaincodeName,
+			"eventName":     event.EventName,
+			"payload":       string(event.Payload),
+		}
+	}), nil
+}
+
+// subscribeBlocks starts a block event stream. Since blocks carry every
+// transaction, callers need a scope that allows evaluating any chaincode of
+// the channel.
+func (s *Server) subscribeBlocks(p graphql.ResolveParams) (interface{}, error) {
+	if principal := auth.FromContext(p.Context); principal != nil && !principal.AllowsChannel(s.fabricClient.ChannelName(), auth.OperationEvaluate) {
+		return nil, graphqlError(newError(codeForbidden, fmt.Sprintf("%s is not allowed to read the blocks of channel %s", principal.Name, s.fabricClient.ChannelName())))
+	}
+
+	blocks, err := s.fabricClient.BlockEvents(p.Context, eventOptions(p))
+	if err != nil {
+		return nil, graphqlError(fabricError(err))
+	}
+	return forward(p.Context, blocks, func(block *fabric.Block) interface{} {
+		return blockValue(block)
+	}), nil
+}
+
+// resolveEvent resolves a subscription field to the event it was executed
+// for, or to the error that ended the stream
+func resolveEvent(p graphql.ResolveParams) (interface{}, error) {
+	if err, ok := p.Source.(error); ok {
+		return nil, err
+	}
+	return p.Source, nil
+}
+
+// authorizeLedger checks a ledger query like the equivalent evaluation of the
+// qscc function through /api/evaluate
+func (s *Server) authorizeLedger(p graphql.ResolveParams, function string) error {
+	if err := s.authorize(p.Context, qscc, function, auth.OperationEvaluate); err != nil {
+		return err
+	}
+	if s.limiter != nil {
+		if err := s.limiter.AllowChaincode(clientKey(p.Context), qscc); err != nil {
+			return limitError(err)
+		}
+	}
+	return nil
+}
+
+// qscc is the system chaincode that answers the ledger queries
+const qscc = "qscc"
+
+func transactionArgValues(p graphql.ResolveParams) (chaincode, function string, args []string) {
+	chaincode, _ = p.Args["chaincodeName"].(string)
+	function, _ = p.Args["function"].(string)
+	values, _ := p.Args["args"].([]interface{})
+	args = make([]string, 0, len(values))
+	for _, value := range values {
+		args = append(args, value.(string))
+	}
+	return chaincode, function, args
+}
+
+func eventOptions(p graphql.ResolveParams) fabric.EventOptions {
+	var opts fabric.EventOptions
+	if startBlock, ok := p.Args["startBlock"].(uint64); ok {
+		opts.StartBlock = &startBlock
+	}
+	return opts
+}
+
+func blockValue(block *fabric.Block) map[string]interface{} {
+	transactions := make([]interface{}, 0, len(block.Transactions))
+	for i := range block.Transactions {
+		transactions = append(transactions, transactionValue(&block.Transactions[i]))
+	}
+	return map[string]interface{}{
+		"number":       block.Number,
+		"dataHash":     block.DataHash,
+		"previousHash": block.PreviousHash,
+		"transactions": transactions,
+	}
+}
+
+func transactionValue(tx *fabric.Transaction) map[string]interface{} {
+	value := map[string]interface{}{
+		"txId":           tx.ID,
+		"blockNumber":    tx.BlockNumber,
+		"index":          tx.Index,
+		"type":           tx.Type,
+		"channel":        tx.ChannelID,
+		"validationCode": tx.ValidationCode,
+		"creatorMspId":   tx.CreatorMSP,
+		"creatorSubject": nullable(tx.CreatorSubject),
+		"chaincodeName":  nullable(tx.Chaincode),
+		"function":       nullable(tx.Function),
+		"args":           stringList(tx.Args),
+		"endorsers":      stringList(tx.Endorsers),
+		"timestamp":      nil,
+	}
+	if !tx.Timestamp.IsZero() {
+		value["timestamp"] = tx.Timestamp.UTC().Format(time.RFC3339Nano)
+	}
+	return value
+}
+
+// nullable maps empty strings to null
+func nullable(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func stringList(values []string) []interface{} {
+	list := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		list = append(list, value)
+	}
+	return list
+}
@@ -0,0 +1,402 @@
+package graphqlapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/location"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/ratelimit"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/response"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/schema"
+)
+
+// Route is the path the GraphQL API is served at; it is also the route key of
+// its rate limit
+const Route = "/graphql"
+
+// Server serves the GraphQL API over HTTP and WebSocket with the same Fabric
+// client, authentication and rate limits as the REST API
+type Server struct {
+	fabricClient *fabric.FabricClient
+	authChain    *auth.Chain
+	limiter      *ratelimit.Limiter
+	schemas      *schema.Registry
+	schema       graphql.Schema
+
+	shutdownOnce sync.Once
+	shutdown     chan struct{}
+}
+
+// Option configures optional behaviour of a Server
+type Option func(*Server)
+
+// WithAuthentication requires the requests to authenticate with the chain's
+// authenticators
+func WithAuthentication(authChain *auth.Chain) Option {
+	return func(s *Server) {
+		s.authChain = authChain
+	}
+}
+
+// WithRateLimiter enables the per-client rate limits. The route limit of
+// /graphql applies to every HTTP request and to every operation started over a
+// WebSocket connection.
+func WithRateLimiter(limiter *ratelimit.Limiter) Option {
+	return func(s *Server) {
+		s.limiter = limiter
+	}
+}
+
+// WithSchemas validates the arguments of the evaluate query and the invoke
+// mutation against the registered function schemas before they are endorsed
+func WithSchemas(schemas *schema.Registry) Option {
+	return func(s *Server) {
+		s.schemas = schemas
+	}
+}
+
+// NewServer creates the GraphQL API
+func NewServer(fabricClient *fabric.FabricClient, opts ...Option) (*Server, error) {
+	s := &Server{
+		fabricClient: fabricClient,
+		shutdown:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	graphqlSchema, err := s.newSchema()
+	if err != nil {
+		return nil, err
+	}
+	s.schema = graphqlSchema
+	return s, nil
+}
+
+// Shutdown closes the WebSocket connections, which the HTTP server does not
+// track once they are upgraded
+func (s *Server) Shutdown() {
+	s.shutdownOnce.Do(func() { close(s.shutdown) })
+}
+
+// Request is a GraphQL request
+// @Description GraphQL request with its variables
+type Request struct {
+	Query         string                 `json:"query" example:"{ ledgerInfo { height } }"`
+	Variables     map[string]interface{} `json:"variables,omitempty" swaggertype:"object"`
+	OperationName string                 `json:"operationName,omitempty"`
+}
+
+// Response is the result of a GraphQL request
+// @Description Data and errors of a GraphQL request
+type Response struct {
+	Data   interface{}                `json:"data,omitempty" swaggertype:"object"`
+	Errors []gqlerrors.FormattedError `json:"errors,omitempty" swaggertype:"array,object"`
+}
+
+// ServeHTTP godoc
+// @Summary Execute a GraphQL query or mutation
+// @Description Executes GraphQL queries for ledger info, blocks, transactions and chaincode evaluation, and the invoke mutation. Subscriptions are served on the same path over WebSocket with the graphql-transport-ws protocol.
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Param request body Request true "GraphQL Request"
+// @Success 200 {object} Response
+// @Failure 400 {object} Response
+// @Failure 401 {object} Response
+// @Failure 429 {object} Response
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /graphql [post]
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if isWebSocketUpgrade(r) {
+		s.serveWebSocket(w, r)
+		return
+	}
+
+	var req Request
+	switch r.Method {
+	case http.MethodGet:
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if variables := r.URL.Query().Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				sendErrors(w, http.StatusBadRequest, newError(codeBadRequest, "invalid variables"))
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendErrors(w, http.StatusBadRequest, newError(codeBadRequest, "invalid request body"))
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		sendErrors(w, http.StatusMethodNotAllowed, newError(codeBadRequest, "GraphQL requests must use GET or POST"))
+		return
+	}
+	if req.Query == "" {
+		sendErrors(w, http.StatusBadRequest, newError(codeBadRequest, "query is required"))
+		return
+	}
+	switch operationType(req) {
+	case ast.OperationTypeSubscription:
+		sendErrors(w, http.StatusBadRequest, newError(codeBadRequest, "subscriptions require a WebSocket connection"))
+		return
+	case ast.OperationTypeMutation:
+		// Mutations over GET could be triggered by links and prefetching
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			sendErrors(w, http.StatusMethodNotAllowed, newError(codeBadRequest, "mutations must use POST"))
+			return
+		}
+	}
+
+	ctx, err := s.authenticate(r)
+	if err != nil {
+		sendErrors(w, http.StatusUnauthorized, newError(codeUnauthenticated, err.Error()))
+		return
+	}
+	if err := s.limitRoute(ctx); err != nil {
+		var exceeded *Error
+		if errors.As(err, &exceeded) && exceeded.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Max(1, math.Ceil(exceeded.RetryAfter.Seconds())))))
+		}
+		sendErrors(w, http.StatusTooManyRequests, err)
+		return
+	}
+
+	result := s.execute(ctx, req)
+	status := http.StatusOK
+	if isRequestError(result) {
+		status = http.StatusBadRequest
+	}
+	response.JSON(w, status, Response{Data: result.Data, Errors: result.Errors})
+}
+
+// execute runs a query or a mutation
+func (s *Server) execute(ctx context.Context, req Request) *graphql.Result {
+	return graphql.Do(graphql.Params{
+		Schema:         s.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        ctx,
+	})
+}
+
+// isRequestError reports whether a request failed before execution, e.g. it
+// did not parse or validate. Errors of resolvers carry the path of their field.
+func isRequestError(result *graphql.Result) bool {
+	return result.Data == nil && result.HasErrors() && result.Errors[0].Path == nil
+}
+
+// authenticate runs the authenticators on the request and returns its context
+// carrying the principal and the client key used for rate limiting
+func (s *Server) authenticate(r *http.Request) (context.Context, error) {
+	ctx := r.Context()
+	if s.authChain != nil {
+		var err error
+		if ctx, err = auth.Authenticate(s.authChain, r); err != nil {
+			return nil, err
+		}
+	}
+	return context.WithValue(ctx, clientKeyKey{}, ratelimit.ClientKeyFromContext(ctx, r.RemoteAddr)), nil
+}
+
+type clientKeyKey struct{}
+
+// clientKey returns the client key stored by authenticate
+func clientKey(ctx context.Context) string {
+	key, _ := ctx.Value(clientKeyKey{}).(string)
+	return key
+}
+
+// limitRoute applies the /graphql request rate of the client
+func (s *Server) limitRoute(ctx context.Context) error {
+	if s.limiter == nil {
+		return nil
+	}
+	if err := s.limiter.AllowRoute(clientKey(ctx), Route); err != nil {
+		return limitError(err)
+	}
+	return nil
+}
+
+// authorize checks a chaincode call against the scopes of the authenticated principal
+func (s *Server) authorize(ctx context.Context, chaincode, function string, op auth.Operation) error {
+	principal := auth.FromContext(ctx)
+	if principal == nil || principal.Allows(s.fabricClient.ChannelName(), chaincode, function, op) {
+		return nil
+	}
+	return newError(codeForbidden, fmt.Sprintf("%s is not allowed to %s %s on chaincode %s", principal.Name, op, function, chaincode))
+}
+
+// validate checks the arguments of a chaincode call against the function's
+// schema, reporting the invalid fields in the extensions
+func (s *Server) validate(chaincode, function string, args []string) error {
+	if validationErr := s.schemas.Check(chaincode, function, args, nil); validationErr != nil {
+		return &Error{Code: codeBadRequest, Message: validationErr.Error(), Fields: validationErr.Fields}
+	}
+	return nil
+}
+
+// limitChaincode applies the per-chaincode request rate of the client and, for
+// invokes, holds one of its in-flight invoke slots until release is called
+func (s *Server) limitChaincode(ctx context.Context, chaincode string, op auth.Operation) (func(), error) {
+	if s.limiter == nil {
+		return func() {}, nil
+	}
+	client := clientKey(ctx)
+	if err := s.limiter.AllowChaincode(client, chaincode); err != nil {
+		return nil, limitError(err)
+	}
+	if op != auth.OperationInvoke {
+		return func() {}, nil
+	}
+	release, err := s.limiter.AcquireInvoke(client, chaincode)
+	if err != nil {
+		return nil, limitError(err)
+	}
+	return release, nil
+}
+
+// operationType returns the type of the operation a request executes, or an
+// empty string when the query does not parse; execution reports the error then
+func operationType(req Request) string {
+	document, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return ""
+	}
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if req.OperationName == "" || (operation.Name != nil && operation.Name.Value == req.OperationName) {
+			return operation.Operation
+		}
+	}
+	return ""
+}
+
+// Error codes reported in the extensions of GraphQL errors
+const (
+	codeBadRequest      = "BAD_USER_INPUT"
+	codeUnauthenticated = "UNAUTHENTICATED"
+	codeForbidden       = "FORBIDDEN"
+	codeNotFound        = "NOT_FOUND"
+	codeRateLimited     = "RATE_LIMITED"
+	codeUnavailable     = "UNAVAILABLE"
+	codeInternal        = "INTERNAL_SERVER_ERROR"
+)
+
+// Error is an error of a resolver with the code clients can act on
+type Error struct {
+	Code    string
+	Message string
+	// RetryAfter is set for rate limit errors
+	RetryAfter time.Duration
+	// Fields is set for arguments that do not match the function's schema
+	Fields []schema.FieldError
+}
+
+func newError(code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Extensions implements gqlerrors.ExtendedError
+func (e *Error) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{"code": e.Code}
+	if e.RetryAfter > 0 {
+		extensions["retryAfter"] = e.RetryAfter.Seconds()
+	}
+	if len(e.Fields) > 0 {
+		extensions["fields"] = e.Fields
+	}
+	return extensions
+}
+
+// graphqlError wraps an error that is not returned by a query resolver, which
+// would be formatted without its extensions otherwise
+func graphqlError(err error) *gqlerrors.Error {
+	return &gqlerrors.Error{
+		Message:       err.Error(),
+		OriginalError: err,
+		Locations:     []location.SourceLocation{},
+	}
+}
+
+// fabricError converts an error of the Fabric client
+func fabricError(err error) error {
+	if errors.Is(err, fabric.ErrClosed) {
+		return newError(codeUnavailable, err.Error())
+	}
+	return newError(codeInternal, err.Error())
+}
+
+// limitError converts a rate limit error
+func limitError(err error) error {
+	var exceeded *ratelimit.ExceededError
+	if errors.As(err, &exceeded) {
+		return &Error{Code: codeRateLimited, Message: err.Error(), RetryAfter: exceeded.RetryAfter}
+	}
+	return newError(codeInternal, err.Error())
+}
+
+// errStreamEnded ends a subscription whose event stream from the peer failed
+var errStreamEnded = newError(codeUnavailable, "event stream from the peer ended; subscribe again with startBlock to resume")
+
+// forward converts the events of a Fabric event stream for a subscription.
+// When the stream ends while ctx is still live, errStreamEnded is delivered as
+// the last event.
+func forward[T any](ctx context.Context, events <-chan T, convert func(T) interface{}) chan interface{} {
+	out := make(chan interface{})
+	go func() {
+		defer close(out)
+		for event := range events {
+			select {
+			case out <- convert(event):
+			case <-ctx.Done():
+				return
+			}
+		}
+		if ctx.Err() == nil {
+			select {
+			case out <- errStreamEnded:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return out
+}
+
+// sendErrors writes a response that only carries errors, in the format of the
+// GraphQL specification rather than the error body of the REST API
+func sendErrors(w http.ResponseWriter, status int, errs ...error) {
+	formatted := make([]gqlerrors.FormattedError, 0, len(errs))
+	for _, err := range errs {
+		formatted = append(formatted, gqlerrors.FormatError(graphqlError(err)))
+	}
+	response.JSON(w, status, Response{Errors: formatted})
+}
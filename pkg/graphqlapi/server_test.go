@@ -0,0 +1,192 @@
+package graphqlapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql/language/ast"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/ratelimit"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/schema"
+)
+
+const testAPIKey = "hlf_test"
+
+// newTestServer returns a server whose API key may call the basic chaincode.
+// The Fabric client cannot reach its peer, so resolvers that pass the checks
+// fail with INTERNAL_SERVER_ERROR.
+func newTestServer(t *testing.T, limits *ratelimit.Config, extra ...Option) *Server {
+	t.Helper()
+	fabricClient, err := fabric.NewFabricClient(&fabric.ClientConfig{
+		ChannelName: "mychannel",
+		Peers:       []fabric.PeerConfig{{Endpoint: "127.0.0.1:1", TLSCertPath: t.TempDir() + "/missing-ca.pem"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiKeys, err := auth.NewAPIKeyAuthenticator([]auth.APIKeyConfig{{
+		Name:   "ci",
+		Hash:   auth.HashAPIKey(testAPIKey),
+		Scopes: []auth.Scope{{Chaincodes: []string{"basic"}}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := []Option{WithAuthentication(auth.NewChain([]auth.Authenticator{apiKeys}))}
+	if limits != nil {
+		opts = append(opts, WithRateLimiter(ratelimit.NewLimiter(*limits)))
+	}
+	opts = append(opts, extra...)
+	s, err := NewServer(fabricClient, opts...)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	return s
+}
+
+// do sends a GraphQL request and decodes the response
+func do(t *testing.T, s *Server, method, query, key string) (*httptest.ResponseRecorder, Response) {
+	t.Helper()
+	var r *http.Request
+	if method == http.MethodGet {
+		r = httptest.NewRequest(method, Route+"?query="+url.QueryEscape(query), nil)
+	} else {
+		body, _ := json.Marshal(Request{Query: query})
+		r = httptest.NewRequest(method, Route, strings.NewReader(string(body)))
+	}
+	if key != "" {
+		r.Header.Set(auth.HeaderAPIKey, key)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	var resp Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response %q: %v", w.Body.String(), err)
+	}
+	return w, resp
+}
+
+// errorCode returns the code in the extensions of the first error
+func errorCode(resp Response) string {
+	if len(resp.Errors) == 0 {
+		return ""
+	}
+	code, _ := resp.Errors[0].Extensions["code"].(string)
+	return code
+}
+
+func TestServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		query  string
+		key    string
+		status int
+		code   string
+	}{
+		{name: "no credentials", method: http.MethodPost, query: `{ evaluate(chaincodeName: "basic", function: "ReadAsset") }`, status: http.StatusUnauthorized, code: codeUnauthenticated},
+		{name: "invalid query", method: http.MethodPost, query: `{ unknownField }`, key: testAPIKey, status: http.StatusBadRequest},
+		{name: "mutation over GET", method: http.MethodGet, query: `mutation { invoke(chaincodeName: "basic", function: "CreateAsset") { txId } }`, key: testAPIKey, status: http.StatusMethodNotAllowed, code: codeBadRequest},
+		{name: "subscription over HTTP", method: http.MethodPost, query: `subscription { blocks { number } }`, key: testAPIKey, status: http.StatusBadRequest, code: codeBadRequest},
+		{name: "chaincode out of scope", method: http.MethodPost, query: `{ evaluate(chaincodeName: "other", function: "ReadAsset") }`, key: testAPIKey, status: http.StatusOK, code: codeForbidden},
+		{name: "ledger query out of scope", method: http.MethodGet, query: `{ ledgerInfo { height } }`, key: testAPIKey, status: http.StatusOK, code: codeForbidden},
+		{name: "allowed query", method: http.MethodGet, query: `{ evaluate(chaincodeName: "basic", function: "ReadAsset") }`, key: testAPIKey, status: http.StatusOK, code: codeInternal},
+		{name: "allowed mutation", method: http.MethodPost, query: `mutation { invoke(chaincodeName: "basic", function: "CreateAsset") { txId } }`, key: testAPIKey, status: http.StatusOK, code: codeInternal},
+	}
+	s := newTestServer(t, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, resp := do(t, s, tt.method, tt.query, tt.key)
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if len(resp.Errors) == 0 {
+				t.Fatal("the response has no errors")
+			}
+			if tt.code != "" && errorCode(resp) != tt.code {
+				t.Errorf("code = %q, want %q: %s", errorCode(resp), tt.code, w.Body)
+			}
+		})
+	}
+}
+
+func TestServeHTTPRateLimit(t *testing.T) {
+	s := newTestServer(t, &ratelimit.Config{Routes: map[string]ratelimit.Limit{Route: {Rate: 1, Burst: 1}}})
+	query := `{ evaluate(chaincodeName: "basic", function: "ReadAsset") }`
+	if w, _ := do(t, s, http.MethodPost, query, testAPIKey); w.Code != http.StatusOK {
+		t.Fatalf("first request status = %d", w.Code)
+	}
+	w, resp := do(t, s, http.MethodPost, query, testAPIKey)
+	if w.Code != http.StatusTooManyRequests || errorCode(resp) != codeRateLimited {
+		t.Errorf("second request status = %d, code = %q", w.Code, errorCode(resp))
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Retry-After is not set")
+	}
+}
+
+func TestServeHTTPSchemaValidation(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "basic"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	schemaFile := `{"properties": {"args": {"prefixItems": [{"type": "string", "minLength": 1}]}}}`
+	for _, function := range []string{"ReadAsset", "CreateAsset"} {
+		if err := os.WriteFile(filepath.Join(dir, "basic", function+".json"), []byte(schemaFile), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	schemas, err := schema.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newTestServer(t, nil, WithSchemas(schemas))
+
+	tests := []struct {
+		name  string
+		query string
+		code  string
+	}{
+		{name: "invalid query", query: `{ evaluate(chaincodeName: "basic", function: "ReadAsset", args: [""]) }`, code: codeBadRequest},
+		{name: "invalid mutation", query: `mutation { invoke(chaincodeName: "basic", function: "CreateAsset", args: [""]) { txId } }`, code: codeBadRequest},
+		{name: "valid query", query: `{ evaluate(chaincodeName: "basic", function: "ReadAsset", args: ["asset1"]) }`, code: codeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, resp := do(t, s, http.MethodPost, tt.query, testAPIKey)
+			if errorCode(resp) != tt.code {
+				t.Fatalf("code = %q, want %q: %s", errorCode(resp), tt.code, w.Body)
+			}
+			if tt.code == codeBadRequest && !strings.Contains(w.Body.String(), `"field":"/args/0"`) {
+				t.Errorf("body = %s, want the invalid field in the extensions", w.Body)
+			}
+		})
+	}
+}
+
+func TestOperationType(t *testing.T) {
+	query := `query Info { ledgerInfo { height } } mutation Create { invoke(chaincodeName: "basic", function: "CreateAsset") { txId } }`
+	tests := []struct {
+		req  Request
+		want string
+	}{
+		{Request{Query: `{ ledgerInfo { height } }`}, ast.OperationTypeQuery},
+		{Request{Query: query, OperationName: "Create"}, ast.OperationTypeMutation},
+		{Request{Query: query, OperationName: "Info"}, ast.OperationTypeQuery},
+		{Request{Query: `subscription { blocks { number } }`}, ast.OperationTypeSubscription},
+		{Request{Query: `{ ledgerInfo`}, ""},
+	}
+	for _, tt := range tests {
+		if got := operationType(tt.req); got != tt.want {
+			t.Errorf("operationType(%q, %q) = %q, want %q", tt.req.Query, tt.req.OperationName, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package graphqlapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+)
+
+// subprotocol is the GraphQL over WebSocket protocol implemented by the server,
+// see https://github.com/enisdenjo/graphql-ws/blob/master/PROTOCOL.md
+const subprotocol = "graphql-transport-ws"
+
+const (
+	// initTimeout is how long a connection may stay open without connection_init
+	initTimeout = 10 * time.Second
+	// pingInterval is how often the server pings idle connections; a
+	// connection that does not answer within pongWait is closed
+	pingInterval = 30 * time.Second
+	pongWait     = 60 * time.Second
+	writeTimeout = 10 * time.Second
+)
+
+// Close codes of the protocol
+const (
+	closeInvalidMessage      = 4400
+	closeUnauthorized        = 4401
+	closeForbidden           = 4403
+	closeSubprotocol         = 4406
+	closeInitTimeout         = 4408
+	closeSubscriberExists    = 4409
+	closeTooManyInitRequests = 4429
+)
+
+// message is a message of the protocol in either direction
+type message struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+var upgrader = websocket.Upgrader{
+	Subprotocols: []string{subprotocol},
+}
+
+func isWebSocketUpgrade(r *http.Request) bool {
+	return websocket.IsWebSocketUpgrade(r)
+}
+
+// wsConnection is a GraphQL over WebSocket connection
+type wsConnection struct {
+	server  *Server
+	ws      *websocket.Conn
+	request *http.Request
+
+	writeMu sync.Mutex
+
+	mu sync.Mutex
+	// ctx is set once connection_init is acknowledged and carries the
+	// principal the operations run as
+	ctx        context.Context
+	initDone   bool
+	operations map[string]context.CancelFunc
+	running    sync.WaitGroup
+}
+
+// serveWebSocket serves the operations of a WebSocket connection. Browsers
+// cannot set headers on WebSocket requests, so when the upgrade request carries
+// no credentials the client authenticates with the payload of connection_init,
+// e.g. {"x-api-key": "..."} or {"authorization": "Bearer ..."}.
+func (s *Server) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	r = r.WithContext(ctx)
+	authenticated, err := s.authenticate(r)
+	if errors.Is(err, auth.ErrAuthenticationRequired) {
+		authenticated = nil
+	} else if err != nil {
+		sendErrors(w, http.StatusUnauthorized, newError(codeUnauthenticated, err.Error()))
+		return
+	}
+
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has already replied with an HTTP error
+		return
+	}
+	c := &wsConnection{
+		server:     s,
+		ws:         ws,
+		request:    r,
+		operations: map[string]context.CancelFunc{},
+	}
+	defer func() {
+		cancel()
+		// Wait for the operations so that their event streams are closed
+		c.running.Wait()
+		ws.Close()
+	}()
+
+	if ws.Subprotocol() != subprotocol {
+		c.close(closeSubprotocol, "Subprotocol not acceptable")
+		return
+	}
+
+	go func() {
+		pings := time.NewTicker(pingInterval)
+		defer pings.Stop()
+		initTimer := time.NewTimer(initTimeout)
+		defer initTimer.Stop()
+		for {
+			select {
+			case <-initTimer.C:
+				if !c.initialised() {
+					c.close(closeInitTimeout, "Connection initialisation timeout")
+				}
+			case <-pings.C:
+				c.writeControl(websocket.PingMessage, nil)
+			case <-s.shutdown:
+				c.close(websocket.CloseGoingAway, "server is shutting down")
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	c.readLoop(ctx, authenticated)
+}
+
+// readLoop handles the client's messages until the connection is closed
+func (c *wsConnection) readLoop(ctx, authenticated context.Context) {
+	c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		var msg message
+		if err := c.ws.ReadJSON(&msg); err != nil {
+			if _, ok := err.(*json.SyntaxError); ok {
+				c.close(closeInvalidMessage, "Invalid message received")
+			}
+			return
+		}
+		c.ws.SetReadDeadline(time.Now().Add(pongWait))
+
+		switch msg.Type {
+		case "connection_init":
+			if !c.init(ctx, authenticated, msg.Payload) {
+				return
+			}
+		case "ping":
+			c.write(message{Type: "pong"})
+		case "pong":
+		case "subscribe":
+			if !c.subscribe(msg) {
+				return
+			}
+		case "complete":
+			c.mu.Lock()
+			if cancel, ok := c.operations[msg.ID]; ok {
+				cancel()
+				delete(c.operations, msg.ID)
+			}
+			c.mu.Unlock()
+		default:
+			c.close(closeInvalidMessage, "Invalid message received")
+			return
+		}
+	}
+}
+
+// init authenticates the connection and acknowledges it; it reports whether
+// the connection stays open
+func (c *wsConnection) init(ctx, authenticated context.Context, payload json.RawMessage) bool {
+	c.mu.Lock()
+	if c.initDone {
+		c.mu.Unlock()
+		c.close(closeTooManyInitRequests, "Too many initialisation requests")
+		return false
+	}
+	c.initDone = true
+	c.mu.Unlock()
+
+	if authenticated == nil {
+		var params map[string]interface{}
+		if len(payload) > 0 && string(payload) != "null" {
+			if err := json.Unmarshal(payload, &params); err != nil {
+				c.close(closeInvalidMessage, "Invalid message received")
+				return false
+			}
+		}
+		// Present the payload to the authenticators as request headers
+		r := c.request.Clone(ctx)
+		for key, value := range params {
+			if s, ok := value.(string); ok {
+				r.Header.Set(key, s)
+			}
+		}
+		var err error
+		if authenticated, err = c.server.authenticate(r); err != nil {
+			c.close(closeForbidden, "Forbidden")
+			return false
+		}
+	}
+
+	c.mu.Lock()
+	c.ctx = authenticated
+	c.mu.Unlock()
+	c.write(message{Type: "connection_ack"})
+	return true
+}
+
+func (c *wsConnection) initialised() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ctx != nil
+}
+
+// subscribe starts an operation; it reports whether the connection stays open
+func (c *wsConnection) subscribe(msg message) bool {
+	var req Request
+	if err := json.Unmarshal(msg.Payload, &req); err != nil || msg.ID == "" || req.Query == "" {
+		c.close(closeInvalidMessage, "Invalid message received")
+		return false
+	}
+
+	c.mu.Lock()
+	if c.ctx == nil {
+		c.mu.Unlock()
+		c.close(closeUnauthorized, "Unauthorized")
+		return false
+	}
+	if _, ok := c.operations[msg.ID]; ok {
+		c.mu.Unlock()
+		c.close(closeSubscriberExists, fmt.Sprintf("Subscriber for %s already exists", msg.ID))
+		return false
+	}
+	ctx, cancel := context.WithCancel(c.ctx)
+	c.operations[msg.ID] = cancel
+	c.running.Add(1)
+	c.mu.Unlock()
+
+	go func() {
+		defer c.running.Done()
+		defer c.finish(msg.ID, ctx)
+		if err := c.server.limitRoute(ctx); err != nil {
+			c.sendError(msg.ID, gqlerrors.FormatErrors(graphqlError(err)))
+			return
+		}
+		if operationType(req) == ast.OperationTypeSubscription {
+			c.runSubscription(ctx, msg.ID, req)
+		} else {
+			c.runOperation(ctx, msg.ID, req)
+		}
+	}()
+	return true
+}
+
+// finish removes an operation that ended on the server's side
+func (c *wsConnection) finish(id string, ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// A completed operation may have been replaced by a new one with its ID
+	if cancel, ok := c.operations[id]; ok && ctx.Err() == nil {
+		cancel()
+		delete(c.operations, id)
+	}
+}
+
+// runOperation executes a query or a mutation
+func (c *wsConnection) runOperation(ctx context.Context, id string, req Request) {
+	result := c.server.execute(ctx, req)
+	if ctx.Err() != nil {
+		return
+	}
+	if isRequestError(result) {
+		c.sendError(id, result.Errors)
+		return
+	}
+	c.write(message{ID: id, Type: "next", Payload: mustMarshal(Response{Data: result.Data, Errors: result.Errors})})
+	c.write(message{ID: id, Type: "complete"})
+}
+
+// runSubscription delivers the results of a subscription until it is
+// completed by either side
+func (c *wsConnection) runSubscription(ctx context.Context, id string, req Request) {
+	results := graphql.Subscribe(graphql.Params{
+		Schema:         c.server.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        ctx,
+	})
+	failed := false
+	// The executor blocks on sending results, so the channel is drained even
+	// after the operation is completed
+	for result := range results {
+		if ctx.Err() != nil || failed {
+			continue
+		}
+		if isRequestError(result) {
+			c.sendError(id, result.Errors)
+			failed = true
+			continue
+		}
+		c.write(message{ID: id, Type: "next", Payload: mustMarshal(Response{Data: result.Data, Errors: result.Errors})})
+	}
+	if ctx.Err() == nil && !failed {
+		c.write(message{ID: id, Type: "complete"})
+	}
+}
+
+func (c *wsConnection) sendError(id string, errs []gqlerrors.FormattedError) {
+	c.write(message{ID: id, Type: "error", Payload: mustMarshal(errs)})
+}
+
+func (c *wsConnection) write(msg message) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+	c.ws.WriteJSON(msg)
+}
+
+func (c *wsConnection) writeControl(messageType int, data []byte) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.ws.WriteControl(messageType, data, time.Now().Add(writeTimeout))
+}
+
+// close sends a close frame and closes the connection, which ends the read loop
+func (c *wsConnection) close(code int, reason string) {
+	c.writeControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason))
+	c.ws.Close()
+}
+
+func mustMarshal(v interface{}) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal([]gqlerrors.FormattedError{gqlerrors.NewFormattedError(err.Error())})
+	}
+	return data
+}
@@ -0,0 +1,126 @@
+package graphqlapi
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// dial opens a GraphQL over WebSocket connection to the server
+func dial(t *testing.T, s *Server) *websocket.Conn {
+	t.Helper()
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	dialer := websocket.Dialer{Subprotocols: []string{subprotocol}}
+	ws, _, err := dialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+Route, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { ws.Close() })
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return ws
+}
+
+func send(t *testing.T, ws *websocket.Conn, msg message) {
+	t.Helper()
+	if err := ws.WriteJSON(msg); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+}
+
+func receive(t *testing.T, ws *websocket.Conn) message {
+	t.Helper()
+	var msg message
+	if err := ws.ReadJSON(&msg); err != nil {
+		t.Fatalf("ReadJSON() error = %v", err)
+	}
+	return msg
+}
+
+// closeCode reads until the server closes the connection and returns the close code
+func closeCode(t *testing.T, ws *websocket.Conn) int {
+	t.Helper()
+	for {
+		if _, _, err := ws.ReadMessage(); err != nil {
+			if closeErr, ok := err.(*websocket.CloseError); ok {
+				return closeErr.Code
+			}
+			t.Fatalf("ReadMessage() error = %v, want a close frame", err)
+		}
+	}
+}
+
+func TestWebSocketAuthenticatesWithInitPayload(t *testing.T) {
+	ws := dial(t, newTestServer(t, nil))
+	send(t, ws, message{Type: "connection_init", Payload: json.RawMessage(`{"x-api-key": "` + testAPIKey + `"}`)})
+	if msg := receive(t, ws); msg.Type != "connection_ack" {
+		t.Fatalf("message = %+v, want connection_ack", msg)
+	}
+
+	send(t, ws, message{Type: "ping"})
+	if msg := receive(t, ws); msg.Type != "pong" {
+		t.Errorf("message = %+v, want pong", msg)
+	}
+
+	// Subscriptions are authorized like queries
+	send(t, ws, message{ID: "1", Type: "subscribe", Payload: mustMarshal(Request{Query: `subscription { chaincodeEvents(chaincodeName: "other") { txId } }`})})
+	msg := receive(t, ws)
+	if msg.ID != "1" || msg.Type != "error" || !strings.Contains(string(msg.Payload), codeForbidden) {
+		t.Errorf("message = %+v, want a FORBIDDEN error", msg)
+	}
+
+	send(t, ws, message{ID: "2", Type: "subscribe", Payload: mustMarshal(Request{Query: `{ evaluate(chaincodeName: "basic", function: "ReadAsset") }`})})
+	if msg := receive(t, ws); msg.ID != "2" || msg.Type != "next" || !strings.Contains(string(msg.Payload), codeInternal) {
+		t.Errorf("message = %+v, want the result of the query", msg)
+	}
+	if msg := receive(t, ws); msg.ID != "2" || msg.Type != "complete" {
+		t.Errorf("message = %+v, want complete", msg)
+	}
+}
+
+func TestWebSocketProtocolErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []message
+		want     int
+	}{
+		{
+			name:     "invalid credentials",
+			messages: []message{{Type: "connection_init", Payload: json.RawMessage(`{"x-api-key": "hlf_unknown"}`)}},
+			want:     closeForbidden,
+		},
+		{
+			name:     "subscribe before init",
+			messages: []message{{ID: "1", Type: "subscribe", Payload: mustMarshal(Request{Query: `{ ledgerInfo { height } }`})}},
+			want:     closeUnauthorized,
+		},
+		{
+			name: "second init",
+			messages: []message{
+				{Type: "connection_init", Payload: json.RawMessage(`{"x-api-key": "` + testAPIKey + `"}`)},
+				{Type: "connection_init"},
+			},
+			want: closeTooManyInitRequests,
+		},
+		{
+			name:     "unknown message type",
+			messages: []message{{Type: "start"}},
+			want:     closeInvalidMessage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := dial(t, newTestServer(t, nil))
+			for _, msg := range tt.messages {
+				send(t, ws, msg)
+			}
+			if got := closeCode(t, ws); got != tt.want {
+				t.Errorf("close code = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
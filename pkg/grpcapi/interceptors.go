@@ -0,0 +1,114 @@
+package grpcapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/ratelimit"
+	hlfapiv1 "github.com/kfsoftware/chainlaunch-plugin-hlf/proto/hlfapi/v1"
+)
+
+// servicePrefix selects the methods that are authenticated and rate limited;
+// the health and reflection services are open like /readyz and /swagger
+var servicePrefix = "/" + hlfapiv1.TransactionService_ServiceDesc.ServiceName + "/"
+
+// UnaryInterceptor authenticates the calls to the TransactionService and
+// applies the per-method request rate of the client
+func (s *Server) UnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.admit(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// StreamInterceptor is the streaming counterpart of UnaryInterceptor
+func (s *Server) StreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.admit(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
+
+// admit runs the authenticators of the REST API on the call's metadata and
+// TLS state and returns the context carrying the principal
+func (s *Server) admit(ctx context.Context, method string) (context.Context, error) {
+	r := httpRequest(ctx, method)
+	ctx = auth.ClientCertContext(r)
+	if !strings.HasPrefix(method, servicePrefix) {
+		return ctx, nil
+	}
+
+	if s.authChain != nil {
+		var err error
+		if ctx, err = auth.Authenticate(s.authChain, r.WithContext(ctx)); err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+	}
+	if s.limiter != nil {
+		if err := s.limiter.AllowRoute(clientKey(ctx), method); err != nil {
+			return nil, limitError(err)
+		}
+	}
+	return ctx, nil
+}
+
+// httpRequest presents a gRPC call to the authenticators as the equivalent
+// HTTP request: the metadata become headers, e.g. x-api-key and authorization,
+// and the TLS state of the connection carries the client certificate
+func httpRequest(ctx context.Context, method string) *http.Request {
+	r := &http.Request{
+		Method:     http.MethodPost,
+		URL:        &url.URL{Path: method},
+		Proto:      "HTTP/2.0",
+		ProtoMajor: 2,
+		Header:     http.Header{},
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for key, values := range md {
+			if strings.HasPrefix(key, ":") {
+				continue
+			}
+			for _, value := range values {
+				r.Header.Add(key, value)
+			}
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		r.RemoteAddr = p.Addr.String()
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			r.TLS = &tlsInfo.State
+		}
+	}
+	return r.WithContext(ctx)
+}
+
+// clientKey identifies the client of a call like ratelimit.ClientKey
+func clientKey(ctx context.Context) string {
+	var remoteAddr string
+	if p, ok := peer.FromContext(ctx); ok {
+		remoteAddr = p.Addr.String()
+	}
+	return ratelimit.ClientKeyFromContext(ctx, remoteAddr)
+}
+
+// serverStream replaces the context of a server stream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,346 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/metrics"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/ratelimit"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/schema"
+	hlfapiv1 "github.com/kfsoftware/chainlaunch-plugin-hlf/proto/hlfapi/v1"
+)
+
+// Server implements the gRPC TransactionService with the same Fabric client,
+// authentication and rate limits as the REST API
+type Server struct {
+	hlfapiv1.UnimplementedTransactionServiceServer
+
+	fabricClient *fabric.FabricClient
+	authChain    *auth.Chain
+	limiter      *ratelimit.Limiter
+	schemas      *schema.Registry
+
+	shutdownOnce sync.Once
+	shutdown     chan struct{}
+}
+
+// Option configures optional behaviour of a Server
+type Option func(*Server)
+
+// WithAuthentication requires the calls to the TransactionService to
+// authenticate with the chain's authenticators
+func WithAuthentication(authChain *auth.Chain) Option {
+	return func(s *Server) {
+		s.authChain = authChain
+	}
+}
+
+// WithRateLimiter enables the per-client rate limits. The route limits apply
+// to the full gRPC method name, e.g. /hlfapi.v1.TransactionService/Invoke.
+func WithRateLimiter(limiter *ratelimit.Limiter) Option {
+	return func(s *Server) {
+		s.limiter = limiter
+	}
+}
+
+// WithSchemas validates the arguments of Invoke and Evaluate against the
+// registered function schemas before they are endorsed
+func WithSchemas(schemas *schema.Registry) Option {
+	return func(s *Server) {
+		s.schemas = schemas
+	}
+}
+
+// NewServer creates the TransactionService
+func NewServer(fabricClient *fabric.FabricClient, opts ...Option) *Server {
+	s := &Server{
+		fabricClient: fabricClient,
+		shutdown:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Shutdown ends the event streams, which would otherwise keep the gRPC server
+// from stopping gracefully
+func (s *Server) Shutdown() {
+	s.shutdownOnce.Do(func() { close(s.shutdown) })
+}
+
+// Invoke submits a transaction and waits for it to be committed
+func (s *Server) Invoke(ctx context.Context, req *hlfapiv1.TransactionRequest) (*hlfapiv1.InvokeResponse, error) {
+	if req.GetChaincodeName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "chaincode_name is required")
+	}
+	if err := s.authorize(ctx, req, auth.OperationInvoke); err != nil {
+		return nil, err
+	}
+	if err := s.validate(req); err != nil {
+		return nil, err
+	}
+	metrics.SetTransaction(ctx, req.GetChaincodeName(), req.GetFunction())
+	release, err := s.limitChaincode(ctx, req.GetChaincodeName(), auth.OperationInvoke)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	result, err := s.fabricClient.InvokeTransaction(ctx, req.GetChaincodeName(), req.GetFunction(), req.GetArgs())
+	if err != nil {
+		return nil, fabricError(err)
+	}
+	return &hlfapiv1.InvokeResponse{
+		Result:         result.Result,
+		TxId:           result.TxID,
+		BlockNumber:    result.BlockNumber,
+		ResultCode:     result.ResultCode,
+		ValidationCode: fabric.ValidationCodeName(result.ResultCode),
+		Success:        result.Success,
+	}, nil
+}
+
+// Evaluate queries the chaincode without submitting
+func (s *Server) Evaluate(ctx context.Context, req *hlfapiv1.TransactionRequest) (*hlfapiv1.EvaluateResponse, error) {
+	if req.GetChaincodeName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "chaincode_name is required")
+	}
+	if err := s.authorize(ctx, req, auth.OperationEvaluate); err != nil {
+		return nil, err
+	}
+	if err := s.validate(req); err != nil {
+		return nil, err
+	}
+	metrics.SetTransaction(ctx, req.GetChaincodeName(), req.GetFunction())
+	release, err := s.limitChaincode(ctx, req.GetChaincodeName(), auth.OperationEvaluate)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	result, err := s.fabricClient.EvaluateTransaction(ctx, req.GetChaincodeName(), req.GetFunction(), req.GetArgs())
+	if err != nil {
+		return nil, fabricError(err)
+	}
+	return &hlfapiv1.EvaluateResponse{Result: result}, nil
+}
+
+// ChaincodeEvents streams the events emitted by a chaincode's committed
+// transactions. Callers need a scope that allows evaluating some function of
+// the chaincode.
+func (s *Server) ChaincodeEvents(req *hlfapiv1.ChaincodeEventsRequest, stream hlfapiv1.TransactionService_ChaincodeEventsServer) error {
+	ctx := stream.Context()
+	if req.GetChaincodeName() == "" {
+		return status.Error(codes.InvalidArgument, "chaincode_name is required")
+	}
+	if principal := auth.FromContext(ctx); principal != nil && !principal.AllowsChaincode(s.fabricClient.ChannelName(), req.GetChaincodeName(), auth.OperationEvaluate) {
+		return status.Errorf(codes.PermissionDenied, "%s is not allowed to read the events of chaincode %s", principal.Name, req.GetChaincodeName())
+	}
+	metrics.SetTransaction(ctx, req.GetChaincodeName(), "")
+	if err := s.limitEvents(ctx, req.GetChaincodeName()); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events, err := s.fabricClient.ChaincodeEvents(ctx, req.GetChaincodeName(), fabric.EventOptions{StartBlock: req.StartBlock})
+	if err != nil {
+		return fabricError(err)
+	}
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return s.streamEnded(ctx)
+			}
+			if err := stream.Send(&hlfapiv1.ChaincodeEvent{
+				BlockNumber:   event.BlockNumber,
+				TxId:          event.TxID,
+				ChaincodeName: event.ChaincodeName,
+				EventName:     event.EventName,
+				Payload:       event.Payload,
+			}); err != nil {
+				return err
+			}
+		case <-s.shutdown:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		}
+	}
+}
+
+// BlockEvents streams the blocks committed to the channel. Since blocks carry
+// every transaction, callers need a scope that allows evaluating any
+// chaincode of the channel.
+func (s *Server) BlockEvents(req *hlfapiv1.BlockEventsRequest, stream hlfapiv1.TransactionService_BlockEventsServer) error {
+	ctx := stream.Context()
+	if principal := auth.FromContext(ctx); principal != nil && !principal.AllowsChannel(s.fabricClient.ChannelName(), auth.OperationEvaluate) {
+		return status.Errorf(codes.PermissionDenied, "%s is not allowed to read the blocks of channel %s", principal.Name, s.fabricClient.ChannelName())
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	blocks, err := s.fabricClient.BlockEvents(ctx, fabric.EventOptions{StartBlock: req.StartBlock})
+	if err != nil {
+		return fabricError(err)
+	}
+	for {
+		select {
+		case block, ok := <-blocks:
+			if !ok {
+				return s.streamEnded(ctx)
+			}
+			if err := stream.Send(blockMessage(block)); err != nil {
+				return err
+			}
+		case <-s.shutdown:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		}
+	}
+}
+
+// streamEnded returns the status of an event stream closed by the Fabric client
+func (s *Server) streamEnded(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	return status.Error(codes.Unavailable, "event stream from the peer ended; reconnect with start_block to resume")
+}
+
+// authorize checks the call against the scopes of the authenticated principal
+func (s *Server) authorize(ctx context.Context, req *hlfapiv1.TransactionRequest, op auth.Operation) error {
+	principal := auth.FromContext(ctx)
+	if principal == nil || principal.Allows(s.fabricClient.ChannelName(), req.GetChaincodeName(), req.GetFunction(), op) {
+		return nil
+	}
+	return status.Errorf(codes.PermissionDenied, "%s is not allowed to %s %s on chaincode %s", principal.Name, op, req.GetFunction(), req.GetChaincodeName())
+}
+
+// validate checks the arguments against the function's schema, returning
+// INVALID_ARGUMENT with a BadRequest detail listing the invalid fields
+func (s *Server) validate(req *hlfapiv1.TransactionRequest) error {
+	validationErr := s.schemas.Check(req.GetChaincodeName(), req.GetFunction(), req.GetArgs(), nil)
+	if validationErr == nil {
+		return nil
+	}
+	badRequest := &errdetails.BadRequest{}
+	for _, field := range validationErr.Fields {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field.Field,
+			Description: field.Message,
+		})
+	}
+	st, err := status.New(codes.InvalidArgument, validationErr.Error()).WithDetails(badRequest)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, validationErr.Error())
+	}
+	return st.Err()
+}
+
+// limitChaincode applies the per-chaincode request rate of the client and, for
+// invokes, holds one of its in-flight invoke slots until release is called
+func (s *Server) limitChaincode(ctx context.Context, chaincode string, op auth.Operation) (func(), error) {
+	if s.limiter == nil {
+		return func() {}, nil
+	}
+	client := clientKey(ctx)
+	if err := s.limiter.AllowChaincode(client, chaincode); err != nil {
+		return nil, limitError(err)
+	}
+	if op != auth.OperationInvoke {
+		return func() {}, nil
+	}
+	release, err := s.limiter.AcquireInvoke(client, chaincode)
+	if err != nil {
+		return nil, limitError(err)
+	}
+	return release, nil
+}
+
+// limitEvents applies the per-chaincode request rate to opening an event stream
+func (s *Server) limitEvents(ctx context.Context, chaincode string) error {
+	if s.limiter == nil {
+		return nil
+	}
+	if err := s.limiter.AllowChaincode(clientKey(ctx), chaincode); err != nil {
+		return limitError(err)
+	}
+	return nil
+}
+
+// commitStatusReason is the ErrorInfo reason of invokes whose transaction
+// was submitted but whose commit status is unknown
+const commitStatusReason = "COMMIT_STATUS_UNKNOWN"
+
+// fabricError converts an error of the Fabric client to a gRPC status. A
+// transaction whose commit status is unknown may still be committed, so it is
+// reported with UNKNOWN and its transaction ID rather than as a failure that
+// clients would retry.
+func fabricError(err error) error {
+	var commitErr *fabric.CommitStatusError
+	switch {
+	case errors.As(err, &commitErr):
+		st, detailsErr := status.New(codes.Unknown, err.Error()).WithDetails(&errdetails.ErrorInfo{
+			Reason:   commitStatusReason,
+			Domain:   "hlfapi.v1",
+			Metadata: map[string]string{"tx_id": commitErr.TxID},
+		})
+		if detailsErr != nil {
+			return status.Error(codes.Unknown, err.Error())
+		}
+		return st.Err()
+	case errors.Is(err, fabric.ErrClosed):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// limitError converts a rate limit error to a gRPC status
+func limitError(err error) error {
+	var exceeded *ratelimit.ExceededError
+	if errors.As(err, &exceeded) {
+		return status.Error(codes.ResourceExhausted, fmt.Sprintf("%s; retry after %s", err, exceeded.RetryAfter))
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func blockMessage(block *fabric.Block) *hlfapiv1.Block {
+	message := &hlfapiv1.Block{
+		Number:       block.Number,
+		DataHash:     block.DataHash,
+		PreviousHash: block.PreviousHash,
+		Transactions: make([]*hlfapiv1.Transaction, 0, len(block.Transactions)),
+	}
+	for _, tx := range block.Transactions {
+		transaction := &hlfapiv1.Transaction{
+			TxId:           tx.ID,
+			BlockNumber:    tx.BlockNumber,
+			Index:          uint32(tx.Index),
+			Type:           tx.Type,
+			ValidationCode: tx.ValidationCode,
+			CreatorMspid:   tx.CreatorMSP,
+			CreatorSubject: tx.CreatorSubject,
+			ChaincodeName:  tx.Chaincode,
+			Function:       tx.Function,
+			Args:           tx.Args,
+			Endorsers:      tx.Endorsers,
+		}
+		if !tx.Timestamp.IsZero() {
+			transaction.Timestamp = timestamppb.New(tx.Timestamp)
+		}
+		message.Transactions = append(message.Transactions, transaction)
+	}
+	return message
+}
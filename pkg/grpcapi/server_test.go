@@ -0,0 +1,202 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/ratelimit"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/schema"
+	hlfapiv1 "github.com/kfsoftware/chainlaunch-plugin-hlf/proto/hlfapi/v1"
+)
+
+const testAPIKey = "hlf_test"
+
+// newTestClient serves the TransactionService over an in-memory connection
+// and returns a client for it. The Fabric client cannot reach its peer, so
+// calls that pass authentication and the limits fail with Internal.
+func newTestClient(t *testing.T, limits *ratelimit.Config, extra ...Option) hlfapiv1.TransactionServiceClient {
+	t.Helper()
+	fabricClient, err := fabric.NewFabricClient(&fabric.ClientConfig{
+		ChannelName: "mychannel",
+		Peers:       []fabric.PeerConfig{{Endpoint: "127.0.0.1:1", TLSCertPath: t.TempDir() + "/missing-ca.pem"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiKeys, err := auth.NewAPIKeyAuthenticator([]auth.APIKeyConfig{{
+		Name:   "ci",
+		Hash:   auth.HashAPIKey(testAPIKey),
+		Scopes: []auth.Scope{{Chaincodes: []string{"basic"}}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := []Option{WithAuthentication(auth.NewChain([]auth.Authenticator{apiKeys}))}
+	if limits != nil {
+		opts = append(opts, WithRateLimiter(ratelimit.NewLimiter(*limits)))
+	}
+	opts = append(opts, extra...)
+	s := NewServer(fabricClient, opts...)
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.UnaryInterceptor(s.UnaryInterceptor), grpc.StreamInterceptor(s.StreamInterceptor))
+	hlfapiv1.RegisterTransactionServiceServer(server, s)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return hlfapiv1.NewTransactionServiceClient(conn)
+}
+
+func withAPIKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key)
+}
+
+func TestAuthentication(t *testing.T) {
+	client := newTestClient(t, nil)
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		chaincode string
+		want      codes.Code
+	}{
+		{name: "no credentials", ctx: context.Background(), chaincode: "basic", want: codes.Unauthenticated},
+		{name: "unknown key", ctx: withAPIKey("hlf_unknown"), chaincode: "basic", want: codes.Unauthenticated},
+		{name: "chaincode out of scope", ctx: withAPIKey(testAPIKey), chaincode: "other", want: codes.PermissionDenied},
+		{name: "missing chaincode", ctx: withAPIKey(testAPIKey), chaincode: "", want: codes.InvalidArgument},
+		{name: "allowed", ctx: withAPIKey(testAPIKey), chaincode: "basic", want: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Evaluate(tt.ctx, &hlfapiv1.TransactionRequest{ChaincodeName: tt.chaincode, Function: "ReadAsset"})
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("Evaluate() code = %v, want %v (%v)", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRateLimits(t *testing.T) {
+	client := newTestClient(t, &ratelimit.Config{
+		Routes: map[string]ratelimit.Limit{"/hlfapi.v1.TransactionService/Evaluate": {Rate: 1, Burst: 1}},
+	})
+	req := &hlfapiv1.TransactionRequest{ChaincodeName: "basic", Function: "ReadAsset"}
+	if _, err := client.Evaluate(withAPIKey(testAPIKey), req); status.Code(err) != codes.Internal {
+		t.Fatalf("first Evaluate() error = %v", err)
+	}
+	_, err := client.Evaluate(withAPIKey(testAPIKey), req)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("second Evaluate() error = %v, want ResourceExhausted", err)
+	}
+	// The limit of one method does not apply to the others
+	if _, err := client.Invoke(withAPIKey(testAPIKey), req); status.Code(err) != codes.Internal {
+		t.Errorf("Invoke() error = %v, want it not to be limited", err)
+	}
+}
+
+func TestSchemaValidation(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "basic"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	schemaFile := `{"properties": {"args": {"prefixItems": [{"type": "string", "minLength": 1}]}}}`
+	if err := os.WriteFile(filepath.Join(dir, "basic", "CreateAsset.json"), []byte(schemaFile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	schemas, err := schema.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newTestClient(t, nil, WithSchemas(schemas))
+
+	invalid := &hlfapiv1.TransactionRequest{ChaincodeName: "basic", Function: "CreateAsset", Args: []string{""}}
+	for name, call := range map[string]func(context.Context, *hlfapiv1.TransactionRequest, ...grpc.CallOption) (interface{}, error){
+		"Invoke": func(ctx context.Context, req *hlfapiv1.TransactionRequest, opts ...grpc.CallOption) (interface{}, error) {
+			return client.Invoke(ctx, req, opts...)
+		},
+		"Evaluate": func(ctx context.Context, req *hlfapiv1.TransactionRequest, opts ...grpc.CallOption) (interface{}, error) {
+			return client.Evaluate(ctx, req, opts...)
+		},
+	} {
+		_, err := call(withAPIKey(testAPIKey), invalid)
+		st := status.Convert(err)
+		if st.Code() != codes.InvalidArgument {
+			t.Errorf("%s() of invalid arguments error = %v, want InvalidArgument", name, err)
+			continue
+		}
+		var badRequest *errdetails.BadRequest
+		for _, detail := range st.Details() {
+			if d, ok := detail.(*errdetails.BadRequest); ok {
+				badRequest = d
+			}
+		}
+		if badRequest == nil || len(badRequest.FieldViolations) != 1 || badRequest.FieldViolations[0].Field != "/args/0" {
+			t.Errorf("%s() details = %v, want the invalid argument", name, st.Details())
+		}
+	}
+
+	valid := &hlfapiv1.TransactionRequest{ChaincodeName: "basic", Function: "CreateAsset", Args: []string{"asset1"}}
+	if _, err := client.Evaluate(withAPIKey(testAPIKey), valid); status.Code(err) != codes.Internal {
+		t.Errorf("Evaluate() of valid arguments error = %v, want it to reach the peer", err)
+	}
+}
+
+func TestAdmitLeavesOtherServicesOpen(t *testing.T) {
+	s := NewServer(nil, WithAuthentication(auth.NewChain(nil)))
+	if _, err := s.admit(context.Background(), "/grpc.health.v1.Health/Check"); err != nil {
+		t.Errorf("admit() of the health service error = %v", err)
+	}
+	if _, err := s.admit(context.Background(), servicePrefix+"Invoke"); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("admit() of the TransactionService error = %v, want Unauthenticated", err)
+	}
+}
+
+func TestFabricError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{fabric.ErrClosed, codes.Unavailable},
+		{context.DeadlineExceeded, codes.DeadlineExceeded},
+		{context.Canceled, codes.Canceled},
+		{errors.New("endorsement failed"), codes.Internal},
+		{&fabric.CommitStatusError{TxID: "tx1", Err: context.DeadlineExceeded}, codes.Unknown},
+	}
+	for _, tt := range tests {
+		if got := status.Code(fabricError(tt.err)); got != tt.want {
+			t.Errorf("fabricError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+
+	st := status.Convert(fabricError(&fabric.CommitStatusError{TxID: "tx1", Err: errors.New("connection reset")}))
+	var info *errdetails.ErrorInfo
+	for _, detail := range st.Details() {
+		if d, ok := detail.(*errdetails.ErrorInfo); ok {
+			info = d
+		}
+	}
+	if info == nil || info.Reason != commitStatusReason || info.Metadata["tx_id"] != "tx1" {
+		t.Errorf("details = %v, want the transaction ID of the submitted transaction", st.Details())
+	}
+}
@@ -0,0 +1,83 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// watchInterval is how often Watch re-evaluates the readiness of the server
+const watchInterval = time.Second
+
+// GRPCServer implements the standard gRPC health service from the readiness
+// checks: the overall server ("") and every listed service are SERVING
+// exactly when /readyz reports ready
+type GRPCServer struct {
+	healthpb.UnimplementedHealthServer
+
+	checker  *Checker
+	services map[string]bool
+}
+
+// NewGRPCServer creates the health service for the named gRPC services
+func NewGRPCServer(checker *Checker, services ...string) *GRPCServer {
+	s := &GRPCServer{
+		checker:  checker,
+		services: map[string]bool{"": true},
+	}
+	for _, service := range services {
+		s.services[service] = true
+	}
+	return s
+}
+
+func (s *GRPCServer) status() healthpb.HealthCheckResponse_ServingStatus {
+	if s.checker.Ready() {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
+
+// Check returns the serving status of the service
+func (s *GRPCServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if !s.services[req.GetService()] {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.GetService())
+	}
+	return &healthpb.HealthCheckResponse{Status: s.status()}, nil
+}
+
+// Watch streams the serving status of the service whenever it changes. The
+// stream ends once NOT_SERVING was sent for a shutdown, so that it does not
+// hold up the graceful stop of the server.
+func (s *GRPCServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	if !s.services[req.GetService()] {
+		if err := stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVICE_UNKNOWN}); err != nil {
+			return err
+		}
+		<-stream.Context().Done()
+		return stream.Context().Err()
+	}
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	last := healthpb.HealthCheckResponse_UNKNOWN
+	for {
+		if current := s.status(); current != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: current}); err != nil {
+				return err
+			}
+			last = current
+		}
+		if s.checker.draining.Load() {
+			return status.Error(codes.Unavailable, "server is shutting down")
+		}
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-ticker.C:
+		}
+	}
+}
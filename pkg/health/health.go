@@ -0,0 +1,275 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/response"
+)
+
+const (
+	// StatusOK and StatusFailed are the statuses of a single check
+	StatusOK     = "ok"
+	StatusFailed = "failed"
+	// StatusReady and StatusNotReady are the statuses of a report
+	StatusReady    = "ready"
+	StatusNotReady = "not_ready"
+
+	// PeerUp and PeerDown are the states of a peer
+	PeerUp   = "up"
+	PeerDown = "down"
+)
+
+// Config configures the readiness checks
+type Config struct {
+	// MinPeers is the number of peers that must be reachable to be ready
+	MinPeers int
+	// Interval is the time between two checks
+	Interval time.Duration
+	// Timeout bounds the query sent to each peer
+	Timeout time.Duration
+}
+
+// Check is the outcome of a single readiness check
+// @Description Outcome of a single readiness check
+type Check struct {
+	// Status of the check ("ok" or "failed")
+	Status string `json:"status" example:"ok"`
+	// Error describing why the check failed
+	Error string `json:"error,omitempty" example:"1 of 3 peers reachable, 2 required"`
+}
+
+// PeerReport is the state of a peer as seen by the last check
+// @Description State of a peer as seen by the last check
+type PeerReport struct {
+	Endpoint string `json:"endpoint" example:"peer0.org1.example.com:7051"`
+	// State of the peer ("up" or "down")
+	State string `json:"state" example:"up"`
+	// Height of the channel's ledger on the peer
+	BlockHeight uint64 `json:"block_height,omitempty" example:"42"`
+	// Duration of the last check in milliseconds
+	LatencyMs float64 `json:"latency_ms" example:"12.5"`
+	// Last error returned by the peer, kept after it recovered
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+	LastSeenAt  *time.Time `json:"last_seen_at,omitempty"`
+}
+
+// Report is the detailed readiness state
+// @Description Detailed readiness state with the result of each check and the state of each peer
+type Report struct {
+	// Status of the server ("ready" or "not_ready")
+	Status    string           `json:"status" example:"ready"`
+	CheckedAt time.Time        `json:"checked_at"`
+	Checks    map[string]Check `json:"checks"`
+	Peers     []PeerReport     `json:"peers"`
+}
+
+// Checker periodically verifies that the server can serve requests: the
+// identity material loads, enough peers are reachable and the channel is
+// accessible
+type Checker struct {
+	fabricClient *fabric.FabricClient
+	config       Config
+
+	mu     sync.RWMutex
+	report *Report
+	peers  map[string]PeerReport
+
+	draining atomic.Bool
+}
+
+// NewChecker creates a checker; call Run to start checking
+func NewChecker(fabricClient *fabric.FabricClient, config Config) *Checker {
+	if config.MinPeers <= 0 {
+		config.MinPeers = 1
+	}
+	if config.Interval <= 0 {
+		config.Interval = 15 * time.Second
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	return &Checker{
+		fabricClient: fabricClient,
+		config:       config,
+		peers:        make(map[string]PeerReport),
+	}
+}
+
+// Run checks immediately and then at every interval until ctx is cancelled
+func (c *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+	for {
+		c.Check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check runs all checks and stores the resulting report
+func (c *Checker) Check(ctx context.Context) *Report {
+	report := &Report{
+		Status: StatusReady,
+		Checks: make(map[string]Check),
+	}
+
+	identityErrs := c.fabricClient.CheckIdentities()
+	if len(identityErrs) == 0 {
+		report.Checks["identities"] = Check{Status: StatusOK}
+	} else {
+		names := make([]string, 0, len(identityErrs))
+		for name := range identityErrs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		report.Checks["identities"] = Check{
+			Status: StatusFailed,
+			Error:  fmt.Sprintf("identity %s: %v", names[0], identityErrs[names[0]]),
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	statuses := c.fabricClient.CheckPeers(ctx)
+	report.CheckedAt = time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	up := 0
+	var lastErr error
+	for _, status := range statuses {
+		peer := c.peers[status.Endpoint]
+		peer.Endpoint = status.Endpoint
+		peer.LatencyMs = float64(status.Latency.Microseconds()) / 1000
+		checkedAt := report.CheckedAt
+		if status.Err != nil {
+			peer.State = PeerDown
+			peer.BlockHeight = 0
+			peer.LastError = status.Err.Error()
+			peer.LastErrorAt = &checkedAt
+			lastErr = status.Err
+		} else {
+			peer.State = PeerUp
+			peer.BlockHeight = status.BlockHeight
+			peer.LastSeenAt = &checkedAt
+			up++
+		}
+		c.peers[status.Endpoint] = peer
+		report.Peers = append(report.Peers, peer)
+	}
+
+	if up >= c.config.MinPeers {
+		report.Checks["peers"] = Check{Status: StatusOK}
+	} else {
+		report.Checks["peers"] = Check{
+			Status: StatusFailed,
+			Error:  fmt.Sprintf("%d of %d peers reachable, %d required", up, len(statuses), c.config.MinPeers),
+		}
+	}
+	// The peers are checked by querying the channel, so the channel is
+	// accessible as soon as one of them answered
+	if up > 0 {
+		report.Checks["channel"] = Check{Status: StatusOK}
+	} else {
+		report.Checks["channel"] = Check{Status: StatusFailed, Error: fmt.Sprintf("channel %s is not accessible: %v", c.fabricClient.ChannelName(), lastErr)}
+	}
+
+	for _, check := range report.Checks {
+		if check.Status != StatusOK {
+			report.Status = StatusNotReady
+		}
+	}
+	c.report = report
+	return report
+}
+
+// Report returns the result of the last check, or nil before the first one
+func (c *Checker) Report() *Report {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.report
+}
+
+// SetDraining makes the server report not ready from now on, so that load
+// balancers stop sending requests while it shuts down
+func (c *Checker) SetDraining() {
+	c.draining.Store(true)
+}
+
+// LivezHandler godoc
+// @Summary Liveness probe
+// @Description Reports that the process is running and able to serve HTTP requests
+// @Tags health
+// @Produce json
+// @Success 200 {object} Check
+// @Router /livez [get]
+func (c *Checker) LivezHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusOK, Check{Status: StatusOK})
+}
+
+// ReadyzHandler godoc
+// @Summary Readiness probe
+// @Description Reports whether the identities load, enough peers are reachable and the channel is accessible, with the state of every peer
+// @Tags health
+// @Produce json
+// @Success 200 {object} Report
+// @Failure 503 {object} Report
+// @Router /readyz [get]
+func (c *Checker) ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	report := c.readiness()
+	status := http.StatusOK
+	if report.Status != StatusReady {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, status, report)
+}
+
+// readiness returns the last report, marked not ready while the server is
+// starting up or shutting down
+func (c *Checker) readiness() *Report {
+	report := c.Report()
+	if report == nil && c.draining.Load() {
+		return &Report{
+			Status: StatusNotReady,
+			Checks: map[string]Check{"shutdown": {Status: StatusFailed, Error: "server is shutting down"}},
+			Peers:  []PeerReport{},
+		}
+	}
+	if report == nil {
+		return &Report{
+			Status: StatusNotReady,
+			Checks: map[string]Check{"startup": {Status: StatusFailed, Error: "checks have not completed yet"}},
+			Peers:  []PeerReport{},
+		}
+	}
+
+	if c.draining.Load() {
+		draining := *report
+		draining.Status = StatusNotReady
+		draining.Checks = map[string]Check{"shutdown": {Status: StatusFailed, Error: "server is shutting down"}}
+		for name, check := range report.Checks {
+			draining.Checks[name] = check
+		}
+		report = &draining
+	}
+	return report
+}
+
+// Ready reports whether the server is ready to serve requests
+func (c *Checker) Ready() bool {
+	return c.readiness().Status == StatusReady
+}
@@ -0,0 +1,117 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+)
+
+// newTestChecker returns a checker for a client whose peer and identity
+// cannot be loaded, so that every check fails without a network
+func newTestChecker(t *testing.T) *Checker {
+	t.Helper()
+	dir := t.TempDir()
+	client, err := fabric.NewFabricClient(&fabric.ClientConfig{
+		MspID:       "Org1MSP",
+		CertPath:    dir + "/missing-cert.pem",
+		KeyPath:     dir + "/missing-key.pem",
+		ChannelName: "mychannel",
+		Peers:       []fabric.PeerConfig{{Endpoint: "127.0.0.1:1", TLSCertPath: dir + "/missing-ca.pem"}},
+	})
+	if err != nil {
+		t.Fatalf("NewFabricClient() error = %v", err)
+	}
+	return NewChecker(client, Config{})
+}
+
+// readyReport is a report in which every check passed
+func readyReport() *Report {
+	return &Report{
+		Status: StatusReady,
+		Checks: map[string]Check{"identities": {Status: StatusOK}, "peers": {Status: StatusOK}, "channel": {Status: StatusOK}},
+		Peers:  []PeerReport{{Endpoint: "127.0.0.1:1", State: PeerUp}},
+	}
+}
+
+func readyz(t *testing.T, c *Checker) (int, Report) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	c.ReadyzHandler(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var report Report
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("invalid report %q: %v", w.Body.String(), err)
+	}
+	return w.Code, report
+}
+
+func TestCheckReportsFailedChecks(t *testing.T) {
+	c := newTestChecker(t)
+	report := c.Check(context.Background())
+
+	if report.Status != StatusNotReady {
+		t.Errorf("Status = %q, want %q", report.Status, StatusNotReady)
+	}
+	for _, name := range []string{"identities", "peers", "channel"} {
+		if check := report.Checks[name]; check.Status != StatusFailed || check.Error == "" {
+			t.Errorf("check %s = %+v, want it to fail with an error", name, check)
+		}
+	}
+	if !strings.Contains(report.Checks["peers"].Error, "0 of 1 peers reachable, 1 required") {
+		t.Errorf("peers error = %q", report.Checks["peers"].Error)
+	}
+	if len(report.Peers) != 1 || report.Peers[0].State != PeerDown || report.Peers[0].LastErrorAt == nil {
+		t.Errorf("Peers = %+v, want one peer down", report.Peers)
+	}
+	if c.Report() != report {
+		t.Error("Report() does not return the last report")
+	}
+}
+
+func TestReadyzHandler(t *testing.T) {
+	c := newTestChecker(t)
+	if code, report := readyz(t, c); code != http.StatusServiceUnavailable || report.Checks["startup"].Status != StatusFailed {
+		t.Errorf("before the first check: status = %d, report = %+v", code, report)
+	}
+
+	c.Check(context.Background())
+	if code, _ := readyz(t, c); code != http.StatusServiceUnavailable {
+		t.Errorf("with failed checks: status = %d, want 503", code)
+	}
+
+	c.report = readyReport()
+	if code, report := readyz(t, c); code != http.StatusOK || report.Status != StatusReady {
+		t.Errorf("with passed checks: status = %d, report = %+v", code, report)
+	}
+}
+
+func TestSetDraining(t *testing.T) {
+	c := newTestChecker(t)
+	c.report = readyReport()
+	c.SetDraining()
+
+	code, report := readyz(t, c)
+	if code != http.StatusServiceUnavailable || report.Status != StatusNotReady {
+		t.Errorf("status = %d, report = %+v, want not ready", code, report)
+	}
+	if report.Checks["shutdown"].Status != StatusFailed || report.Checks["peers"].Status != StatusOK {
+		t.Errorf("Checks = %+v, want the shutdown check added to the last checks", report.Checks)
+	}
+	if c.Report().Status != StatusReady {
+		t.Error("draining modified the stored report")
+	}
+}
+
+func TestLivezHandler(t *testing.T) {
+	c := newTestChecker(t)
+	c.SetDraining()
+	w := httptest.NewRecorder()
+	c.LivezHandler(w, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200 while draining", w.Code)
+	}
+}
@@ -0,0 +1,120 @@
+package idempotency
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var responsesBucket = []byte("idempotency_responses")
+
+// BoltStore keeps responses in a bbolt database file so they survive restarts
+type BoltStore struct {
+	db *bolt.DB
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// NewBoltStore opens (or creates) the database file at path
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open idempotency database %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(responsesBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize idempotency database: %w", err)
+	}
+
+	s := &BoltStore{db: db}
+	if err := s.sweep(time.Now()); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *BoltStore) Get(key string) (*Response, error) {
+	var resp *Response
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(responsesBucket).Get([]byte(key))
+		if data == nil {
+			return nil
+		}
+		resp = &Response{}
+		return json.Unmarshal(data, resp)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read idempotency record: %w", err)
+	}
+	if resp == nil || resp.Expired(time.Now()) {
+		return nil, nil
+	}
+	return resp, nil
+}
+
+func (s *BoltStore) Put(key string, resp *Response) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("failed to encode idempotency record: %w", err)
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(responsesBucket).Put([]byte(key), data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write idempotency record: %w", err)
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	due := now.Sub(s.lastSweep) >= sweepInterval
+	s.mu.Unlock()
+	if due {
+		return s.sweep(now)
+	}
+	return nil
+}
+
+// sweep deletes every expired record
+func (s *BoltStore) sweep(now time.Time) error {
+	s.mu.Lock()
+	s.lastSweep = now
+	s.mu.Unlock()
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(responsesBucket)
+		var expired [][]byte
+		err := b.ForEach(func(k, v []byte) error {
+			var resp Response
+			if err := json.Unmarshal(v, &resp); err != nil || resp.Expired(now) {
+				expired = append(expired, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range expired {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to purge expired idempotency records: %w", err)
+	}
+	return nil
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,61 @@
+package idempotency
+
+import (
+	"sync"
+	"time"
+)
+
+const sweepInterval = time.Minute
+
+// MemoryStore keeps responses in process memory; they are lost on restart
+type MemoryStore struct {
+	mu        sync.Mutex
+	responses map[string]*Response
+	lastSweep time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		responses: make(map[string]*Response),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *MemoryStore) Get(key string) (*Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp, ok := s.responses[key]
+	if !ok {
+		return nil, nil
+	}
+	if resp.Expired(time.Now()) {
+		delete(s.responses, key)
+		return nil, nil
+	}
+	return resp, nil
+}
+
+func (s *MemoryStore) Put(key string, resp *Response) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses[key] = resp
+
+	// Drop expired entries from time to time so unused keys do not accumulate
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for k, r := range s.responses {
+			if r.Expired(now) {
+				delete(s.responses, k)
+			}
+		}
+		s.lastSweep = now
+	}
+	return nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,213 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/response"
+)
+
+const (
+	// HeaderKey is the request header carrying the client supplied idempotency key
+	HeaderKey = "Idempotency-Key"
+	// HeaderReplayed is set on responses replayed from the store
+	HeaderReplayed = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+)
+
+// Manager deduplicates requests carrying the same idempotency key. The first
+// request is executed and its response stored; concurrent duplicates wait for
+// it to finish and later retries within the TTL receive the stored response.
+type Manager struct {
+	store Store
+	ttl   time.Duration
+
+	mu       sync.Mutex
+	inflight map[string]*flight
+}
+
+type flight struct {
+	fingerprint string
+	done        chan struct{}
+}
+
+// NewManager creates a manager that keeps responses in store for ttl
+func NewManager(store Store, ttl time.Duration) *Manager {
+	return &Manager{
+		store:    store,
+		ttl:      ttl,
+		inflight: make(map[string]*flight),
+	}
+}
+
+// Middleware applies idempotency handling to requests that carry an Idempotency-Key header.
+// Requests without the header are passed through unchanged.
+func (m *Manager) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(HeaderKey)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxKeyLength {
+			response.Error(w, http.StatusBadRequest, "Idempotency-Key must not exceed 255 characters")
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.Error(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit))
+			return
+		}
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Keys are scoped per client so that callers cannot replay each other's responses
+		storeKey := r.URL.Path + ":" + key
+		if principal := auth.FromContext(r.Context()); principal != nil {
+			storeKey = principal.ID() + ":" + storeKey
+		}
+		fingerprint := requestFingerprint(r, body)
+
+		for {
+			// The lock only guards the in-flight map. The store is read and
+			// written by the request holding the key's flight, so requests
+			// for other keys are not held up by its I/O.
+			m.mu.Lock()
+			if f, ok := m.inflight[storeKey]; ok {
+				m.mu.Unlock()
+				if f.fingerprint != fingerprint {
+					response.Error(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request")
+					return
+				}
+				// Wait for the in-flight request and then replay its stored response
+				select {
+				case <-f.done:
+					continue
+				case <-r.Context().Done():
+					return
+				}
+			}
+			f := &flight{fingerprint: fingerprint, done: make(chan struct{})}
+			m.inflight[storeKey] = f
+			m.mu.Unlock()
+
+			defer m.release(storeKey, f)
+			m.serve(w, r, next, storeKey, fingerprint)
+			return
+		}
+	})
+}
+
+// release ends the flight of a key. It is deferred so that a panicking
+// handler does not leave the key's duplicates waiting forever.
+func (m *Manager) release(storeKey string, f *flight) {
+	m.mu.Lock()
+	delete(m.inflight, storeKey)
+	close(f.done)
+	m.mu.Unlock()
+}
+
+// serve replays the stored response of a key, or executes the request when
+// there is none. The caller holds the key's flight.
+func (m *Manager) serve(w http.ResponseWriter, r *http.Request, next http.Handler, storeKey, fingerprint string) {
+	stored, err := m.store.Get(storeKey)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to look up idempotency key", "error", err)
+		response.Error(w, http.StatusInternalServerError, "failed to look up idempotency key")
+		return
+	}
+	if stored != nil {
+		if stored.Fingerprint != fingerprint {
+			response.Error(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request")
+			return
+		}
+		replay(w, stored)
+		return
+	}
+	m.execute(w, r, next, storeKey, fingerprint)
+}
+
+// execute runs the request and records its response. The request context is
+// detached from the client so a client timeout does not abandon the transaction
+// before its outcome is recorded.
+func (m *Manager) execute(w http.ResponseWriter, r *http.Request, next http.Handler, storeKey, fingerprint string) {
+	rec := &responseRecorder{header: make(http.Header), status: http.StatusOK}
+	next.ServeHTTP(rec, r.WithContext(context.WithoutCancel(r.Context())))
+
+	// Server errors and rejections by rate limits are not recorded so that the request can be retried
+	if rec.status < http.StatusInternalServerError && rec.status != http.StatusTooManyRequests {
+		err := m.store.Put(storeKey, &Response{
+			Fingerprint: fingerprint,
+			StatusCode:  rec.status,
+			ContentType: rec.header.Get("Content-Type"),
+			Body:        rec.body.Bytes(),
+			ExpiresAt:   time.Now().Add(m.ttl),
+		})
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to store idempotent response", "error", err)
+		}
+	}
+
+	for k, v := range rec.header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(rec.status)
+	w.Write(rec.body.Bytes())
+}
+
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func replay(w http.ResponseWriter, resp *Response) {
+	if resp.ContentType != "" {
+		w.Header().Set("Content-Type", resp.ContentType)
+	}
+	w.Header().Set(HeaderReplayed, "true")
+	w.WriteHeader(resp.StatusCode)
+	w.Write(resp.Body)
+}
+
+// responseRecorder buffers the response written by the wrapped handler
+type responseRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rec *responseRecorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.wroteHeader {
+		return
+	}
+	rec.status = status
+	rec.wroteHeader = true
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	return rec.body.Write(b)
+}
@@ -0,0 +1,238 @@
+package idempotency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+)
+
+// countingHandler answers every request with status and counts the calls
+type countingHandler struct {
+	calls  atomic.Int32
+	status int
+	// release, when set, blocks the handler until it is closed
+	release chan struct{}
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := h.calls.Add(1)
+	if h.release != nil {
+		<-h.release
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(h.status)
+	w.Write([]byte(`{"call":` + string(rune('0'+n)) + `}`))
+}
+
+func newRequest(key, body string, principal *auth.Principal) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/api/invoke", strings.NewReader(body))
+	if key != "" {
+		r.Header.Set(HeaderKey, key)
+	}
+	if principal != nil {
+		r = r.WithContext(auth.NewContext(r.Context(), principal))
+	}
+	return r
+}
+
+func serve(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestMiddlewareReplaysResponses(t *testing.T) {
+	next := &countingHandler{status: http.StatusOK}
+	h := NewManager(NewMemoryStore(), time.Hour).Middleware(next)
+
+	first := serve(h, newRequest("key-1", `{"a":1}`, nil))
+	second := serve(h, newRequest("key-1", `{"a":1}`, nil))
+	if next.calls.Load() != 1 {
+		t.Fatalf("handler called %d times, want 1", next.calls.Load())
+	}
+	if second.Code != first.Code || second.Body.String() != first.Body.String() {
+		t.Errorf("replayed %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get(HeaderReplayed) != "true" || first.Header().Get(HeaderReplayed) != "" {
+		t.Errorf("only the replayed response should carry %s", HeaderReplayed)
+	}
+
+	serve(h, newRequest("", `{"a":1}`, nil))
+	serve(h, newRequest("", `{"a":1}`, nil))
+	if next.calls.Load() != 3 {
+		t.Errorf("requests without a key were deduplicated")
+	}
+}
+
+func TestMiddlewareRejectsKeyReuseWithDifferentRequest(t *testing.T) {
+	next := &countingHandler{status: http.StatusOK}
+	h := NewManager(NewMemoryStore(), time.Hour).Middleware(next)
+
+	serve(h, newRequest("key-1", `{"a":1}`, nil))
+	w := serve(h, newRequest("key-1", `{"a":2}`, nil))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+	if next.calls.Load() != 1 {
+		t.Errorf("handler called %d times, want 1", next.calls.Load())
+	}
+}
+
+func TestMiddlewareRecordedStatuses(t *testing.T) {
+	tests := []struct {
+		status    int
+		wantCalls int32
+	}{
+		// Submitted invokes must not be submitted again on retry
+		{status: http.StatusAccepted, wantCalls: 1},
+		{status: http.StatusBadRequest, wantCalls: 1},
+		{status: http.StatusTooManyRequests, wantCalls: 2},
+		{status: http.StatusInternalServerError, wantCalls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			next := &countingHandler{status: tt.status}
+			h := NewManager(NewMemoryStore(), time.Hour).Middleware(next)
+			serve(h, newRequest("key-1", `{}`, nil))
+			serve(h, newRequest("key-1", `{}`, nil))
+			if next.calls.Load() != tt.wantCalls {
+				t.Errorf("handler called %d times, want %d", next.calls.Load(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestMiddlewareScopesKeysByPrincipal(t *testing.T) {
+	next := &countingHandler{status: http.StatusOK}
+	h := NewManager(NewMemoryStore(), time.Hour).Middleware(next)
+
+	apiKey := &auth.Principal{Name: "alice", Method: "api_key"}
+	subject := &auth.Principal{Name: "alice", Method: "jwt"}
+	serve(h, newRequest("key-1", `{}`, apiKey))
+	w := serve(h, newRequest("key-1", `{}`, subject))
+	if next.calls.Load() != 2 || w.Header().Get(HeaderReplayed) != "" {
+		t.Fatal("a JWT subject replayed the response of an API key with the same name")
+	}
+	serve(h, newRequest("key-1", `{}`, apiKey))
+	if next.calls.Load() != 2 {
+		t.Errorf("the principal's own retry was not replayed")
+	}
+}
+
+func TestMiddlewareWaitsForInFlightDuplicates(t *testing.T) {
+	next := &countingHandler{status: http.StatusOK, release: make(chan struct{})}
+	h := NewManager(NewMemoryStore(), time.Hour).Middleware(next)
+
+	var wg sync.WaitGroup
+	codes := make([]int, 3)
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = serve(h, newRequest("key-1", `{}`, nil)).Code
+		}()
+	}
+	// Let the duplicates queue up behind the first request
+	time.Sleep(50 * time.Millisecond)
+	close(next.release)
+	wg.Wait()
+
+	if next.calls.Load() != 1 {
+		t.Fatalf("handler called %d times, want 1", next.calls.Load())
+	}
+	for i, code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("request %d status = %d, want %d", i, code, http.StatusOK)
+		}
+	}
+}
+
+// blockingStore blocks the lookups of one key until release is closed
+type blockingStore struct {
+	*MemoryStore
+	key     string
+	release chan struct{}
+}
+
+func (s *blockingStore) Get(key string) (*Response, error) {
+	if key == s.key {
+		<-s.release
+	}
+	return s.MemoryStore.Get(key)
+}
+
+func TestMiddlewareDoesNotHoldOtherKeysDuringStoreIO(t *testing.T) {
+	store := &blockingStore{MemoryStore: NewMemoryStore(), key: "/api/invoke:slow", release: make(chan struct{})}
+	h := NewManager(store, time.Hour).Middleware(&countingHandler{status: http.StatusOK})
+
+	slow := make(chan int)
+	go func() { slow <- serve(h, newRequest("slow", `{}`, nil)).Code }()
+	time.Sleep(50 * time.Millisecond)
+
+	done := make(chan int)
+	go func() { done <- serve(h, newRequest("fast", `{}`, nil)).Code }()
+	select {
+	case code := <-done:
+		if code != http.StatusOK {
+			t.Errorf("status = %d, want %d", code, http.StatusOK)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("request waited for the store lookup of another key")
+	}
+
+	close(store.release)
+	if code := <-slow; code != http.StatusOK {
+		t.Errorf("slow request status = %d, want %d", code, http.StatusOK)
+	}
+}
+
+func TestMiddlewareReleasesKeysOfPanickingHandlers(t *testing.T) {
+	calls := 0
+	h := NewManager(NewMemoryStore(), time.Hour).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls++; calls == 1 {
+			panic("handler failed")
+		}
+	}))
+	func() {
+		defer func() { recover() }()
+		serve(h, newRequest("key-1", `{}`, nil))
+	}()
+
+	done := make(chan int)
+	go func() { done <- serve(h, newRequest("key-1", `{}`, nil)).Code }()
+	select {
+	case code := <-done:
+		if code != http.StatusOK || calls != 2 {
+			t.Errorf("retry status = %d after %d calls, want 200 from a second call", code, calls)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the retry waited for the flight of the panicked request")
+	}
+}
+
+func TestMiddlewareRejectsLargeBodies(t *testing.T) {
+	next := &countingHandler{status: http.StatusOK}
+	h := NewManager(NewMemoryStore(), time.Hour).Middleware(next)
+
+	r := newRequest("key-1", strings.Repeat("x", 100), nil)
+	w := httptest.NewRecorder()
+	r.Body = http.MaxBytesReader(w, r.Body, 10)
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+	if next.calls.Load() != 0 {
+		t.Error("the handler was called with a truncated body")
+	}
+
+	w = serve(h, newRequest(strings.Repeat("k", maxKeyLength+1), `{}`, nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status for an overlong key = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,31 @@
+package idempotency
+
+import (
+	"time"
+)
+
+// Response is the recorded outcome of the first request sent with an idempotency key
+type Response struct {
+	// Fingerprint identifies the request payload the key was first used with
+	Fingerprint string `json:"fingerprint"`
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+	// ExpiresAt is the time after which the response is no longer replayed
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Expired reports whether the response is past its TTL at the given time
+func (r *Response) Expired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && now.After(r.ExpiresAt)
+}
+
+// Store persists recorded responses by idempotency key
+type Store interface {
+	// Get returns the response stored for key, or nil if there is none or it has expired
+	Get(key string) (*Response, error)
+	// Put stores the response for key, replacing any previous one
+	Put(key string, resp *Response) error
+	// Close releases the resources held by the store
+	Close() error
+}
@@ -0,0 +1,56 @@
+package idempotency
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStores(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"memory": func(t *testing.T) Store { return NewMemoryStore() },
+		"bolt": func(t *testing.T) Store {
+			s, err := NewBoltStore(filepath.Join(t.TempDir(), "idempotency.db"))
+			if err != nil {
+				t.Fatalf("NewBoltStore: %v", err)
+			}
+			return s
+		},
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+			defer s.Close()
+
+			if resp, err := s.Get("missing"); resp != nil || err != nil {
+				t.Fatalf("Get(missing) = %v, %v; want nil, nil", resp, err)
+			}
+
+			want := &Response{
+				Fingerprint: "abc",
+				StatusCode:  202,
+				ContentType: "application/json",
+				Body:        []byte(`{"status":"submitted"}`),
+				ExpiresAt:   time.Now().Add(time.Hour),
+			}
+			if err := s.Put("key", want); err != nil {
+				t.Fatalf("Put: %v", err)
+			}
+			got, err := s.Get("key")
+			if err != nil || got == nil {
+				t.Fatalf("Get(key) = %v, %v", got, err)
+			}
+			if got.Fingerprint != want.Fingerprint || got.StatusCode != want.StatusCode ||
+				got.ContentType != want.ContentType || string(got.Body) != string(want.Body) {
+				t.Errorf("Get(key) = %+v, want %+v", got, want)
+			}
+
+			if err := s.Put("expired", &Response{Fingerprint: "abc", ExpiresAt: time.Now().Add(-time.Second)}); err != nil {
+				t.Fatalf("Put: %v", err)
+			}
+			if resp, err := s.Get("expired"); resp != nil || err != nil {
+				t.Errorf("Get(expired) = %v, %v; want nil, nil", resp, err)
+			}
+		})
+	}
+}
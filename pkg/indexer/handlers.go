@@ -0,0 +1,289 @@
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/response"
+)
+
+// SearchResponse is a page of indexed transactions
+type SearchResponse struct {
+	Transactions []fabric.Transaction `json:"transactions"`
+	// NextCursor fetches the next page; it is omitted on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// HistoryResponse is a page of the modifications of a key
+type HistoryResponse struct {
+	Chaincode string            `json:"chaincode" example:"basic"`
+	Key       string            `json:"key" example:"asset1"`
+	History   []KeyModification `json:"history"`
+	// NextCursor fetches the next page; it is omitted on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// StateResponse is the value of a key at a point in time with the
+// modification that set it; is_delete means the key did not exist then
+type StateResponse struct {
+	Key string `json:"key" example:"asset1"`
+	KeyModification
+}
+
+// SearchHandler godoc
+// @Summary Search indexed transactions
+// @Description Returns the transactions of the local index matching every given filter, newest first. Callers need a scope that allows evaluating the given chaincode, or any chaincode of the channel when no chaincode is given. Transactions calling functions outside the caller's scopes are left out, so a page may hold fewer than limit transactions.
+// @Tags index
+// @Produce json
+// @Param chaincode query string false "Chaincode name"
+// @Param function query string false "Function name"
+// @Param creator_mspid query string false "MSP ID of the creator"
+// @Param creator_subject query string false "Certificate subject of the creator"
+// @Param validation_code query string false "Validation code, e.g. VALID or MVCC_READ_CONFLICT"
+// @Param from query string false "Earliest transaction timestamp (RFC 3339), inclusive"
+// @Param to query string false "Latest transaction timestamp (RFC 3339), exclusive"
+// @Param order query string false "asc for oldest first" Enums(asc, desc)
+// @Param limit query int false "Page size, at most 500" default(50)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} SearchResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/index/transactions [get]
+func (ix *Indexer) SearchHandler(w http.ResponseWriter, r *http.Request) {
+	q, err := parseQuery(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	principal := auth.FromContext(r.Context())
+	channel := ix.fabricClient.ChannelName()
+	if principal != nil {
+		if q.Chaincode != "" && !principal.AllowsChaincode(channel, q.Chaincode, auth.OperationEvaluate) {
+			response.Error(w, http.StatusForbidden, fmt.Sprintf("%s is not allowed to read the transactions of chaincode %s", principal.Name, q.Chaincode))
+			return
+		}
+		if q.Chaincode != "" && q.Function != "" && !principal.Allows(channel, q.Chaincode, q.Function, auth.OperationEvaluate) {
+			response.Error(w, http.StatusForbidden, fmt.Sprintf("%s is not allowed to read the transactions of function %s of chaincode %s", principal.Name, q.Function, q.Chaincode))
+			return
+		}
+		if q.Chaincode == "" && !principal.AllowsChannel(channel, auth.OperationEvaluate) {
+			response.Error(w, http.StatusForbidden, fmt.Sprintf("%s is not allowed to read the transactions of channel %s", principal.Name, channel))
+			return
+		}
+	}
+
+	transactions, next, err := ix.Search(q)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			response.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		slog.ErrorContext(r.Context(), "failed to search the index", "error", err)
+		response.Error(w, http.StatusInternalServerError, "failed to search the index")
+		return
+	}
+	if principal != nil {
+		// Scopes may allow only some functions of a chaincode, and the
+		// arguments of the others must not be revealed
+		allowed := transactions[:0]
+		for _, tx := range transactions {
+			if principal.Allows(channel, tx.Chaincode, tx.Function, auth.OperationEvaluate) {
+				allowed = append(allowed, tx)
+			}
+		}
+		transactions = allowed
+	}
+	response.JSON(w, http.StatusOK, SearchResponse{Transactions: transactions, NextCursor: next})
+}
+
+// StatusHandler godoc
+// @Summary Get the progress of the indexer
+// @Description Returns the next block to index and the number of indexed transactions
+// @Tags index
+// @Produce json
+// @Success 200 {object} Status
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/index/status [get]
+func (ix *Indexer) StatusHandler(w http.ResponseWriter, r *http.Request) {
+	status, err := ix.Status()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to read the index status", "error", err)
+		response.Error(w, http.StatusInternalServerError, "failed to read the index status")
+		return
+	}
+	response.JSON(w, http.StatusOK, status)
+}
+
+// HistoryHandler godoc
+// @Summary Get the history of a key
+// @Description Returns the writes and deletes of a key of the chaincode's state by valid transactions, newest first, with the writing transaction and its creator. The history is built from the write sets of the indexed blocks and does not need GetHistoryForKey support of the chaincode. Values are base64 encoded.
+// @Tags index
+// @Produce json
+// @Param cc path string true "Chaincode name"
+// @Param key path string true "Key, URL encoded"
+// @Param order query string false "asc for oldest first" Enums(asc, desc)
+// @Param limit query int false "Page size, at most 500" default(50)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} HistoryResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/chaincodes/{cc}/keys/{key}/history [get]
+func (ix *Indexer) HistoryHandler(w http.ResponseWriter, r *http.Request) {
+	chaincode, key, ok := ix.keyParams(w, r)
+	if !ok {
+		return
+	}
+	q, err := parseQuery(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	history, next, err := ix.History(chaincode, key, HistoryQuery{Cursor: q.Cursor, Limit: q.Limit, Ascending: q.Ascending})
+	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			response.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		slog.ErrorContext(r.Context(), "failed to read key history", "error", err)
+		response.Error(w, http.StatusInternalServerError, "failed to read key history")
+		return
+	}
+	response.JSON(w, http.StatusOK, HistoryResponse{Chaincode: chaincode, Key: key, History: history, NextCursor: next})
+}
+
+// StateHandler godoc
+// @Summary Get the value of a key at a point in time
+// @Description Returns the value of a key of the chaincode's state after block at_block was committed, or as of at_time, with the transaction that set it. Without either, the value after the last indexed block is returned. is_delete is true when the key had been deleted. at_time compares the timestamps the clients set on their transactions. A block or time after the latest indexed transaction answers 409, and one before the first indexed block 400.
+// @Tags index
+// @Produce json
+// @Param cc path string true "Chaincode name"
+// @Param key path string true "Key, URL encoded"
+// @Param at_block query int false "Block number"
+// @Param at_time query string false "Timestamp (RFC 3339)"
+// @Success 200 {object} StateResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/chaincodes/{cc}/keys/{key} [get]
+func (ix *Indexer) StateHandler(w http.ResponseWriter, r *http.Request) {
+	chaincode, key, ok := ix.keyParams(w, r)
+	if !ok {
+		return
+	}
+	atBlock, atTime := r.URL.Query().Get("at_block"), r.URL.Query().Get("at_time")
+	if atBlock != "" && atTime != "" {
+		response.Error(w, http.StatusBadRequest, "at_block and at_time are mutually exclusive")
+		return
+	}
+
+	var modification *KeyModification
+	var err error
+	switch {
+	case atTime != "":
+		at, parseErr := time.Parse(time.RFC3339Nano, atTime)
+		if parseErr != nil {
+			response.Error(w, http.StatusBadRequest, "at_time must be an RFC 3339 timestamp")
+			return
+		}
+		modification, err = ix.StateAt(chaincode, key, at)
+	case atBlock != "":
+		block, parseErr := strconv.ParseUint(atBlock, 10, 64)
+		if parseErr != nil {
+			response.Error(w, http.StatusBadRequest, "at_block must be a block number")
+			return
+		}
+		modification, err = ix.StateAtBlock(chaincode, key, block)
+	default:
+		modification, err = ix.State(chaincode, key)
+	}
+	var notIndexed *NotIndexedError
+	var beforeIndex *BeforeIndexError
+	switch {
+	case errors.As(err, &notIndexed):
+		response.Error(w, http.StatusConflict, err.Error())
+		return
+	case errors.As(err, &beforeIndex):
+		response.Error(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		slog.ErrorContext(r.Context(), "failed to read key state", "error", err)
+		response.Error(w, http.StatusInternalServerError, "failed to read key state")
+		return
+	case modification == nil:
+		response.Error(w, http.StatusNotFound, fmt.Sprintf("key %s of chaincode %s had not been written", key, chaincode))
+		return
+	}
+	response.JSON(w, http.StatusOK, StateResponse{Key: key, KeyModification: *modification})
+}
+
+// keyParams reads the chaincode and the key from the path and checks that
+// the caller may read the chaincode's state
+func (ix *Indexer) keyParams(w http.ResponseWriter, r *http.Request) (chaincode, key string, ok bool) {
+	chaincode = chi.URLParam(r, "cc")
+	// Keys may contain slashes and NUL bytes, so they are URL encoded
+	key, err := url.PathUnescape(chi.URLParam(r, "key"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid key encoding")
+		return "", "", false
+	}
+	if principal := auth.FromContext(r.Context()); principal != nil && !principal.AllowsChaincode(ix.fabricClient.ChannelName(), chaincode, auth.OperationEvaluate) {
+		response.Error(w, http.StatusForbidden, fmt.Sprintf("%s is not allowed to read the state of chaincode %s", principal.Name, chaincode))
+		return "", "", false
+	}
+	return chaincode, key, true
+}
+
+// parseQuery reads the filters of a search from the query string
+func parseQuery(r *http.Request) (Query, error) {
+	values := r.URL.Query()
+	q := Query{
+		Chaincode:      values.Get("chaincode"),
+		Function:       values.Get("function"),
+		CreatorMSP:     values.Get("creator_mspid"),
+		CreatorSubject: values.Get("creator_subject"),
+		ValidationCode: values.Get("validation_code"),
+		Cursor:         values.Get("cursor"),
+	}
+	for name, t := range map[string]*time.Time{"from": &q.From, "to": &q.To} {
+		if value := values.Get(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return q, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+			}
+			*t = parsed
+		}
+	}
+	switch values.Get("order") {
+	case "", "desc":
+	case "asc":
+		q.Ascending = true
+	default:
+		return q, errors.New("order must be asc or desc")
+	}
+	if value := values.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return q, errors.New("limit must be a positive integer")
+		}
+		q.Limit = limit
+	}
+	return q, nil
+}
@@ -0,0 +1,119 @@
+package indexer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+)
+
+// newTestRouter serves the handlers of an indexer of the test blocks, whose
+// Fabric client cannot reach its peer
+func newTestRouter(t *testing.T) (*Indexer, http.Handler) {
+	t.Helper()
+	fabricClient, err := fabric.NewFabricClient(&fabric.ClientConfig{
+		ChannelName: "mychannel",
+		Peers:       []fabric.PeerConfig{{Endpoint: "127.0.0.1:1", TLSCertPath: t.TempDir() + "/missing-ca.pem"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { fabricClient.Close() })
+	store := newTestStore(t)
+	putBlocks(t, store)
+	ix := New(store, fabricClient, 0)
+
+	r := chi.NewRouter()
+	r.Get("/api/index/transactions", ix.SearchHandler)
+	r.Get("/api/index/status", ix.StatusHandler)
+	r.Get("/api/chaincodes/{cc}/keys/{key}", ix.StateHandler)
+	r.Get("/api/chaincodes/{cc}/keys/{key}/history", ix.HistoryHandler)
+	return ix, r
+}
+
+// get requests path as principal, or without authentication when principal is nil
+func get(r http.Handler, path string, principal *auth.Principal) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if principal != nil {
+		req = req.WithContext(auth.NewContext(req.Context(), principal))
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestSearchHandler(t *testing.T) {
+	_, r := newTestRouter(t)
+	basicReader := &auth.Principal{Name: "reader", Scopes: []auth.Scope{{
+		Chaincodes: []string{"basic"},
+		Operations: []auth.Operation{auth.OperationEvaluate},
+	}}}
+	createReader := &auth.Principal{Name: "creator", Scopes: []auth.Scope{{
+		Chaincodes: []string{"basic"},
+		Functions:  []string{"CreateAsset"},
+		Operations: []auth.Operation{auth.OperationEvaluate},
+	}}}
+	tests := []struct {
+		name      string
+		query     string
+		principal *auth.Principal
+		status    int
+		want      string
+	}{
+		{name: "filters", query: "?chaincode=basic&order=asc&limit=2", status: http.StatusOK, want: "a,c"},
+		{name: "time range", query: "?from=2024-05-01T12:01:00Z&to=2024-05-01T12:03:00Z", status: http.StatusOK, want: "c,b"},
+		{name: "invalid timestamp", query: "?from=yesterday", status: http.StatusBadRequest},
+		{name: "invalid order", query: "?order=random", status: http.StatusBadRequest},
+		{name: "invalid limit", query: "?limit=0", status: http.StatusBadRequest},
+		{name: "invalid cursor", query: "?cursor=AAAA", status: http.StatusBadRequest},
+		{name: "chaincode in scope", query: "?chaincode=basic", principal: basicReader, status: http.StatusOK, want: "e,c,a"},
+		{name: "chaincode out of scope", query: "?chaincode=basic2", principal: basicReader, status: http.StatusForbidden},
+		{name: "channel out of scope", query: "", principal: basicReader, status: http.StatusForbidden},
+		{name: "functions out of scope left out", query: "?chaincode=basic", principal: createReader, status: http.StatusOK, want: "e,a"},
+		{name: "function in scope", query: "?chaincode=basic&function=CreateAsset", principal: createReader, status: http.StatusOK, want: "e,a"},
+		{name: "function out of scope", query: "?chaincode=basic&function=TransferAsset", principal: createReader, status: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(r, "/api/index/transactions"+tt.query, tt.principal)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var resp SearchResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if got := ids(resp.Transactions); got != tt.want {
+				t.Errorf("transactions = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusHandler(t *testing.T) {
+	_, r := newTestRouter(t)
+	w := get(r, "/api/index/status", nil)
+	var status Status
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("status = %d %s", w.Code, w.Body)
+	}
+	if status.Height != 4 || status.Transactions != 5 || status.Error != "" {
+		t.Errorf("Status = %+v, want height 4 and 5 transactions", status)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for retry, want := range map[int]string{0: "1s", 1: "2s", 5: "32s", 6: "1m0s", 20: "1m0s"} {
+		if got := backoff(retry).String(); got != want {
+			t.Errorf("backoff(%d) = %s, want %s", retry, got, want)
+		}
+	}
+}
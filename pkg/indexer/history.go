@@ -0,0 +1,259 @@
+package indexer
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+)
+
+// valid is the validation code of transactions whose writes took effect
+var valid = fabric.ValidationCodeName(0)
+
+// KeyModification is a write of a key by a valid transaction
+type KeyModification struct {
+	TxID        string `json:"tx_id"`
+	BlockNumber uint64 `json:"block_number"`
+	// TxIndex is the position of the transaction in its block
+	TxIndex        int       `json:"tx_index"`
+	Timestamp      time.Time `json:"timestamp"`
+	CreatorMSP     string    `json:"creator_mspid"`
+	CreatorSubject string    `json:"creator_subject,omitempty"`
+	// Chaincode and Function are the call that wrote the key, which belongs
+	// to another chaincode for chaincode-to-chaincode calls
+	Chaincode string `json:"chaincode,omitempty"`
+	Function  string `json:"function,omitempty"`
+	IsDelete  bool   `json:"is_delete"`
+	Value     []byte `json:"value,omitempty"`
+}
+
+// HistoryQuery selects a page of the history of a key
+type HistoryQuery struct {
+	// Cursor is the next_cursor of the previous page
+	Cursor string
+	Limit  int
+	// Ascending returns the oldest modifications first
+	Ascending bool
+}
+
+// putHistory records the public writes of a valid transaction. Writes of
+// invalid transactions did not change the state, and private data writes
+// are only hashed in blocks.
+func putHistory(tx *bolt.Tx, pos []byte, transaction *fabric.Transaction) error {
+	if transaction.ValidationCode != valid {
+		return nil
+	}
+	for _, set := range transaction.ReadWriteSets {
+		for _, write := range set.Writes {
+			data, err := json.Marshal(KeyModification{
+				TxID:           transaction.ID,
+				BlockNumber:    transaction.BlockNumber,
+				TxIndex:        transaction.Index,
+				Timestamp:      transaction.Timestamp,
+				CreatorMSP:     transaction.CreatorMSP,
+				CreatorSubject: transaction.CreatorSubject,
+				Chaincode:      transaction.Chaincode,
+				Function:       transaction.Function,
+				IsDelete:       write.IsDelete,
+				Value:          write.Value,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to encode write of %s by %s: %w", write.Key, transaction.ID, err)
+			}
+			if err := tx.Bucket(historyBucket).Put(append(historyPrefix(set.Namespace, write.Key), pos...), data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// History returns the modifications of a key in ledger order, newest first
+// unless the query is ascending, and the cursor of the next page
+func (s *Store) History(namespace, key string, q HistoryQuery) ([]KeyModification, string, error) {
+	plan := prefixPlan(historyBucket, historyPrefix(namespace, key))
+	var start []byte
+	if q.Cursor != "" {
+		cursor, err := decodeCursor(q.Cursor)
+		if err != nil || !plan.contains(cursor) {
+			return nil, "", ErrInvalidCursor
+		}
+		start = cursor
+	}
+
+	modifications := []KeyModification{}
+	var next string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(historyBucket).Cursor()
+		for k := plan.first(c, start, q.Ascending); k != nil && plan.contains(k); k = plan.step(c, q.Ascending) {
+			var modification KeyModification
+			if err := json.Unmarshal(tx.Bucket(historyBucket).Get(k), &modification); err != nil {
+				return fmt.Errorf("failed to decode modification at %x: %w", k[len(k)-positionSize:], err)
+			}
+			modifications = append(modifications, modification)
+			if len(modifications) == q.Limit {
+				next = encodeCursor(k)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read key history: %w", err)
+	}
+	return modifications, next, nil
+}
+
+// LastModification returns the last modification of a key committed up to
+// and including block atBlock, or nil if the key was not written by then
+func (s *Store) LastModification(namespace, key string, atBlock uint64) (*KeyModification, error) {
+	return s.lastModification(namespace, key, position(atBlock+1, 0), func(*KeyModification) bool { return true })
+}
+
+// LastModificationAt returns the last modification of a key, in ledger order,
+// by a transaction whose timestamp is not after at, or nil if there is none
+func (s *Store) LastModificationAt(namespace, key string, at time.Time) (*KeyModification, error) {
+	return s.lastModification(namespace, key, nil, func(m *KeyModification) bool { return !m.Timestamp.After(at) })
+}
+
+// lastModification scans the history of a key backwards from before the
+// position and returns the first modification accepted by match
+func (s *Store) lastModification(namespace, key string, before []byte, match func(*KeyModification) bool) (*KeyModification, error) {
+	plan := prefixPlan(historyBucket, historyPrefix(namespace, key))
+	if before != nil {
+		plan.upper = append(historyPrefix(namespace, key), before...)
+	}
+
+	var found *KeyModification
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(historyBucket).Cursor()
+		for k := plan.first(c, nil, false); k != nil && plan.contains(k); k, _ = c.Prev() {
+			var modification KeyModification
+			if err := json.Unmarshal(tx.Bucket(historyBucket).Get(k), &modification); err != nil {
+				return fmt.Errorf("failed to decode modification at %x: %w", k[len(k)-positionSize:], err)
+			}
+			if match(&modification) {
+				found = &modification
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key history: %w", err)
+	}
+	return found, nil
+}
+
+// History returns a page of the modifications of a key and the cursor of the
+// next page
+func (ix *Indexer) History(namespace, key string, q HistoryQuery) ([]KeyModification, string, error) {
+	if q.Limit <= 0 {
+		q.Limit = DefaultLimit
+	}
+	if q.Limit > MaxLimit {
+		q.Limit = MaxLimit
+	}
+	return ix.store.History(namespace, key, q)
+}
+
+// State returns the modification that set the value of a key as of the last
+// indexed block
+func (ix *Indexer) State(namespace, key string) (*KeyModification, error) {
+	return ix.store.lastModification(namespace, key, nil, func(*KeyModification) bool { return true })
+}
+
+// StateAtBlock returns the modification that set the value of a key as of
+// block atBlock. The block must have been indexed.
+func (ix *Indexer) StateAtBlock(namespace, key string, atBlock uint64) (*KeyModification, error) {
+	e, ok, err := ix.extent()
+	if err != nil {
+		return nil, err
+	}
+	if !ok || atBlock >= e.next {
+		return nil, &NotIndexedError{Block: atBlock, Height: e.next}
+	}
+	if atBlock < e.first {
+		return nil, &BeforeIndexError{Block: atBlock, First: e.first}
+	}
+	return ix.store.LastModification(namespace, key, atBlock)
+}
+
+// StateAt returns the modification that set the value of a key as of the
+// time at. The time must not be after the latest indexed transaction, since
+// later blocks may have changed the key, nor before the first indexed block
+// when indexing did not start at the genesis block.
+func (ix *Indexer) StateAt(namespace, key string, at time.Time) (*KeyModification, error) {
+	e, ok, err := ix.extent()
+	if err != nil {
+		return nil, err
+	}
+	if !ok || at.After(e.lastTime) {
+		return nil, &NotIndexedError{Time: at, LastTime: e.lastTime, Height: e.next}
+	}
+	if e.first > 0 && at.Before(e.firstTime) {
+		return nil, &BeforeIndexError{Time: at, First: e.first}
+	}
+	return ix.store.LastModificationAt(namespace, key, at)
+}
+
+// extent returns the indexed part of the ledger. Indexes written before the
+// first block was recorded start at the configured start block.
+func (ix *Indexer) extent() (extent, bool, error) {
+	e, ok, err := ix.store.extent()
+	if ok && e.first == 0 && e.firstTime.IsZero() {
+		e.first = ix.startBlock
+	}
+	return e, ok, err
+}
+
+// NotIndexedError is returned for state lookups at blocks the indexer has
+// not reached yet, or at times after the latest indexed transaction
+type NotIndexedError struct {
+	Block uint64
+	// Time is set for lookups by time, with the timestamp of the latest
+	// indexed transaction
+	Time     time.Time
+	LastTime time.Time
+	Height   uint64
+}
+
+func (e *NotIndexedError) Error() string {
+	if !e.Time.IsZero() {
+		if e.LastTime.IsZero() {
+			return fmt.Sprintf("%s has not been indexed yet, no transactions have been indexed", e.Time.Format(time.RFC3339Nano))
+		}
+		return fmt.Sprintf("%s has not been indexed yet, the latest indexed transaction is from %s and the index height is %d",
+			e.Time.Format(time.RFC3339Nano), e.LastTime.Format(time.RFC3339Nano), e.Height)
+	}
+	return fmt.Sprintf("block %d has not been indexed yet, the index height is %d", e.Block, e.Height)
+}
+
+// BeforeIndexError is returned for state lookups before the first indexed
+// block, whose history the index does not hold
+type BeforeIndexError struct {
+	Block uint64
+	// Time is set for lookups by time
+	Time  time.Time
+	First uint64
+}
+
+func (e *BeforeIndexError) Error() string {
+	if !e.Time.IsZero() {
+		return fmt.Sprintf("%s is before the first indexed block %d", e.Time.Format(time.RFC3339Nano), e.First)
+	}
+	return fmt.Sprintf("block %d is before the first indexed block %d", e.Block, e.First)
+}
+
+// historyPrefix encodes a namespace and a key with their lengths, since
+// composite keys contain NUL bytes
+func historyPrefix(namespace, key string) []byte {
+	prefix := binary.BigEndian.AppendUint32(nil, uint32(len(namespace)))
+	prefix = append(prefix, namespace...)
+	prefix = binary.BigEndian.AppendUint32(prefix, uint32(len(key)))
+	return append(prefix, key...)
+}
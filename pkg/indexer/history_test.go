@@ -0,0 +1,258 @@
+package indexer
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+)
+
+// putHistoryBlocks indexes blocks 4 to 6, which write asset1 twice, delete
+// it, and write keys that share a prefix with it
+func putHistoryBlocks(t *testing.T, store *Store) {
+	t.Helper()
+	tx := func(id string, blockNumber uint64, index, minute int, code string, sets ...fabric.ReadWriteSet) fabric.Transaction {
+		return fabric.Transaction{
+			ID:             id,
+			BlockNumber:    blockNumber,
+			Index:          index,
+			Timestamp:      t0.Add(time.Duration(minute) * time.Minute),
+			ValidationCode: code,
+			CreatorMSP:     "Org1MSP",
+			Chaincode:      "basic",
+			Function:       "UpdateAsset",
+			ReadWriteSets:  sets,
+		}
+	}
+	write := func(namespace, key, value string) fabric.ReadWriteSet {
+		return fabric.ReadWriteSet{Namespace: namespace, Writes: []fabric.KVWrite{{Key: key, Value: []byte(value)}}}
+	}
+	blocks := []*fabric.Block{
+		{Number: 4, Transactions: []fabric.Transaction{
+			tx("h1", 4, 0, 10, "VALID", write("basic", "asset1", "v1"), write("basic2", "asset1", "other"), write("basic", "a/b", "slash")),
+		}},
+		{Number: 5, Transactions: []fabric.Transaction{
+			tx("h2", 5, 0, 11, "MVCC_READ_CONFLICT", write("basic", "asset1", "conflict")),
+			tx("h3", 5, 1, 12, "VALID", write("basic", "asset1", "v2")),
+		}},
+		{Number: 6, Transactions: []fabric.Transaction{
+			tx("h4", 6, 0, 13, "VALID", fabric.ReadWriteSet{Namespace: "basic", Writes: []fabric.KVWrite{{Key: "asset1", IsDelete: true}}}),
+			tx("h5", 6, 1, 14, "VALID", write("basic", "asset10", "v1")),
+		}},
+	}
+	for _, block := range blocks {
+		if err := store.PutBlock(block); err != nil {
+			t.Fatalf("PutBlock() error = %v", err)
+		}
+	}
+}
+
+func txIDs(modifications []KeyModification) string {
+	var ids []string
+	for _, m := range modifications {
+		ids = append(ids, m.TxID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestStoreHistory(t *testing.T) {
+	store := newTestStore(t)
+	putHistoryBlocks(t, store)
+
+	tests := []struct {
+		name      string
+		namespace string
+		key       string
+		query     HistoryQuery
+		want      string
+	}{
+		{name: "newest first", namespace: "basic", key: "asset1", want: "h4,h3,h1"},
+		{name: "ascending", namespace: "basic", key: "asset1", query: HistoryQuery{Ascending: true}, want: "h1,h3,h4"},
+		{name: "other namespace", namespace: "basic2", key: "asset1", want: "h1"},
+		{name: "key sharing a prefix", namespace: "basic", key: "asset10", want: "h5"},
+		{name: "unknown key", namespace: "basic", key: "asset", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history, next, err := store.History(tt.namespace, tt.key, tt.query)
+			if err != nil {
+				t.Fatalf("History() error = %v", err)
+			}
+			if got := txIDs(history); got != tt.want || next != "" {
+				t.Errorf("History() = %s, cursor %q; want %s", got, next, tt.want)
+			}
+		})
+	}
+
+	history, _, _ := store.History("basic", "asset1", HistoryQuery{})
+	if m := history[0]; !m.IsDelete || m.BlockNumber != 6 || m.CreatorMSP != "Org1MSP" || m.Function != "UpdateAsset" {
+		t.Errorf("delete = %+v", m)
+	}
+	if m := history[1]; m.IsDelete || string(m.Value) != "v2" || m.TxIndex != 1 || !m.Timestamp.Equal(t0.Add(12*time.Minute)) {
+		t.Errorf("write = %+v", m)
+	}
+}
+
+func TestStoreHistoryPages(t *testing.T) {
+	store := newTestStore(t)
+	putHistoryBlocks(t, store)
+
+	q := HistoryQuery{Limit: 2}
+	page, next, err := store.History("basic", "asset1", q)
+	if err != nil || txIDs(page) != "h4,h3" || next == "" {
+		t.Fatalf("first page = %s, %q, %v", txIDs(page), next, err)
+	}
+	q.Cursor = next
+	if page, next, err = store.History("basic", "asset1", q); err != nil || txIDs(page) != "h1" || next != "" {
+		t.Errorf("second page = %s, %q, %v", txIDs(page), next, err)
+	}
+
+	// The cursor of another key is rejected
+	if _, _, err := store.History("basic", "asset10", q); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("History() with the cursor of another key error = %v, want %v", err, ErrInvalidCursor)
+	}
+}
+
+func TestStoreLastModification(t *testing.T) {
+	store := newTestStore(t)
+	putHistoryBlocks(t, store)
+
+	for _, tt := range []struct {
+		block uint64
+		want  string
+	}{
+		{3, ""},
+		{4, "h1"},
+		{5, "h3"},
+		{6, "h4"},
+	} {
+		m, err := store.LastModification("basic", "asset1", tt.block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := modificationID(m); got != tt.want {
+			t.Errorf("LastModification() at block %d = %s, want %s", tt.block, got, tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		at   time.Time
+		want string
+	}{
+		{t0.Add(9 * time.Minute), ""},
+		{t0.Add(10 * time.Minute), "h1"},
+		{t0.Add(11 * time.Minute), "h1"},
+		{t0.Add(time.Hour), "h4"},
+	} {
+		m, err := store.LastModificationAt("basic", "asset1", tt.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := modificationID(m); got != tt.want {
+			t.Errorf("LastModificationAt(%s) = %s, want %s", tt.at, got, tt.want)
+		}
+	}
+}
+
+func modificationID(m *KeyModification) string {
+	if m == nil {
+		return ""
+	}
+	return m.TxID
+}
+
+func TestStateAtBlock(t *testing.T) {
+	ix := New(newTestStore(t), nil, 0)
+	var notIndexed *NotIndexedError
+	if _, err := ix.StateAtBlock("basic", "asset1", 0); !errors.As(err, &notIndexed) {
+		t.Errorf("StateAtBlock() of an empty index error = %v, want a NotIndexedError", err)
+	}
+
+	if _, err := ix.StateAt("basic", "asset1", t0); !errors.As(err, &notIndexed) {
+		t.Errorf("StateAt() of an empty index error = %v, want a NotIndexedError", err)
+	}
+
+	putHistoryBlocks(t, ix.store)
+	var beforeIndex *BeforeIndexError
+	if _, err := ix.StateAtBlock("basic", "asset1", 3); !errors.As(err, &beforeIndex) || beforeIndex.First != 4 {
+		t.Errorf("StateAtBlock() before the index error = %v, want a BeforeIndexError from block 4", err)
+	}
+	if _, err := ix.StateAt("basic", "asset1", t0.Add(9*time.Minute)); !errors.As(err, &beforeIndex) {
+		t.Errorf("StateAt() before the index error = %v, want a BeforeIndexError", err)
+	}
+	if _, err := ix.StateAt("basic", "asset1", t0.Add(15*time.Minute)); !errors.As(err, &notIndexed) || !notIndexed.LastTime.Equal(t0.Add(14*time.Minute)) {
+		t.Errorf("StateAt() past the index error = %v, want a NotIndexedError", err)
+	}
+	if m, err := ix.StateAt("basic", "asset1", t0.Add(14*time.Minute)); err != nil || modificationID(m) != "h4" {
+		t.Errorf("StateAt() of the latest transaction = %+v, %v; want h4", m, err)
+	}
+	if _, err := ix.StateAtBlock("basic", "asset1", 7); !errors.As(err, &notIndexed) || notIndexed.Height != 7 {
+		t.Errorf("StateAtBlock() past the index error = %v, want a NotIndexedError at height 7", err)
+	}
+	if m, err := ix.StateAtBlock("basic", "asset1", 5); err != nil || modificationID(m) != "h3" {
+		t.Errorf("StateAtBlock() = %+v, %v; want h3", m, err)
+	}
+	if m, err := ix.State("basic", "asset1"); err != nil || !m.IsDelete {
+		t.Errorf("State() = %+v, %v; want the delete", m, err)
+	}
+}
+
+func TestKeyHandlers(t *testing.T) {
+	ix, r := newTestRouter(t)
+	putHistoryBlocks(t, ix.store)
+	otherReader := &auth.Principal{Name: "reader", Scopes: []auth.Scope{{Chaincodes: []string{"basic2"}}}}
+
+	tests := []struct {
+		name      string
+		path      string
+		principal *auth.Principal
+		status    int
+		want      string
+	}{
+		{name: "history", path: "/api/chaincodes/basic/keys/asset1/history?limit=2", status: http.StatusOK, want: "h4,h3"},
+		{name: "history of an encoded key", path: "/api/chaincodes/basic/keys/a%2Fb/history", status: http.StatusOK, want: "h1"},
+		{name: "history out of scope", path: "/api/chaincodes/basic/keys/asset1/history", principal: otherReader, status: http.StatusForbidden},
+		{name: "history with an invalid cursor", path: "/api/chaincodes/basic/keys/asset1/history?cursor=AAAA", status: http.StatusBadRequest},
+		{name: "state", path: "/api/chaincodes/basic/keys/asset1", status: http.StatusOK, want: "h4"},
+		{name: "state at a block", path: "/api/chaincodes/basic/keys/asset1?at_block=4", status: http.StatusOK, want: "h1"},
+		{name: "state at a time", path: "/api/chaincodes/basic/keys/asset1?at_time=2024-05-01T12:12:30Z", status: http.StatusOK, want: "h3"},
+		{name: "state at a block not indexed", path: "/api/chaincodes/basic/keys/asset1?at_block=9", status: http.StatusConflict},
+		{name: "state at a time not indexed", path: "/api/chaincodes/basic/keys/asset1?at_time=2024-05-01T13:00:00Z", status: http.StatusConflict},
+		{name: "state before the index", path: "/api/chaincodes/basic/keys/asset1?at_block=0", status: http.StatusBadRequest},
+		{name: "state of an unknown key", path: "/api/chaincodes/basic/keys/asset2", status: http.StatusNotFound},
+		{name: "state at a block and a time", path: "/api/chaincodes/basic/keys/asset1?at_block=4&at_time=2024-05-01T12:00:00Z", status: http.StatusBadRequest},
+		{name: "state at an invalid block", path: "/api/chaincodes/basic/keys/asset1?at_block=latest", status: http.StatusBadRequest},
+		{name: "state at an invalid time", path: "/api/chaincodes/basic/keys/asset1?at_time=yesterday", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(r, tt.path, tt.principal)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			// History pages list modifications, states are a single one
+			var resp struct {
+				History []KeyModification `json:"history"`
+				TxID    string            `json:"tx_id"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			got := resp.TxID
+			if resp.History != nil {
+				got = txIDs(resp.History)
+			}
+			if got != tt.want {
+				t.Errorf("response = %s, want %s", w.Body, tt.want)
+			}
+		})
+	}
+}
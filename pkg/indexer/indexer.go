@@ -0,0 +1,190 @@
+// Package indexer follows the blocks of the channel into a local database so
+// that transactions can be searched by chaincode, function, creator,
+// validation code and time, which the ledger itself only answers by
+// transaction ID or block number.
+package indexer
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+)
+
+const (
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+)
+
+// Status describes the progress of the indexer
+type Status struct {
+	// Height is the number of the next block to index
+	Height       uint64 `json:"height"`
+	Transactions int    `json:"transactions"`
+	// LastIndexedAt is when the last block was indexed
+	LastIndexedAt *time.Time `json:"last_indexed_at,omitempty"`
+	// Error is why the block stream was interrupted, until it is resumed
+	Error string `json:"error,omitempty"`
+}
+
+// Indexer indexes the blocks of the channel from the block it stopped at
+type Indexer struct {
+	store        *Store
+	fabricClient *fabric.FabricClient
+	startBlock   uint64
+
+	mu            sync.Mutex
+	lastIndexedAt time.Time
+	lastErr       error
+}
+
+// New creates an indexer that writes to store. Indexing starts at startBlock
+// when the store is empty and at the block after the last indexed one
+// otherwise.
+func New(store *Store, fabricClient *fabric.FabricClient, startBlock uint64) *Indexer {
+	return &Indexer{
+		store:        store,
+		fabricClient: fabricClient,
+		startBlock:   startBlock,
+	}
+}
+
+// Run indexes blocks until ctx is cancelled, reconnecting with backoff
+// whenever the block stream ends
+func (ix *Indexer) Run(ctx context.Context) {
+	for retry := 0; ; {
+		err := ix.stream(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			retry = 0
+			err = errors.New("block stream ended")
+		}
+		ix.setError(err)
+		wait := backoff(retry)
+		retry++
+		slog.WarnContext(ctx, "index block stream interrupted, reconnecting", "error", err, "retry_in", wait.String())
+		if !sleep(ctx, wait) {
+			return
+		}
+	}
+}
+
+// stream indexes blocks from the next block until the block stream ends. It
+// returns nil if at least one block was indexed, so that the reconnect
+// backoff is reset.
+func (ix *Indexer) stream(ctx context.Context) error {
+	next, ok, err := ix.store.NextBlock()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		next = ix.startBlock
+	}
+	streamCtx, cancel := context.WithCancel(ctx)
+	blocks, err := ix.fabricClient.BlockEvents(streamCtx, fabric.EventOptions{StartBlock: &next})
+	if err != nil {
+		cancel()
+		return err
+	}
+	defer func() {
+		// Drain the stream so that its gateway connection is closed
+		cancel()
+		for range blocks {
+		}
+	}()
+	slog.InfoContext(ctx, "indexing blocks", "start_block", next)
+
+	indexed := false
+	for block := range blocks {
+		if block.Number < next {
+			continue
+		}
+		if err := ix.store.PutBlock(block); err != nil {
+			return err
+		}
+		next = block.Number + 1
+		indexed = true
+
+		ix.mu.Lock()
+		ix.lastIndexedAt = time.Now().UTC()
+		ix.lastErr = nil
+		ix.mu.Unlock()
+		slog.DebugContext(ctx, "indexed block", "block", block.Number, "transactions", len(block.Transactions))
+	}
+	if indexed {
+		return nil
+	}
+	return errors.New("block stream ended")
+}
+
+// Search returns the indexed transactions matching q and the cursor of the
+// next page
+func (ix *Indexer) Search(q Query) ([]fabric.Transaction, string, error) {
+	if q.Limit <= 0 {
+		q.Limit = DefaultLimit
+	}
+	if q.Limit > MaxLimit {
+		q.Limit = MaxLimit
+	}
+	return ix.store.Search(q)
+}
+
+// Status returns the progress of the indexer
+func (ix *Indexer) Status() (*Status, error) {
+	next, ok, err := ix.store.NextBlock()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		next = ix.startBlock
+	}
+	count, err := ix.store.Count()
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{Height: next, Transactions: count}
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if !ix.lastIndexedAt.IsZero() {
+		lastIndexedAt := ix.lastIndexedAt
+		status.LastIndexedAt = &lastIndexedAt
+	}
+	if ix.lastErr != nil {
+		status.Error = ix.lastErr.Error()
+	}
+	return status, nil
+}
+
+func (ix *Indexer) setError(err error) {
+	ix.mu.Lock()
+	ix.lastErr = err
+	ix.mu.Unlock()
+}
+
+// backoff returns the wait before the given reconnect, starting at 0
+func backoff(retry int) time.Duration {
+	wait := minBackoff
+	for i := 0; i < retry && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > maxBackoff {
+		wait = maxBackoff
+	}
+	return wait
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
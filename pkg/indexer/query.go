@@ -0,0 +1,57 @@
+package indexer
+
+import (
+	"encoding/base64"
+	"time"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+)
+
+const (
+	// DefaultLimit is the page size of searches that do not set one
+	DefaultLimit = 50
+	// MaxLimit is the largest page size of a search
+	MaxLimit = 500
+)
+
+// Query selects indexed transactions. Empty fields match every transaction.
+type Query struct {
+	Chaincode      string
+	Function       string
+	CreatorMSP     string
+	CreatorSubject string
+	ValidationCode string
+	// From and To bound the transaction timestamps; From is inclusive and
+	// To exclusive
+	From time.Time
+	To   time.Time
+
+	// Cursor is the next_cursor of the previous page
+	Cursor string
+	Limit  int
+	// Ascending returns the oldest transactions first
+	Ascending bool
+}
+
+// matches checks every condition of the query on a transaction
+func (q Query) matches(tx *fabric.Transaction) bool {
+	switch {
+	case q.Chaincode != "" && tx.Chaincode != q.Chaincode,
+		q.Function != "" && tx.Function != q.Function,
+		q.CreatorMSP != "" && tx.CreatorMSP != q.CreatorMSP,
+		q.CreatorSubject != "" && tx.CreatorSubject != q.CreatorSubject,
+		q.ValidationCode != "" && tx.ValidationCode != q.ValidationCode,
+		!q.From.IsZero() && tx.Timestamp.Before(q.From),
+		!q.To.IsZero() && !tx.Timestamp.Before(q.To):
+		return false
+	}
+	return true
+}
+
+func encodeCursor(key []byte) string {
+	return base64.RawURLEncoding.EncodeToString(key)
+}
+
+func decodeCursor(cursor string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(cursor)
+}
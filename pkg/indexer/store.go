@@ -0,0 +1,401 @@
+package indexer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+)
+
+var (
+	metaBucket = []byte("indexer_meta")
+	// transactionsBucket maps the position of a transaction in the ledger
+	// to the transaction
+	transactionsBucket = []byte("indexer_transactions")
+	// The index buckets map <value>\x00<position> to nothing, so that the
+	// transactions with a value are found in ledger order by prefix
+	byChaincodeBucket  = []byte("indexer_by_chaincode")
+	byFunctionBucket   = []byte("indexer_by_function")
+	byCreatorBucket    = []byte("indexer_by_creator")
+	byValidationBucket = []byte("indexer_by_validation")
+	// byTimeBucket maps <timestamp><position> to nothing
+	byTimeBucket = []byte("indexer_by_time")
+	// historyBucket maps <namespace><key><position> to the KeyModification
+	// of a key by a valid transaction
+	historyBucket = []byte("indexer_key_history")
+
+	nextBlockKey = []byte("next_block")
+	// firstBlockKey is the number of the first indexed block, whose
+	// earliest transaction timestamp is kept under firstTimeKey
+	firstBlockKey = []byte("first_block")
+	firstTimeKey  = []byte("first_time")
+	// lastTimeKey is the latest timestamp of the indexed transactions
+	lastTimeKey = []byte("last_time")
+)
+
+// ErrInvalidCursor is returned for cursors that were not returned by Search
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// positionSize is the length of the key of a transaction: its block number
+// and its index in the block
+const positionSize = 8 + 4
+
+// Store keeps the indexed transactions in a bbolt database file together with
+// the number of the next block to index
+type Store struct {
+	db *bolt.DB
+}
+
+// NewStore opens (or creates) the database file at path
+func NewStore(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index database %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{metaBucket, transactionsBucket, byChaincodeBucket, byFunctionBucket, byCreatorBucket, byValidationBucket, byTimeBucket, historyBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize index database: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// NextBlock returns the number of the next block to index; ok is false while
+// no block has been indexed
+func (s *Store) NextBlock() (next uint64, ok bool, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		if data := tx.Bucket(metaBucket).Get(nextBlockKey); data != nil {
+			next, ok = binary.BigEndian.Uint64(data), true
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read index position: %w", err)
+	}
+	return next, ok, nil
+}
+
+// extent is the part of the ledger the store holds
+type extent struct {
+	// first is the number of the first indexed block and next the number of
+	// the next block to index
+	first, next uint64
+	// firstTime is the earliest transaction timestamp of the first block and
+	// lastTime the latest of all indexed transactions; either is zero when
+	// the blocks had no timestamped transactions
+	firstTime, lastTime time.Time
+}
+
+// extent returns the indexed part of the ledger; ok is false while no block
+// has been indexed
+func (s *Store) extent() (e extent, ok bool, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		meta := tx.Bucket(metaBucket)
+		data := meta.Get(nextBlockKey)
+		if data == nil {
+			return nil
+		}
+		e.next, ok = binary.BigEndian.Uint64(data), true
+		if data := meta.Get(firstBlockKey); data != nil {
+			e.first = binary.BigEndian.Uint64(data)
+		}
+		e.firstTime = decodeTime(meta.Get(firstTimeKey))
+		e.lastTime = decodeTime(meta.Get(lastTimeKey))
+		return nil
+	})
+	if err != nil {
+		return extent{}, false, fmt.Errorf("failed to read index position: %w", err)
+	}
+	return e, ok, nil
+}
+
+// Count returns the number of indexed transactions
+func (s *Store) Count() (int, error) {
+	var count int
+	err := s.db.View(func(tx *bolt.Tx) error {
+		count = tx.Bucket(transactionsBucket).Stats().KeyN
+		return nil
+	})
+	return count, err
+}
+
+// PutBlock indexes the transactions of a block and advances the next block
+// past it in a single database transaction, so that a block is indexed
+// exactly once even if the process stops in between
+func (s *Store) PutBlock(block *fabric.Block) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		var earliest, latest time.Time
+		for i := range block.Transactions {
+			if err := putTransaction(tx, &block.Transactions[i]); err != nil {
+				return err
+			}
+			if ts := block.Transactions[i].Timestamp; !ts.IsZero() {
+				if earliest.IsZero() || ts.Before(earliest) {
+					earliest = ts
+				}
+				if ts.After(latest) {
+					latest = ts
+				}
+			}
+		}
+
+		meta := tx.Bucket(metaBucket)
+		if meta.Get(nextBlockKey) == nil {
+			if err := meta.Put(firstBlockKey, binary.BigEndian.AppendUint64(nil, block.Number)); err != nil {
+				return err
+			}
+			if !earliest.IsZero() {
+				if err := meta.Put(firstTimeKey, timeKey(earliest)); err != nil {
+					return err
+				}
+			}
+		}
+		if !latest.IsZero() && latest.After(decodeTime(meta.Get(lastTimeKey))) {
+			if err := meta.Put(lastTimeKey, timeKey(latest)); err != nil {
+				return err
+			}
+		}
+		return meta.Put(nextBlockKey, binary.BigEndian.AppendUint64(nil, block.Number+1))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index block %d: %w", block.Number, err)
+	}
+	return nil
+}
+
+func putTransaction(tx *bolt.Tx, transaction *fabric.Transaction) error {
+	// The writes are kept in the key history, so search results stay small
+	stored := *transaction
+	stored.ReadWriteSets = nil
+	data, err := json.Marshal(&stored)
+	if err != nil {
+		return fmt.Errorf("failed to encode transaction %s: %w", transaction.ID, err)
+	}
+	pos := position(transaction.BlockNumber, transaction.Index)
+	if err := tx.Bucket(transactionsBucket).Put(pos, data); err != nil {
+		return err
+	}
+
+	// Every transaction is indexed by creator and validation code, endorser
+	// transactions also by chaincode and function
+	entries := [][2][]byte{
+		{byCreatorBucket, indexKey(pos, transaction.CreatorMSP, transaction.CreatorSubject)},
+		{byValidationBucket, indexKey(pos, transaction.ValidationCode)},
+	}
+	if !transaction.Timestamp.IsZero() {
+		entries = append(entries, [2][]byte{byTimeBucket, append(timeKey(transaction.Timestamp), pos...)})
+	}
+	if transaction.Chaincode != "" {
+		entries = append(entries,
+			[2][]byte{byChaincodeBucket, indexKey(pos, transaction.Chaincode)},
+			[2][]byte{byFunctionBucket, indexKey(pos, transaction.Chaincode, transaction.Function)},
+		)
+	}
+	for _, entry := range entries {
+		if err := tx.Bucket(entry[0]).Put(entry[1], nil); err != nil {
+			return err
+		}
+	}
+	return putHistory(tx, pos, transaction)
+}
+
+// Search returns the transactions matching the query in ledger order, newest
+// first unless the query is ascending, and the cursor of the next page. The
+// cursor is empty when the page is the last one.
+func (s *Store) Search(q Query) ([]fabric.Transaction, string, error) {
+	plan := planQuery(q)
+	var start []byte
+	if q.Cursor != "" {
+		cursor, err := decodeCursor(q.Cursor)
+		if err != nil || !plan.contains(cursor) {
+			return nil, "", ErrInvalidCursor
+		}
+		start = cursor
+	}
+
+	transactions := []fabric.Transaction{}
+	var next string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(plan.bucket).Cursor()
+		data := tx.Bucket(transactionsBucket)
+		for k := plan.first(c, start, q.Ascending); k != nil && plan.contains(k); k = plan.step(c, q.Ascending) {
+			value := data.Get(k[len(k)-positionSize:])
+			if value == nil {
+				continue
+			}
+			var transaction fabric.Transaction
+			if err := json.Unmarshal(value, &transaction); err != nil {
+				return fmt.Errorf("failed to decode transaction at %x: %w", k[len(k)-positionSize:], err)
+			}
+			if !q.matches(&transaction) {
+				continue
+			}
+			transactions = append(transactions, transaction)
+			if len(transactions) == q.Limit {
+				next = encodeCursor(k)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to search index: %w", err)
+	}
+	return transactions, next, nil
+}
+
+// Close closes the database file
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// plan is the key range of the bucket a query is answered from
+type plan struct {
+	bucket []byte
+	// lower is the first key of the range; upper is the first key past it,
+	// or nil for the end of the bucket
+	lower, upper []byte
+}
+
+// planQuery picks the most selective index for a query. The conditions the
+// index does not cover are checked on the transactions read from it.
+func planQuery(q Query) plan {
+	switch {
+	case q.Chaincode != "" && q.Function != "":
+		return prefixPlan(byFunctionBucket, indexPrefix(q.Chaincode, q.Function))
+	case q.Chaincode != "":
+		return prefixPlan(byChaincodeBucket, indexPrefix(q.Chaincode))
+	case q.CreatorMSP != "" && q.CreatorSubject != "":
+		return prefixPlan(byCreatorBucket, indexPrefix(q.CreatorMSP, q.CreatorSubject))
+	case q.CreatorMSP != "":
+		return prefixPlan(byCreatorBucket, indexPrefix(q.CreatorMSP))
+	case q.ValidationCode != "":
+		return prefixPlan(byValidationBucket, indexPrefix(q.ValidationCode))
+	case !q.From.IsZero() || !q.To.IsZero():
+		p := plan{bucket: byTimeBucket}
+		if !q.From.IsZero() {
+			p.lower = timeKey(q.From)
+		}
+		if !q.To.IsZero() {
+			p.upper = timeKey(q.To)
+		}
+		return p
+	default:
+		return plan{bucket: transactionsBucket}
+	}
+}
+
+func prefixPlan(bucket, prefix []byte) plan {
+	return plan{bucket: bucket, lower: prefix, upper: prefixEnd(prefix)}
+}
+
+// contains reports whether a key lies in the range of the plan
+func (p plan) contains(k []byte) bool {
+	return len(k) >= positionSize && bytes.Compare(k, p.lower) >= 0 && (p.upper == nil || bytes.Compare(k, p.upper) < 0)
+}
+
+// first positions c at the first key of the range, or at the key after start
+// when a cursor is given
+func (p plan) first(c *bolt.Cursor, start []byte, ascending bool) []byte {
+	if ascending {
+		if start == nil && p.lower == nil {
+			k, _ := c.First()
+			return k
+		}
+		if start == nil {
+			k, _ := c.Seek(p.lower)
+			return k
+		}
+		k, _ := c.Seek(start)
+		if bytes.Equal(k, start) {
+			k, _ = c.Next()
+		}
+		return k
+	}
+
+	from := start
+	if from == nil {
+		from = p.upper
+	}
+	if from == nil {
+		k, _ := c.Last()
+		return k
+	}
+	// Seek lands on the first key at or after from, so the key before it is
+	// the first one in the range
+	if k, _ := c.Seek(from); k == nil {
+		k, _ = c.Last()
+		return k
+	}
+	k, _ := c.Prev()
+	return k
+}
+
+func (p plan) step(c *bolt.Cursor, ascending bool) []byte {
+	if ascending {
+		k, _ := c.Next()
+		return k
+	}
+	k, _ := c.Prev()
+	return k
+}
+
+// position encodes the location of a transaction so that keys sort in ledger order
+func position(blockNumber uint64, index int) []byte {
+	key := make([]byte, positionSize)
+	binary.BigEndian.PutUint64(key, blockNumber)
+	binary.BigEndian.PutUint32(key[8:], uint32(index))
+	return key
+}
+
+// indexPrefix joins values with a NUL byte, which does not occur in them
+func indexPrefix(values ...string) []byte {
+	var prefix []byte
+	for _, value := range values {
+		prefix = append(prefix, value...)
+		prefix = append(prefix, 0)
+	}
+	return prefix
+}
+
+func indexKey(pos []byte, values ...string) []byte {
+	return append(indexPrefix(values...), pos...)
+}
+
+// timeKey encodes a timestamp so that keys sort in time order
+func timeKey(t time.Time) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(t.UnixNano()))
+}
+
+// decodeTime decodes a timeKey, returning the zero time for nil
+func decodeTime(data []byte) time.Time {
+	if len(data) != 8 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(data))).UTC()
+}
+
+// prefixEnd returns the first key after every key starting with prefix
+func prefixEnd(prefix []byte) []byte {
+	end := bytes.Clone(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
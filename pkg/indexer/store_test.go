@@ -0,0 +1,177 @@
+package indexer
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+)
+
+// t0 is the timestamp of the first test transaction; every following one is
+// a minute later
+var t0 = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := NewStore(filepath.Join(t.TempDir(), "index.db"))
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// putBlocks indexes blocks 1 to 3 with the transactions a to e
+func putBlocks(t *testing.T, store *Store) {
+	t.Helper()
+	tx := func(id string, blockNumber uint64, index, minute int, chaincode, function, mspID, code string) fabric.Transaction {
+		return fabric.Transaction{
+			ID:             id,
+			BlockNumber:    blockNumber,
+			Index:          index,
+			Timestamp:      t0.Add(time.Duration(minute) * time.Minute),
+			ValidationCode: code,
+			CreatorMSP:     mspID,
+			Chaincode:      chaincode,
+			Function:       function,
+		}
+	}
+	blocks := []*fabric.Block{
+		{Number: 1, Transactions: []fabric.Transaction{
+			tx("a", 1, 0, 0, "basic", "CreateAsset", "Org1MSP", "VALID"),
+			tx("b", 1, 1, 1, "basic2", "Init", "Org2MSP", "VALID"),
+		}},
+		{Number: 2, Transactions: []fabric.Transaction{
+			tx("c", 2, 0, 2, "basic", "TransferAsset", "Org1MSP", "MVCC_READ_CONFLICT"),
+			tx("d", 2, 1, 3, "", "", "Org1MSP", "VALID"),
+		}},
+		{Number: 3, Transactions: []fabric.Transaction{
+			tx("e", 3, 0, 4, "basic", "CreateAsset", "Org2MSP", "VALID"),
+		}},
+	}
+	for _, block := range blocks {
+		if err := store.PutBlock(block); err != nil {
+			t.Fatalf("PutBlock() error = %v", err)
+		}
+	}
+}
+
+func ids(transactions []fabric.Transaction) string {
+	var ids []string
+	for _, tx := range transactions {
+		ids = append(ids, tx.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestStoreNextBlock(t *testing.T) {
+	store := newTestStore(t)
+	if _, ok, err := store.NextBlock(); ok || err != nil {
+		t.Errorf("NextBlock() of an empty store = %v, %v; want no block", ok, err)
+	}
+	putBlocks(t, store)
+	if next, ok, err := store.NextBlock(); next != 4 || !ok || err != nil {
+		t.Errorf("NextBlock() = %d, %v, %v; want 4", next, ok, err)
+	}
+	if count, err := store.Count(); count != 5 || err != nil {
+		t.Errorf("Count() = %d, %v; want 5", count, err)
+	}
+}
+
+func TestStoreSearch(t *testing.T) {
+	store := newTestStore(t)
+	putBlocks(t, store)
+	tests := []struct {
+		name  string
+		query Query
+		want  string
+	}{
+		{name: "everything", query: Query{}, want: "e,d,c,b,a"},
+		{name: "ascending", query: Query{Ascending: true}, want: "a,b,c,d,e"},
+		{name: "chaincode", query: Query{Chaincode: "basic"}, want: "e,c,a"},
+		{name: "function", query: Query{Chaincode: "basic", Function: "CreateAsset", Ascending: true}, want: "a,e"},
+		{name: "creator", query: Query{CreatorMSP: "Org2MSP"}, want: "e,b"},
+		{name: "validation code", query: Query{ValidationCode: "MVCC_READ_CONFLICT"}, want: "c"},
+		{name: "time range", query: Query{From: t0.Add(time.Minute), To: t0.Add(4 * time.Minute)}, want: "d,c,b"},
+		{name: "conditions outside the index", query: Query{Chaincode: "basic", ValidationCode: "VALID"}, want: "e,a"},
+		{name: "no match", query: Query{Chaincode: "other"}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactions, next, err := store.Search(tt.query)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if got := ids(transactions); got != tt.want || next != "" {
+				t.Errorf("Search() = %s, cursor %q; want %s", got, next, tt.want)
+			}
+		})
+	}
+}
+
+func TestStoreSearchPages(t *testing.T) {
+	store := newTestStore(t)
+	putBlocks(t, store)
+	for _, tt := range []struct {
+		query Query
+		want  []string
+	}{
+		{Query{Limit: 2}, []string{"e,d", "c,b", "a"}},
+		{Query{Limit: 2, Ascending: true}, []string{"a,b", "c,d", "e"}},
+		{Query{Limit: 1, Chaincode: "basic"}, []string{"e", "c", "a", ""}},
+	} {
+		q := tt.query
+		for i, want := range tt.want {
+			transactions, next, err := store.Search(q)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if got := ids(transactions); got != want {
+				t.Errorf("page %d of %+v = %s, want %s", i, tt.query, got, want)
+			}
+			if q.Cursor = next; next == "" {
+				if i != len(tt.want)-1 {
+					t.Errorf("page %d of %+v has no next cursor", i, tt.query)
+				}
+				break
+			}
+		}
+	}
+}
+
+func TestStoreSearchRejectsInvalidCursors(t *testing.T) {
+	store := newTestStore(t)
+	putBlocks(t, store)
+	_, cursor, err := store.Search(Query{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range []Query{
+		{Cursor: "not base64!"},
+		{Cursor: "AAAA"},
+		// A cursor of another index
+		{Chaincode: "basic", Cursor: cursor},
+	} {
+		if _, _, err := store.Search(q); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("Search(%+v) error = %v, want %v", q, err, ErrInvalidCursor)
+		}
+	}
+}
+
+func TestPrefixEnd(t *testing.T) {
+	tests := []struct {
+		prefix, want []byte
+	}{
+		{[]byte("basic\x00"), []byte("basic\x01")},
+		{[]byte{0x01, 0xff}, []byte{0x02}},
+		{[]byte{0xff, 0xff}, nil},
+	}
+	for _, tt := range tests {
+		if got := prefixEnd(tt.prefix); string(got) != string(tt.want) {
+			t.Errorf("prefixEnd(%x) = %x, want %x", tt.prefix, got, tt.want)
+		}
+	}
+}
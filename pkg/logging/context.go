@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+type attrsKey struct{}
+
+// attrSet holds the attributes added to every record logged with a context
+type attrSet struct {
+	parent *attrSet
+
+	mu    sync.Mutex
+	attrs []slog.Attr
+}
+
+func (s *attrSet) collect() []slog.Attr {
+	var attrs []slog.Attr
+	if s.parent != nil {
+		attrs = s.parent.collect()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append(attrs, s.attrs...)
+}
+
+// With returns a copy of ctx whose log records carry attrs in addition to
+// those of ctx
+func With(ctx context.Context, attrs ...slog.Attr) context.Context {
+	parent, _ := ctx.Value(attrsKey{}).(*attrSet)
+	return context.WithValue(ctx, attrsKey{}, &attrSet{parent: parent, attrs: attrs})
+}
+
+// Add attaches attrs to the records logged with ctx from now on, including
+// those logged by callers sharing the context created with With, e.g. the
+// request log written once the handler returns
+func Add(ctx context.Context, attrs ...slog.Attr) {
+	if set, ok := ctx.Value(attrsKey{}).(*attrSet); ok {
+		set.mu.Lock()
+		set.attrs = append(set.attrs, attrs...)
+		set.mu.Unlock()
+	}
+}
+
+// contextHandler adds the attributes stored in the context to every record
+type contextHandler struct {
+	handler slog.Handler
+}
+
+func (h *contextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if set, ok := ctx.Value(attrsKey{}).(*attrSet); ok {
+		record.AddAttrs(set.collect()...)
+	}
+	return h.handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{handler: h.handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{handler: h.handler.WithGroup(name)}
+}
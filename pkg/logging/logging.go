@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync/atomic"
+)
+
+var (
+	level        = new(slog.LevelVar)
+	logSensitive atomic.Bool
+)
+
+// Options configures the process-wide logger
+type Options struct {
+	// Level is one of debug, info, warn or error
+	Level string
+	// Format is json or text
+	Format string
+	// LogSensitive disables the redaction of transaction arguments
+	LogSensitive bool
+}
+
+// Setup installs the default slog logger writing to w. Output of the standard
+// log package is routed through it as well.
+func Setup(w io.Writer, opts Options) error {
+	if err := SetLevel(opts.Level); err != nil {
+		return err
+	}
+	logSensitive.Store(opts.LogSensitive)
+
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch opts.Format {
+	case "", "json":
+		handler = slog.NewJSONHandler(w, handlerOpts)
+	case "text":
+		handler = slog.NewTextHandler(w, handlerOpts)
+	default:
+		return fmt.Errorf("unknown log format %q (expected json or text)", opts.Format)
+	}
+	slog.SetDefault(slog.New(&contextHandler{handler: handler}))
+	return nil
+}
+
+// SetLevel changes the minimum level of the default logger
+func SetLevel(name string) error {
+	if name == "" {
+		name = "info"
+	}
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(name)); err != nil {
+		return fmt.Errorf("invalid log level %q: %w", name, err)
+	}
+	level.Set(l)
+	return nil
+}
+
+// SetSensitive enables or disables the logging of sensitive values
+func SetSensitive(enabled bool) {
+	logSensitive.Store(enabled)
+}
+
+// Fatal logs msg at error level and exits the process
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// sensitive defers the decision to log a value until it is written
+type sensitive struct {
+	value any
+}
+
+func (s sensitive) LogValue() slog.Value {
+	if logSensitive.Load() {
+		return slog.AnyValue(s.value)
+	}
+	return slog.StringValue("[REDACTED]")
+}
+
+// Sensitive wraps a value, such as transaction arguments or transient data,
+// that is logged as [REDACTED] unless sensitive logging is enabled
+func Sensitive(value any) slog.LogValuer {
+	return sensitive{value: value}
+}
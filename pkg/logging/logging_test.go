@@ -0,0 +1,125 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// setupTest installs a JSON logger writing to the returned buffer for the
+// duration of the test
+func setupTest(t *testing.T, opts Options) *bytes.Buffer {
+	t.Helper()
+	previous := slog.Default()
+	t.Cleanup(func() {
+		slog.SetDefault(previous)
+		SetSensitive(false)
+		SetLevel("info")
+	})
+	var buf bytes.Buffer
+	if err := Setup(&buf, opts); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	return &buf
+}
+
+// records decodes the JSON records written to buf
+func records(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var result []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid record %q: %v", line, err)
+		}
+		result = append(result, record)
+	}
+	return result
+}
+
+func TestSensitive(t *testing.T) {
+	args := []string{"asset1", "secret"}
+	for _, enabled := range []bool{false, true} {
+		buf := setupTest(t, Options{Level: "info", LogSensitive: enabled})
+		slog.Info("invoke", "args", Sensitive(args))
+		logged := buf.String()
+		if got := strings.Contains(logged, "secret"); got != enabled {
+			t.Errorf("with sensitive logging %v the arguments were logged: %v\n%s", enabled, got, logged)
+		}
+		if !enabled && !strings.Contains(logged, "[REDACTED]") {
+			t.Errorf("the redacted arguments are not marked: %s", logged)
+		}
+	}
+
+	// Reloading the setting applies to records written afterwards
+	buf := setupTest(t, Options{Level: "info"})
+	SetSensitive(true)
+	slog.Info("invoke", "args", Sensitive(args))
+	if !strings.Contains(buf.String(), "secret") {
+		t.Error("SetSensitive(true) was not applied")
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	buf := setupTest(t, Options{Level: "warn"})
+	slog.Info("hidden")
+	if err := SetLevel("debug"); err != nil {
+		t.Fatal(err)
+	}
+	slog.Debug("shown")
+	logged := buf.String()
+	if strings.Contains(logged, "hidden") || !strings.Contains(logged, "shown") {
+		t.Errorf("log = %s", logged)
+	}
+	if err := SetLevel("verbose"); err == nil {
+		t.Error("SetLevel() accepted an unknown level")
+	}
+	if err := Setup(&bytes.Buffer{}, Options{Format: "xml"}); err == nil {
+		t.Error("Setup() accepted an unknown format")
+	}
+}
+
+func TestMiddlewareAddsRequestAttributes(t *testing.T) {
+	buf := setupTest(t, Options{Level: "info"})
+
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
+	r.Use(Middleware)
+	r.Post("/api/invoke", func(w http.ResponseWriter, r *http.Request) {
+		Add(r.Context(), slog.String("tx_id", "tx123"))
+		slog.InfoContext(With(r.Context(), slog.Int("batch_operation", 1)), "submitting")
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/invoke", nil))
+
+	requestID := w.Header().Get(middleware.RequestIDHeader)
+	if requestID == "" {
+		t.Fatal("the request ID is not returned")
+	}
+	logged := records(t, buf)
+	if len(logged) != 2 {
+		t.Fatalf("%d records, want 2: %s", len(logged), buf)
+	}
+	handler, completed := logged[0], logged[1]
+	if handler["request_id"] != requestID || handler["tx_id"] != "tx123" || handler["batch_operation"] != float64(1) {
+		t.Errorf("handler record = %v", handler)
+	}
+	if completed["request_id"] != requestID || completed["tx_id"] != "tx123" || completed["route"] != "/api/invoke" ||
+		completed["status"] != float64(500) || completed["level"] != "ERROR" {
+		t.Errorf("request record = %v", completed)
+	}
+	if _, ok := completed["batch_operation"]; ok {
+		t.Error("attributes of a derived context leaked into the request record")
+	}
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	certificateExpiryDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "certificate_expiry_timestamp_seconds"),
+		"Expiry time of the identity and TLS certificates as a Unix timestamp.",
+		[]string{"type", "name", "subject"}, nil,
+	)
+	certificateErrorDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "certificate_read_errors"),
+		"Whether the certificate file could not be read or parsed (1) or not (0).",
+		[]string{"type", "name"}, nil,
+	)
+)
+
+type certificateFile struct {
+	kind string
+	name string
+	path string
+}
+
+// certificateCollector reports certificate expiry times read at scrape time
+type certificateCollector struct {
+	mu    sync.Mutex
+	files []certificateFile
+}
+
+func (c *certificateCollector) add(file certificateFile) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.files = append(c.files, file)
+}
+
+func (c *certificateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- certificateExpiryDesc
+	ch <- certificateErrorDesc
+}
+
+func (c *certificateCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	files := c.files
+	c.mu.Unlock()
+
+	for _, file := range files {
+		certs, err := readCertificates(file.path)
+		failed := 0.0
+		if err != nil || len(certs) == 0 {
+			failed = 1
+		}
+		ch <- prometheus.MustNewConstMetric(certificateErrorDesc, prometheus.GaugeValue, failed, file.kind, file.name)
+		seen := make(map[string]bool)
+		for _, cert := range certs {
+			subject := cert.Subject.String()
+			if seen[subject] {
+				continue
+			}
+			seen[subject] = true
+			ch <- prometheus.MustNewConstMetric(certificateExpiryDesc, prometheus.GaugeValue,
+				float64(cert.NotAfter.Unix()), file.kind, file.name, subject)
+		}
+	}
+}
+
+// readCertificates parses every certificate of a PEM file, e.g. a CA bundle
+func readCertificates(path string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return certs, nil
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor records the count and latency of unary gRPC calls
+// labelled by method and status code
+func (m *Metrics) UnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	labels := &transactionLabels{}
+	resp, err := handler(context.WithValue(ctx, transactionLabelsKey{}, labels), req)
+
+	chaincode, function := m.transactionLabelValues(labels)
+	values := []string{info.FullMethod, status.Code(err).String(), chaincode, function}
+	m.grpcRequests.WithLabelValues(values...).Inc()
+	m.grpcDuration.WithLabelValues(values...).Observe(time.Since(start).Seconds())
+	return resp, err
+}
+
+// StreamServerInterceptor counts the gRPC streams by method and the status
+// code they ended with
+func (m *Metrics) StreamServerInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	labels := &transactionLabels{}
+	err := handler(srv, &serverStream{
+		ServerStream: ss,
+		ctx:          context.WithValue(ss.Context(), transactionLabelsKey{}, labels),
+	})
+	chaincode, function := m.transactionLabelValues(labels)
+	m.grpcRequests.WithLabelValues(info.FullMethod, status.Code(err).String(), chaincode, function).Inc()
+	return err
+}
+
+// serverStream replaces the context of a server stream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
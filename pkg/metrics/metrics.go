@@ -0,0 +1,205 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+)
+
+const namespace = "hlf_api"
+
+// maxTransactionLabels bounds the chaincode and function label pairs of the
+// request metrics. Further pairs are counted as "other", so that clients
+// cannot grow the number of series without limit.
+const maxTransactionLabels = 500
+
+// Metrics collects the Prometheus metrics of the API in its own registry
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests  *prometheus.CounterVec
+	httpDuration  *prometheus.HistogramVec
+	grpcRequests  *prometheus.CounterVec
+	grpcDuration  *prometheus.HistogramVec
+	phaseDuration *prometheus.HistogramVec
+	peerRequests  *prometheus.CounterVec
+	peerInFlight  *prometheus.GaugeVec
+	transactions  *prometheus.CounterVec
+	certificates  *certificateCollector
+
+	labelsMu sync.Mutex
+	// labels are the chaincode and function label pairs in use
+	labels map[transactionLabels]struct{}
+}
+
+// New creates the metrics and registers them, together with the Go runtime
+// and process collectors, in a new registry
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by route, method, status, chaincode and function.",
+		}, []string{"route", "method", "status", "chaincode", "function"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of HTTP requests by route, method, status, chaincode and function.",
+			Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		}, []string{"route", "method", "status", "chaincode", "function"}),
+		grpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "grpc_requests_total",
+			Help:      "Number of gRPC calls and streams by method, status code, chaincode and function.",
+		}, []string{"method", "code", "chaincode", "function"}),
+		grpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "grpc_request_duration_seconds",
+			Help:      "Latency of unary gRPC calls by method, status code, chaincode and function.",
+			Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		}, []string{"method", "code", "chaincode", "function"}),
+		phaseDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "gateway_phase_duration_seconds",
+			Help:      "Duration of gateway calls by phase (connect, evaluate, endorse, submit, commit_status) and peer.",
+			Buckets:   []float64{0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		}, []string{"phase", "peer"}),
+		peerRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "peer_requests_total",
+			Help:      "Number of gateway calls by peer, phase and result (success or failure).",
+		}, []string{"peer", "phase", "result"}),
+		peerInFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "peer_in_flight_requests",
+			Help:      "Number of gateway calls currently in progress by peer.",
+		}, []string{"peer"}),
+		transactions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transactions_total",
+			Help:      "Number of committed transactions by chaincode and validation code.",
+		}, []string{"chaincode", "validation_code"}),
+		certificates: &certificateCollector{},
+		labels:       make(map[transactionLabels]struct{}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.grpcRequests,
+		m.grpcDuration,
+		m.phaseDuration,
+		m.peerRequests,
+		m.peerInFlight,
+		m.transactions,
+		m.certificates,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+type transactionLabelsKey struct{}
+
+type transactionLabels struct {
+	chaincode string
+	function  string
+}
+
+// SetTransaction records the chaincode and function handled by the request so
+// that they are used as labels of its HTTP metrics. It is called once the
+// request has been authorized and validated, so that rejected requests do not
+// add labels.
+func SetTransaction(ctx context.Context, chaincode, function string) {
+	if labels, ok := ctx.Value(transactionLabelsKey{}).(*transactionLabels); ok {
+		labels.chaincode = chaincode
+		labels.function = function
+	}
+}
+
+// Middleware records the count and latency of HTTP requests labelled by the
+// matched route pattern
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		labels := &transactionLabels{}
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(context.WithValue(r.Context(), transactionLabelsKey{}, labels)))
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		chaincode, function := m.transactionLabelValues(labels)
+		values := []string{route, r.Method, strconv.Itoa(status), chaincode, function}
+		m.httpRequests.WithLabelValues(values...).Inc()
+		m.httpDuration.WithLabelValues(values...).Observe(time.Since(start).Seconds())
+	})
+}
+
+// transactionLabelValues returns the chaincode and function labels of a
+// request, or "other" once maxTransactionLabels pairs are in use
+func (m *Metrics) transactionLabelValues(labels *transactionLabels) (string, string) {
+	if labels.chaincode == "" && labels.function == "" {
+		return "", ""
+	}
+	m.labelsMu.Lock()
+	defer m.labelsMu.Unlock()
+	if _, ok := m.labels[*labels]; !ok {
+		if len(m.labels) >= maxTransactionLabels {
+			return "other", "other"
+		}
+		m.labels[*labels] = struct{}{}
+	}
+	return labels.chaincode, labels.function
+}
+
+// StartCall implements fabric.Observer, recording per-phase and per-peer metrics
+func (m *Metrics) StartCall(ctx context.Context, call fabric.CallInfo) func(error) {
+	start := time.Now()
+	inFlight := m.peerInFlight.WithLabelValues(call.Peer)
+	inFlight.Inc()
+	return func(err error) {
+		inFlight.Dec()
+		m.phaseDuration.WithLabelValues(string(call.Phase), call.Peer).Observe(time.Since(start).Seconds())
+		result := "success"
+		if err != nil {
+			result = "failure"
+		}
+		m.peerRequests.WithLabelValues(call.Peer, string(call.Phase), result).Inc()
+	}
+}
+
+// RecordTransaction is a fabric.TransactionListener counting the validation
+// codes of committed transactions
+func (m *Metrics) RecordTransaction(ctx context.Context, event *fabric.TransactionEvent) {
+	if event.ValidationCode == "" {
+		return
+	}
+	m.transactions.WithLabelValues(event.ChaincodeName, event.ValidationCode).Inc()
+}
+
+// WatchCertificate exposes the expiry time of the certificate at path. The
+// file is read on every scrape so that rotated certificates are picked up.
+func (m *Metrics) WatchCertificate(kind, name, path string) {
+	m.certificates.add(certificateFile{kind: kind, name: name, path: path})
+}
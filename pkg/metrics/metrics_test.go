@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+)
+
+// scrape returns the lines of the metrics exposition that start with prefix
+func scrape(t *testing.T, m *Metrics, prefix string) []string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	var lines []string
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		if strings.HasPrefix(line, prefix) {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func TestMiddlewareLabelsRoutesAndTransactions(t *testing.T) {
+	m := New()
+	r := chi.NewRouter()
+	r.Use(m.Middleware)
+	r.Post("/api/chaincodes/{cc}/invoke", func(w http.ResponseWriter, r *http.Request) {
+		SetTransaction(r.Context(), chi.URLParam(r, "cc"), "CreateAsset")
+		w.WriteHeader(http.StatusCreated)
+	})
+	r.Post("/api/rejected", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	for _, path := range []string{"/api/chaincodes/basic/invoke", "/api/rejected", "/missing"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, path, nil))
+	}
+
+	lines := strings.Join(scrape(t, m, "hlf_api_http_requests_total"), "\n")
+	for _, want := range []string{
+		`chaincode="basic",function="CreateAsset",method="POST",route="/api/chaincodes/{cc}/invoke",status="201"`,
+		`chaincode="",function="",method="POST",route="/api/rejected",status="403"`,
+		`route="unmatched",status="404"`,
+	} {
+		if !strings.Contains(lines, want) {
+			t.Errorf("metrics do not contain %s:\n%s", want, lines)
+		}
+	}
+}
+
+func TestTransactionLabelsAreCapped(t *testing.T) {
+	m := New()
+	h := m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SetTransaction(r.Context(), r.URL.Query().Get("cc"), "fn")
+	}))
+	for i := 0; i < maxTransactionLabels+50; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, fmt.Sprintf("/?cc=cc%d", i), nil))
+	}
+	// Pairs seen before the cap keep their labels
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/?cc=cc0", nil))
+
+	lines := scrape(t, m, "hlf_api_http_requests_total")
+	if len(lines) != maxTransactionLabels+1 {
+		t.Errorf("%d series, want %d", len(lines), maxTransactionLabels+1)
+	}
+	joined := strings.Join(lines, "\n")
+	if !strings.Contains(joined, `chaincode="other",function="other",method="POST",route="unmatched",status="200"} 50`) {
+		t.Error("the pairs beyond the cap were not counted as other")
+	}
+	if !strings.Contains(joined, `chaincode="cc0",function="fn",method="POST",route="unmatched",status="200"} 2`) {
+		t.Error("a pair seen before the cap lost its labels")
+	}
+}
+
+func TestObserverAndTransactions(t *testing.T) {
+	m := New()
+	done := m.StartCall(context.Background(), fabric.CallInfo{Phase: fabric.PhaseEndorse, Peer: "peer0:7051"})
+	if lines := scrape(t, m, `hlf_api_peer_in_flight_requests{peer="peer0:7051"} 1`); len(lines) != 1 {
+		t.Error("the in-flight call was not counted")
+	}
+	done(errors.New("endorsement failed"))
+
+	m.RecordTransaction(context.Background(), &fabric.TransactionEvent{ChaincodeName: "basic", ValidationCode: "VALID"})
+	m.RecordTransaction(context.Background(), &fabric.TransactionEvent{ChaincodeName: "basic"})
+
+	for _, want := range []string{
+		`hlf_api_peer_in_flight_requests{peer="peer0:7051"} 0`,
+		`hlf_api_peer_requests_total{peer="peer0:7051",phase="endorse",result="failure"} 1`,
+		`hlf_api_transactions_total{chaincode="basic",validation_code="VALID"} 1`,
+	} {
+		if len(scrape(t, m, want)) != 1 {
+			t.Errorf("metrics do not contain %s", want)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package offline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/metrics"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/ratelimit"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/response"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/schema"
+)
+
+// PrepareRequest is the transaction to prepare for the holder of a certificate
+type PrepareRequest struct {
+	ChaincodeName string   `json:"chaincode_name" example:"basic"`
+	Function      string   `json:"function" example:"CreateAsset"`
+	Args          []string `json:"args" example:"[\"asset1\",\"blue\"]"`
+	// Transient data passed to the chaincode but not recorded on the ledger
+	Transient map[string]string `json:"transient,omitempty"`
+	// MSP ID of the signer
+	MspID string `json:"mspid" example:"Org1MSP"`
+	// PEM encoded X.509 certificate of the signer, with an ECDSA public key
+	Certificate string `json:"certificate"`
+}
+
+// SignRequest carries the signature of the digest returned by the previous step
+type SignRequest struct {
+	// Base64 encoded ASN.1 DER ECDSA signature of the digest
+	Signature []byte `json:"signature" swaggertype:"string" format:"base64"`
+}
+
+// StepResponse is the state of an offline transaction and the digest to sign next
+type StepResponse struct {
+	Handle string `json:"handle" example:"3f1c9a7e0b5d4c2a8e6f1b3d5a7c9e0f"`
+	TxID   string `json:"tx_id"`
+	// Next is the step the digest is signed for: endorse, submit or commit
+	Next string `json:"next" example:"endorse"`
+	// Base64 encoded SHA-256 digest to sign
+	Digest []byte `json:"digest" swaggertype:"string" format:"base64"`
+	// Chaincode response, once endorsed
+	Result    string    `json:"result,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// InvalidArgumentsResponse is the body of prepare requests whose arguments
+// do not match the function's schema
+type InvalidArgumentsResponse struct {
+	response.ErrorResponse
+	Fields []schema.FieldError `json:"fields"`
+}
+
+// CommitResponse is the outcome of a committed offline transaction
+type CommitResponse struct {
+	Status         string `json:"status" example:"success"`
+	Result         string `json:"result,omitempty"`
+	TxID           string `json:"tx_id"`
+	BlockNumber    uint64 `json:"block_number" example:"123"`
+	ResultCode     uint32 `json:"result_code" example:"0"`
+	ValidationCode string `json:"validation_code" example:"VALID"`
+	Success        bool   `json:"success" example:"true"`
+}
+
+// PrepareHandler godoc
+// @Summary Prepare a transaction for offline signing
+// @Description Creates the proposal of a transaction for the holder of the given certificate and returns its digest, which the client signs with its own key for the endorse step. The handle expires when no step follows within the configured time. Requests to functions with a registered schema are validated first and rejected with the invalid fields.
+// @Tags offline
+// @Accept json
+// @Produce json
+// @Param request body PrepareRequest true "Transaction and signer"
+// @Success 201 {object} StepResponse
+// @Failure 400 {object} InvalidArgumentsResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
+// @Failure 502 {object} response.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/offline/transactions [post]
+func (m *Manager) PrepareHandler(w http.ResponseWriter, r *http.Request) {
+	var req PrepareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BodyError(w, err)
+		return
+	}
+	switch {
+	case req.ChaincodeName == "":
+		response.Error(w, http.StatusBadRequest, "chaincode_name is required")
+		return
+	case req.MspID == "":
+		response.Error(w, http.StatusBadRequest, "mspid is required")
+		return
+	case req.Certificate == "":
+		response.Error(w, http.StatusBadRequest, "certificate is required")
+		return
+	}
+	if principal := auth.FromContext(r.Context()); principal != nil {
+		if !principal.Allows(m.fabricClient.ChannelName(), req.ChaincodeName, req.Function, auth.OperationInvoke) {
+			response.Error(w, http.StatusForbidden, fmt.Sprintf("%s is not allowed to %s %s on chaincode %s", principal.Name, auth.OperationInvoke, req.Function, req.ChaincodeName))
+			return
+		}
+	}
+	if validationErr := m.schemas.Check(req.ChaincodeName, req.Function, req.Args, req.Transient); validationErr != nil {
+		response.JSON(w, http.StatusBadRequest, InvalidArgumentsResponse{
+			ErrorResponse: response.ErrorResponse{Status: "error", Error: validationErr.Error()},
+			Fields:        validationErr.Fields,
+		})
+		return
+	}
+	metrics.SetTransaction(r.Context(), req.ChaincodeName, req.Function)
+	if m.limiter != nil {
+		if err := m.limiter.AllowChaincode(ratelimit.ClientKey(r), req.ChaincodeName); err != nil {
+			ratelimit.WriteError(w, err)
+			return
+		}
+	}
+
+	ctx := r.Context()
+	if len(req.Transient) > 0 {
+		transient := make(map[string][]byte, len(req.Transient))
+		for name, value := range req.Transient {
+			transient[name] = []byte(value)
+		}
+		ctx = fabric.WithTransient(ctx, transient)
+	}
+	tx, err := m.fabricClient.PrepareOffline(ctx, req.MspID, []byte(req.Certificate), req.ChaincodeName, req.Function, req.Args)
+	if err != nil {
+		m.sendError(w, r, err)
+		return
+	}
+	s, err := m.add(owner(r), tx)
+	if err != nil {
+		tx.Close()
+		m.sendError(w, r, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, m.response(s))
+}
+
+// EndorseHandler godoc
+// @Summary Endorse an offline transaction
+// @Description Endorses the proposal with the client's signature of the proposal digest and returns the chaincode response and the transaction digest to sign for the submit step
+// @Tags offline
+// @Accept json
+// @Produce json
+// @Param handle path string true "Handle of the prepared transaction"
+// @Param request body SignRequest true "Signature of the proposal digest"
+// @Success 200 {object} StepResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 502 {object} response.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/offline/transactions/{handle}/endorse [post]
+func (m *Manager) EndorseHandler(w http.ResponseWriter, r *http.Request) {
+	s, signature, ok := m.lookup(w, r)
+	if !ok {
+		return
+	}
+	if _, err := s.tx.Endorse(r.Context(), signature); err != nil {
+		m.sendError(w, r, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, m.response(s))
+}
+
+// SubmitHandler godoc
+// @Summary Submit an offline transaction
+// @Description Sends the endorsed transaction signed by the client for ordering and returns the digest of the commit status request to sign for the commit step
+// @Tags offline
+// @Accept json
+// @Produce json
+// @Param handle path string true "Handle of the prepared transaction"
+// @Param request body SignRequest true "Signature of the transaction digest"
+// @Success 200 {object} StepResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 502 {object} response.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/offline/transactions/{handle}/submit [post]
+func (m *Manager) SubmitHandler(w http.ResponseWriter, r *http.Request) {
+	s, signature, ok := m.lookup(w, r)
+	if !ok {
+		return
+	}
+	if _, err := s.tx.Submit(r.Context(), signature); err != nil {
+		m.sendError(w, r, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, m.response(s))
+}
+
+// CommitHandler godoc
+// @Summary Get the commit status of an offline transaction
+// @Description Waits for the commit of the submitted transaction with the commit status request signed by the client and releases the handle
+// @Tags offline
+// @Accept json
+// @Produce json
+// @Param handle path string true "Handle of the prepared transaction"
+// @Param request body SignRequest true "Signature of the commit status request digest"
+// @Success 200 {object} CommitResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 502 {object} response.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/offline/transactions/{handle}/commit [post]
+func (m *Manager) CommitHandler(w http.ResponseWriter, r *http.Request) {
+	s, signature, ok := m.lookup(w, r)
+	if !ok {
+		return
+	}
+	result, err := s.tx.CommitStatus(r.Context(), signature)
+	if err != nil {
+		m.sendError(w, r, err)
+		return
+	}
+	m.remove(s.handle)
+	response.JSON(w, http.StatusOK, CommitResponse{
+		Status:         "success",
+		Result:         string(result.Result),
+		TxID:           result.TxID,
+		BlockNumber:    result.BlockNumber,
+		ResultCode:     result.ResultCode,
+		ValidationCode: fabric.ValidationCodeName(result.ResultCode),
+		Success:        result.Success,
+	})
+}
+
+// GetHandler godoc
+// @Summary Get an offline transaction
+// @Description Returns the step an offline transaction waits for and the digest to sign for it
+// @Tags offline
+// @Produce json
+// @Param handle path string true "Handle of the prepared transaction"
+// @Success 200 {object} StepResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/offline/transactions/{handle} [get]
+func (m *Manager) GetHandler(w http.ResponseWriter, r *http.Request) {
+	s, err := m.get(chi.URLParam(r, "handle"), owner(r))
+	if err != nil {
+		m.sendError(w, r, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, m.response(s))
+}
+
+// DiscardHandler godoc
+// @Summary Discard an offline transaction
+// @Description Releases the handle of an offline transaction that will not be completed
+// @Tags offline
+// @Param handle path string true "Handle of the prepared transaction"
+// @Success 204
+// @Failure 404 {object} response.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/offline/transactions/{handle} [delete]
+func (m *Manager) DiscardHandler(w http.ResponseWriter, r *http.Request) {
+	s, err := m.get(chi.URLParam(r, "handle"), owner(r))
+	if err != nil {
+		m.sendError(w, r, err)
+		return
+	}
+	m.remove(s.handle)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// lookup returns the caller's transaction named in the URL and the
+// signature of the request body, replying with an error when either is missing
+func (m *Manager) lookup(w http.ResponseWriter, r *http.Request) (*session, []byte, bool) {
+	s, err := m.get(chi.URLParam(r, "handle"), owner(r))
+	if err != nil {
+		m.sendError(w, r, err)
+		return nil, nil, false
+	}
+	var req SignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BodyError(w, err)
+		return nil, nil, false
+	}
+	if len(req.Signature) == 0 {
+		response.Error(w, http.StatusBadRequest, "signature is required")
+		return nil, nil, false
+	}
+	return s, req.Signature, true
+}
+
+func (m *Manager) response(s *session) StepResponse {
+	m.mu.Lock()
+	expiresAt := s.expiresAt
+	m.mu.Unlock()
+	return StepResponse{
+		Handle:    s.handle,
+		TxID:      s.tx.TxID(),
+		Next:      string(s.tx.Step()),
+		Digest:    s.tx.Digest(),
+		Result:    string(s.tx.Result()),
+		ExpiresAt: expiresAt.UTC(),
+	}
+}
+
+// owner returns the principal handles are scoped to, empty when
+// authentication is disabled
+func owner(r *http.Request) string {
+	if principal := auth.FromContext(r.Context()); principal != nil {
+		return principal.ID()
+	}
+	return ""
+}
+
+func (m *Manager) sendError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		response.Error(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, fabric.ErrInvalidCertificate), errors.Is(err, fabric.ErrInvalidSignature):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, fabric.ErrUnexpectedStep):
+		response.Error(w, http.StatusConflict, err.Error())
+	case errors.Is(err, fabric.ErrClosed):
+		response.Error(w, http.StatusServiceUnavailable, err.Error())
+	default:
+		slog.ErrorContext(r.Context(), "offline transaction failed", "error", err)
+		response.Error(w, http.StatusBadGateway, err.Error())
+	}
+}
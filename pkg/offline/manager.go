@@ -0,0 +1,159 @@
+// Package offline lets clients sign transactions with keys the server never
+// sees. The prepared proposal, transaction and commit status request are held
+// in memory under a random handle until the client returned their signatures
+// or the handle expired.
+package offline
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/ratelimit"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/schema"
+)
+
+// ErrNotFound is returned for unknown and expired handles
+var ErrNotFound = errors.New("offline transaction not found or expired")
+
+// session is a prepared transaction waiting for the client's signatures
+type session struct {
+	handle    string
+	owner     string
+	expiresAt time.Time
+	tx        *fabric.OfflineTransaction
+}
+
+// Manager holds the offline transactions in progress
+type Manager struct {
+	fabricClient *fabric.FabricClient
+	ttl          time.Duration
+	limiter      *ratelimit.Limiter
+	schemas      *schema.Registry
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+// Option configures optional behaviour of a Manager
+type Option func(*Manager)
+
+// WithRateLimiter applies the per-chaincode request rate of the client to
+// prepared transactions
+func WithRateLimiter(limiter *ratelimit.Limiter) Option {
+	return func(m *Manager) {
+		m.limiter = limiter
+	}
+}
+
+// WithSchemas validates the arguments and transient data of prepared
+// transactions against the registered function schemas
+func WithSchemas(schemas *schema.Registry) Option {
+	return func(m *Manager) {
+		m.schemas = schemas
+	}
+}
+
+// NewManager creates a manager whose handles expire ttl after the last step
+func NewManager(fabricClient *fabric.FabricClient, ttl time.Duration, opts ...Option) *Manager {
+	m := &Manager{
+		fabricClient: fabricClient,
+		ttl:          ttl,
+		sessions:     map[string]*session{},
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Run releases expired transactions until ctx is cancelled
+func (m *Manager) Run(ctx context.Context) {
+	interval := m.ttl / 2
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.expire(now)
+		}
+	}
+}
+
+// add stores a prepared transaction under a new handle
+func (m *Manager) add(owner string, tx *fabric.OfflineTransaction) (*session, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	s := &session{
+		handle:    hex.EncodeToString(b),
+		owner:     owner,
+		expiresAt: time.Now().Add(m.ttl),
+		tx:        tx,
+	}
+	m.mu.Lock()
+	m.sessions[s.handle] = s
+	m.mu.Unlock()
+	return s, nil
+}
+
+// get returns the transaction of a handle owned by owner and extends its
+// expiry. Every handle is visible when owner is empty.
+func (m *Manager) get(handle, owner string) (*session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[handle]
+	if !ok || (owner != "" && s.owner != owner) || time.Now().After(s.expiresAt) {
+		return nil, ErrNotFound
+	}
+	s.expiresAt = time.Now().Add(m.ttl)
+	return s, nil
+}
+
+// remove releases the transaction of a handle
+func (m *Manager) remove(handle string) {
+	m.mu.Lock()
+	s, ok := m.sessions[handle]
+	delete(m.sessions, handle)
+	m.mu.Unlock()
+	if ok {
+		s.tx.Close()
+	}
+}
+
+func (m *Manager) expire(now time.Time) {
+	var expired []*session
+	m.mu.Lock()
+	for handle, s := range m.sessions {
+		if now.After(s.expiresAt) {
+			expired = append(expired, s)
+			delete(m.sessions, handle)
+		}
+	}
+	m.mu.Unlock()
+	for _, s := range expired {
+		s.tx.Close()
+	}
+}
+
+// Close releases the transactions in progress, which are reported as not
+// completed
+func (m *Manager) Close() {
+	m.mu.Lock()
+	sessions := m.sessions
+	m.sessions = map[string]*session{}
+	m.mu.Unlock()
+	for _, s := range sessions {
+		s.tx.Close()
+	}
+}
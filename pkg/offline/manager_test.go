@@ -0,0 +1,241 @@
+package offline
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/schema"
+)
+
+// newTestManager returns a manager whose Fabric client prepares transactions
+// without reaching its peer, and the PEM certificate of a signer
+func newTestManager(t *testing.T, ttl time.Duration, opts ...Option) (*Manager, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "user1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caPath, cert, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fabricClient, err := fabric.NewFabricClient(&fabric.ClientConfig{
+		ChannelName: "mychannel",
+		Peers:       []fabric.PeerConfig{{Endpoint: "127.0.0.1:1", TLSCertPath: caPath}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(fabricClient, ttl, opts...)
+	t.Cleanup(func() {
+		m.Close()
+		fabricClient.Close()
+	})
+	return m, string(cert)
+}
+
+// prepare adds a transaction of owner to m
+func prepare(t *testing.T, m *Manager, cert, owner string) *session {
+	t.Helper()
+	tx, err := m.fabricClient.PrepareOffline(context.Background(), "Org1MSP", []byte(cert), "basic", "CreateAsset", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := m.add(owner, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSessions(t *testing.T) {
+	m, cert := newTestManager(t, time.Minute)
+	s := prepare(t, m, cert, "alice")
+	if len(s.handle) != 32 {
+		t.Errorf("handle = %q, want 32 hex characters", s.handle)
+	}
+
+	tests := []struct {
+		name    string
+		handle  string
+		owner   string
+		wantErr error
+	}{
+		{"owner", s.handle, "alice", nil},
+		{"authentication disabled", s.handle, "", nil},
+		{"other owner", s.handle, "bob", ErrNotFound},
+		{"unknown handle", "unknown", "alice", ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := m.get(tt.handle, tt.owner); !errors.Is(err, tt.wantErr) {
+				t.Errorf("get() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	m.remove(s.handle)
+	if _, err := m.get(s.handle, "alice"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("get() after remove error = %v, want %v", err, ErrNotFound)
+	}
+	if _, err := s.tx.Endorse(context.Background(), []byte("signature")); !errors.Is(err, fabric.ErrUnexpectedStep) {
+		t.Errorf("Endorse() of a removed transaction error = %v, want it closed", err)
+	}
+}
+
+func TestExpire(t *testing.T) {
+	m, cert := newTestManager(t, time.Minute)
+	s := prepare(t, m, cert, "alice")
+
+	// A step extends the expiry
+	m.mu.Lock()
+	s.expiresAt = time.Now().Add(time.Second)
+	m.mu.Unlock()
+	if _, err := m.get(s.handle, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if remaining := time.Until(s.expiresAt); remaining < 50*time.Second {
+		t.Errorf("expiry in %v after a step, want the TTL", remaining)
+	}
+
+	m.expire(time.Now())
+	if len(m.sessions) != 1 {
+		t.Fatalf("expire() released an active transaction")
+	}
+	m.expire(time.Now().Add(2 * time.Minute))
+	if _, err := m.get(s.handle, "alice"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("get() after expiry error = %v, want %v", err, ErrNotFound)
+	}
+	if _, err := s.tx.Endorse(context.Background(), []byte("signature")); !errors.Is(err, fabric.ErrUnexpectedStep) {
+		t.Errorf("Endorse() of an expired transaction error = %v, want it closed", err)
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	m, cert := newTestManager(t, time.Minute)
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, 4096)
+			next.ServeHTTP(w, r)
+		})
+	})
+	r.Post("/api/offline/transactions", m.PrepareHandler)
+	r.Get("/api/offline/transactions/{handle}", m.GetHandler)
+	r.Delete("/api/offline/transactions/{handle}", m.DiscardHandler)
+	r.Post("/api/offline/transactions/{handle}/endorse", m.EndorseHandler)
+	r.Post("/api/offline/transactions/{handle}/submit", m.SubmitHandler)
+
+	alice := &auth.Principal{Name: "alice", Method: "api_key", Scopes: []auth.Scope{{Chaincodes: []string{"basic"}}}}
+	requestAs := func(principal *auth.Principal, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req = req.WithContext(auth.NewContext(req.Context(), principal))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		return requestAs(alice, method, path, body)
+	}
+
+	prepareBody, _ := json.Marshal(PrepareRequest{ChaincodeName: "basic", Function: "CreateAsset", MspID: "Org1MSP", Certificate: cert})
+	w := request(http.MethodPost, "/api/offline/transactions", string(prepareBody))
+	var step StepResponse
+	if w.Code != http.StatusCreated || json.Unmarshal(w.Body.Bytes(), &step) != nil || step.Next != "endorse" || len(step.Digest) == 0 {
+		t.Fatalf("prepare = %d %s, want the endorse digest", w.Code, w.Body)
+	}
+	path := "/api/offline/transactions/" + step.Handle
+	aliceJWT := &auth.Principal{Name: "alice", Method: "jwt", Scopes: alice.Scopes}
+	if w := requestAs(aliceJWT, http.MethodGet, path, ""); w.Code != http.StatusNotFound {
+		t.Errorf("GET by a principal of the same name from another method status = %d, want 404", w.Code)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"oversized request", http.MethodPost, "/api/offline/transactions", `{"chaincode_name": "` + strings.Repeat("a", 4096) + `"}`, http.StatusRequestEntityTooLarge},
+		{"missing certificate", http.MethodPost, "/api/offline/transactions", `{"chaincode_name": "basic", "mspid": "Org1MSP"}`, http.StatusBadRequest},
+		{"chaincode out of scope", http.MethodPost, "/api/offline/transactions", `{"chaincode_name": "other", "mspid": "Org1MSP", "certificate": "cert"}`, http.StatusForbidden},
+		{"invalid certificate", http.MethodPost, "/api/offline/transactions", `{"chaincode_name": "basic", "mspid": "Org1MSP", "certificate": "cert"}`, http.StatusBadRequest},
+		{"get", http.MethodGet, path, "", http.StatusOK},
+		{"unknown handle", http.MethodGet, "/api/offline/transactions/unknown", "", http.StatusNotFound},
+		{"missing signature", http.MethodPost, path + "/endorse", `{}`, http.StatusBadRequest},
+		{"oversized signature", http.MethodPost, path + "/endorse", `{"signature": "` + strings.Repeat("c2ln", 1024) + `"}`, http.StatusRequestEntityTooLarge},
+		{"invalid signature", http.MethodPost, path + "/endorse", `{"signature": "c2lnbmF0dXJl"}`, http.StatusBadRequest},
+		{"unexpected step", http.MethodPost, path + "/submit", `{"signature": "c2lnbmF0dXJl"}`, http.StatusConflict},
+		{"discard", http.MethodDelete, path, "", http.StatusNoContent},
+		{"discarded", http.MethodGet, path, "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := request(tt.method, tt.path, tt.body); w.Code != tt.status {
+				t.Errorf("%s %s status = %d, want %d: %s", tt.method, tt.path, w.Code, tt.status, w.Body)
+			}
+		})
+	}
+}
+
+func TestPrepareHandlerValidatesSchemas(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "basic"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	schemaFile := `{"properties": {"args": {"prefixItems": [{"type": "string", "minLength": 1}]}}}`
+	if err := os.WriteFile(filepath.Join(dir, "basic", "CreateAsset.json"), []byte(schemaFile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	schemas, err := schema.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, cert := newTestManager(t, time.Minute, WithSchemas(schemas))
+
+	prepare := func(args ...string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(PrepareRequest{ChaincodeName: "basic", Function: "CreateAsset", Args: args, MspID: "Org1MSP", Certificate: cert})
+		w := httptest.NewRecorder()
+		m.PrepareHandler(w, httptest.NewRequest(http.MethodPost, "/api/offline/transactions", strings.NewReader(string(body))))
+		return w
+	}
+	w := prepare("")
+	var resp InvalidArgumentsResponse
+	if w.Code != http.StatusBadRequest || json.Unmarshal(w.Body.Bytes(), &resp) != nil || len(resp.Fields) != 1 || resp.Fields[0].Field != "/args/0" {
+		t.Errorf("prepare of invalid arguments = %d %s, want 400 with the invalid field", w.Code, w.Body)
+	}
+	if w := prepare("asset1"); w.Code != http.StatusCreated {
+		t.Errorf("prepare of valid arguments = %d %s, want 201", w.Code, w.Body)
+	}
+}
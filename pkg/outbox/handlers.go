@@ -0,0 +1,156 @@
+package outbox
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/response"
+)
+
+// ListHandler godoc
+// @Summary List queued invokes
+// @Description Returns the invokes waiting to be submitted, in submission order. Callers see the invokes they queued; callers allowed to invoke every chaincode of the channel see all of them.
+// @Tags outbox
+// @Produce json
+// @Success 200 {array} Item
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/outbox [get]
+func (m *Manager) ListHandler(w http.ResponseWriter, r *http.Request) {
+	records, err := m.store.Queued()
+	if err != nil {
+		m.sendStoreError(w, r, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, m.visibleItems(r, records))
+}
+
+// GetHandler godoc
+// @Summary Get the status of a queued invoke
+// @Description Returns a queued invoke with its attempts, or the transaction it was committed with. Committed invokes are kept for the configured retention.
+// @Tags outbox
+// @Produce json
+// @Param id path string true "Outbox item ID"
+// @Success 200 {object} Item
+// @Failure 404 {object} response.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/outbox/{id} [get]
+func (m *Manager) GetHandler(w http.ResponseWriter, r *http.Request) {
+	rec, err := m.store.Item(chi.URLParam(r, "id"))
+	if err == nil && !m.visible(r, rec) {
+		err = ErrNotFound
+	}
+	if err != nil {
+		m.sendStoreError(w, r, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, rec.Item)
+}
+
+// DeadLettersHandler godoc
+// @Summary List failed invokes
+// @Description Returns the invokes that failed permanently, oldest failure first: the chaincode rejected them, their transaction was invalidated, or the outcome of their submitted transaction could not be found
+// @Tags outbox
+// @Produce json
+// @Success 200 {array} Item
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/outbox/dead-letters [get]
+func (m *Manager) DeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	records, err := m.store.DeadLetters()
+	if err != nil {
+		m.sendStoreError(w, r, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, m.visibleItems(r, records))
+}
+
+// RetryHandler godoc
+// @Summary Retry a failed invoke
+// @Description Moves a failed invoke to the end of the queue. It is submitted as a new transaction.
+// @Tags outbox
+// @Produce json
+// @Param id path string true "Outbox item ID"
+// @Success 200 {object} Item
+// @Failure 404 {object} response.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/outbox/dead-letters/{id}/retry [post]
+func (m *Manager) RetryHandler(w http.ResponseWriter, r *http.Request) {
+	if !m.lookupDeadLetter(w, r) {
+		return
+	}
+	item, err := m.Retry(chi.URLParam(r, "id"))
+	if err != nil {
+		m.sendStoreError(w, r, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, item)
+}
+
+// DiscardHandler godoc
+// @Summary Discard a failed invoke
+// @Tags outbox
+// @Param id path string true "Outbox item ID"
+// @Success 204
+// @Failure 404 {object} response.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/outbox/dead-letters/{id} [delete]
+func (m *Manager) DiscardHandler(w http.ResponseWriter, r *http.Request) {
+	if !m.lookupDeadLetter(w, r) {
+		return
+	}
+	if err := m.store.Discard(chi.URLParam(r, "id")); err != nil {
+		m.sendStoreError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// lookupDeadLetter checks that the item named by the request path is a dead
+// letter the caller may manage, replying 404 otherwise
+func (m *Manager) lookupDeadLetter(w http.ResponseWriter, r *http.Request) bool {
+	rec, err := m.store.Item(chi.URLParam(r, "id"))
+	if err == nil && (rec.Status != StatusFailed || !m.visible(r, rec)) {
+		err = ErrNotFound
+	}
+	if err != nil {
+		m.sendStoreError(w, r, err)
+		return false
+	}
+	return true
+}
+
+// visible reports whether the caller of r may see and manage the item: its
+// owner and principals allowed to invoke every chaincode of the channel may.
+// Every item is visible when authentication is disabled.
+func (m *Manager) visible(r *http.Request, rec *record) bool {
+	principal := auth.FromContext(r.Context())
+	return principal == nil || principal.ID() == rec.Owner ||
+		principal.AllowsChannel(m.fabricClient.ChannelName(), auth.OperationInvoke)
+}
+
+func (m *Manager) visibleItems(r *http.Request, records []*record) []Item {
+	items := []Item{}
+	for _, rec := range records {
+		if m.visible(r, rec) {
+			items = append(items, rec.Item)
+		}
+	}
+	return items
+}
+
+func (m *Manager) sendStoreError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, ErrNotFound) {
+		response.Error(w, http.StatusNotFound, "not found")
+		return
+	}
+	slog.ErrorContext(r.Context(), "outbox store failed", "error", err)
+	response.Error(w, http.StatusInternalServerError, "internal error")
+}
@@ -0,0 +1,85 @@
+package outbox
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+)
+
+func TestHandlers(t *testing.T) {
+	m := newTestManager(t, false)
+	for _, rec := range []*record{
+		{Item: Item{ID: "failed", Status: StatusFailed, Owner: "api_key:alice"}},
+		{Item: Item{ID: "discarded", Status: StatusFailed, Owner: "api_key:alice"}},
+	} {
+		if err := m.store.Enqueue(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range []string{"failed", "discarded"} {
+		rec, key := head(t, m.store)
+		if rec.ID != id {
+			t.Fatalf("Head() = %s, want %s", rec.ID, id)
+		}
+		if err := m.store.DeadLetter(key, rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.store.Enqueue(&record{Item: Item{ID: "queued", Status: StatusQueued, Owner: "api_key:alice"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := chi.NewRouter()
+	r.Get("/api/outbox", m.ListHandler)
+	r.Get("/api/outbox/dead-letters", m.DeadLettersHandler)
+	r.Post("/api/outbox/dead-letters/{id}/retry", m.RetryHandler)
+	r.Delete("/api/outbox/dead-letters/{id}", m.DiscardHandler)
+	r.Get("/api/outbox/{id}", m.GetHandler)
+
+	alice := &auth.Principal{Name: "alice", Method: "api_key", Scopes: []auth.Scope{{Chaincodes: []string{"basic"}}}}
+	aliceJWT := &auth.Principal{Name: "alice", Method: "jwt", Scopes: []auth.Scope{{Chaincodes: []string{"basic"}}}}
+	bob := &auth.Principal{Name: "bob", Method: "api_key", Scopes: []auth.Scope{{Chaincodes: []string{"basic"}}}}
+	operator := &auth.Principal{Name: "operator", Scopes: []auth.Scope{{Operations: []auth.Operation{auth.OperationInvoke}}}}
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		principal *auth.Principal
+		status    int
+		items     int
+	}{
+		{name: "owner lists", method: http.MethodGet, path: "/api/outbox", principal: alice, status: http.StatusOK, items: 1},
+		{name: "other principal lists", method: http.MethodGet, path: "/api/outbox", principal: bob, status: http.StatusOK, items: 0},
+		{name: "operator lists", method: http.MethodGet, path: "/api/outbox/dead-letters", principal: operator, status: http.StatusOK, items: 2},
+		{name: "owner gets", method: http.MethodGet, path: "/api/outbox/queued", principal: alice, status: http.StatusOK},
+		{name: "other principal gets", method: http.MethodGet, path: "/api/outbox/queued", principal: bob, status: http.StatusNotFound},
+		{name: "same name of another method gets", method: http.MethodGet, path: "/api/outbox/queued", principal: aliceJWT, status: http.StatusNotFound},
+		{name: "unknown item", method: http.MethodGet, path: "/api/outbox/unknown", principal: alice, status: http.StatusNotFound},
+		{name: "retry of a queued item", method: http.MethodPost, path: "/api/outbox/dead-letters/queued/retry", principal: alice, status: http.StatusNotFound},
+		{name: "retry by another principal", method: http.MethodPost, path: "/api/outbox/dead-letters/failed/retry", principal: bob, status: http.StatusNotFound},
+		{name: "retry", method: http.MethodPost, path: "/api/outbox/dead-letters/failed/retry", principal: alice, status: http.StatusOK},
+		{name: "discard", method: http.MethodDelete, path: "/api/outbox/dead-letters/discarded", principal: operator, status: http.StatusNoContent},
+		{name: "discarded", method: http.MethodGet, path: "/api/outbox/discarded", principal: alice, status: http.StatusNotFound},
+		{name: "queue after the retry", method: http.MethodGet, path: "/api/outbox", principal: alice, status: http.StatusOK, items: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req = req.WithContext(auth.NewContext(req.Context(), tt.principal))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			var items []Item
+			if tt.status == http.StatusOK && json.Unmarshal(w.Body.Bytes(), &items) == nil && len(items) != tt.items {
+				t.Errorf("items = %s, want %d", w.Body, tt.items)
+			}
+		})
+	}
+}
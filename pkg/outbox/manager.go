@@ -0,0 +1,335 @@
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+)
+
+// lookupAttempts is how often a submitted transaction whose outcome is
+// unknown is looked up on the ledger before the item is dead-lettered
+const lookupAttempts = 5
+
+// Request is an invoke to queue
+type Request struct {
+	ChaincodeName string
+	Function      string
+	Args          []string
+	Transient     map[string]string
+}
+
+// Manager queues invokes and submits them one at a time, in the order they
+// were queued. An item that fails because the network is unreachable is
+// retried with backoff before the items behind it are tried; an item that
+// fails permanently is dead-lettered so that the queue moves on.
+type Manager struct {
+	store        *Store
+	fabricClient *fabric.FabricClient
+	config       Config
+	// wake is signalled when an item is queued
+	wake chan struct{}
+}
+
+// NewManager creates a manager submitting the items kept in store
+func NewManager(store *Store, fabricClient *fabric.FabricClient, config Config) *Manager {
+	m := &Manager{
+		store:        store,
+		fabricClient: fabricClient,
+		config:       config,
+		wake:         make(chan struct{}, 1),
+	}
+	// Failed invokes return neither the transaction ID nor whether the
+	// transaction reached ordering, so the worker reads them from the
+	// transaction events
+	fabricClient.AddTransactionListener(m.recordAttempt)
+	return m
+}
+
+// attemptKey marks the context of an invoke made by the worker
+type attemptKey struct{}
+
+func (m *Manager) recordAttempt(ctx context.Context, event *fabric.TransactionEvent) {
+	if recorded, ok := ctx.Value(attemptKey{}).(*fabric.TransactionEvent); ok {
+		*recorded = *event
+	}
+}
+
+// Enqueue stores an invoke for submission on behalf of the caller of ctx
+func (m *Manager) Enqueue(ctx context.Context, req Request) (*Item, error) {
+	id, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	rec := &record{
+		Item: Item{
+			ID:            id,
+			Status:        StatusQueued,
+			ChaincodeName: req.ChaincodeName,
+			Function:      req.Function,
+			Args:          req.Args,
+			CreatedAt:     time.Now().UTC(),
+		},
+		Transient: req.Transient,
+		Identity:  fabric.IdentityFromContext(ctx),
+	}
+	if principal := auth.FromContext(ctx); principal != nil {
+		rec.Owner = principal.ID()
+	}
+	if err := m.store.Enqueue(rec); err != nil {
+		return nil, err
+	}
+	m.notify()
+	slog.InfoContext(ctx, "queued invoke", "outbox_id", id, "chaincode", req.ChaincodeName, "function", req.Function)
+	return &rec.Item, nil
+}
+
+// Item returns a stored item
+func (m *Manager) Item(id string) (*Item, error) {
+	rec, err := m.store.Item(id)
+	if err != nil {
+		return nil, err
+	}
+	return &rec.Item, nil
+}
+
+// Retry moves a dead letter to the end of the queue. The item is submitted
+// as a new transaction.
+func (m *Manager) Retry(id string) (*Item, error) {
+	rec, err := m.store.Requeue(id, func(rec *record) {
+		rec.Status = StatusQueued
+		rec.Attempts = 0
+		rec.LastError = ""
+		rec.NextAttemptAt = nil
+		rec.TxID = ""
+		rec.BlockNumber = 0
+		rec.ValidationCode = ""
+		rec.CompletedAt = nil
+		rec.Submitted = false
+		rec.Lookups = 0
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.notify()
+	return &rec.Item, nil
+}
+
+func (m *Manager) notify() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run submits the queued items until ctx is cancelled and removes committed
+// items once their retention has passed
+func (m *Manager) Run(ctx context.Context) {
+	sweep := time.NewTicker(sweepInterval(m.config.Retention))
+	defer sweep.Stop()
+	for ctx.Err() == nil {
+		select {
+		case <-sweep.C:
+			m.sweep(ctx)
+		default:
+		}
+
+		rec, key, err := m.store.Head()
+		var wait time.Duration
+		switch {
+		case err != nil:
+			slog.ErrorContext(ctx, "failed to read the outbox queue", "error", err)
+			wait = m.config.MaxBackoff
+		case rec == nil:
+			// Wait for the next item
+		case rec.NextAttemptAt != nil && time.Until(*rec.NextAttemptAt) > 0:
+			wait = time.Until(*rec.NextAttemptAt)
+		default:
+			m.attempt(ctx, key, rec)
+			continue
+		}
+
+		var timeout <-chan time.Time
+		var timer *time.Timer
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			timeout = timer.C
+		}
+		select {
+		case <-ctx.Done():
+		case <-m.wake:
+		case <-timeout:
+		case <-sweep.C:
+			m.sweep(ctx)
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// attempt submits the item at the head of the queue, or looks up the
+// transaction of a previous attempt whose outcome is unknown
+func (m *Manager) attempt(ctx context.Context, key []byte, rec *record) {
+	callCtx := ctx
+	if rec.Owner != "" {
+		callCtx = auth.NewContext(callCtx, &auth.Principal{Name: rec.Owner, Method: "outbox"})
+	}
+	if rec.Identity != "" {
+		callCtx = fabric.WithIdentity(callCtx, rec.Identity)
+	}
+	if len(rec.Transient) > 0 {
+		transient := make(map[string][]byte, len(rec.Transient))
+		for name, value := range rec.Transient {
+			transient[name] = []byte(value)
+		}
+		callCtx = fabric.WithTransient(callCtx, transient)
+	}
+	rec.Attempts++
+
+	if rec.Submitted {
+		m.lookup(ctx, callCtx, key, rec)
+		return
+	}
+
+	event := &fabric.TransactionEvent{}
+	result, err := m.fabricClient.InvokeTransaction(context.WithValue(callCtx, attemptKey{}, event), rec.ChaincodeName, rec.Function, rec.Args)
+	if event.TxID != "" {
+		rec.TxID = event.TxID
+	}
+	switch {
+	case err == nil:
+		rec.Result = string(result.Result)
+		m.finish(ctx, key, rec, result.BlockNumber, event.ValidationCode, result.Success)
+	case event.Submitted:
+		// The transaction may still be committed, so it is looked up
+		// rather than submitted again
+		rec.Submitted = true
+		m.retry(ctx, rec, err)
+	case transient(err):
+		m.retry(ctx, rec, err)
+	default:
+		m.fail(ctx, key, rec, err.Error())
+	}
+}
+
+// lookup resolves the outcome of a submitted transaction from the ledger
+func (m *Manager) lookup(ctx, callCtx context.Context, key []byte, rec *record) {
+	tx, err := m.fabricClient.Transaction(callCtx, rec.TxID)
+	switch {
+	case err == nil:
+		m.finish(ctx, key, rec, tx.BlockNumber, tx.ValidationCode, tx.ValidationCode == fabric.ValidationCodeName(0))
+	case transient(err):
+		m.retry(ctx, rec, err)
+	case rec.Lookups+1 < lookupAttempts:
+		rec.Lookups++
+		m.retry(ctx, rec, fmt.Errorf("transaction %s was submitted but is not on the ledger yet: %w", rec.TxID, err))
+	default:
+		m.fail(ctx, key, rec, fmt.Sprintf("transaction %s was submitted but was not found on the ledger; retrying submits a new transaction: %v", rec.TxID, err))
+	}
+}
+
+// finish records the commit of the item's transaction, dead-lettering items
+// whose transaction was invalidated
+func (m *Manager) finish(ctx context.Context, key []byte, rec *record, blockNumber uint64, validationCode string, valid bool) {
+	rec.BlockNumber = blockNumber
+	rec.ValidationCode = validationCode
+	if !valid {
+		m.fail(ctx, key, rec, fmt.Sprintf("transaction %s was invalidated with %s", rec.TxID, validationCode))
+		return
+	}
+	now := time.Now().UTC()
+	rec.Status = StatusCommitted
+	rec.LastError = ""
+	rec.NextAttemptAt = nil
+	rec.CompletedAt = &now
+	rec.Submitted = false
+	rec.Lookups = 0
+	// Transient data is only kept for as long as it may be submitted again
+	rec.Transient = nil
+	if err := m.store.Complete(key, rec); err != nil {
+		slog.ErrorContext(ctx, "failed to record committed outbox item", "outbox_id", rec.ID, "error", err)
+		return
+	}
+	slog.InfoContext(ctx, "outbox item committed", "outbox_id", rec.ID, "tx_id", rec.TxID, "attempts", rec.Attempts)
+}
+
+// retry schedules the next attempt of the item, which stays at the head of
+// the queue
+func (m *Manager) retry(ctx context.Context, rec *record, err error) {
+	wait := m.config.backoff(rec.Attempts - 1)
+	next := time.Now().UTC().Add(wait)
+	rec.LastError = err.Error()
+	rec.NextAttemptAt = &next
+	if err := m.store.Put(rec); err != nil {
+		slog.ErrorContext(ctx, "failed to record outbox attempt", "outbox_id", rec.ID, "error", err)
+		return
+	}
+	slog.WarnContext(ctx, "outbox item failed, retrying", "outbox_id", rec.ID, "attempts", rec.Attempts, "retry_in", wait.String(), "error", err)
+}
+
+// fail moves the item to the dead letters
+func (m *Manager) fail(ctx context.Context, key []byte, rec *record, reason string) {
+	now := time.Now().UTC()
+	rec.Status = StatusFailed
+	rec.LastError = reason
+	rec.NextAttemptAt = nil
+	rec.CompletedAt = &now
+	rec.Submitted = false
+	rec.Lookups = 0
+	if err := m.store.DeadLetter(key, rec); err != nil {
+		slog.ErrorContext(ctx, "failed to dead-letter outbox item", "outbox_id", rec.ID, "error", err)
+		return
+	}
+	slog.ErrorContext(ctx, "outbox item dead-lettered", "outbox_id", rec.ID, "attempts", rec.Attempts, "error", reason)
+}
+
+func (m *Manager) sweep(ctx context.Context) {
+	removed, err := m.store.Sweep(time.Now().Add(-m.config.Retention))
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to sweep the outbox", "error", err)
+		return
+	}
+	if removed > 0 {
+		slog.DebugContext(ctx, "removed committed outbox items", "count", removed)
+	}
+}
+
+// transient reports whether an invoke failed because the network could not
+// be reached, rather than because of the transaction itself
+func transient(err error) bool {
+	if errors.Is(err, fabric.ErrClosed) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return true
+	}
+	return false
+}
+
+// sweepInterval returns how often committed items are swept
+func sweepInterval(retention time.Duration) time.Duration {
+	interval := retention / 2
+	if interval > time.Minute || interval <= 0 {
+		interval = time.Minute
+	}
+	return interval
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
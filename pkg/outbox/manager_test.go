@@ -0,0 +1,215 @@
+package outbox
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+)
+
+var testConfig = Config{
+	MinBackoff: time.Minute,
+	MaxBackoff: 4 * time.Minute,
+	Retention:  time.Hour,
+}
+
+// writeIdentity writes a self-signed certificate, usable as both the signing
+// identity and the TLS root of the peer, and its private key to dir
+func writeIdentity(t *testing.T, dir string) (certPath, keyPath string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "user1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPath, keyPath = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certPath, keyPath
+}
+
+// newTestManager returns a manager whose peer is not listening. Without an
+// identity every invoke fails permanently; with one it fails as unreachable.
+func newTestManager(t *testing.T, withIdentity bool) *Manager {
+	t.Helper()
+	dir := t.TempDir()
+	config := &fabric.ClientConfig{
+		MspID:       "Org1MSP",
+		CertPath:    filepath.Join(dir, "missing-cert.pem"),
+		KeyPath:     filepath.Join(dir, "missing-key.pem"),
+		ChannelName: "mychannel",
+		Peers:       []fabric.PeerConfig{{Endpoint: "127.0.0.1:1", TLSCertPath: filepath.Join(dir, "missing-ca.pem")}},
+	}
+	if withIdentity {
+		config.CertPath, config.KeyPath = writeIdentity(t, dir)
+		config.Peers[0].TLSCertPath = config.CertPath
+	}
+	fabricClient, err := fabric.NewFabricClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { fabricClient.Close() })
+	return NewManager(newTestStore(t), fabricClient, testConfig)
+}
+
+// attemptHead makes one attempt of the item at the head of the queue
+func attemptHead(t *testing.T, m *Manager) {
+	t.Helper()
+	rec, key := head(t, m.store)
+	if rec == nil {
+		t.Fatal("the queue is empty")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	m.attempt(ctx, key, rec)
+}
+
+func TestEnqueue(t *testing.T) {
+	m := newTestManager(t, false)
+	ctx := auth.NewContext(context.Background(), &auth.Principal{Name: "alice", Method: "api_key"})
+	ctx = fabric.WithIdentity(ctx, "admin")
+	item, err := m.Enqueue(ctx, Request{ChaincodeName: "basic", Function: "CreateAsset", Args: []string{"asset1"}, Transient: map[string]string{"price": "10"}})
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if item.Status != StatusQueued || item.Owner != "api_key:alice" || len(item.ID) != 32 {
+		t.Errorf("Enqueue() = %+v", item)
+	}
+	rec, _ := head(t, m.store)
+	if rec == nil || rec.ID != item.ID || rec.Identity != "admin" || rec.Transient["price"] != "10" {
+		t.Errorf("queued record = %+v, want the identity and transient data of the request", rec)
+	}
+	select {
+	case <-m.wake:
+	default:
+		t.Error("Enqueue() did not wake the worker")
+	}
+}
+
+func TestAttemptDeadLettersPermanentFailures(t *testing.T) {
+	m := newTestManager(t, false)
+	item, err := m.Enqueue(context.Background(), Request{ChaincodeName: "basic", Function: "CreateAsset", Transient: map[string]string{"price": "10"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	attemptHead(t, m)
+
+	if rec, _ := head(t, m.store); rec != nil {
+		t.Errorf("Head() = %+v, want the failed item out of the queue", rec)
+	}
+	letters, err := m.store.DeadLetters()
+	if err != nil || len(letters) != 1 {
+		t.Fatalf("DeadLetters() = %v, %v", letters, err)
+	}
+	if letter := letters[0]; letter.ID != item.ID || letter.Status != StatusFailed || letter.Attempts != 1 || letter.LastError == "" || letter.CompletedAt == nil {
+		t.Errorf("dead letter = %+v", letter)
+	}
+
+	retried, err := m.Retry(item.ID)
+	if err != nil {
+		t.Fatalf("Retry() error = %v", err)
+	}
+	if retried.Status != StatusQueued || retried.Attempts != 0 || retried.LastError != "" || retried.CompletedAt != nil {
+		t.Errorf("Retry() = %+v, want a fresh queued item", retried)
+	}
+	if rec, _ := head(t, m.store); rec == nil || rec.ID != item.ID || rec.Transient["price"] != "10" {
+		t.Errorf("Head() after Retry = %+v, want the item with its transient data", rec)
+	}
+	if _, err := m.Retry(item.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Retry() of a queued item error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestAttemptRetriesUnreachablePeers(t *testing.T) {
+	m := newTestManager(t, true)
+	item, err := m.Enqueue(context.Background(), Request{ChaincodeName: "basic", Function: "CreateAsset"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := time.Now()
+	attemptHead(t, m)
+
+	rec, _ := head(t, m.store)
+	if rec == nil || rec.ID != item.ID {
+		t.Fatalf("Head() = %+v, want the item to stay at the head of the queue", rec)
+	}
+	if rec.Status != StatusQueued || rec.Attempts != 1 || rec.LastError == "" || rec.Submitted {
+		t.Errorf("retried item = %+v", rec)
+	}
+	if rec.NextAttemptAt == nil || rec.NextAttemptAt.Before(before.Add(testConfig.MinBackoff)) {
+		t.Errorf("NextAttemptAt = %v, want the minimum backoff", rec.NextAttemptAt)
+	}
+}
+
+func TestTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{fabric.ErrClosed, true},
+		{fmt.Errorf("failed to endorse: %w", context.DeadlineExceeded), true},
+		{status.Error(codes.Unavailable, "connection refused"), true},
+		{status.Error(codes.ResourceExhausted, "too many requests"), true},
+		{status.Error(codes.Aborted, "chaincode error"), false},
+		{errors.New("failed to read certificate file"), false},
+	}
+	for _, tt := range tests {
+		if got := transient(tt.err); got != tt.want {
+			t.Errorf("transient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	config := Config{MinBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	for retry, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if got := config.backoff(retry); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", retry, got, want)
+		}
+	}
+}
+
+func TestSweepInterval(t *testing.T) {
+	for retention, want := range map[time.Duration]time.Duration{
+		0:                time.Minute,
+		30 * time.Second: 15 * time.Second,
+		24 * time.Hour:   time.Minute,
+	} {
+		if got := sweepInterval(retention); got != want {
+			t.Errorf("sweepInterval(%v) = %v, want %v", retention, got, want)
+		}
+	}
+}
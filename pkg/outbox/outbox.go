@@ -0,0 +1,82 @@
+// Package outbox keeps invokes accepted while the network may be unreachable
+// in a local database and submits them in order from a background worker, so
+// that clients do not lose work during peer or orderer outages.
+package outbox
+
+import (
+	"time"
+)
+
+// Status is the state of a queued invoke
+type Status string
+
+const (
+	// StatusQueued items wait for their turn or for the next retry
+	StatusQueued Status = "queued"
+	// StatusCommitted items were committed as valid transactions
+	StatusCommitted Status = "committed"
+	// StatusFailed items failed permanently and are kept as dead letters
+	// until they are retried or discarded
+	StatusFailed Status = "failed"
+)
+
+// Item is an invoke accepted into the outbox
+type Item struct {
+	ID            string   `json:"id" example:"4f7d0c1e9b2a43d58c6e1f0a2b3c4d5e"`
+	Status        Status   `json:"status" example:"queued"`
+	ChaincodeName string   `json:"chaincode_name" example:"basic"`
+	Function      string   `json:"function" example:"CreateAsset"`
+	Args          []string `json:"args"`
+	// Owner is the ID of the principal that queued the invoke
+	Owner    string `json:"owner,omitempty"`
+	Attempts int    `json:"attempts"`
+	// LastError is why the last attempt failed
+	LastError string `json:"last_error,omitempty"`
+	// NextAttemptAt is when a failed attempt is retried
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	// TxID is the ID of the last transaction created for the item
+	TxID           string `json:"tx_id,omitempty"`
+	BlockNumber    uint64 `json:"block_number,omitempty"`
+	ValidationCode string `json:"validation_code,omitempty"`
+	// Result is the chaincode response of the committed transaction
+	Result    string    `json:"result,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// CompletedAt is when the item was committed or dead-lettered
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// record is the stored form of an item with the fields that are needed to
+// submit it but must not be returned by the API
+type record struct {
+	Item
+	Transient map[string]string `json:"transient,omitempty"`
+	// Identity is the signing identity selected for the owner
+	Identity string `json:"identity,omitempty"`
+	// Submitted reports that TxID was sent for ordering without its outcome
+	// becoming known, so the next attempt looks it up instead of submitting
+	// a second transaction
+	Submitted bool `json:"submitted,omitempty"`
+	// Lookups counts the lookups that did not find the submitted transaction
+	Lookups int `json:"lookups,omitempty"`
+}
+
+// Config tunes the submission of queued invokes
+type Config struct {
+	// MinBackoff and MaxBackoff bound the exponential wait between attempts
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Retention is how long committed items can be looked up
+	Retention time.Duration
+}
+
+// backoff returns the wait before the given retry, starting at 0
+func (c Config) backoff(retry int) time.Duration {
+	wait := c.MinBackoff
+	for i := 0; i < retry && wait < c.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > c.MaxBackoff {
+		wait = c.MaxBackoff
+	}
+	return wait
+}
@@ -0,0 +1,296 @@
+package outbox
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	itemsBucket = []byte("outbox_items")
+	// queueBucket maps a sequence number to the ID of a queued item, so
+	// that items are submitted in the order they were queued
+	queueBucket       = []byte("outbox_queue")
+	deadLettersBucket = []byte("outbox_dead_letters")
+)
+
+// ErrNotFound is returned for unknown items and dead letters
+var ErrNotFound = errors.New("not found")
+
+// Store keeps the queued, committed and dead-lettered items in a bbolt
+// database file so that queued invokes survive a restart
+type Store struct {
+	db *bolt.DB
+}
+
+// NewStore opens (or creates) the database file at path
+func NewStore(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open outbox database %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{itemsBucket, queueBucket, deadLettersBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize outbox database: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Enqueue stores a new item at the end of the queue
+func (s *Store) Enqueue(rec *record) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if err := putRecord(tx, rec); err != nil {
+			return err
+		}
+		return appendQueue(tx, rec.ID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to queue item: %w", err)
+	}
+	return nil
+}
+
+// Head returns the first queued item and its queue key, or nil when the
+// queue is empty
+func (s *Store) Head() (*record, []byte, error) {
+	var rec *record
+	var key []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		k, id := tx.Bucket(queueBucket).Cursor().First()
+		if k == nil {
+			return nil
+		}
+		var err error
+		rec, err = getRecord(tx, string(id))
+		key = append([]byte(nil), k...)
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read the outbox queue: %w", err)
+	}
+	return rec, key, nil
+}
+
+// Queued returns the queued items in submission order
+func (s *Store) Queued() ([]*record, error) {
+	records := []*record{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(queueBucket).ForEach(func(_, id []byte) error {
+			rec, err := getRecord(tx, string(id))
+			if err != nil {
+				return err
+			}
+			records = append(records, rec)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the outbox queue: %w", err)
+	}
+	return records, nil
+}
+
+// Item returns a stored item
+func (s *Store) Item(id string) (*record, error) {
+	var rec *record
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		rec, err = getRecord(tx, id)
+		return err
+	})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("failed to read item: %w", err)
+	}
+	return rec, err
+}
+
+// Put updates a stored item
+func (s *Store) Put(rec *record) error {
+	if err := s.db.Update(func(tx *bolt.Tx) error { return putRecord(tx, rec) }); err != nil {
+		return fmt.Errorf("failed to write item: %w", err)
+	}
+	return nil
+}
+
+// Complete removes the item at the queue key from the queue and stores its
+// outcome
+func (s *Store) Complete(key []byte, rec *record) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(queueBucket).Delete(key); err != nil {
+			return err
+		}
+		return putRecord(tx, rec)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete item: %w", err)
+	}
+	return nil
+}
+
+// DeadLetter moves the item at the queue key from the queue to the dead
+// letters
+func (s *Store) DeadLetter(key []byte, rec *record) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(queueBucket).Delete(key); err != nil {
+			return err
+		}
+		if err := tx.Bucket(deadLettersBucket).Put([]byte(rec.ID), []byte{}); err != nil {
+			return err
+		}
+		return putRecord(tx, rec)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to dead-letter item: %w", err)
+	}
+	return nil
+}
+
+// DeadLetters returns the dead-lettered items, oldest failure first
+func (s *Store) DeadLetters() ([]*record, error) {
+	records := []*record{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(deadLettersBucket).ForEach(func(id, _ []byte) error {
+			rec, err := getRecord(tx, string(id))
+			if err != nil {
+				return err
+			}
+			records = append(records, rec)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead letters: %w", err)
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return completedAt(records[i]).Before(completedAt(records[j]))
+	})
+	return records, nil
+}
+
+// Requeue moves a dead letter to the end of the queue after applying update
+// to it
+func (s *Store) Requeue(id string, update func(*record)) (*record, error) {
+	var rec *record
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(deadLettersBucket).Get([]byte(id)) == nil {
+			return ErrNotFound
+		}
+		var err error
+		if rec, err = getRecord(tx, id); err != nil {
+			return err
+		}
+		update(rec)
+		if err := tx.Bucket(deadLettersBucket).Delete([]byte(id)); err != nil {
+			return err
+		}
+		if err := putRecord(tx, rec); err != nil {
+			return err
+		}
+		return appendQueue(tx, id)
+	})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("failed to requeue item: %w", err)
+	}
+	return rec, err
+}
+
+// Discard removes a dead letter
+func (s *Store) Discard(id string) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(deadLettersBucket).Get([]byte(id)) == nil {
+			return ErrNotFound
+		}
+		if err := tx.Bucket(deadLettersBucket).Delete([]byte(id)); err != nil {
+			return err
+		}
+		return tx.Bucket(itemsBucket).Delete([]byte(id))
+	})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to discard item: %w", err)
+	}
+	return err
+}
+
+// Sweep removes the committed items completed before the given time and
+// returns how many were removed
+func (s *Store) Sweep(before time.Time) (int, error) {
+	removed := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		c := tx.Bucket(itemsBucket).Cursor()
+		for k, v := c.First(); k != nil; {
+			var rec record
+			if err := json.Unmarshal(v, &rec); err != nil {
+				return fmt.Errorf("item %s: %w", k, err)
+			}
+			if rec.Status != StatusCommitted || !completedAt(&rec).Before(before) {
+				k, v = c.Next()
+				continue
+			}
+			if err := c.Delete(); err != nil {
+				return err
+			}
+			removed++
+			// Delete moves the cursor to the next item
+			k, v = c.Seek(k)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to sweep committed items: %w", err)
+	}
+	return removed, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func getRecord(tx *bolt.Tx, id string) (*record, error) {
+	data := tx.Bucket(itemsBucket).Get([]byte(id))
+	if data == nil {
+		return nil, ErrNotFound
+	}
+	var rec record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("item %s: %w", id, err)
+	}
+	return &rec, nil
+}
+
+func putRecord(tx *bolt.Tx, rec *record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode item: %w", err)
+	}
+	return tx.Bucket(itemsBucket).Put([]byte(rec.ID), data)
+}
+
+func appendQueue(tx *bolt.Tx, id string) error {
+	bucket := tx.Bucket(queueBucket)
+	seq, err := bucket.NextSequence()
+	if err != nil {
+		return err
+	}
+	return bucket.Put(binary.BigEndian.AppendUint64(nil, seq), []byte(id))
+}
+
+func completedAt(rec *record) time.Time {
+	if rec.CompletedAt == nil {
+		return time.Time{}
+	}
+	return *rec.CompletedAt
+}
@@ -0,0 +1,152 @@
+package outbox
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := NewStore(filepath.Join(t.TempDir(), "outbox.db"))
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// enqueue stores queued items with the given IDs
+func enqueue(t *testing.T, store *Store, ids ...string) {
+	t.Helper()
+	for _, id := range ids {
+		if err := store.Enqueue(&record{Item: Item{ID: id, Status: StatusQueued, ChaincodeName: "basic"}}); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+}
+
+func head(t *testing.T, store *Store) (*record, []byte) {
+	t.Helper()
+	rec, key, err := store.Head()
+	if err != nil {
+		t.Fatalf("Head() error = %v", err)
+	}
+	return rec, key
+}
+
+func TestStoreQueue(t *testing.T) {
+	store := newTestStore(t)
+	if rec, _ := head(t, store); rec != nil {
+		t.Fatalf("Head() of an empty queue = %+v", rec)
+	}
+	enqueue(t, store, "a", "b", "c")
+	if queued, err := store.Queued(); err != nil || len(queued) != 3 || queued[0].ID != "a" || queued[2].ID != "c" {
+		t.Fatalf("Queued() = %v, %v; want a, b and c in order", queued, err)
+	}
+
+	rec, key := head(t, store)
+	now := time.Now()
+	rec.Status, rec.CompletedAt = StatusCommitted, &now
+	if err := store.Complete(key, rec); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if stored, err := store.Item("a"); err != nil || stored.Status != StatusCommitted {
+		t.Errorf("Item() of the committed item = %+v, %v", stored, err)
+	}
+
+	rec, key = head(t, store)
+	if rec.ID != "b" {
+		t.Fatalf("Head() = %s, want b", rec.ID)
+	}
+	rec.Status, rec.CompletedAt = StatusFailed, &now
+	if err := store.DeadLetter(key, rec); err != nil {
+		t.Fatalf("DeadLetter() error = %v", err)
+	}
+	if rec, _ := head(t, store); rec.ID != "c" {
+		t.Errorf("Head() after the dead letter = %s, want c", rec.ID)
+	}
+	if letters, err := store.DeadLetters(); err != nil || len(letters) != 1 || letters[0].ID != "b" {
+		t.Errorf("DeadLetters() = %v, %v; want b", letters, err)
+	}
+
+	if _, err := store.Item("unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Item() of an unknown item error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestStoreRequeue(t *testing.T) {
+	store := newTestStore(t)
+	enqueue(t, store, "a", "b")
+	rec, key := head(t, store)
+	rec.Status = StatusFailed
+	if err := store.DeadLetter(key, rec); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only dead letters are requeued
+	for _, id := range []string{"b", "unknown"} {
+		if _, err := store.Requeue(id, func(*record) {}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Requeue(%q) error = %v, want %v", id, err, ErrNotFound)
+		}
+	}
+	requeued, err := store.Requeue("a", func(rec *record) { rec.Status = StatusQueued })
+	if err != nil || requeued.Status != StatusQueued {
+		t.Fatalf("Requeue() = %+v, %v", requeued, err)
+	}
+	if queued, _ := store.Queued(); len(queued) != 2 || queued[0].ID != "b" || queued[1].ID != "a" {
+		t.Errorf("Queued() = %v, want a requeued behind b", queued)
+	}
+	if letters, _ := store.DeadLetters(); len(letters) != 0 {
+		t.Errorf("DeadLetters() = %v, want none", letters)
+	}
+}
+
+func TestStoreDiscard(t *testing.T) {
+	store := newTestStore(t)
+	enqueue(t, store, "a", "b")
+	rec, key := head(t, store)
+	rec.Status = StatusFailed
+	if err := store.DeadLetter(key, rec); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Discard("b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Discard() of a queued item error = %v, want %v", err, ErrNotFound)
+	}
+	if err := store.Discard("a"); err != nil {
+		t.Fatalf("Discard() error = %v", err)
+	}
+	if _, err := store.Item("a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Item() of a discarded item error = %v, want %v", err, ErrNotFound)
+	}
+	if err := store.Discard("a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Discard() error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestStoreSweep(t *testing.T) {
+	store := newTestStore(t)
+	old, recent := time.Now().Add(-2*time.Hour), time.Now()
+	for _, rec := range []*record{
+		{Item: Item{ID: "old", Status: StatusCommitted, CompletedAt: &old}},
+		{Item: Item{ID: "old2", Status: StatusCommitted, CompletedAt: &old}},
+		{Item: Item{ID: "recent", Status: StatusCommitted, CompletedAt: &recent}},
+		{Item: Item{ID: "failed", Status: StatusFailed, CompletedAt: &old}},
+		{Item: Item{ID: "queued", Status: StatusQueued}},
+	} {
+		if err := store.Put(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if removed, err := store.Sweep(time.Now().Add(-time.Hour)); err != nil || removed != 2 {
+		t.Fatalf("Sweep() = %d, %v; want 2", removed, err)
+	}
+	for id, kept := range map[string]bool{"old": false, "old2": false, "recent": true, "failed": true, "queued": true} {
+		if _, err := store.Item(id); (err == nil) != kept {
+			t.Errorf("Item(%q) after Sweep error = %v, want kept %v", id, err, kept)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/response"
+)
+
+// ClientKey identifies the client of a request: the ID of the authenticated
+// principal, or the source IP address when the request is unauthenticated
+func ClientKey(r *http.Request) string {
+	return ClientKeyFromContext(r.Context(), r.RemoteAddr)
+}
+
+// ClientKeyFromContext is ClientKey for calls that are not HTTP requests
+func ClientKeyFromContext(ctx context.Context, remoteAddr string) string {
+	if principal := auth.FromContext(ctx); principal != nil {
+		return principal.ID()
+	}
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	return "ip:" + host
+}
+
+// Middleware applies the per-route request rate of the client
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := l.AllowRoute(ClientKey(r), routePattern(r)); err != nil {
+			WriteError(w, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// routePattern returns the pattern of the route matching r, e.g.
+// /api/outbox/{id}, so that all requests to a route share its buckets. The
+// /networks/{name} prefix of additional networks is removed, so that their
+// routes match the configured ones.
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return r.URL.Path
+	}
+	// The middleware runs before the route is matched, so the pattern is
+	// looked up from the root router
+	pattern := rctx.Routes.Find(chi.NewRouteContext(), r.Method, r.URL.Path)
+	if pattern == "" {
+		return "unmatched"
+	}
+	if rest, ok := strings.CutPrefix(pattern, "/networks/"); ok {
+		if i := strings.Index(rest, "/"); i >= 0 {
+			pattern = rest[i:]
+		}
+	}
+	return pattern
+}
+
+// WriteError writes a 429 response with a Retry-After header for quota errors
+// and a 500 response for anything else
+func WriteError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var exceeded *ExceededError
+	if errors.As(err, &exceeded) {
+		status = http.StatusTooManyRequests
+		seconds := int(math.Ceil(exceeded.RetryAfter.Seconds()))
+		if seconds < 1 {
+			seconds = 1
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
+	response.Error(w, status, err.Error())
+}
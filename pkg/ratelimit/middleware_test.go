@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+)
+
+// newTestRouter mounts a limited outbox route for the default network and
+// for an additional one, the way the server does
+func newTestRouter(l *Limiter) http.Handler {
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	api := func(r chi.Router) {
+		r.Use(l.Middleware)
+		r.Get("/outbox/{id}", ok)
+		r.Get("/quota", ok)
+	}
+	r := chi.NewRouter()
+	network := chi.NewRouter()
+	network.Route("/api", api)
+	r.Mount("/networks/partner", network)
+	r.Route("/api", api)
+	return r
+}
+
+func get(h http.Handler, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	r.RemoteAddr = "192.0.2.1:1234"
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestMiddlewareKeysBucketsByRoutePattern(t *testing.T) {
+	l := NewLimiter(Config{Routes: map[string]Limit{"/api/outbox/{id}": {Rate: 1, Burst: 2}}})
+	h := newTestRouter(l)
+
+	for _, path := range []string{"/api/outbox/1", "/api/outbox/2"} {
+		if w := get(h, path); w.Code != http.StatusOK {
+			t.Fatalf("GET %s status = %d", path, w.Code)
+		}
+	}
+	w := get(h, "/api/outbox/3")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("a new id got a fresh bucket: status = %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Retry-After is not set")
+	}
+	if w := get(h, "/api/quota"); w.Code != http.StatusOK {
+		t.Errorf("a route without a limit was limited: status = %d", w.Code)
+	}
+}
+
+func TestMiddlewareAppliesRouteLimitsToNetworks(t *testing.T) {
+	l := NewLimiter(Config{Routes: map[string]Limit{"/api/outbox/{id}": {Rate: 1, Burst: 1}}})
+	h := newTestRouter(l)
+
+	if w := get(h, "/networks/partner/api/outbox/1"); w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if w := get(h, "/networks/partner/api/outbox/2"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("the configured route limit did not apply under /networks/partner: status = %d", w.Code)
+	}
+	usage := l.Usage("ip:192.0.2.1")
+	if _, ok := usage.Routes["/api/outbox/{id}"]; !ok || len(usage.Routes) != 1 {
+		t.Errorf("routes = %v, want only /api/outbox/{id}", usage.Routes)
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/quota", nil)
+	r.RemoteAddr = "192.0.2.1:1234"
+	if got := ClientKey(r); got != "ip:192.0.2.1" {
+		t.Errorf("ClientKey() = %q, want the source address", got)
+	}
+	r = r.WithContext(auth.NewContext(r.Context(), &auth.Principal{Name: "ci", Method: "api_key"}))
+	if got := ClientKey(r); got != "api_key:ci" {
+		t.Errorf("ClientKey() = %q, want the principal", got)
+	}
+}
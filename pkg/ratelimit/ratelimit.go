@@ -0,0 +1,296 @@
+package ratelimit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"gopkg.in/yaml.v3"
+)
+
+// idleTimeout is how long an unused client bucket is kept before it is dropped
+const idleTimeout = 10 * time.Minute
+
+// Limit describes the quota of a single client
+type Limit struct {
+	// Rate is the sustained number of requests per second; 0 means unlimited
+	Rate float64 `yaml:"rate" json:"rate"`
+	// Burst is the number of requests that may be made at once (default: rate rounded up)
+	Burst int `yaml:"burst" json:"burst"`
+	// MaxInFlightInvokes is the number of invokes a client may have running at
+	// the same time; 0 means unlimited
+	MaxInFlightInvokes int `yaml:"max_in_flight_invokes" json:"max_in_flight_invokes"`
+}
+
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return int(math.Max(1, math.Ceil(l.Rate)))
+}
+
+// Config holds the rate limits applied per client
+type Config struct {
+	// Default applies to routes without their own entry; its in-flight limit
+	// bounds the invokes of a client across all chaincodes
+	Default Limit `yaml:"default"`
+	// Routes overrides the request rate per route path, e.g. /api/invoke
+	Routes map[string]Limit `yaml:"routes"`
+	// Chaincodes adds request rate and in-flight invoke limits per chaincode
+	Chaincodes map[string]Limit `yaml:"chaincodes"`
+}
+
+// LoadConfig reads and parses the rate limit configuration file at path
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rate limit config: %w", err)
+	}
+
+	var config Config
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse rate limit config %s: %w", path, err)
+	}
+	return &config, config.Validate()
+}
+
+// Validate checks that the limits are not negative
+func (c *Config) Validate() error {
+	check := func(name string, l Limit) error {
+		if l.Rate < 0 || l.Burst < 0 || l.MaxInFlightInvokes < 0 {
+			return fmt.Errorf("%s: limits must not be negative", name)
+		}
+		return nil
+	}
+	if err := check("default", c.Default); err != nil {
+		return err
+	}
+	for route, l := range c.Routes {
+		if err := check("routes."+route, l); err != nil {
+			return err
+		}
+	}
+	for chaincode, l := range c.Chaincodes {
+		if err := check("chaincodes."+chaincode, l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExceededError is returned when a client is over its quota
+type ExceededError struct {
+	// Scope describes which limit was exceeded
+	Scope string
+	// RetryAfter is how long the client should wait before retrying
+	RetryAfter time.Duration
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("rate limit exceeded for %s", e.Scope)
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	limit    Limit
+	lastSeen time.Time
+}
+
+// Limiter enforces the configured quotas per client
+type Limiter struct {
+	mu        sync.Mutex
+	config    Config
+	buckets   map[string]*bucket
+	inFlight  map[string]int
+	lastSweep time.Time
+}
+
+// NewLimiter creates a limiter for the given configuration
+func NewLimiter(config Config) *Limiter {
+	return &Limiter{
+		config:    config,
+		buckets:   make(map[string]*bucket),
+		inFlight:  make(map[string]int),
+		lastSweep: time.Now(),
+	}
+}
+
+// SetConfig replaces the configured limits. Buckets are recreated with the
+// new limits on their next use; in-flight counters are preserved.
+func (l *Limiter) SetConfig(config Config) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.config = config
+	l.buckets = make(map[string]*bucket)
+}
+
+func (l *Limiter) routeLimit(route string) Limit {
+	if limit, ok := l.config.Routes[route]; ok {
+		return limit
+	}
+	return l.config.Default
+}
+
+// AllowRoute consumes one request of the client's quota for the route
+func (l *Limiter) AllowRoute(client, route string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.allowLocked("route:"+route+"|"+client, l.routeLimit(route), "route "+route)
+}
+
+// AllowChaincode consumes one request of the client's quota for the chaincode
+func (l *Limiter) AllowChaincode(client, chaincode string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limit, ok := l.config.Chaincodes[chaincode]
+	if !ok {
+		return nil
+	}
+	return l.allowLocked("chaincode:"+chaincode+"|"+client, limit, "chaincode "+chaincode)
+}
+
+func (l *Limiter) allowLocked(key string, limit Limit, scope string) error {
+	now := time.Now()
+	l.sweepLocked(now)
+	if limit.Rate <= 0 {
+		return nil
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{
+			limiter: rate.NewLimiter(rate.Limit(limit.Rate), limit.burst()),
+			limit:   limit,
+		}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+
+	reservation := b.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return &ExceededError{Scope: scope, RetryAfter: delay}
+	}
+	return nil
+}
+
+// AcquireInvoke reserves an in-flight invoke slot for the client on the
+// chaincode. The returned function must be called once the invoke finished.
+func (l *Limiter) AcquireInvoke(client, chaincode string) (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	clientKey := "client|" + client
+	chaincodeKey := "chaincode:" + chaincode + "|" + client
+	if max := l.config.Default.MaxInFlightInvokes; max > 0 && l.inFlight[clientKey] >= max {
+		return nil, &ExceededError{Scope: "in-flight invokes", RetryAfter: time.Second}
+	}
+	if max := l.config.Chaincodes[chaincode].MaxInFlightInvokes; max > 0 && l.inFlight[chaincodeKey] >= max {
+		return nil, &ExceededError{Scope: "in-flight invokes on chaincode " + chaincode, RetryAfter: time.Second}
+	}
+	l.inFlight[clientKey]++
+	l.inFlight[chaincodeKey]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			for _, key := range []string{clientKey, chaincodeKey} {
+				if l.inFlight[key]--; l.inFlight[key] <= 0 {
+					delete(l.inFlight, key)
+				}
+			}
+		})
+	}, nil
+}
+
+// sweepLocked drops buckets that have not been used for a while
+func (l *Limiter) sweepLocked(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) > idleTimeout {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// QuotaUsage reports the state of one of the client's quotas
+type QuotaUsage struct {
+	Limit Limit `json:"limit"`
+	// Remaining is the number of requests that can be made right now
+	Remaining float64 `json:"remaining"`
+	// InFlightInvokes is the number of invokes currently running
+	InFlightInvokes int `json:"in_flight_invokes"`
+}
+
+// Usage reports the current usage of the client's quotas
+type Usage struct {
+	Client          string                `json:"client"`
+	InFlightInvokes int                   `json:"in_flight_invokes"`
+	MaxInFlight     int                   `json:"max_in_flight_invokes"`
+	Routes          map[string]QuotaUsage `json:"routes"`
+	Chaincodes      map[string]QuotaUsage `json:"chaincodes"`
+}
+
+// Usage returns the current usage of the client's quotas for every configured
+// route and chaincode, and for the other routes the client has used
+func (l *Limiter) Usage(client string) Usage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	remaining := func(key string, limit Limit) float64 {
+		if b, ok := l.buckets[key]; ok {
+			return math.Max(0, b.limiter.TokensAt(now))
+		}
+		return float64(limit.burst())
+	}
+
+	usage := Usage{
+		Client:          client,
+		InFlightInvokes: l.inFlight["client|"+client],
+		MaxInFlight:     l.config.Default.MaxInFlightInvokes,
+		Routes:          make(map[string]QuotaUsage),
+		Chaincodes:      make(map[string]QuotaUsage),
+	}
+	for route, limit := range l.config.Routes {
+		usage.Routes[route] = QuotaUsage{
+			Limit:     limit,
+			Remaining: remaining("route:"+route+"|"+client, limit),
+		}
+	}
+	for key, b := range l.buckets {
+		route, ok := strings.CutPrefix(key, "route:")
+		if !ok || !strings.HasSuffix(route, "|"+client) {
+			continue
+		}
+		route = strings.TrimSuffix(route, "|"+client)
+		if _, configured := usage.Routes[route]; !configured {
+			usage.Routes[route] = QuotaUsage{
+				Limit:     b.limit,
+				Remaining: math.Max(0, b.limiter.TokensAt(now)),
+			}
+		}
+	}
+	for chaincode, limit := range l.config.Chaincodes {
+		usage.Chaincodes[chaincode] = QuotaUsage{
+			Limit:           limit,
+			Remaining:       remaining("chaincode:"+chaincode+"|"+client, limit),
+			InFlightInvokes: l.inFlight["chaincode:"+chaincode+"|"+client],
+		}
+	}
+	return usage
+}
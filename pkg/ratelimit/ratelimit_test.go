@@ -0,0 +1,132 @@
+package ratelimit
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAllowRoute(t *testing.T) {
+	l := NewLimiter(Config{
+		Default: Limit{Rate: 1, Burst: 2},
+		Routes:  map[string]Limit{"/api/invoke": {Rate: 1, Burst: 1}},
+	})
+
+	for i := 0; i < 2; i++ {
+		if err := l.AllowRoute("alice", "/api/evaluate"); err != nil {
+			t.Fatalf("request %d within the burst: %v", i, err)
+		}
+	}
+	err := l.AllowRoute("alice", "/api/evaluate")
+	var exceeded *ExceededError
+	if !errors.As(err, &exceeded) || exceeded.RetryAfter <= 0 {
+		t.Fatalf("AllowRoute() over the burst error = %v, want an ExceededError with a retry delay", err)
+	}
+
+	if err := l.AllowRoute("bob", "/api/evaluate"); err != nil {
+		t.Errorf("clients share buckets: %v", err)
+	}
+	if err := l.AllowRoute("alice", "/api/invoke"); err != nil {
+		t.Fatalf("routes share buckets: %v", err)
+	}
+	if err := l.AllowRoute("alice", "/api/invoke"); err == nil {
+		t.Error("the route override was not applied")
+	}
+}
+
+func TestAllowChaincode(t *testing.T) {
+	l := NewLimiter(Config{Chaincodes: map[string]Limit{"basic": {Rate: 1, Burst: 1}}})
+	for i := 0; i < 3; i++ {
+		if err := l.AllowChaincode("alice", "other"); err != nil {
+			t.Fatalf("a chaincode without a limit was limited: %v", err)
+		}
+	}
+	if err := l.AllowChaincode("alice", "basic"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.AllowChaincode("alice", "basic"); err == nil {
+		t.Error("the chaincode limit was not applied")
+	}
+}
+
+func TestAcquireInvoke(t *testing.T) {
+	l := NewLimiter(Config{
+		Default:    Limit{MaxInFlightInvokes: 2},
+		Chaincodes: map[string]Limit{"basic": {MaxInFlightInvokes: 1}},
+	})
+
+	releaseBasic, err := l.AcquireInvoke("alice", "basic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.AcquireInvoke("alice", "basic"); err == nil {
+		t.Fatal("the chaincode in-flight limit was not applied")
+	}
+	releaseOther, err := l.AcquireInvoke("alice", "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.AcquireInvoke("alice", "third"); err == nil {
+		t.Fatal("the client in-flight limit was not applied")
+	}
+	if usage := l.Usage("alice"); usage.InFlightInvokes != 2 || usage.Chaincodes["basic"].InFlightInvokes != 1 {
+		t.Errorf("usage = %+v", usage)
+	}
+
+	releaseBasic()
+	// Releasing twice must not free a slot held by another invoke
+	releaseBasic()
+	if _, err := l.AcquireInvoke("alice", "basic"); err != nil {
+		t.Fatalf("the released slot is not available: %v", err)
+	}
+	if _, err := l.AcquireInvoke("alice", "other"); err == nil {
+		t.Error("a double release freed a slot")
+	}
+	releaseOther()
+}
+
+func TestSetConfigResetsBuckets(t *testing.T) {
+	l := NewLimiter(Config{Default: Limit{Rate: 1, Burst: 1}})
+	l.AllowRoute("alice", "/api/quota")
+	if err := l.AllowRoute("alice", "/api/quota"); err == nil {
+		t.Fatal("the limit was not applied")
+	}
+	l.SetConfig(Config{Default: Limit{Rate: 10, Burst: 10}})
+	if err := l.AllowRoute("alice", "/api/quota"); err != nil {
+		t.Errorf("the new limits were not applied: %v", err)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	l := NewLimiter(Config{
+		Default: Limit{Rate: 1, Burst: 5},
+		Routes:  map[string]Limit{"/api/invoke": {Rate: 1, Burst: 3}},
+	})
+	l.AllowRoute("alice", "/api/evaluate")
+
+	usage := l.Usage("alice")
+	if got := usage.Routes["/api/invoke"].Remaining; got != 3 {
+		t.Errorf("remaining on an unused configured route = %v, want 3", got)
+	}
+	if got := usage.Routes["/api/evaluate"].Remaining; got < 3.9 || got > 4.1 {
+		t.Errorf("remaining on a used route = %v, want 4", got)
+	}
+	if _, ok := l.Usage("bob").Routes["/api/evaluate"]; ok {
+		t.Error("the usage of a client includes the routes of another")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := (&Config{Default: Limit{Rate: 1}}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	invalid := []Config{
+		{Default: Limit{Rate: -1}},
+		{Routes: map[string]Limit{"/api/invoke": {Burst: -1}}},
+		{Chaincodes: map[string]Limit{"basic": {MaxInFlightInvokes: -1}}},
+	}
+	for _, config := range invalid {
+		if err := config.Validate(); err == nil {
+			t.Errorf("Validate(%+v) succeeded", config)
+		}
+	}
+}
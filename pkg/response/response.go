@@ -0,0 +1,40 @@
+// Package response writes the JSON bodies shared by the HTTP handlers of the
+// API, so that every endpoint reports errors in the same format.
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrorResponse is the body of failed requests
+type ErrorResponse struct {
+	Status string `json:"status" example:"error"`
+	Error  string `json:"error"`
+}
+
+// JSON writes data as the JSON body of a response with the given status
+func JSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+// Error writes an ErrorResponse with the given status and message
+func Error(w http.ResponseWriter, status int, message string) {
+	JSON(w, status, ErrorResponse{Status: "error", Error: message})
+}
+
+// BodyError writes the ErrorResponse of a request whose body could not be
+// read or decoded: 413 when it exceeds the size limit of the server, 400
+// otherwise
+func BodyError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		Error(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit))
+		return
+	}
+	Error(w, http.StatusBadRequest, "Invalid request body")
+}
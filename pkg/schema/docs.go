@@ -0,0 +1,98 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/swaggo/swag"
+)
+
+// documentedPaths are the operations whose requests are validated
+var documentedPaths = []string{"/api/invoke", "/api/evaluate"}
+
+// DocHandler serves the generated Swagger document with a definition per
+// registered function, named functions.<chaincode>.<function>, which the
+// invoke and evaluate operations refer to in their description
+func (r *Registry) DocHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		doc, err := swag.ReadDoc()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		data, err := r.augment([]byte(doc))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}
+}
+
+// augment adds the function schemas to a Swagger document
+func (r *Registry) augment(doc []byte) ([]byte, error) {
+	functions := r.Functions()
+	if len(functions) == 0 {
+		return doc, nil
+	}
+	var spec map[string]interface{}
+	if err := json.Unmarshal(doc, &spec); err != nil {
+		return nil, fmt.Errorf("failed to decode the API documentation: %w", err)
+	}
+
+	definitions, _ := spec["definitions"].(map[string]interface{})
+	if definitions == nil {
+		definitions = map[string]interface{}{}
+		spec["definitions"] = definitions
+	}
+	var list strings.Builder
+	list.WriteString("\n\nRequests to these functions are validated against their schema, see the models:")
+	for _, fn := range functions {
+		name := fmt.Sprintf("functions.%s.%s", fn.Chaincode, fn.Function)
+		definitions[name] = fn.definition()
+		fmt.Fprintf(&list, "\n- `%s` `%s`: %s", fn.Chaincode, fn.Function, name)
+	}
+
+	paths, _ := spec["paths"].(map[string]interface{})
+	for _, path := range documentedPaths {
+		item, _ := paths[path].(map[string]interface{})
+		operation, _ := item["post"].(map[string]interface{})
+		if operation == nil {
+			continue
+		}
+		description, _ := operation["description"].(string)
+		operation["description"] = description + list.String()
+	}
+	return json.Marshal(spec)
+}
+
+// definition describes the request of the function as a Swagger model. The
+// complete schema is kept in x-json-schema, since Swagger 2.0 models only
+// support a subset of JSON Schema.
+func (fn *Function) definition() map[string]interface{} {
+	properties := map[string]interface{}{
+		"chaincode_name": map[string]interface{}{"type": "string", "enum": []string{fn.Chaincode}},
+		"function":       map[string]interface{}{"type": "string", "enum": []string{fn.Function}},
+	}
+	docProperties, _ := fn.Document["properties"].(map[string]interface{})
+	for _, name := range []string{"args", "transient"} {
+		if property, ok := docProperties[name]; ok {
+			properties[name] = property
+		}
+	}
+	definition := map[string]interface{}{
+		"type":          "object",
+		"required":      []string{"chaincode_name", "function"},
+		"properties":    properties,
+		"x-json-schema": fn.Document,
+	}
+	for _, name := range []string{"title", "description"} {
+		if value, ok := fn.Document[name]; ok {
+			definition[name] = value
+		}
+	}
+	return definition
+}
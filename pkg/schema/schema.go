@@ -0,0 +1,224 @@
+package schema
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+var printer = message.NewPrinter(language.English)
+
+// FieldError is a validation error of a single request field
+type FieldError struct {
+	// JSON pointer of the invalid value in the request, e.g. /args/1/color
+	Field string `json:"field" example:"/args/0"`
+	// What is wrong with the value
+	Message string `json:"message" example:"minLength: got 0, want 1"`
+}
+
+// ValidationError is returned by Check for a call whose arguments do not match
+// the function's schema
+type ValidationError struct {
+	Chaincode string
+	Function  string
+	Fields    []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid arguments for %s on chaincode %s", e.Function, e.Chaincode)
+}
+
+// Function is the schema of a chaincode function's request
+type Function struct {
+	Chaincode string
+	Function  string
+	// Document is the decoded schema file
+	Document map[string]interface{}
+
+	schema *jsonschema.Schema
+}
+
+// Registry holds the schemas of chaincode functions. A nil registry
+// validates nothing.
+type Registry struct {
+	functions map[string]*Function
+}
+
+// Load compiles the schemas found in dir, which holds a directory per
+// chaincode with a <function>.json file per function. Each file is a JSON
+// Schema (draft 2020-12 unless $schema says otherwise) of the object
+// {"args": [...], "transient": {...}} built from the request. Arguments and
+// transient values holding a JSON object or array are validated as the
+// decoded value, all others as strings.
+func Load(dir string) (*Registry, error) {
+	chaincodes, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema directory: %w", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.AssertFormat()
+
+	registry := &Registry{functions: map[string]*Function{}}
+	for _, chaincode := range chaincodes {
+		if !chaincode.IsDir() {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(dir, chaincode.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema directory: %w", err)
+		}
+		for _, file := range files {
+			if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+				continue
+			}
+			path, err := filepath.Abs(filepath.Join(dir, chaincode.Name(), file.Name()))
+			if err != nil {
+				return nil, err
+			}
+			fn, err := compile(compiler, path)
+			if err != nil {
+				return nil, fmt.Errorf("schema %s: %w", path, err)
+			}
+			fn.Chaincode = chaincode.Name()
+			fn.Function = strings.TrimSuffix(file.Name(), ".json")
+			registry.functions[key(fn.Chaincode, fn.Function)] = fn
+		}
+	}
+	return registry, nil
+}
+
+func compile(compiler *jsonschema.Compiler, path string) (*Function, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	doc, err := jsonschema.UnmarshalJSON(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	document, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("schema must be an object")
+	}
+	if err := compiler.AddResource(path, doc); err != nil {
+		return nil, err
+	}
+	compiled, err := compiler.Compile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Function{Document: document, schema: compiled}, nil
+}
+
+func key(chaincode, function string) string {
+	return chaincode + "/" + function
+}
+
+// Functions returns the registered functions ordered by chaincode and function
+func (r *Registry) Functions() []*Function {
+	if r == nil {
+		return nil
+	}
+	functions := make([]*Function, 0, len(r.functions))
+	for _, fn := range r.functions {
+		functions = append(functions, fn)
+	}
+	sort.Slice(functions, func(i, j int) bool {
+		return key(functions[i].Chaincode, functions[i].Function) < key(functions[j].Chaincode, functions[j].Function)
+	})
+	return functions
+}
+
+// Validate checks the arguments and transient data of a call against the
+// function's schema. It returns nil when they are valid or the function has
+// no schema.
+func (r *Registry) Validate(chaincode, function string, args []string, transient map[string]string) []FieldError {
+	if r == nil {
+		return nil
+	}
+	fn, ok := r.functions[key(chaincode, function)]
+	if !ok {
+		return nil
+	}
+
+	instanceArgs := make([]interface{}, len(args))
+	for i, arg := range args {
+		instanceArgs[i] = decode(arg)
+	}
+	instanceTransient := make(map[string]interface{}, len(transient))
+	for name, value := range transient {
+		instanceTransient[name] = decode(value)
+	}
+	err := fn.schema.Validate(map[string]interface{}{
+		"args":      instanceArgs,
+		"transient": instanceTransient,
+	})
+	if err == nil {
+		return nil
+	}
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return []FieldError{{Field: "", Message: err.Error()}}
+	}
+	return FieldErrors(validationErr)
+}
+
+// Check is Validate for callers that report errors: it returns the invalid
+// fields as a ValidationError, or nil when the call is valid or the function
+// has no schema. Every API calling chaincode functions checks the calls with
+// it before they are endorsed.
+func (r *Registry) Check(chaincode, function string, args []string, transient map[string]string) *ValidationError {
+	if fields := r.Validate(chaincode, function, args, transient); len(fields) > 0 {
+		return &ValidationError{Chaincode: chaincode, Function: function, Fields: fields}
+	}
+	return nil
+}
+
+// decode returns the decoded value of a JSON object or array, and value
+// itself for anything else
+func decode(value string) interface{} {
+	trimmed := strings.TrimSpace(value)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return value
+	}
+	decoded, err := jsonschema.UnmarshalJSON(strings.NewReader(trimmed))
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+// FieldErrors flattens a validation error into the errors of its leaves
+func FieldErrors(err *jsonschema.ValidationError) []FieldError {
+	if len(err.Causes) == 0 {
+		return []FieldError{{
+			Field:   pointer(err.InstanceLocation),
+			Message: err.ErrorKind.LocalizedString(printer),
+		}}
+	}
+	var errs []FieldError
+	for _, cause := range err.Causes {
+		errs = append(errs, FieldErrors(cause)...)
+	}
+	return errs
+}
+
+// pointer encodes a location as a JSON pointer
+func pointer(tokens []string) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		sb.WriteByte('/')
+		token = strings.ReplaceAll(token, "~", "~0")
+		sb.WriteString(strings.ReplaceAll(token, "/", "~1"))
+	}
+	return sb.String()
+}
@@ -0,0 +1,174 @@
+package schema
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const createAssetSchema = `{
+  "title": "Create an asset",
+  "type": "object",
+  "properties": {
+    "args": {
+      "type": "array",
+      "prefixItems": [
+        {"type": "string", "minLength": 1},
+        {"type": "object", "properties": {"color": {"enum": ["blue", "red"]}}, "required": ["color"]}
+      ],
+      "minItems": 2
+    },
+    "transient": {
+      "type": "object",
+      "properties": {"price": {"type": "string", "pattern": "^[0-9]+$"}}
+    }
+  }
+}`
+
+// writeSchemas creates a schema directory with the given files, keyed by
+// their path relative to the directory
+func writeSchemas(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	dir := writeSchemas(t, map[string]string{
+		"basic/CreateAsset.json": createAssetSchema,
+		"basic/ReadAsset.json":   `{"type": "object"}`,
+		"basic/notes.txt":        "ignored",
+		"README.md":              "ignored",
+	})
+	registry, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	functions := registry.Functions()
+	if len(functions) != 2 || functions[0].Function != "CreateAsset" || functions[1].Function != "ReadAsset" || functions[0].Chaincode != "basic" {
+		t.Errorf("Functions() = %v, want CreateAsset and ReadAsset of basic", functions)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{name: "invalid JSON", files: map[string]string{"basic/CreateAsset.json": "{"}, want: "invalid JSON"},
+		{name: "not an object", files: map[string]string{"basic/CreateAsset.json": "[]"}, want: "schema must be an object"},
+		{name: "invalid schema", files: map[string]string{"basic/CreateAsset.json": `{"type": 5}`}, want: "CreateAsset.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeSchemas(t, tt.files))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Load() of a missing directory succeeded")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	registry, err := Load(writeSchemas(t, map[string]string{"basic/CreateAsset.json": createAssetSchema}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		function  string
+		args      []string
+		transient map[string]string
+		want      []string
+	}{
+		{name: "valid", function: "CreateAsset", args: []string{"asset1", `{"color": "blue"}`}, transient: map[string]string{"price": "100"}},
+		{name: "function without schema", function: "DeleteAsset", args: nil},
+		{name: "empty id", function: "CreateAsset", args: []string{"", `{"color": "blue"}`}, want: []string{"/args/0"}},
+		{name: "invalid JSON argument", function: "CreateAsset", args: []string{"asset1", `{"color": "green"}`}, want: []string{"/args/1/color"}},
+		{name: "missing argument", function: "CreateAsset", args: []string{"asset1"}, want: []string{"/args"}},
+		{name: "invalid transient", function: "CreateAsset", args: []string{"asset1", `{"color": "red"}`}, transient: map[string]string{"price": "ten"}, want: []string{"/transient/price"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := registry.Validate("basic", tt.function, tt.args, tt.transient)
+			var fields []string
+			for _, err := range errs {
+				fields = append(fields, err.Field)
+				if err.Message == "" {
+					t.Errorf("field %s has no message", err.Field)
+				}
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Validate() = %+v, want errors for %v", errs, tt.want)
+			}
+		})
+	}
+
+	var nilRegistry *Registry
+	if errs := nilRegistry.Validate("basic", "CreateAsset", nil, nil); errs != nil {
+		t.Errorf("Validate() of a nil registry = %v", errs)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	registry, err := Load(writeSchemas(t, map[string]string{"basic/CreateAsset.json": createAssetSchema}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Check("basic", "CreateAsset", []string{"asset1", `{"color": "blue"}`}, nil); err != nil {
+		t.Errorf("Check() of a valid call error = %v", err)
+	}
+	validationErr := registry.Check("basic", "CreateAsset", []string{""}, nil)
+	if validationErr == nil || len(validationErr.Fields) == 0 || validationErr.Error() != "invalid arguments for CreateAsset on chaincode basic" {
+		t.Errorf("Check() of an invalid call = %v, want the invalid fields", validationErr)
+	}
+}
+
+func TestPointer(t *testing.T) {
+	if got := pointer([]string{"transient", "a/b", "c~d"}); got != "/transient/a~1b/c~0d" {
+		t.Errorf("pointer() = %q", got)
+	}
+}
+
+func TestAugment(t *testing.T) {
+	registry, err := Load(writeSchemas(t, map[string]string{"basic/CreateAsset.json": createAssetSchema}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := `{"paths": {"/api/invoke": {"post": {"description": "Invokes a function"}}}}`
+	data, err := registry.augment([]byte(doc))
+	if err != nil {
+		t.Fatalf("augment() error = %v", err)
+	}
+	var spec struct {
+		Definitions map[string]map[string]interface{} `json:"definitions"`
+		Paths       map[string]map[string]struct {
+			Description string `json:"description"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatal(err)
+	}
+	definition, ok := spec.Definitions["functions.basic.CreateAsset"]
+	if !ok || definition["title"] != "Create an asset" || definition["x-json-schema"] == nil {
+		t.Errorf("definition = %v", definition)
+	}
+	if description := spec.Paths["/api/invoke"]["post"].Description; !strings.Contains(description, "functions.basic.CreateAsset") {
+		t.Errorf("the invoke description does not refer to the model: %q", description)
+	}
+}
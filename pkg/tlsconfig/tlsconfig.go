@@ -0,0 +1,177 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// statInterval limits how often the certificate files are checked for changes
+const statInterval = 10 * time.Second
+
+// ClientAuth values accepted by Config.ClientAuth
+const (
+	ClientAuthNone    = "none"
+	ClientAuthRequest = "request"
+	ClientAuthRequire = "require"
+)
+
+// Config holds the TLS settings of the API server
+type Config struct {
+	CertPath string
+	KeyPath  string
+	// ClientCAPath is a PEM bundle of CAs trusted to issue client certificates;
+	// client certificates are not verified when empty
+	ClientCAPath string
+	// ClientAuth is "request" to verify client certificates when presented or
+	// "require" to reject connections without one (default when a CA is set)
+	ClientAuth string
+}
+
+// Reloader serves the server certificate and client CA pool, reloading them
+// from disk when the files change so certificates can be rotated without a restart
+type Reloader struct {
+	config Config
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+	lastStat  time.Time
+}
+
+// NewReloader loads the configured certificate and client CA bundle
+func NewReloader(config Config) (*Reloader, error) {
+	if config.CertPath == "" || config.KeyPath == "" {
+		return nil, fmt.Errorf("both a TLS certificate and key are required")
+	}
+	config.ClientAuth = ResolveClientAuth(config.ClientCAPath, config.ClientAuth)
+	switch config.ClientAuth {
+	case ClientAuthNone, ClientAuthRequest, ClientAuthRequire:
+	default:
+		return nil, fmt.Errorf("unknown client auth mode %q (expected none, request or require)", config.ClientAuth)
+	}
+	if config.ClientAuth != ClientAuthNone && config.ClientCAPath == "" {
+		return nil, fmt.Errorf("client auth mode %q requires a client CA bundle", config.ClientAuth)
+	}
+
+	r := &Reloader{config: config}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ResolveClientAuth returns the client auth mode in effect: the configured
+// one, or require when only a client CA bundle is set
+func ResolveClientAuth(clientCAPath, clientAuth string) string {
+	if clientAuth != "" {
+		return clientAuth
+	}
+	if clientCAPath != "" {
+		return ClientAuthRequire
+	}
+	return ClientAuthNone
+}
+
+// Reload reads the certificate, key and client CA bundle from disk. On error
+// the previously loaded material is kept.
+func (r *Reloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.config.CertPath, r.config.KeyPath)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.config.ClientCAPath != "" {
+		caPem, err := os.ReadFile(r.config.ClientCAPath)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA bundle: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(caPem) {
+			return fmt.Errorf("client CA bundle %s contains no certificates", r.config.ClientCAPath)
+		}
+	}
+
+	modTimes := make(map[string]time.Time)
+	for _, path := range r.paths() {
+		if info, err := os.Stat(path); err == nil {
+			modTimes[path] = info.ModTime()
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	r.lastStat = time.Now()
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *Reloader) paths() []string {
+	paths := []string{r.config.CertPath, r.config.KeyPath}
+	if r.config.ClientCAPath != "" {
+		paths = append(paths, r.config.ClientCAPath)
+	}
+	return paths
+}
+
+// reloadIfChanged reloads the files when their modification time changed
+func (r *Reloader) reloadIfChanged() {
+	r.mu.Lock()
+	if time.Since(r.lastStat) < statInterval {
+		r.mu.Unlock()
+		return
+	}
+	r.lastStat = time.Now()
+	changed := false
+	for _, path := range r.paths() {
+		info, err := os.Stat(path)
+		if err == nil && !info.ModTime().Equal(r.modTimes[path]) {
+			changed = true
+			break
+		}
+	}
+	r.mu.Unlock()
+
+	if changed {
+		if err := r.Reload(); err != nil {
+			slog.Error("failed to reload TLS certificates, keeping the previous ones", "error", err)
+			return
+		}
+		slog.Info("reloaded TLS certificates")
+	}
+}
+
+// ServerConfig returns a TLS configuration that picks up reloaded certificates
+// on every new connection
+func (r *Reloader) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.reloadIfChanged()
+
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			config := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.cert},
+				ClientCAs:    r.clientCAs,
+				NextProtos:   []string{"h2", "http/1.1"},
+			}
+			switch r.config.ClientAuth {
+			case ClientAuthRequest:
+				config.ClientAuth = tls.VerifyClientCertIfGiven
+			case ClientAuthRequire:
+				config.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return config, nil
+		},
+	}
+}
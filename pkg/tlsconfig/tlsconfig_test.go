@@ -0,0 +1,214 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testPKI is a CA with a server and a client certificate issued by it
+type testPKI struct {
+	dir        string
+	caPath     string
+	certPath   string
+	keyPath    string
+	caPool     *x509.CertPool
+	clientCert tls.Certificate
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	dir := t.TempDir()
+	caKey, caCert := issue(t, "test-ca", nil, nil, func(tmpl *x509.Certificate) {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign
+	})
+	serverKey, serverCert := issue(t, "localhost", caCert, caKey, func(tmpl *x509.Certificate) {
+		tmpl.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	})
+	clientKey, clientCert := issue(t, "client", caCert, caKey, func(tmpl *x509.Certificate) {
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	})
+
+	p := &testPKI{
+		dir:      dir,
+		caPath:   filepath.Join(dir, "ca.pem"),
+		certPath: filepath.Join(dir, "server.pem"),
+		keyPath:  filepath.Join(dir, "server-key.pem"),
+		caPool:   x509.NewCertPool(),
+	}
+	p.caPool.AddCert(caCert)
+	writePEM(t, p.caPath, "CERTIFICATE", caCert.Raw)
+	writePEM(t, p.certPath, "CERTIFICATE", serverCert.Raw)
+	writeKey(t, p.keyPath, serverKey)
+	p.clientCert = tls.Certificate{Certificate: [][]byte{clientCert.Raw}, PrivateKey: clientKey}
+	return p
+}
+
+func issue(t *testing.T, cn string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, customize func(*x509.Certificate)) (*ecdsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	customize(tmpl)
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, cert
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeKey(t *testing.T, path string, key *ecdsa.PrivateKey) {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, path, "PRIVATE KEY", der)
+}
+
+// serve starts an HTTPS server using the reloader and reports whether a
+// client with or without a certificate completes a request
+func serve(t *testing.T, p *testPKI, r *Reloader) func(withCert bool) error {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = r.ServerConfig()
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	return func(withCert bool) error {
+		clientConfig := &tls.Config{RootCAs: p.caPool}
+		if withCert {
+			clientConfig.Certificates = []tls.Certificate{p.clientCert}
+		}
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientConfig}}
+		defer client.CloseIdleConnections()
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+}
+
+func TestReloaderClientAuth(t *testing.T) {
+	p := newTestPKI(t)
+	tests := []struct {
+		clientAuth     string
+		clientCA       bool
+		wantWithout    bool
+		wantWithClient bool
+	}{
+		{clientAuth: ClientAuthNone, wantWithout: true, wantWithClient: true},
+		{clientAuth: ClientAuthRequest, clientCA: true, wantWithout: true, wantWithClient: true},
+		{clientAuth: ClientAuthRequire, clientCA: true, wantWithout: false, wantWithClient: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.clientAuth, func(t *testing.T) {
+			config := Config{CertPath: p.certPath, KeyPath: p.keyPath, ClientAuth: tt.clientAuth}
+			if tt.clientCA {
+				config.ClientCAPath = p.caPath
+			}
+			r, err := NewReloader(config)
+			if err != nil {
+				t.Fatalf("NewReloader: %v", err)
+			}
+			get := serve(t, p, r)
+			if err := get(false); (err == nil) != tt.wantWithout {
+				t.Errorf("request without a client certificate error = %v, want success %v", err, tt.wantWithout)
+			}
+			if err := get(true); (err == nil) != tt.wantWithClient {
+				t.Errorf("request with a client certificate error = %v, want success %v", err, tt.wantWithClient)
+			}
+		})
+	}
+}
+
+func TestResolveClientAuth(t *testing.T) {
+	tests := []struct {
+		clientCA, clientAuth, want string
+	}{
+		{"", "", ClientAuthNone},
+		{"ca.pem", "", ClientAuthRequire},
+		{"ca.pem", ClientAuthRequest, ClientAuthRequest},
+		{"", ClientAuthNone, ClientAuthNone},
+	}
+	for _, tt := range tests {
+		if got := ResolveClientAuth(tt.clientCA, tt.clientAuth); got != tt.want {
+			t.Errorf("ResolveClientAuth(%q, %q) = %q, want %q", tt.clientCA, tt.clientAuth, got, tt.want)
+		}
+	}
+}
+
+func TestNewReloaderRejectsInvalidConfig(t *testing.T) {
+	p := newTestPKI(t)
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "missing key", config: Config{CertPath: p.certPath}},
+		{name: "unknown client auth", config: Config{CertPath: p.certPath, KeyPath: p.keyPath, ClientAuth: "optional"}},
+		{name: "require without CA", config: Config{CertPath: p.certPath, KeyPath: p.keyPath, ClientAuth: ClientAuthRequire}},
+		{name: "key does not match", config: Config{CertPath: p.caPath, KeyPath: p.keyPath}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewReloader(tt.config); err == nil {
+				t.Error("NewReloader() succeeded")
+			}
+		})
+	}
+}
+
+func TestReloadKeepsPreviousCertificateOnError(t *testing.T) {
+	p := newTestPKI(t)
+	r, err := NewReloader(Config{CertPath: p.certPath, KeyPath: p.keyPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p.certPath, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reload(); err == nil {
+		t.Fatal("Reload() of an invalid certificate succeeded")
+	}
+	if err := serve(t, p, r)(false); err != nil {
+		t.Errorf("the previous certificate was not kept: %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestMiddlewareExportsServerSpans(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := Setup(context.Background(), Config{
+		Exporter:    ExporterFile,
+		FilePath:    path,
+		SampleRatio: 0,
+		ServiceName: "hlf-api-test",
+	})
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+
+	r := chi.NewRouter()
+	r.Use(Middleware)
+	r.Post("/api/chaincodes/{cc}/invoke", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/chaincodes/basic/invoke", nil)
+	// A sampled parent is followed even though new traces are not sampled
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spans := string(data)
+	for _, want := range []string{
+		`"Name":"POST /api/chaincodes/{cc}/invoke"`,
+		`"TraceID":"4bf92f3577b34da6a3ce929d0e0e4736"`,
+		`"Key":"http.route","Value":{"Type":"STRING","Value":"/api/chaincodes/{cc}/invoke"}`,
+		`"Key":"http.response.status_code","Value":{"Type":"INT64","Value":500}`,
+		`"Code":"Error"`,
+		`"Value":"hlf-api-test"`,
+	} {
+		if !strings.Contains(spans, want) {
+			t.Errorf("exported spans do not contain %s:\n%s", want, spans)
+		}
+	}
+}
+
+func TestSetupRejectsUnknownExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), Config{Exporter: "zipkin"}); err == nil {
+		t.Error("Setup() with an unknown exporter succeeded")
+	}
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterNone})
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown error = %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// errAddressNotAllowed is returned for receivers in networks deliveries may
+// not be sent to
+var errAddressNotAllowed = errors.New("address is not allowed")
+
+// allows reports whether deliveries may be sent to addr. Public addresses
+// are allowed; loopback, private, link-local and other special addresses,
+// such as the metadata endpoint of cloud instances, only when one of the
+// allowed networks contains them.
+func (c Config) allows(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range c.AllowedNetworks {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return addr.IsGlobalUnicast() && !addr.IsPrivate()
+}
+
+// deniedHost returns the address named by the host of a subscription URL
+// and whether it is not allowed. Host names are resolved when delivering,
+// where the address actually connected to is checked.
+func (c Config) deniedHost(host string) (netip.Addr, bool) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		host = "127.0.0.1"
+	}
+	addr, err := netip.ParseAddr(host)
+	return addr, err == nil && !c.allows(addr)
+}
+
+// transport returns the transport of the deliveries. It checks every
+// address it connects to, so that host names resolving to a network that is
+// not allowed are refused as well. Proxies are not used, since only the
+// address of the proxy could be checked.
+func (c Config) transport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !c.allows(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", errAddressNotAllowed, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
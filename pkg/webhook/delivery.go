@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+)
+
+// Headers set on every delivery
+const (
+	HeaderID        = "X-Webhook-ID"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Sign returns the signature of a delivery: the hex encoded HMAC-SHA256 of
+// the timestamp, a dot and the body, keyed with the subscription secret
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// eventID identifies an event; a transaction emits at most one chaincode event
+func eventID(event *fabric.ChaincodeEvent) string {
+	return fmt.Sprintf("%d-%s", event.BlockNumber, event.TxID)
+}
+
+// deliver POSTs an event to the subscription URL once. Any 2xx response
+// acknowledges the event; redirects are not followed.
+func (m *Manager) deliver(ctx context.Context, sub *Subscription, event *fabric.ChaincodeEvent) error {
+	delivery := Delivery{
+		ID:             eventID(event),
+		SubscriptionID: sub.ID,
+		Event:          event,
+	}
+	body, err := json.Marshal(delivery)
+	if err != nil {
+		return fmt.Errorf("failed to encode delivery: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.config.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderID, delivery.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, timestamp, body))
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("receiver responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// deliverWithRetries delivers an event until it is acknowledged or the
+// attempts are exhausted, waiting with exponential backoff in between. It
+// returns the number of attempts made and the last error, or ctx.Err() when
+// ctx was cancelled before the event was acknowledged.
+func (m *Manager) deliverWithRetries(ctx context.Context, sub *Subscription, event *fabric.ChaincodeEvent) (int, error) {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = m.deliver(ctx, sub, event); err == nil {
+			return attempt, nil
+		}
+		if ctx.Err() != nil {
+			return attempt, ctx.Err()
+		}
+		if attempt >= m.config.MaxAttempts {
+			return attempt, err
+		}
+		if !sleep(ctx, m.config.backoff(attempt-1)) {
+			return attempt, ctx.Err()
+		}
+	}
+}
+
+// sleep waits for d and reports whether ctx is still live afterwards
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
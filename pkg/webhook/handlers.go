@@ -0,0 +1,320 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/response"
+)
+
+// CreateRequest is the body of a subscription creation request
+type CreateRequest struct {
+	ChaincodeName string `json:"chaincode_name" example:"basic"`
+	EventPattern  string `json:"event_pattern,omitempty" example:"Asset*"`
+	URL           string `json:"url" example:"https://example.com/hooks/fabric"`
+	// Secret signs the deliveries; one is generated when omitted
+	Secret string `json:"secret,omitempty"`
+	// StartBlock is the first block whose events are delivered; omitted
+	// starts with the next block committed
+	StartBlock *uint64 `json:"start_block,omitempty"`
+}
+
+// SubscriptionStatus is a subscription with its delivery progress
+type SubscriptionStatus struct {
+	*Subscription
+	Checkpoint  Checkpoint `json:"checkpoint"`
+	DeadLetters int        `json:"dead_letters"`
+}
+
+// RedeliverResponse reports the outcome of redelivering dead letters
+type RedeliverResponse struct {
+	Delivered int `json:"delivered"`
+	Failed    int `json:"failed"`
+}
+
+// CreateHandler godoc
+// @Summary Create a webhook subscription
+// @Description Delivers the chaincode's events whose name matches event_pattern to url as signed POST requests. The secret is only returned in this response. The url must not address a loopback, private or link-local network unless the server allows it.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body CreateRequest true "Subscription"
+// @Success 201 {object} Subscription
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 502 {object} response.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/webhooks [post]
+func (m *Manager) CreateHandler(w http.ResponseWriter, r *http.Request) {
+	var req CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	sub := &Subscription{
+		ChaincodeName: req.ChaincodeName,
+		EventPattern:  req.EventPattern,
+		URL:           req.URL,
+		Secret:        req.Secret,
+	}
+	if principal := auth.FromContext(r.Context()); principal != nil {
+		if req.ChaincodeName != "" && !principal.AllowsChaincode(m.fabricClient.ChannelName(), req.ChaincodeName, auth.OperationEvaluate) {
+			response.Error(w, http.StatusForbidden, fmt.Sprintf("%s is not allowed to receive events of chaincode %s", principal.Name, req.ChaincodeName))
+			return
+		}
+		sub.Owner = principal.ID()
+	}
+
+	if err := m.Create(r.Context(), sub, req.StartBlock); err != nil {
+		var validationErr *ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			response.Error(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, fabric.ErrClosed):
+			response.Error(w, http.StatusServiceUnavailable, err.Error())
+		case errors.Is(err, errLedgerHeight):
+			response.Error(w, http.StatusBadGateway, err.Error())
+		default:
+			slog.ErrorContext(r.Context(), "failed to create webhook subscription", "error", err)
+			response.Error(w, http.StatusInternalServerError, "failed to create subscription")
+		}
+		return
+	}
+	response.JSON(w, http.StatusCreated, sub)
+}
+
+// ListHandler godoc
+// @Summary List webhook subscriptions
+// @Description Returns the caller's subscriptions without their secrets
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} Subscription
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/webhooks [get]
+func (m *Manager) ListHandler(w http.ResponseWriter, r *http.Request) {
+	subs, err := m.store.Subscriptions()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to list webhook subscriptions", "error", err)
+		response.Error(w, http.StatusInternalServerError, "failed to list subscriptions")
+		return
+	}
+	visible := []*Subscription{}
+	for _, sub := range subs {
+		if ownedBy(r, sub) {
+			sub.Secret = ""
+			visible = append(visible, sub)
+		}
+	}
+	response.JSON(w, http.StatusOK, visible)
+}
+
+// GetHandler godoc
+// @Summary Get a webhook subscription
+// @Description Returns a subscription with its checkpoint and the number of dead letters
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} SubscriptionStatus
+// @Failure 404 {object} response.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/webhooks/{id} [get]
+func (m *Manager) GetHandler(w http.ResponseWriter, r *http.Request) {
+	sub, ok := m.lookup(w, r)
+	if !ok {
+		return
+	}
+	checkpoint, err := m.store.Checkpoint(sub.ID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		m.sendStoreError(w, r, err)
+		return
+	}
+	count, err := m.store.CountDeadLetters(sub.ID)
+	if err != nil {
+		m.sendStoreError(w, r, err)
+		return
+	}
+	sub.Secret = ""
+	response.JSON(w, http.StatusOK, SubscriptionStatus{Subscription: sub, Checkpoint: checkpoint, DeadLetters: count})
+}
+
+// DeleteHandler godoc
+// @Summary Delete a webhook subscription
+// @Description Stops the deliveries of a subscription and removes its checkpoint and dead letters
+// @Tags webhooks
+// @Param id path string true "Subscription ID"
+// @Success 204
+// @Failure 404 {object} response.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/webhooks/{id} [delete]
+func (m *Manager) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+	sub, ok := m.lookup(w, r)
+	if !ok {
+		return
+	}
+	if err := m.Delete(r.Context(), sub.ID); err != nil {
+		m.sendStoreError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeadLettersHandler godoc
+// @Summary List the dead letters of a webhook subscription
+// @Description Returns the events that could not be delivered within the configured attempts, oldest first
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {array} DeadLetter
+// @Failure 404 {object} response.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/webhooks/{id}/dead-letters [get]
+func (m *Manager) DeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	sub, ok := m.lookup(w, r)
+	if !ok {
+		return
+	}
+	letters, err := m.store.DeadLetters(sub.ID)
+	if err != nil {
+		m.sendStoreError(w, r, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, letters)
+}
+
+// RedeliverAllHandler godoc
+// @Summary Redeliver all dead letters of a webhook subscription
+// @Description Tries every dead letter once, oldest first. Delivered letters are removed; the others keep their place with the new error.
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} RedeliverResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/webhooks/{id}/dead-letters/redeliver [post]
+func (m *Manager) RedeliverAllHandler(w http.ResponseWriter, r *http.Request) {
+	sub, ok := m.lookup(w, r)
+	if !ok {
+		return
+	}
+	letters, err := m.store.DeadLetters(sub.ID)
+	if err != nil {
+		m.sendStoreError(w, r, err)
+		return
+	}
+	var resp RedeliverResponse
+	for _, letter := range letters {
+		err := m.Redeliver(r.Context(), sub, letter)
+		var deliveryErr *DeliveryError
+		switch {
+		case err == nil:
+			resp.Delivered++
+		case errors.As(err, &deliveryErr), errors.Is(err, ErrNotFound):
+			// Letters removed concurrently count as failed
+			resp.Failed++
+		default:
+			m.sendStoreError(w, r, err)
+			return
+		}
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// RedeliverHandler godoc
+// @Summary Redeliver a dead letter
+// @Description Tries to deliver a dead letter once. The letter is removed when the receiver acknowledges it.
+// @Tags webhooks
+// @Param id path string true "Subscription ID"
+// @Param letter path string true "Dead letter ID"
+// @Success 204
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 502 {object} response.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/webhooks/{id}/dead-letters/{letter}/redeliver [post]
+func (m *Manager) RedeliverHandler(w http.ResponseWriter, r *http.Request) {
+	sub, ok := m.lookup(w, r)
+	if !ok {
+		return
+	}
+	letter, err := m.store.DeadLetter(sub.ID, chi.URLParam(r, "letter"))
+	if err != nil {
+		m.sendStoreError(w, r, err)
+		return
+	}
+	if err := m.Redeliver(r.Context(), sub, letter); err != nil {
+		var deliveryErr *DeliveryError
+		if errors.As(err, &deliveryErr) {
+			response.Error(w, http.StatusBadGateway, err.Error())
+			return
+		}
+		m.sendStoreError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteDeadLetterHandler godoc
+// @Summary Discard a dead letter
+// @Tags webhooks
+// @Param id path string true "Subscription ID"
+// @Param letter path string true "Dead letter ID"
+// @Success 204
+// @Failure 404 {object} response.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/webhooks/{id}/dead-letters/{letter} [delete]
+func (m *Manager) DeleteDeadLetterHandler(w http.ResponseWriter, r *http.Request) {
+	sub, ok := m.lookup(w, r)
+	if !ok {
+		return
+	}
+	if err := m.store.DeleteDeadLetter(sub.ID, chi.URLParam(r, "letter")); err != nil {
+		m.sendStoreError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// lookup returns the subscription named by the request path, replying 404
+// when it does not exist or belongs to another principal
+func (m *Manager) lookup(w http.ResponseWriter, r *http.Request) (*Subscription, bool) {
+	sub, err := m.store.Subscription(chi.URLParam(r, "id"))
+	if err == nil && !ownedBy(r, sub) {
+		err = ErrNotFound
+	}
+	if err != nil {
+		m.sendStoreError(w, r, err)
+		return nil, false
+	}
+	return sub, true
+}
+
+// ownedBy reports whether the caller of r may manage the subscription; every
+// subscription is visible when authentication is disabled
+func ownedBy(r *http.Request, sub *Subscription) bool {
+	principal := auth.FromContext(r.Context())
+	return principal == nil || principal.ID() == sub.Owner
+}
+
+func (m *Manager) sendStoreError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, ErrNotFound) {
+		response.Error(w, http.StatusNotFound, "not found")
+		return
+	}
+	slog.ErrorContext(r.Context(), "webhook store failed", "error", err)
+	response.Error(w, http.StatusInternalServerError, "internal error")
+}
@@ -0,0 +1,321 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+)
+
+// errLedgerHeight is returned by Create when the start block cannot be resolved
+var errLedgerHeight = errors.New("failed to query the ledger height")
+
+// Manager runs a delivery worker per subscription. Each worker follows the
+// chaincode events from the subscription's checkpoint and delivers them in
+// order; an event is retried until it is acknowledged or dead-lettered before
+// the next one is delivered, so delivery is at least once.
+type Manager struct {
+	store        *Store
+	fabricClient *fabric.FabricClient
+	config       Config
+	httpClient   *http.Client
+
+	mu sync.Mutex
+	// ctx is set once Run is called; subscriptions created before are
+	// started by Run
+	ctx     context.Context
+	workers map[string]*worker
+	running sync.WaitGroup
+}
+
+type worker struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewManager creates a manager delivering the subscriptions kept in store
+func NewManager(store *Store, fabricClient *fabric.FabricClient, config Config) *Manager {
+	return &Manager{
+		store:        store,
+		fabricClient: fabricClient,
+		config:       config,
+		httpClient: &http.Client{
+			Transport: config.transport(),
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		workers: map[string]*worker{},
+	}
+}
+
+// Run delivers the events of every subscription until ctx is cancelled
+func (m *Manager) Run(ctx context.Context) {
+	subs, err := m.store.Subscriptions()
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load webhook subscriptions", "error", err)
+	}
+	m.mu.Lock()
+	m.ctx = ctx
+	for _, sub := range subs {
+		m.startLocked(sub)
+	}
+	m.mu.Unlock()
+	slog.InfoContext(ctx, "delivering webhooks", "subscriptions", len(subs))
+
+	<-ctx.Done()
+	m.running.Wait()
+}
+
+// startLocked starts the worker of a subscription; m.mu must be held
+func (m *Manager) startLocked(sub *Subscription) {
+	if m.ctx == nil || m.ctx.Err() != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(m.ctx)
+	w := &worker{cancel: cancel, done: make(chan struct{})}
+	m.workers[sub.ID] = w
+	m.running.Add(1)
+	go func() {
+		defer m.running.Done()
+		defer close(w.done)
+		m.follow(ctx, sub)
+	}()
+}
+
+// stop stops the worker of a subscription and waits until it returned
+func (m *Manager) stop(id string) {
+	m.mu.Lock()
+	w, ok := m.workers[id]
+	delete(m.workers, id)
+	m.mu.Unlock()
+	if ok {
+		w.cancel()
+		<-w.done
+	}
+}
+
+// Create validates and stores a subscription and starts delivering its events
+// from startBlock, or from the next block committed when startBlock is nil. A
+// secret is generated when the subscription has none.
+func (m *Manager) Create(ctx context.Context, sub *Subscription, startBlock *uint64) error {
+	if err := validate(sub, m.config); err != nil {
+		return err
+	}
+	if sub.Secret == "" {
+		secret, err := randomHex(32)
+		if err != nil {
+			return err
+		}
+		sub.Secret = secret
+	}
+	id, err := randomHex(16)
+	if err != nil {
+		return err
+	}
+	sub.ID = id
+	sub.CreatedAt = time.Now().UTC()
+
+	checkpoint := Checkpoint{}
+	if startBlock != nil {
+		checkpoint.BlockNumber = *startBlock
+	} else {
+		info, err := m.fabricClient.ChainInfo(ctx)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errLedgerHeight, err)
+		}
+		checkpoint.BlockNumber = info.Height
+	}
+	if err := m.store.Create(sub, checkpoint); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.startLocked(sub)
+	m.mu.Unlock()
+	slog.InfoContext(ctx, "created webhook subscription", "subscription", sub.ID, "chaincode", sub.ChaincodeName, "event_pattern", sub.EventPattern, "start_block", checkpoint.BlockNumber)
+	return nil
+}
+
+// Subscription returns a stored subscription
+func (m *Manager) Subscription(id string) (*Subscription, error) {
+	return m.store.Subscription(id)
+}
+
+// Delete stops delivering a subscription and removes it with its checkpoint
+// and dead letters
+func (m *Manager) Delete(ctx context.Context, id string) error {
+	m.stop(id)
+	if err := m.store.Delete(id); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "deleted webhook subscription", "subscription", id)
+	return nil
+}
+
+// Redeliver makes a single delivery attempt of a dead letter. The letter is
+// removed when the event is acknowledged and updated otherwise.
+func (m *Manager) Redeliver(ctx context.Context, sub *Subscription, letter *DeadLetter) error {
+	err := m.deliver(ctx, sub, &letter.Event)
+	if err == nil {
+		return m.store.DeleteDeadLetter(sub.ID, letter.ID)
+	}
+	letter.Attempts++
+	letter.LastError = err.Error()
+	letter.FailedAt = time.Now().UTC()
+	if updateErr := m.store.UpdateDeadLetter(letter); updateErr != nil {
+		return updateErr
+	}
+	return &DeliveryError{Err: err}
+}
+
+// DeliveryError is returned when a receiver did not acknowledge a redelivery
+type DeliveryError struct {
+	Err error
+}
+
+func (e *DeliveryError) Error() string {
+	return fmt.Sprintf("delivery failed: %v", e.Err)
+}
+
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// follow delivers the events of a subscription until ctx is cancelled,
+// reconnecting with backoff whenever the event stream ends
+func (m *Manager) follow(ctx context.Context, sub *Subscription) {
+	for retry := 0; ; {
+		err := m.stream(ctx, sub)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			retry = 0
+			err = errors.New("event stream ended")
+		}
+		wait := m.config.backoff(retry)
+		retry++
+		slog.WarnContext(ctx, "webhook event stream interrupted, reconnecting", "subscription", sub.ID, "chaincode", sub.ChaincodeName, "error", err, "retry_in", wait.String())
+		if !sleep(ctx, wait) {
+			return
+		}
+	}
+}
+
+// stream delivers events from the subscription's checkpoint until the event
+// stream ends. It returns nil if at least one event was handled, so that the
+// reconnect backoff is reset.
+func (m *Manager) stream(ctx context.Context, sub *Subscription) error {
+	checkpoint, err := m.store.Checkpoint(sub.ID)
+	if err != nil {
+		return err
+	}
+	streamCtx, cancel := context.WithCancel(ctx)
+	events, err := m.fabricClient.ChaincodeEvents(streamCtx, sub.ChaincodeName, fabric.EventOptions{StartBlock: &checkpoint.BlockNumber})
+	if err != nil {
+		cancel()
+		return err
+	}
+	defer func() {
+		// Drain the stream so that its gateway connection is closed
+		cancel()
+		for range events {
+		}
+	}()
+
+	handled := false
+	// Events of the checkpoint block up to its transaction were handled before
+	skipping := checkpoint.TxID != ""
+	for event := range events {
+		if skipping && event.BlockNumber == checkpoint.BlockNumber {
+			if event.TxID == checkpoint.TxID {
+				skipping = false
+			}
+			continue
+		}
+		skipping = false
+
+		if matches(sub.EventPattern, event.EventName) {
+			attempts, err := m.deliverWithRetries(ctx, sub, event)
+			if ctx.Err() != nil {
+				// The event is delivered again after a restart
+				return nil
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "webhook delivery failed, dead-lettering event", "subscription", sub.ID, "tx_id", event.TxID, "attempts", attempts, "error", err)
+				letter := &DeadLetter{
+					SubscriptionID: sub.ID,
+					Event:          *event,
+					Attempts:       attempts,
+					LastError:      err.Error(),
+					FailedAt:       time.Now().UTC(),
+				}
+				if err := m.store.AddDeadLetter(letter); err != nil {
+					// Keep the checkpoint so that the event is tried again
+					return err
+				}
+			}
+		}
+		if err := m.store.SetCheckpoint(sub.ID, Checkpoint{BlockNumber: event.BlockNumber, TxID: event.TxID}); err != nil {
+			slog.ErrorContext(ctx, "failed to store webhook checkpoint", "subscription", sub.ID, "error", err)
+		}
+		handled = true
+	}
+	if handled {
+		return nil
+	}
+	return errors.New("event stream ended")
+}
+
+// validate checks the fields of a new subscription
+func validate(sub *Subscription, config Config) error {
+	if sub.ChaincodeName == "" {
+		return &ValidationError{"chaincode_name is required"}
+	}
+	if _, err := path.Match(sub.EventPattern, ""); err != nil {
+		return &ValidationError{fmt.Sprintf("invalid event_pattern %q", sub.EventPattern)}
+	}
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &ValidationError{"url must be an absolute http or https URL"}
+	}
+	if addr, denied := config.deniedHost(u.Hostname()); denied {
+		return &ValidationError{fmt.Sprintf("url must not address %s: loopback, private and link-local networks are not allowed", addr)}
+	}
+	return nil
+}
+
+// ValidationError is returned for subscriptions with invalid fields
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func matches(pattern, eventName string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(pattern, eventName)
+	return ok
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
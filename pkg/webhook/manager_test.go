@@ -0,0 +1,240 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/auth"
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+)
+
+var testConfig = Config{
+	MaxAttempts: 3,
+	Timeout:     time.Second,
+	MinBackoff:  time.Millisecond,
+	MaxBackoff:  4 * time.Millisecond,
+	// The receivers of the tests listen on the loopback interface
+	AllowedNetworks: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")},
+}
+
+// newTestManager returns a manager whose Fabric client cannot reach its peer
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	fabricClient, err := fabric.NewFabricClient(&fabric.ClientConfig{
+		ChannelName: "mychannel",
+		Peers:       []fabric.PeerConfig{{Endpoint: "127.0.0.1:1", TLSCertPath: t.TempDir() + "/missing-ca.pem"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewManager(newTestStore(t), fabricClient, testConfig)
+}
+
+// receiver is a webhook endpoint that rejects the given number of deliveries
+// before it acknowledges them
+type receiver struct {
+	failures  int32
+	calls     atomic.Int32
+	lastBody  []byte
+	lastValid bool
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rc.lastBody = body
+	rc.lastValid = r.Header.Get(HeaderSignature) == Sign("secret", r.Header.Get(HeaderTimestamp), body)
+	if rc.calls.Add(1) <= rc.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestDeliverWithRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int32
+		attempts int
+		wantErr  bool
+	}{
+		{name: "acknowledged", failures: 0, attempts: 1},
+		{name: "acknowledged after retries", failures: 2, attempts: 3},
+		{name: "attempts exhausted", failures: 5, attempts: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := &receiver{failures: tt.failures}
+			ts := httptest.NewServer(rc)
+			defer ts.Close()
+
+			m := newTestManager(t)
+			sub := &Subscription{ID: "sub1", URL: ts.URL, Secret: "secret"}
+			event := &fabric.ChaincodeEvent{BlockNumber: 7, TxID: "tx1", ChaincodeName: "basic", EventName: "AssetCreated"}
+			attempts, err := m.deliverWithRetries(context.Background(), sub, event)
+			if attempts != tt.attempts || (err != nil) != tt.wantErr {
+				t.Errorf("deliverWithRetries() = %d, %v; want %d attempts, error %v", attempts, err, tt.attempts, tt.wantErr)
+			}
+			if !rc.lastValid {
+				t.Error("the delivery signature does not verify")
+			}
+			var delivery Delivery
+			if err := json.Unmarshal(rc.lastBody, &delivery); err != nil || delivery.ID != "7-tx1" || delivery.Event.EventName != "AssetCreated" {
+				t.Errorf("delivery = %s, %v", rc.lastBody, err)
+			}
+		})
+	}
+}
+
+func TestRedeliver(t *testing.T) {
+	rc := &receiver{failures: 1}
+	ts := httptest.NewServer(rc)
+	defer ts.Close()
+
+	m := newTestManager(t)
+	sub := &Subscription{ID: "sub1", URL: ts.URL, Secret: "secret"}
+	if err := m.store.Create(sub, Checkpoint{}); err != nil {
+		t.Fatal(err)
+	}
+	letter := &DeadLetter{SubscriptionID: "sub1", Event: fabric.ChaincodeEvent{TxID: "tx1"}, Attempts: 3}
+	if err := m.store.AddDeadLetter(letter); err != nil {
+		t.Fatal(err)
+	}
+
+	var deliveryErr *DeliveryError
+	if err := m.Redeliver(context.Background(), sub, letter); !errors.As(err, &deliveryErr) {
+		t.Fatalf("Redeliver() error = %v, want a DeliveryError", err)
+	}
+	if stored, _ := m.store.DeadLetter("sub1", letter.ID); stored == nil || stored.Attempts != 4 || stored.LastError == "" {
+		t.Errorf("dead letter after a failed redelivery = %+v", stored)
+	}
+	if err := m.Redeliver(context.Background(), sub, letter); err != nil {
+		t.Fatalf("Redeliver() error = %v", err)
+	}
+	if _, err := m.store.DeadLetter("sub1", letter.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("the delivered dead letter was kept: %v", err)
+	}
+}
+
+func TestDeliverRefusesNetworksNotAllowed(t *testing.T) {
+	rc := &receiver{}
+	ts := httptest.NewServer(rc)
+	defer ts.Close()
+
+	config := testConfig
+	config.AllowedNetworks = nil
+	m := NewManager(newTestStore(t), nil, config)
+	if err := validate(&Subscription{ChaincodeName: "basic", URL: "http://localhost/hook"}, config); err == nil {
+		t.Error("validate() of a localhost URL succeeded, want it rejected")
+	}
+	// Host names are checked once resolved, when connecting
+	sub := &Subscription{ID: "sub1", URL: strings.Replace(ts.URL, "127.0.0.1", "localhost", 1), Secret: "secret"}
+	err := m.deliver(context.Background(), sub, &fabric.ChaincodeEvent{TxID: "tx1"})
+	if !errors.Is(err, errAddressNotAllowed) || rc.calls.Load() != 0 {
+		t.Errorf("deliver() to the loopback interface error = %v after %d calls, want %v", err, rc.calls.Load(), errAddressNotAllowed)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	m := newTestManager(t)
+	start := uint64(3)
+
+	var validationErr *ValidationError
+	for _, sub := range []*Subscription{
+		{URL: "https://example.com/hook"},
+		{ChaincodeName: "basic", URL: "ftp://example.com/hook"},
+		{ChaincodeName: "basic", URL: "https://example.com/hook", EventPattern: "["},
+		{ChaincodeName: "basic", URL: "http://169.254.169.254/latest/meta-data"},
+		{ChaincodeName: "basic", URL: "http://10.0.0.1:8080/hook"},
+		{ChaincodeName: "basic", URL: "http://[::1]/hook"},
+		{ChaincodeName: "basic", URL: "http://[::ffff:192.168.1.1]/hook"},
+	} {
+		if err := m.Create(context.Background(), sub, &start); !errors.As(err, &validationErr) {
+			t.Errorf("Create(%+v) error = %v, want a ValidationError", sub, err)
+		}
+	}
+
+	sub := &Subscription{ChaincodeName: "basic", URL: "https://example.com/hook"}
+	if err := m.Create(context.Background(), sub, &start); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if sub.ID == "" || len(sub.Secret) != 64 {
+		t.Errorf("Create() = %+v, want an ID and a generated secret", sub)
+	}
+	if checkpoint, _ := m.store.Checkpoint(sub.ID); checkpoint.BlockNumber != 3 {
+		t.Errorf("Checkpoint() = %+v, want the start block", checkpoint)
+	}
+
+	// Without a start block the subscription starts at the ledger height
+	err := m.Create(context.Background(), &Subscription{ChaincodeName: "basic", URL: "https://example.com/hook"}, nil)
+	if !errors.Is(err, errLedgerHeight) {
+		t.Errorf("Create() error = %v, want %v", err, errLedgerHeight)
+	}
+}
+
+func TestHandlersHideOtherOwners(t *testing.T) {
+	m := newTestManager(t)
+	if err := m.store.Create(&Subscription{ID: "sub1", ChaincodeName: "basic", Owner: "api_key:alice", Secret: "secret"}, Checkpoint{}); err != nil {
+		t.Fatal(err)
+	}
+	r := chi.NewRouter()
+	r.Get("/api/webhooks", m.ListHandler)
+	r.Get("/api/webhooks/{id}", m.GetHandler)
+
+	request := func(path, method, principal string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req = req.WithContext(auth.NewContext(req.Context(), &auth.Principal{Name: principal, Method: method}))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := request("/api/webhooks/sub1", "api_key", "alice"); w.Code != http.StatusOK || strings.Contains(w.Body.String(), "secret") {
+		t.Errorf("GET by the owner = %d %s, want the subscription without its secret", w.Code, w.Body)
+	}
+	if w := request("/api/webhooks/sub1", "api_key", "bob"); w.Code != http.StatusNotFound {
+		t.Errorf("GET by another principal status = %d, want 404", w.Code)
+	}
+	if w := request("/api/webhooks/sub1", "jwt", "alice"); w.Code != http.StatusNotFound {
+		t.Errorf("GET by a principal of the same name from another method status = %d, want 404", w.Code)
+	}
+	if w := request("/api/webhooks", "api_key", "bob"); strings.TrimSpace(w.Body.String()) != "[]" {
+		t.Errorf("list by another principal = %s, want []", w.Body)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	config := Config{MinBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	for retry, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if got := config.backoff(retry); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", retry, got, want)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"", "AssetCreated", true},
+		{"Asset*", "AssetCreated", true},
+		{"Asset*", "OwnerChanged", false},
+		{"AssetCreated", "AssetCreated", true},
+	}
+	for _, tt := range tests {
+		if got := matches(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matches(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
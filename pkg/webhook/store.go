@@ -0,0 +1,315 @@
+package webhook
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	subscriptionsBucket = []byte("webhook_subscriptions")
+	checkpointsBucket   = []byte("webhook_checkpoints")
+	// deadLettersBucket holds a nested bucket of dead letters per subscription
+	deadLettersBucket = []byte("webhook_dead_letters")
+)
+
+// ErrNotFound is returned for unknown subscriptions and dead letters
+var ErrNotFound = errors.New("not found")
+
+// Store keeps the subscriptions, their checkpoints and dead letters in a
+// bbolt database file so that deliveries resume after a restart
+type Store struct {
+	db *bolt.DB
+}
+
+// NewStore opens (or creates) the database file at path
+func NewStore(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open webhook database %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{subscriptionsBucket, checkpointsBucket, deadLettersBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize webhook database: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Create stores a new subscription with its initial checkpoint
+func (s *Store) Create(sub *Subscription, checkpoint Checkpoint) error {
+	subData, err := json.Marshal(sub)
+	if err != nil {
+		return fmt.Errorf("failed to encode subscription: %w", err)
+	}
+	checkpointData, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(subscriptionsBucket).Put([]byte(sub.ID), subData); err != nil {
+			return err
+		}
+		return tx.Bucket(checkpointsBucket).Put([]byte(sub.ID), checkpointData)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write subscription: %w", err)
+	}
+	return nil
+}
+
+// Subscriptions returns every stored subscription
+func (s *Store) Subscriptions() ([]*Subscription, error) {
+	var subs []*Subscription
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(subscriptionsBucket).ForEach(func(k, v []byte) error {
+			var sub Subscription
+			if err := json.Unmarshal(v, &sub); err != nil {
+				return fmt.Errorf("subscription %s: %w", k, err)
+			}
+			subs = append(subs, &sub)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+// Subscription returns a stored subscription
+func (s *Store) Subscription(id string) (*Subscription, error) {
+	var sub *Subscription
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(subscriptionsBucket).Get([]byte(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		sub = &Subscription{}
+		return json.Unmarshal(data, sub)
+	})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("failed to read subscription: %w", err)
+	}
+	return sub, err
+}
+
+// Delete removes a subscription with its checkpoint and dead letters
+func (s *Store) Delete(id string) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(subscriptionsBucket).Get([]byte(id)) == nil {
+			return ErrNotFound
+		}
+		if err := tx.Bucket(subscriptionsBucket).Delete([]byte(id)); err != nil {
+			return err
+		}
+		if err := tx.Bucket(checkpointsBucket).Delete([]byte(id)); err != nil {
+			return err
+		}
+		err := tx.Bucket(deadLettersBucket).DeleteBucket([]byte(id))
+		if errors.Is(err, bolt.ErrBucketNotFound) {
+			return nil
+		}
+		return err
+	})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to delete subscription: %w", err)
+	}
+	return err
+}
+
+// Checkpoint returns the position a subscription resumes from
+func (s *Store) Checkpoint(id string) (Checkpoint, error) {
+	var checkpoint Checkpoint
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(checkpointsBucket).Get([]byte(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, &checkpoint)
+	})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return checkpoint, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	return checkpoint, err
+}
+
+// SetCheckpoint records the last event handled by a subscription
+func (s *Store) SetCheckpoint(id string, checkpoint Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		// A subscription deleted while its last delivery ran keeps no checkpoint
+		if tx.Bucket(subscriptionsBucket).Get([]byte(id)) == nil {
+			return nil
+		}
+		return tx.Bucket(checkpointsBucket).Put([]byte(id), data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// AddDeadLetter stores an event whose delivery failed and assigns its ID
+func (s *Store) AddDeadLetter(letter *DeadLetter) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(subscriptionsBucket).Get([]byte(letter.SubscriptionID)) == nil {
+			return nil
+		}
+		b, err := tx.Bucket(deadLettersBucket).CreateBucketIfNotExists([]byte(letter.SubscriptionID))
+		if err != nil {
+			return err
+		}
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		letter.ID = strconv.FormatUint(seq, 10)
+		data, err := json.Marshal(letter)
+		if err != nil {
+			return err
+		}
+		return b.Put(sequenceKey(seq), data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write dead letter: %w", err)
+	}
+	return nil
+}
+
+// DeadLetters returns the dead letters of a subscription, oldest first
+func (s *Store) DeadLetters(subscriptionID string) ([]*DeadLetter, error) {
+	letters := []*DeadLetter{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(deadLettersBucket).Bucket([]byte(subscriptionID))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var letter DeadLetter
+			if err := json.Unmarshal(v, &letter); err != nil {
+				return err
+			}
+			letters = append(letters, &letter)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead letters: %w", err)
+	}
+	return letters, nil
+}
+
+// CountDeadLetters returns the number of dead letters of a subscription
+func (s *Store) CountDeadLetters(subscriptionID string) (int, error) {
+	var count int
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket(deadLettersBucket).Bucket([]byte(subscriptionID)); b != nil {
+			count = b.Stats().KeyN
+		}
+		return nil
+	})
+	return count, err
+}
+
+// DeadLetter returns a dead letter of a subscription
+func (s *Store) DeadLetter(subscriptionID, id string) (*DeadLetter, error) {
+	key, err := letterKey(id)
+	if err != nil {
+		return nil, err
+	}
+	var letter *DeadLetter
+	err = s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(deadLettersBucket).Bucket([]byte(subscriptionID))
+		if b == nil {
+			return ErrNotFound
+		}
+		data := b.Get(key)
+		if data == nil {
+			return ErrNotFound
+		}
+		letter = &DeadLetter{}
+		return json.Unmarshal(data, letter)
+	})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("failed to read dead letter: %w", err)
+	}
+	return letter, err
+}
+
+// UpdateDeadLetter replaces a dead letter after a failed redelivery
+func (s *Store) UpdateDeadLetter(letter *DeadLetter) error {
+	key, err := letterKey(letter.ID)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(letter)
+	if err != nil {
+		return fmt.Errorf("failed to encode dead letter: %w", err)
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(deadLettersBucket).Bucket([]byte(letter.SubscriptionID))
+		if b == nil || b.Get(key) == nil {
+			return ErrNotFound
+		}
+		return b.Put(key, data)
+	})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to write dead letter: %w", err)
+	}
+	return err
+}
+
+// DeleteDeadLetter removes a dead letter
+func (s *Store) DeleteDeadLetter(subscriptionID, id string) error {
+	key, err := letterKey(id)
+	if err != nil {
+		return err
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(deadLettersBucket).Bucket([]byte(subscriptionID))
+		if b == nil || b.Get(key) == nil {
+			return ErrNotFound
+		}
+		return b.Delete(key)
+	})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to delete dead letter: %w", err)
+	}
+	return err
+}
+
+// Close closes the database file
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// sequenceKey encodes a dead letter sequence number so that keys sort in order
+func sequenceKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
+
+func letterKey(id string) ([]byte, error) {
+	seq, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	return sequenceKey(seq), nil
+}
@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := NewStore(filepath.Join(t.TempDir(), "webhooks.db"))
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestStoreSubscriptions(t *testing.T) {
+	store := newTestStore(t)
+	sub := &Subscription{ID: "sub1", ChaincodeName: "basic", URL: "https://example.com/hook", Secret: "secret"}
+	if err := store.Create(sub, Checkpoint{BlockNumber: 5}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	got, err := store.Subscription("sub1")
+	if err != nil || got.URL != sub.URL || got.Secret != "secret" {
+		t.Errorf("Subscription() = %+v, %v", got, err)
+	}
+	if checkpoint, err := store.Checkpoint("sub1"); err != nil || checkpoint != (Checkpoint{BlockNumber: 5}) {
+		t.Errorf("Checkpoint() = %+v, %v; want the initial checkpoint", checkpoint, err)
+	}
+	if err := store.SetCheckpoint("sub1", Checkpoint{BlockNumber: 6, TxID: "tx1"}); err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint, _ := store.Checkpoint("sub1"); checkpoint != (Checkpoint{BlockNumber: 6, TxID: "tx1"}) {
+		t.Errorf("Checkpoint() = %+v after SetCheckpoint", checkpoint)
+	}
+
+	if err := store.Delete("sub1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Subscription("sub1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Subscription() after Delete error = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete("sub1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete() error = %v, want ErrNotFound", err)
+	}
+	// A delivery finishing after the subscription was deleted leaves nothing behind
+	if err := store.SetCheckpoint("sub1", Checkpoint{BlockNumber: 7}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Checkpoint("sub1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Checkpoint() of a deleted subscription error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestStoreDeadLetters(t *testing.T) {
+	store := newTestStore(t)
+	if err := store.Create(&Subscription{ID: "sub1"}, Checkpoint{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, txID := range []string{"tx1", "tx2", "tx3"} {
+		letter := &DeadLetter{SubscriptionID: "sub1", Event: fabric.ChaincodeEvent{TxID: txID}, Attempts: 3}
+		if err := store.AddDeadLetter(letter); err != nil {
+			t.Fatalf("AddDeadLetter() error = %v", err)
+		}
+		if letter.ID == "" {
+			t.Error("AddDeadLetter() did not assign an ID")
+		}
+	}
+
+	letters, err := store.DeadLetters("sub1")
+	if err != nil || len(letters) != 3 || letters[0].Event.TxID != "tx1" || letters[2].Event.TxID != "tx3" {
+		t.Fatalf("DeadLetters() = %v, %v; want 3 letters oldest first", letters, err)
+	}
+	if count, _ := store.CountDeadLetters("sub1"); count != 3 {
+		t.Errorf("CountDeadLetters() = %d, want 3", count)
+	}
+
+	letters[1].Attempts = 4
+	if err := store.UpdateDeadLetter(letters[1]); err != nil {
+		t.Fatal(err)
+	}
+	if letter, err := store.DeadLetter("sub1", letters[1].ID); err != nil || letter.Attempts != 4 {
+		t.Errorf("DeadLetter() = %+v, %v; want the update", letter, err)
+	}
+	if err := store.DeleteDeadLetter("sub1", letters[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{letters[0].ID, "invalid", "999"} {
+		if _, err := store.DeadLetter("sub1", id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("DeadLetter(%q) error = %v, want ErrNotFound", id, err)
+		}
+	}
+
+	// Deleting the subscription removes its dead letters, and letters of
+	// deleted subscriptions are dropped
+	if err := store.Delete("sub1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddDeadLetter(&DeadLetter{SubscriptionID: "sub1"}); err != nil {
+		t.Fatal(err)
+	}
+	if letters, _ := store.DeadLetters("sub1"); len(letters) != 0 {
+		t.Errorf("DeadLetters() of a deleted subscription = %v", letters)
+	}
+}
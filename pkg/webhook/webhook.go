@@ -0,0 +1,77 @@
+package webhook
+
+import (
+	"net/netip"
+	"time"
+
+	"github.com/kfsoftware/chainlaunch-plugin-hlf/pkg/fabric"
+)
+
+// Subscription delivers the events of a chaincode to a URL
+type Subscription struct {
+	ID            string `json:"id"`
+	ChaincodeName string `json:"chaincode_name"`
+	// EventPattern selects events by name as understood by path.Match; empty
+	// selects every event of the chaincode
+	EventPattern string `json:"event_pattern,omitempty"`
+	URL          string `json:"url"`
+	// Secret is the key deliveries are signed with; it is only returned when
+	// the subscription is created
+	Secret string `json:"secret,omitempty"`
+	// Owner is the ID of the principal that created the subscription
+	Owner     string    `json:"owner,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Checkpoint is the last event handled by a subscription. Delivery resumes
+// with the events of BlockNumber that follow the transaction TxID, or with the
+// whole block when TxID is empty.
+type Checkpoint struct {
+	BlockNumber uint64 `json:"block_number"`
+	TxID        string `json:"tx_id,omitempty"`
+}
+
+// DeadLetter is an event that could not be delivered within the configured attempts
+type DeadLetter struct {
+	ID             string                `json:"id"`
+	SubscriptionID string                `json:"subscription_id"`
+	Event          fabric.ChaincodeEvent `json:"event"`
+	Attempts       int                   `json:"attempts"`
+	LastError      string                `json:"last_error"`
+	FailedAt       time.Time             `json:"failed_at"`
+}
+
+// Delivery is the body POSTed to the subscription URL
+type Delivery struct {
+	// ID identifies the event across retries and redeliveries, so that
+	// receivers can discard duplicates
+	ID             string                 `json:"id"`
+	SubscriptionID string                 `json:"subscription_id"`
+	Event          *fabric.ChaincodeEvent `json:"event"`
+}
+
+// Config tunes the delivery of events
+type Config struct {
+	// MaxAttempts is how often an event is tried before it is dead-lettered
+	MaxAttempts int
+	// Timeout bounds each delivery attempt
+	Timeout time.Duration
+	// MinBackoff and MaxBackoff bound the exponential wait between attempts
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// AllowedNetworks are the loopback, private or link-local networks
+	// deliveries may be sent to; only public addresses are allowed otherwise
+	AllowedNetworks []netip.Prefix
+}
+
+// backoff returns the wait before the given retry, starting at 0
+func (c Config) backoff(retry int) time.Duration {
+	wait := c.MinBackoff
+	for i := 0; i < retry && wait < c.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > c.MaxBackoff {
+		wait = c.MaxBackoff
+	}
+	return wait
+}
@@ -0,0 +1,256 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: hlfapi/v1/hlfapi.proto
+
+package hlfapiv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TransactionService_Invoke_FullMethodName          = "/hlfapi.v1.TransactionService/Invoke"
+	TransactionService_Evaluate_FullMethodName        = "/hlfapi.v1.TransactionService/Evaluate"
+	TransactionService_ChaincodeEvents_FullMethodName = "/hlfapi.v1.TransactionService/ChaincodeEvents"
+	TransactionService_BlockEvents_FullMethodName     = "/hlfapi.v1.TransactionService/BlockEvents"
+)
+
+// TransactionServiceClient is the client API for TransactionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TransactionService mirrors the REST transaction endpoints and streams the
+// events of the channel the server is connected to.
+type TransactionServiceClient interface {
+	// Invoke submits a transaction and waits for it to be committed, like POST /api/invoke.
+	Invoke(ctx context.Context, in *TransactionRequest, opts ...grpc.CallOption) (*InvokeResponse, error)
+	// Evaluate queries the chaincode without submitting, like POST /api/evaluate.
+	Evaluate(ctx context.Context, in *TransactionRequest, opts ...grpc.CallOption) (*EvaluateResponse, error)
+	// ChaincodeEvents streams the events emitted by a chaincode's committed transactions.
+	ChaincodeEvents(ctx context.Context, in *ChaincodeEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChaincodeEvent], error)
+	// BlockEvents streams the blocks committed to the channel.
+	BlockEvents(ctx context.Context, in *BlockEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Block], error)
+}
+
+type transactionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTransactionServiceClient(cc grpc.ClientConnInterface) TransactionServiceClient {
+	return &transactionServiceClient{cc}
+}
+
+func (c *transactionServiceClient) Invoke(ctx context.Context, in *TransactionRequest, opts ...grpc.CallOption) (*InvokeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InvokeResponse)
+	err := c.cc.Invoke(ctx, TransactionService_Invoke_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) Evaluate(ctx context.Context, in *TransactionRequest, opts ...grpc.CallOption) (*EvaluateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EvaluateResponse)
+	err := c.cc.Invoke(ctx, TransactionService_Evaluate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) ChaincodeEvents(ctx context.Context, in *ChaincodeEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChaincodeEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TransactionService_ServiceDesc.Streams[0], TransactionService_ChaincodeEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ChaincodeEventsRequest, ChaincodeEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TransactionService_ChaincodeEventsClient = grpc.ServerStreamingClient[ChaincodeEvent]
+
+func (c *transactionServiceClient) BlockEvents(ctx context.Context, in *BlockEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Block], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TransactionService_ServiceDesc.Streams[1], TransactionService_BlockEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BlockEventsRequest, Block]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TransactionService_BlockEventsClient = grpc.ServerStreamingClient[Block]
+
+// TransactionServiceServer is the server API for TransactionService service.
+// All implementations must embed UnimplementedTransactionServiceServer
+// for forward compatibility.
+//
+// TransactionService mirrors the REST transaction endpoints and streams the
+// events of the channel the server is connected to.
+type TransactionServiceServer interface {
+	// Invoke submits a transaction and waits for it to be committed, like POST /api/invoke.
+	Invoke(context.Context, *TransactionRequest) (*InvokeResponse, error)
+	// Evaluate queries the chaincode without submitting, like POST /api/evaluate.
+	Evaluate(context.Context, *TransactionRequest) (*EvaluateResponse, error)
+	// ChaincodeEvents streams the events emitted by a chaincode's committed transactions.
+	ChaincodeEvents(*ChaincodeEventsRequest, grpc.ServerStreamingServer[ChaincodeEvent]) error
+	// BlockEvents streams the blocks committed to the channel.
+	BlockEvents(*BlockEventsRequest, grpc.ServerStreamingServer[Block]) error
+	mustEmbedUnimplementedTransactionServiceServer()
+}
+
+// UnimplementedTransactionServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTransactionServiceServer struct{}
+
+func (UnimplementedTransactionServiceServer) Invoke(context.Context, *TransactionRequest) (*InvokeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Invoke not implemented")
+}
+func (UnimplementedTransactionServiceServer) Evaluate(context.Context, *TransactionRequest) (*EvaluateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Evaluate not implemented")
+}
+func (UnimplementedTransactionServiceServer) ChaincodeEvents(*ChaincodeEventsRequest, grpc.ServerStreamingServer[ChaincodeEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method ChaincodeEvents not implemented")
+}
+func (UnimplementedTransactionServiceServer) BlockEvents(*BlockEventsRequest, grpc.ServerStreamingServer[Block]) error {
+	return status.Errorf(codes.Unimplemented, "method BlockEvents not implemented")
+}
+func (UnimplementedTransactionServiceServer) mustEmbedUnimplementedTransactionServiceServer() {}
+func (UnimplementedTransactionServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafeTransactionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TransactionServiceServer will
+// result in compilation errors.
+type UnsafeTransactionServiceServer interface {
+	mustEmbedUnimplementedTransactionServiceServer()
+}
+
+func RegisterTransactionServiceServer(s grpc.ServiceRegistrar, srv TransactionServiceServer) {
+	// If the following call pancis, it indicates UnimplementedTransactionServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TransactionService_ServiceDesc, srv)
+}
+
+func _TransactionService_Invoke_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).Invoke(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_Invoke_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).Invoke(ctx, req.(*TransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_Evaluate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).Evaluate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_Evaluate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).Evaluate(ctx, req.(*TransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_ChaincodeEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ChaincodeEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TransactionServiceServer).ChaincodeEvents(m, &grpc.GenericServerStream[ChaincodeEventsRequest, ChaincodeEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TransactionService_ChaincodeEventsServer = grpc.ServerStreamingServer[ChaincodeEvent]
+
+func _TransactionService_BlockEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BlockEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TransactionServiceServer).BlockEvents(m, &grpc.GenericServerStream[BlockEventsRequest, Block]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TransactionService_BlockEventsServer = grpc.ServerStreamingServer[Block]
+
+// TransactionService_ServiceDesc is the grpc.ServiceDesc for TransactionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TransactionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "hlfapi.v1.TransactionService",
+	HandlerType: (*TransactionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Invoke",
+			Handler:    _TransactionService_Invoke_Handler,
+		},
+		{
+			MethodName: "Evaluate",
+			Handler:    _TransactionService_Evaluate_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ChaincodeEvents",
+			Handler:       _TransactionService_ChaincodeEvents_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "BlockEvents",
+			Handler:       _TransactionService_BlockEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "hlfapi/v1/hlfapi.proto",
+}